@@ -12,6 +12,7 @@ package lib
 
 import (
 	"bytes"
+	"crypto"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -32,6 +33,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/common"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/streamer"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/tls"
+	factory "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/cryptosuitebridge"
 	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -185,6 +187,12 @@ func (c *Client) GetCAInfo(req *api.GetCAInfoRequest) (*GetCAInfoResponse, error
 
 // GenCSR generates a CSR (Certificate Signing Request)
 func (c *Client) GenCSR(req *api.CSRInfo, id string) ([]byte, core.Key, error) {
+	return c.GenCSRUsingKey(req, id, nil)
+}
+
+// GenCSRUsingKey generates a CSR (Certificate Signing Request) using the key
+// k, a new key is generated if k is nil
+func (c *Client) GenCSRUsingKey(req *api.CSRInfo, id string, k core.Key) ([]byte, core.Key, error) {
 	log.Debugf("GenCSR %+v", req)
 
 	err := c.Init()
@@ -199,10 +207,24 @@ func (c *Client) GenCSR(req *api.CSRInfo, id string) ([]byte, core.Key, error) {
 		cr.KeyRequest = newCfsslBasicKeyRequest(api.NewBasicKeyRequest())
 	}
 
-	key, cspSigner, err := util.BCCSPKeyRequestGenerate(cr, c.csp)
-	if err != nil {
-		log.Debugf("failed generating BCCSP key: %s", err)
-		return nil, nil, err
+	if isSM2KeyRequest(cr.KeyRequest) {
+		return c.genSM2CSR(cr, k)
+	}
+
+	var key core.Key
+	var cspSigner crypto.Signer
+	if k == nil {
+		key, cspSigner, err = util.BCCSPKeyRequestGenerate(cr, c.csp)
+		if err != nil {
+			log.Debugf("failed generating BCCSP key: %s", err)
+			return nil, nil, err
+		}
+	} else {
+		key = k
+		cspSigner, err = factory.NewCspSigner(c.csp, key)
+		if err != nil {
+			return nil, nil, errors.WithMessage(err, "Failed initializing CryptoSigner")
+		}
 	}
 
 	csrPEM, err := csr.Generate(cspSigner, cr)
@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/common"
 	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/pkg/errors"
 )
 
@@ -91,7 +92,14 @@ func (i *Identity) Register(req *api.RegistrationRequest) (rr *api.RegistrationR
 func (i *Identity) Reenroll(req *api.ReenrollmentRequest) (*EnrollmentResponse, error) {
 	log.Debugf("Reenrolling %s", util.StructToString(req))
 
-	csrPEM, key, err := i.client.GenCSR(req.CSR, i.GetName())
+	var csrPEM []byte
+	var key core.Key
+	var err error
+	if req.CSR != nil && req.CSR.KeyRequest != nil && req.CSR.KeyRequest.ReuseKey {
+		csrPEM, key, err = i.client.GenCSRUsingKey(req.CSR, i.GetName(), i.GetECert().Key())
+	} else {
+		csrPEM, key, err = i.client.GenCSR(req.CSR, i.GetName())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -141,6 +149,49 @@ func (i *Identity) Revoke(req *api.RevocationRequest) (*api.RevocationResponse,
 	return &api.RevocationResponse{RevokedCerts: result.RevokedCerts, CRL: crl}, nil
 }
 
+// GenCRL generates CRL
+func (i *Identity) GenCRL(req *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	log.Debugf("Entering identity.GenCRL %+v", req)
+	reqBody, err := util.Marshal(req, "GenCRLRequest")
+	if err != nil {
+		return nil, err
+	}
+	var result genCRLResponseNet
+	err = i.Post("gencrl", reqBody, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Successfully generated CRL: %+v", req)
+	crl, err := util.B64Decode(result.CRL)
+	if err != nil {
+		return nil, err
+	}
+	return &api.GenCRLResponse{CRL: crl}, nil
+}
+
+// GetCertificates returns all certificates that the caller is authorized to see
+func (i *Identity) GetCertificates(req *api.GetCertificatesRequest, cb func(*json.Decoder) error) error {
+	log.Debugf("Entering identity.GetCertificates, sending request: %+v", req)
+
+	queryParam := make(map[string]string)
+	queryParam["id"] = req.ID
+	queryParam["aki"] = req.AKI
+	queryParam["serial"] = req.Serial
+	queryParam["revoked_start"] = req.Revoked.StartTime
+	queryParam["revoked_end"] = req.Revoked.EndTime
+	queryParam["expired_start"] = req.Expired.StartTime
+	queryParam["expired_end"] = req.Expired.EndTime
+	queryParam["notrevoked"] = strconv.FormatBool(req.NotRevoked)
+	queryParam["notexpired"] = strconv.FormatBool(req.NotExpired)
+	queryParam["ca"] = req.CAName
+	err := i.GetStreamResponse("certificates", queryParam, "result.certs", cb)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Successfully completed getting certificates request")
+	return nil
+}
+
 // GetIdentity returns information about the requested identity
 func (i *Identity) GetIdentity(id, caname string) (*api.GetIDResponse, error) {
 	log.Debugf("Entering identity.GetIdentity %s", id)
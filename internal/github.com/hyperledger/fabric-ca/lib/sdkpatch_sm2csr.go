@@ -0,0 +1,103 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net"
+	"net/mail"
+
+	"github.com/cloudflare/cfssl/csr"
+	factory "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/cryptosuitebridge"
+	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/pkg/errors"
+)
+
+// sm2KeyAlgo is the key algorithm of the CSR key requests of SM2 keys
+const sm2KeyAlgo = "sm2"
+
+var oidExtensionSubjectKeyID = asn1.ObjectIdentifier{2, 5, 29, 14}
+
+func isSM2KeyRequest(kr csr.KeyRequest) bool {
+	return kr != nil && kr.Algo() == sm2KeyAlgo
+}
+
+// genSM2CSR generates an SM2 key in the crypto suite of the client, unless
+// key is given, and a CSR signed by this key. cfssl only signs CSRs with
+// crypto/x509, which does not support the SM2 curve
+func (c *Client) genSM2CSR(cr *csr.CertificateRequest, key core.Key) ([]byte, core.Key, error) {
+	if size := cr.KeyRequest.Size(); size != 0 && size != 256 {
+		return nil, nil, errors.Errorf("Invalid SM2 key size: %d", size)
+	}
+
+	if key == nil {
+		log.Infof("generating key: %+v", cr.KeyRequest)
+		var err error
+		key, err = c.csp.KeyGen(factory.GetGMSM2KeyGenOpts(false))
+		if err != nil {
+			return nil, nil, errors.WithMessage(err, "Failed to generate SM2 key")
+		}
+	}
+
+	template := newSM2CertificateRequest(cr)
+	if _, ok := c.csp.(core.RemoteKeyManager); ok {
+		// the remote signer finds the key pair by the SKI of the enrollment
+		// certificate, the CA is asked to issue it with the SKI of the key
+		ski, err := asn1.Marshal(key.SKI())
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Failed to marshal subject key identifier")
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: oidExtensionSubjectKeyID, Value: ski})
+	}
+
+	csrPEM, err := cryptoutil.CreateSM2CertificateRequest(template, key, c.csp)
+	if err != nil {
+		log.Debugf("failed generating SM2 CSR: %s", err)
+		return nil, nil, err
+	}
+	return csrPEM, key, nil
+}
+
+// newSM2CertificateRequest returns the certificate request template of a cfssl
+// certificate request, hosts are mapped to SANs as cfssl does
+func newSM2CertificateRequest(cr *csr.CertificateRequest) *sm2.CertificateRequest {
+	subject := pkix.Name{CommonName: cr.CN, SerialNumber: cr.SerialNumber}
+	for _, name := range cr.Names {
+		subject.Country = appendIf(subject.Country, name.C)
+		subject.Province = appendIf(subject.Province, name.ST)
+		subject.Locality = appendIf(subject.Locality, name.L)
+		subject.Organization = appendIf(subject.Organization, name.O)
+		subject.OrganizationalUnit = appendIf(subject.OrganizationalUnit, name.OU)
+	}
+
+	template := &sm2.CertificateRequest{Subject: subject}
+	for _, host := range cr.Hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if email, err := mail.ParseAddress(host); err == nil && email != nil {
+			template.EmailAddresses = append(template.EmailAddresses, email.Address)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	return template
+}
+
+func appendIf(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	return append(values, value)
+}
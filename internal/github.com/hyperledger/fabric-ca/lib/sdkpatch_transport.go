@@ -0,0 +1,26 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+import (
+	"net/http"
+)
+
+// WrapTransport wraps the HTTP transport of the client, e.g. to fail over
+// between several endpoints of the CA. The client is initialized if needed.
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) error {
+	err := c.Init()
+	if err != nil {
+		return err
+	}
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+	return nil
+}
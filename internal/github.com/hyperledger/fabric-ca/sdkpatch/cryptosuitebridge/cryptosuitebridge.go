@@ -77,6 +77,11 @@ func GetSHA256Opts() core.HashOpts {
 	return &bccsp.SHA256Opts{}
 }
 
+//GetGMSM3Opts returns options for computing SM3.
+func GetGMSM3Opts() core.HashOpts {
+	return &bccsp.GMSM3Opts{}
+}
+
 //GetRSA2048KeyGenOpts returns options for RSA key generation at 2048 security.
 func GetRSA2048KeyGenOpts(ephemeral bool) core.KeyGenOpts {
 	return &bccsp.RSA2048KeyGenOpts{Temporary: ephemeral}
@@ -107,6 +112,11 @@ func GetECDSAP384KeyGenOpts(ephemeral bool) core.KeyGenOpts {
 	return &bccsp.ECDSAP384KeyGenOpts{Temporary: ephemeral}
 }
 
+//GetGMSM2KeyGenOpts returns options for SM2 key generation.
+func GetGMSM2KeyGenOpts(ephemeral bool) core.KeyGenOpts {
+	return &bccsp.GMSM2KeyGenOpts{Temporary: ephemeral}
+}
+
 //GetX509PublicKeyImportOpts options for importing public keys from an x509 certificate
 func GetX509PublicKeyImportOpts(ephemeral bool) core.KeyImportOpts {
 	return &bccsp.X509PublicKeyImportOpts{Temporary: ephemeral}
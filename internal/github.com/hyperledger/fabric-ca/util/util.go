@@ -33,6 +33,8 @@ import (
 	mrand "math/rand"
 
 	factory "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/cryptosuitebridge"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/gm"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 
 	"net/http"
@@ -163,6 +165,11 @@ func CreateToken(csp core.CryptoSuite, cert []byte, key core.Key, method, uri st
 		if err != nil {
 			return "", err
 		}
+	case *sm2.PublicKey:
+		token, err = GenSM2Token(csp, cert, key, method, uri, body, fabCACompatibilityMode)
+		if err != nil {
+			return "", err
+		}
 	}
 	return token, nil
 }
@@ -204,11 +211,26 @@ func GenECDSAToken(csp core.CryptoSuite, cert []byte, key core.Key, method, uri
 		payload = b64body + "." + b64cert
 	}
 
-	return genECDSAToken(csp, key, b64cert, payload)
+	return genECDSAToken(csp, key, b64cert, payload, factory.GetSHAOpts())
 }
 
-func genECDSAToken(csp core.CryptoSuite, key core.Key, b64cert, payload string) (string, error) {
-	digest, digestError := csp.Hash([]byte(payload), factory.GetSHAOpts())
+//GenSM2Token signs the http body and cert with SM2 using SM2 private key,
+// the signature is a plain SM2 signature of the SM3 digest, as for ECDSA
+func GenSM2Token(csp core.CryptoSuite, cert []byte, key core.Key, method, uri string, body []byte, fabCACompatibilityMode bool) (string, error) {
+	b64body := B64Encode(body)
+	b64cert := B64Encode(cert)
+	b64uri := B64Encode([]byte(uri))
+	payload := method + "." + b64uri + "." + b64body + "." + b64cert
+
+	if fabCACompatibilityMode {
+		payload = b64body + "." + b64cert
+	}
+
+	return genECDSAToken(csp, key, b64cert, payload, factory.GetGMSM3Opts())
+}
+
+func genECDSAToken(csp core.CryptoSuite, key core.Key, b64cert, payload string, hashOpts core.HashOpts) (string, error) {
+	digest, digestError := csp.Hash([]byte(payload), hashOpts)
 	if digestError != nil {
 		return "", errors.WithMessage(digestError, fmt.Sprintf("Hash failed on '%s'", payload))
 	}
@@ -262,7 +284,12 @@ func GetX509CertificateFromPEM(cert []byte) (*x509.Certificate, error) {
 	}
 	x509Cert, err := x509.ParseCertificate(block.Bytes)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error parsing certificate")
+		// crypto/x509 does not support the SM2 curve
+		sm2Cert, sm2Err := sm2.ParseCertificate(block.Bytes)
+		if sm2Err != nil {
+			return nil, errors.Wrap(err, "Error parsing certificate")
+		}
+		return gm.ParseSm2Certificate2X509(sm2Cert), nil
 	}
 	return x509Cert, nil
 }
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"golang.org/x/crypto/sha3"
 	"hash"
+	"time"
 	
 	"github.com/tjfoc/gmsm/sm3"
 )
@@ -107,9 +108,35 @@ type CNCC_GMOpts struct {
 	Password   string `mapstructure:"password" json:"password" yaml:"Password"`
 	Sensitive  bool   `mapstructure:"sensitivekeys,omitempty" json:"sensitivekeys,omitempty" yaml:"Sensitive"`
 	SoftVerify bool   `mapstructure:"softwareverify,omitempty" json:"softwareverify,omitempty" yaml:"SoftVerify"`
+	
+	// NetSign pool options
+	SessionCacheSize    int           `mapstructure:"sessioncachesize,omitempty" json:"sessioncachesize,omitempty" yaml:"SessionCacheSize"`
+	FailureThreshold    int           `mapstructure:"failurethreshold,omitempty" json:"failurethreshold,omitempty" yaml:"FailureThreshold"`
+	OpenCircuitTimeout  time.Duration `mapstructure:"opencircuittimeout,omitempty" json:"opencircuittimeout,omitempty" yaml:"OpenCircuitTimeout"`
+	HealthCheckInterval time.Duration `mapstructure:"healthcheckinterval,omitempty" json:"healthcheckinterval,omitempty" yaml:"HealthCheckInterval"`
+
+	// Remote signer options: netsign (default), http (over HTTPS) or grpc (over TLS)
+	RemoteSigner string `mapstructure:"remotesigner,omitempty" json:"remotesigner,omitempty" yaml:"RemoteSigner"`
+	// RemoteSignerTimeout is the timeout of the calls to the http and grpc remote signers, 10s by default
+	RemoteSignerTimeout time.Duration `mapstructure:"remotesignertimeout,omitempty" json:"remotesignertimeout,omitempty" yaml:"RemoteSignerTimeout"`
+	// RemoteSignerTLS configures the connections to the http and grpc remote signers
+	RemoteSignerTLS *RemoteSignerTLSOpts `mapstructure:"remotesignertls,omitempty" json:"remotesignertls,omitempty" yaml:"RemoteSignerTLS"`
+}
+
+// RemoteSignerTLSOpts configures the TLS connections to the http and grpc remote signers.
+// The system roots are trusted when no CA certificate is given.
+type RemoteSignerTLSOpts struct {
+	CACertFiles    []string `mapstructure:"cacerts,omitempty" json:"cacerts,omitempty" yaml:"CACerts"`
+	ServerName     string   `mapstructure:"servername,omitempty" json:"servername,omitempty" yaml:"ServerName"`
+	ClientCertFile string   `mapstructure:"clientcert,omitempty" json:"clientcert,omitempty" yaml:"ClientCert"`
+	ClientKeyFile  string   `mapstructure:"clientkey,omitempty" json:"clientkey,omitempty" yaml:"ClientKey"`
+	// Insecure connects without TLS, sessions can then only be opened without a password
+	Insecure bool `mapstructure:"insecure,omitempty" json:"insecure,omitempty" yaml:"Insecure"`
 }
 type FileKeystoreOpts struct {
 	KeyStorePath string `mapstructure:"keystore" json:"keystore" yaml:"KeyStore"`
+	// Password 用于加密保存在本地的 sm4 key，为空时明文保存
+	Password []byte `mapstructure:"password,omitempty" json:"-" yaml:"Password"`
 }
 type DummyKeystoreOpts struct{}
 
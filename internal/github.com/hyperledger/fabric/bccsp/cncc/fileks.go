@@ -100,53 +100,25 @@ func (ks *fileBasedKeyStore) ReadOnly() bool {
 
 
 // GetKey returns a key object whose SKI is the one passed.
+// Only sm4 keys are stored locally, sm2 keys are held by the signing server.
 func (ks *fileBasedKeyStore) GetKey(ski []byte) (k bccsp.Key, err error) {
 	// Validate arguments
-	//if len(ski) == 0 {
-	//	return nil, errors.New("Invalid SKI. Cannot be of zero length.")
-	//}
-	//
-	//suffix := ks.getSuffix(hex.EncodeToString(ski))
-	//
-	//switch suffix {
-	//case "key":
-	//	// Load the key
-	//	key, err := ks.loadKey(hex.EncodeToString(ski))
-	//	if err != nil {
-	//		return nil, fmt.Errorf("Failed loading key [%x] [%s]", ski, err)
-	//	}
-	//
-	//	return &gmsm4PrivateKey{key, false}, nil
-	//case "sk":
-	//	// Load the private key
-	//	key, err := ks.loadPrivateKey(hex.EncodeToString(ski))
-	//	if err != nil {
-	//		return nil, fmt.Errorf("Failed loading secret key [%x] [%s]", ski, err)
-	//	}
-	//
-	//	switch key.(type) {
-	//	case *sm2.PrivateKey:
-	//		return &gmsm2PrivateKey{key.(*sm2.PrivateKey)}, nil
-	//	default:
-	//		return nil, errors.New("Secret key type not recognized")
-	//	}
-	//case "pk":
-	//	// Load the public key
-	//	key, err := ks.loadPublicKey(hex.EncodeToString(ski))
-	//	if err != nil {
-	//		return nil, fmt.Errorf("Failed loading public key [%x] [%s]", ski, err)
-	//	}
-	//
-	//	switch key.(type) {
-	//	case *sm2.PublicKey:
-	//		return &gmsm2PublicKey{key.(*sm2.PublicKey)}, nil
-	//	default:
-	//		return nil, errors.New("Public key type not recognized")
-	//	}
-	//default:
-	//	return ks.searchKeystoreForSKI(ski)
-	//}
-	return nil, nil
+	if len(ski) == 0 {
+		return nil, errors.New("Invalid SKI. Cannot be of zero length.")
+	}
+	
+	switch ks.getSuffix(hex.EncodeToString(ski)) {
+	case "key":
+		// Load the key
+		key, err := ks.loadKey(hex.EncodeToString(ski))
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading key [%x] [%s]", ski, err)
+		}
+		
+		return &gmsm4PrivateKey{key, false}, nil
+	default:
+		return nil, fmt.Errorf("Key with SKI [%x] not found in [%s]", ski, ks.path)
+	}
 }
 
 // StoreKey stores the key k in this KeyStore.
@@ -1,12 +1,12 @@
 package cncc
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/sha512"
 	"fmt"
-	"github.com/hyperledger/fabric-sdk-go/internal/github.com/TaurusWei/go-netsign/netsign"
-	
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/gm"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
@@ -14,7 +14,6 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 	"hash"
-	"strconv"
 )
 /**
  * @Author: WeiBingtao/13156050650@163.com
@@ -25,21 +24,25 @@ import (
 
 
 var (
-	logger            = flogging.MustGetLogger("cncc_gm")
-	SessionCacheSize  = 10
-	BJ_NetSignConfig  []*NetSignConfig
-	SH_NetSignConfig  []*NetSignConfig
-	BAK_NetSignConfig []*NetSignConfig
+	logger = flogging.MustGetLogger("cncc_gm")
 )
 
 type NetSignConfig struct {
-	Ip     string
-	Port   string
-	Passwd string
+	Ip         string
+	Port       string
+	Passwd     string
+	DataCenter string // 所属数据中心：BJ、SH 或 BAK
+}
+//打印签名服务器配置时不输出密码
+func (c *NetSignConfig) String() string {
+	return fmt.Sprintf("{DataCenter:%s Ip:%s Port:%s}", c.DataCenter, c.Ip, c.Port)
 }
+
 type NetSignSesssion struct {
-	NSC       *NetSignConfig
-	NS_sesion int
+	NSC *NetSignConfig
+
+	remote bccsp.RemoteSignerSession // 在签名服务器上打开的会话
+	server *netSignServer
 }
 
 type Impl struct {
@@ -48,8 +51,7 @@ type Impl struct {
 	conf *config        // conf配置
 	ks   bccsp.KeyStore // key存储对象，用于存储及获取key
 	
-	netsign  *netsign.NetSign      // 签名服务器实例
-	Sessions chan *NetSignSesssion // 会话标识符通道，默认10（sessionCacheSize = 10）
+	pool *netSignPool // 签名服务器会话池，按 BJ、SH、BAK 的顺序故障切换
 	
 	noPrivImport bool // 是否禁止导入私钥
 	softVerify   bool // 是否以软件方式验证签名
@@ -57,6 +59,19 @@ type Impl struct {
 }
 
 func New(opts CNCC_GMOpts, keyStore bccsp.KeyStore) (bccsp.BCCSP, error) {
+	signer, err := newRemoteSigner(opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithRemoteSigner(opts, keyStore, signer)
+}
+
+//使用指定的签名服务（如 KMS 或自研签名服务）创建 BCCSP，签名服务器地址仍由 opts 配置
+func NewWithRemoteSigner(opts CNCC_GMOpts, keyStore bccsp.KeyStore, signer bccsp.RemoteSigner) (bccsp.BCCSP, error) {
+	if signer == nil {
+		return nil, errors.New("Invalid bccsp.RemoteSigner instance. It must be different from nil.")
+	}
+	
 	// Init config
 	conf := &config{}
 	err := conf.setSecurityLevel(opts.SecLevel, opts.HashFamily)
@@ -74,44 +89,39 @@ func New(opts CNCC_GMOpts, keyStore bccsp.KeyStore) (bccsp.BCCSP, error) {
 		return nil, errors.New("Invalid bccsp.KeyStore instance. It must be different from nil.")
 	}
 	
-	FindPKCS11Lib(opts)
-	
-	sessions := make(chan *NetSignSesssion, len(BJ_NetSignConfig)*SessionCacheSize)
-	NetSign := netsign.NetSign{}
-	var ok bool
-	// 初始化10个会话句柄
-	for i := 0; i < SessionCacheSize; i++ {
-		
-		for _, netSignConfig := range BJ_NetSignConfig {
-			
-			ip := netSignConfig.Ip
-			passwd := netSignConfig.Passwd
-			
-			port, err := strconv.Atoi(netSignConfig.Port)
-			if err != nil {
-				panic("Get port error !")
-			}
-			socketFd, ret := NetSign.OpenNetSign(ip, passwd, port)
-			if ret != 0 {
-				logger.Errorf("LOGGER-CONN-SIGNAGENT-FAIL: open netsign err: ip [%s], port [%d], passwd [%s]", ip, port, passwd)
-				continue
-			}
-			sessions <- &NetSignSesssion{netSignConfig, socketFd}
-			ok = true
-			break
-		}
+	netSignConfigs, err := LoadNetSignConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := newNetSignPool(netSignConfigs, opts, signer)
+	if err != nil {
+		return nil, err
 	}
-	if !ok {
-		return nil, errors.New("no netsign avaliable!")
+	// 初始化会话句柄，至少需要一个可用的签名服务器
+	if err := pool.fill(cap(pool.sessions)); err != nil {
+		return nil, err
 	}
-	csp := &Impl{swCSP, conf, keyStore, &NetSign, sessions, opts.Sensitive, opts.SoftVerify}
+	pool.startHealthCheck(opts.HealthCheckInterval)
+	logger.Infof("Initialized NetSign pool: %s", pool)
+	
+	csp := &Impl{swCSP, conf, keyStore, pool, opts.Sensitive, opts.SoftVerify}
 	return csp, nil
 }
+
+//关闭签名服务器会话池，停止健康检查
+func (csp *Impl) Close() {
+	csp.pool.close()
+}
 //上传证书
 func(csp *Impl)Uploadcert(ski []byte,certBytes []byte)error{
 	
 	return csp.uploadCert(ski,certBytes)
 }
+//删除签名服务器上的密钥对，用于注销身份或重新登记后废弃旧的密钥标签
+func (csp *Impl) DeleteKeyPair(ski []byte) error {
+	_, err := csp.deleteKeyPair(ski)
+	return err
+}
 
 //根据key生成选项opts生成一个key
 func (csp *Impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
@@ -130,14 +140,14 @@ func (csp *Impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
 		k = &gmsm2PrivateKey{ski, gmsm2PublicKey{ski, pub}}
 	
 	case *bccsp.GMSM4KeyGenOpts:
-		// todo
-		ski, pub, err := csp.generateSM2Key(opts.Ephemeral())
+		//sm4 密钥在本地以软件方式生成，签名服务器只保存 sm2 密钥
+		key, err := GetRandomBytes(16)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Failed generating sm4 key")
 		}
-		k = &gmsm2PrivateKey{ski, gmsm2PublicKey{ski, pub}}
+		k = &gmsm4PrivateKey{key, false}
 	default:
-		return nil, errors.New("Key type not recognized. Supported keys: [SM2]")
+		return nil, errors.New("Key type not recognized. Supported keys: [SM2, SM4]")
 	}
 	// If the key is not Ephemeral, store it.
 	if !opts.Ephemeral() {
@@ -227,6 +237,12 @@ func (csp *Impl) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (k bccsp.K
 		} else {
 			return nil, fmt.Errorf("Failed converting to SM2 key [%s]", err)
 		}
+	case *bccsp.GMSM4ImportKeyOpts:
+		sm4Raw, ok := raw.([]byte)
+		if !ok || len(sm4Raw) == 0 {
+			return nil, errors.New("[GMSM4ImportKeyOpts] Invalid raw material. Expected byte array.")
+		}
+		return &gmsm4PrivateKey{append([]byte{}, sm4Raw...), false}, nil
 	default:
 		return nil, errors.New("Import Key Options not recognized")
 	}
@@ -238,6 +254,15 @@ func (csp *Impl) Hash(msg []byte, opts bccsp.HashOpts) (digest []byte, err error
 		return nil, errors.New("Invalid opts. It must not be nil.")
 	}
 	
+	if _, ok := opts.(*bccsp.SM3HMACOpts); ok {
+		mac, err := csp.GetHash(opts)
+		if err != nil {
+			return nil, err
+		}
+		mac.Write(msg)
+		return mac.Sum(nil), nil
+	}
+	
 	hash := sm3.New()
 	hash.Write(msg)
 	return hash.Sum(nil), nil
@@ -250,7 +275,7 @@ func (csp *Impl) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
 	if opts == nil {
 		return csp.conf.hashFunction(), nil
 	}
-	switch opts.(type) {
+	switch o := opts.(type) {
 	case *bccsp.SHAOpts:
 		return csp.conf.hashFunction(), nil
 	case *bccsp.SHA256Opts:
@@ -264,6 +289,13 @@ func (csp *Impl) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
 	case *bccsp.GMSM3Opts:
 		return sm3.New(), nil
 		//return nil, errors.New("Usage: bccsp.Hash(msg, &bccsp.SM3Opts{})")
+	case *bccsp.SM3HMACOpts:
+		//HMAC 密钥为本地的 sm4 key
+		key, ok := o.Key.(*gmsm4PrivateKey)
+		if !ok {
+			return nil, errors.Errorf("Unsupported HMAC key provided [%v]. Expected an SM4 key", o.Key)
+		}
+		return hmac.New(sm3.New, key.privKey), nil
 	default:
 		return nil, fmt.Errorf("Algorithm not recognized [%s]", opts.Algorithm())
 	}
@@ -271,6 +303,12 @@ func (csp *Impl) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
 
 //根据SKI返回与该接口实例有联系的key
 func (csp *Impl) GetKey(ski []byte) (k bccsp.Key, err error) {
+	//sm4 key 保存在本地 keystore 中，sm2 key 保存在签名服务器中
+	if k, err := csp.ks.GetKey(ski); err == nil && k != nil {
+		if _, ok := k.(*gmsm4PrivateKey); ok {
+			return k, nil
+		}
+	}
 	
 	pub, isPriv, err := csp.getSM2Key(ski)
 	
@@ -310,13 +348,13 @@ func (csp *Impl) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.Signer
 		
 		//verify := sm2.Verify(puk, digest, sig.R, sig.S)
 		//logger.Infof("soft label [%s]\n", "SM2SignKey"+string(k.SKI()))
-		return csp.verifyP11SM2(k.SKI(), digest, signature)
+		return csp.verifyP11SM2(k.SKI(), digest, signature, signerUID(opts))
 	case *gmsm2PublicKey:
-		//puk := k.(*gmsm2PublicKey).pubKey
-		
-		//verify := sm2.Verify(puk, digest, sig.R, sig.S)
-		//logger.Infof("soft label [%s]\n", "SM2SignKey"+string(k.SKI()))
-		return csp.verifyP11SM2(k.SKI(), digest,  signature)
+		//公钥（如从证书导入的公钥）可以在本地验证签名，私钥句柄仍由签名服务器验证
+		if csp.softVerify {
+			return verifySM2(k.(*gmsm2PublicKey).pubKey, signature, digest, signerUID(opts))
+		}
+		return csp.verifyP11SM2(k.SKI(), digest,  signature, signerUID(opts))
 	default:
 		return false, errors.New("Key type not recognized. Supported keys: [SM2 Key]")
 	}
@@ -340,7 +378,7 @@ func (csp *Impl) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signat
 		//if err != nil {
 		//	return nil, err
 		//}
-		return csp.signP11SM2(k.SKI(), digest)
+		return csp.signP11SM2(k.SKI(), digest, signerUID(opts))
 	default:
 		return nil, errors.New("Key type not recognized. Supported keys: [SM2 Private Key]")
 	}
@@ -350,61 +388,72 @@ func (csp *Impl) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signat
 
 //根据加密者选项opts，使用k加密plaintext
 func (csp *Impl) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) (ciphertext []byte, err error) {
-	//// Validate arguments
-	//if k == nil {
-	//	return nil, errors.New("Invalid Key. It must not be nil.")
-	//}
-	//
-	//encryptor, found := csp.encryptors[reflect.TypeOf(k)]
-	//if !found {
-	//	return nil, errors.Errorf("Unsupported 'EncryptKey' provided [%v]", k)
-	//}
-	//
-	//return encryptor.Encrypt(k, plaintext, opts)
-	// todo
-	return nil,nil
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+	
+	switch key := k.(type) {
+	case *gmsm2PublicKey:
+		//公钥在本地，加密无需签名服务器
+		return bccsp.EncryptSM2(key.pubKey, plaintext, opts)
+	case *gmsm4PrivateKey:
+		return (&gmsm4Encryptor{}).Encrypt(k, plaintext, opts)
+	default:
+		return nil, errors.Errorf("Unsupported 'EncryptKey' provided [%v]", k)
+	}
 }
 
 //根据解密者选项opts，使用k对ciphertext进行解密
 func (csp *Impl) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) (plaintext []byte, err error) {
-	//// Validate arguments
-	//if k == nil {
-	//	return nil, errors.New("Invalid Key. It must not be nil.")
-	//}
-	//
-	//decryptor, found := csp.decryptors[reflect.TypeOf(k)]
-	//if !found {
-	//	return nil, errors.Errorf("Unsupported 'DecryptKey' provided [%v]", k)
-	//}
-	//
-	//plaintext, err = decryptor.Decrypt(k, ciphertext, opts)
-	//if err != nil {
-	//	return nil, errors.Wrapf(err, "Failed decrypting with opts [%v]", opts)
-	//}
-	//
-	//return
-	// todo
-	return nil,nil
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+	
+	switch k.(type) {
+	case *gmsm4PrivateKey:
+		plaintext, err = (&gmsm4Decryptor{}).Decrypt(k, ciphertext, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed decrypting with opts [%v]", opts)
+		}
+		return plaintext, nil
+	case *gmsm2PrivateKey:
+		//sm2 私钥保存在签名服务器中，签名服务不提供解密
+		return nil, errors.New("SM2 decryption is not supported: the private key is held by the signing server")
+	default:
+		return nil, errors.Errorf("Unsupported 'DecryptKey' provided [%v]", k)
+	}
 }
-//根据解密者选项opts，使用k对ciphertext进行解密
-func (csp *Impl) KeyDeriv (k bccsp.Key, opts bccsp.KeyDerivOpts) (dk bccsp.Key, err error){
-	//// Validate arguments
-	//if k == nil {
-	//	return nil, errors.New("Invalid Key. It must not be nil.")
-	//}
-	//
-	//decryptor, found := csp.decryptors[reflect.TypeOf(k)]
-	//if !found {
-	//	return nil, errors.Errorf("Unsupported 'DecryptKey' provided [%v]", k)
-	//}
-	//
-	//plaintext, err = decryptor.Decrypt(k, ciphertext, opts)
-	//if err != nil {
-	//	return nil, errors.Wrapf(err, "Failed decrypting with opts [%v]", opts)
-	//}
-	//
-	//return
-	// todo
-	return nil,nil
+//根据key派生选项opts从k中派生一个新的sm4 key，sm2 私钥保存在签名服务器中，不能用于派生
+func (csp *Impl) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (dk bccsp.Key, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+	if opts == nil {
+		return nil, errors.New("Invalid opts. It must not be nil.")
+	}
+	
+	switch key := k.(type) {
+	case *gmsm4PrivateKey:
+		raw, err := gm.SM3DeriveKey(key.privKey, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed deriving key with opts [%v]", opts)
+		}
+		dk = &gmsm4PrivateKey{raw, false}
+	default:
+		return nil, errors.Errorf("Unsupported 'Key' provided [%v]", k)
+	}
+	
+	// If the key is not Ephemeral, store it.
+	if !opts.Ephemeral() {
+		err = csp.ks.StoreKey(dk)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed storing key [%s]", opts.Algorithm())
+		}
+	}
+	
+	return dk, nil
 }
 
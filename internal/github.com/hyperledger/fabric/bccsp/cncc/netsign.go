@@ -0,0 +1,234 @@
+package cncc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/TaurusWei/go-netsign/netsign"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/remotesigner"
+	"github.com/pkg/errors"
+)
+
+/**
+ * NetSign implementation of bccsp.RemoteSigner
+ */
+
+// netSignError is a non-zero NetSign return code
+type netSignError struct {
+	operation string
+	ret       int
+}
+
+func (e *netSignError) Error() string {
+	return "NetSign " + e.operation + " failed [" + strconv.Itoa(e.ret) + "]"
+}
+
+// Timeout reports whether the call timed out, see bccsp.IsRemoteSignerTimeout
+func (e *netSignError) Timeout() bool {
+	return e.ret == netSignTimeout
+}
+
+// Unreachable reports whether the server could not be connected to, see bccsp.IsRemoteSignerUnavailable
+func (e *netSignError) Unreachable() bool {
+	return e.ret == netSignConnectFailed || e.ret == -netSignConnectFailed
+}
+
+func netSignResult(operation string, ret int) error {
+	if ret == 0 {
+		return nil
+	}
+	return &netSignError{operation: operation, ret: ret}
+}
+
+// 根据配置选择签名服务的实现，默认为 NetSign
+func newRemoteSigner(opts CNCC_GMOpts) (bccsp.RemoteSigner, error) {
+	switch strings.ToLower(opts.RemoteSigner) {
+	case "", "netsign":
+		return NewNetSignSigner(), nil
+	case "http":
+		return newHTTPSigner(opts)
+	case "grpc":
+		return newGRPCSigner(opts)
+	default:
+		return nil, errors.Errorf("unsupported remote signer [%s]", opts.RemoteSigner)
+	}
+}
+
+func newHTTPSigner(opts CNCC_GMOpts) (bccsp.RemoteSigner, error) {
+	var httpOpts []remotesigner.HTTPOption
+	if opts.RemoteSignerTimeout > 0 {
+		httpOpts = append(httpOpts, remotesigner.WithHTTPTimeout(opts.RemoteSignerTimeout))
+	}
+	if opts.RemoteSignerTLS != nil && opts.RemoteSignerTLS.Insecure {
+		httpOpts = append(httpOpts, remotesigner.WithInsecureHTTP())
+	} else {
+		tlsConfig, err := remoteSignerTLSConfig(opts.RemoteSignerTLS)
+		if err != nil {
+			return nil, err
+		}
+		httpOpts = append(httpOpts, remotesigner.WithTLSConfig(tlsConfig))
+	}
+
+	signer, err := remotesigner.NewHTTPSigner(httpOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+func newGRPCSigner(opts CNCC_GMOpts) (bccsp.RemoteSigner, error) {
+	var grpcOpts []remotesigner.GRPCOption
+	if opts.RemoteSignerTimeout > 0 {
+		grpcOpts = append(grpcOpts, remotesigner.WithGRPCTimeout(opts.RemoteSignerTimeout))
+	}
+	if opts.RemoteSignerTLS != nil && opts.RemoteSignerTLS.Insecure {
+		grpcOpts = append(grpcOpts, remotesigner.WithInsecureGRPC())
+	} else {
+		tlsConfig, err := remoteSignerTLSConfig(opts.RemoteSignerTLS)
+		if err != nil {
+			return nil, err
+		}
+		grpcOpts = append(grpcOpts, remotesigner.WithGRPCTLSConfig(tlsConfig))
+	}
+
+	signer, err := remotesigner.NewGRPCSigner(grpcOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// remoteSignerTLSConfig returns the TLS config of the connections to the remote signer
+func remoteSignerTLSConfig(opts *RemoteSignerTLSOpts) (*tls.Config, error) {
+	config := &tls.Config{}
+	if opts == nil {
+		return config, nil
+	}
+	config.ServerName = opts.ServerName
+
+	if len(opts.CACertFiles) > 0 {
+		config.RootCAs = x509.NewCertPool()
+		for _, file := range opts.CACertFiles {
+			pemCerts, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read remote signer CA certificate [%s]", file)
+			}
+			if !config.RootCAs.AppendCertsFromPEM(pemCerts) {
+				return nil, errors.Errorf("no certificate found in remote signer CA certificate [%s]", file)
+			}
+		}
+	}
+
+	if opts.ClientCertFile != "" || opts.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load remote signer client certificate")
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// NetSignSigner is the bccsp.RemoteSigner of NetSign servers
+type NetSignSigner struct{}
+
+// NewNetSignSigner returns a bccsp.RemoteSigner talking to NetSign servers
+func NewNetSignSigner() *NetSignSigner {
+	return &NetSignSigner{}
+}
+
+// OpenSession opens a NetSign session
+func (s *NetSignSigner) OpenSession(address, password string) (bccsp.RemoteSignerSession, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid NetSign address [%s]", address)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid NetSign port [%s]", portStr)
+	}
+
+	// every session has its own client since the client keeps the address of the server it opened
+	ns := &netsign.NetSign{}
+	socketFd, ret := ns.OpenNetSign(host, password, port)
+	if err := netSignResult("open", ret); err != nil {
+		return nil, errors.WithMessage(err, "LOGGER-CONN-SIGNAGENT-FAIL: open netsign err: ip ["+host+"], port ["+portStr+"]")
+	}
+	return &netSignSession{ns: ns, socketFd: socketFd}, nil
+}
+
+// CheckStatus probes the NetSign servers
+func (s *NetSignSigner) CheckStatus(addresses []string) []error {
+	errs := make([]error, len(addresses))
+	for i, ret := range netsign.CheckAllNetsignStatus(addresses, len(addresses)) {
+		if i < len(errs) {
+			errs[i] = netSignResult("status check", ret)
+		}
+	}
+	return errs
+}
+
+type netSignSession struct {
+	ns       *netsign.NetSign
+	socketFd int
+}
+
+func (s *netSignSession) GenerateKeyPair(keyLabel, subject string) ([]byte, error) {
+	p10, ret := s.ns.GenP10(s.socketFd, subject, keyLabel, "SM2")
+	if err := netSignResult("generate P10", ret); err != nil {
+		return nil, err
+	}
+
+	// NetSign returns the request PEM encoded, or as plain base64
+	replace1 := strings.Replace(string(p10), "-----BEGIN CERTIFICATE REQUEST-----", "", -1)
+	replace2 := strings.Replace(replace1, "-----END CERTIFICATE REQUEST-----", "", -1)
+	replace := strings.Replace(replace2, "\n", "", -1)
+	csr, err := base64.StdEncoding.DecodeString(replace)
+	if err != nil {
+		return nil, errors.Wrap(err, "base64 decode error")
+	}
+	return csr, nil
+}
+
+func (s *netSignSession) BindCertificate(keyLabel string, cert []byte) error {
+	return netSignResult("upload cert", s.ns.UploadCert(s.socketFd, keyLabel, cert))
+}
+
+func (s *netSignSession) Sign(keyLabel string, digest []byte) ([]byte, error) {
+	signature, ret := s.ns.Sign(s.socketFd, 0, digest, keyLabel, "sm3")
+	if err := netSignResult("sign", ret); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+func (s *netSignSession) Verify(keyLabel string, digest, signature []byte) (bool, error) {
+	// NetSign does not tell an invalid signature apart from other errors
+	if err := netSignResult("verify", s.ns.Verify(s.socketFd, 1, digest, signature, keyLabel, "sm3")); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *netSignSession) Hash(msg []byte) ([]byte, error) {
+	digest, ret := s.ns.Hash(s.socketFd, "sm3", msg)
+	if err := netSignResult("hash", ret); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+func (s *netSignSession) DeleteKeyPair(keyLabel string) error {
+	_, ret := s.ns.DeleteKeyPair(s.socketFd, keyLabel)
+	return netSignResult("delete key pair", ret)
+}
+
+func (s *netSignSession) Close() error {
+	return netSignResult("close", s.ns.CloseNetSign(s.socketFd))
+}
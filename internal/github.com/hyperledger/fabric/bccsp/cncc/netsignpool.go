@@ -0,0 +1,352 @@
+package cncc
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/pkg/errors"
+)
+
+/**
+ * Signing server session pool: sessions are opened on the servers of the primary (BJ)
+ * data center first, then on the ones of the secondary (SH) and backup (BAK)
+ * data centers. A server that keeps timing out or refusing connections, or that fails a health probe,
+ * is skipped by a circuit breaker until it recovers.
+ */
+
+const (
+	// DataCenterBJ is the primary data center
+	DataCenterBJ = "BJ"
+	// DataCenterSH is the secondary data center
+	DataCenterSH = "SH"
+	// DataCenterBAK is the backup data center
+	DataCenterBAK = "BAK"
+
+	// netSignTimeout is the NetSign return code of a connection timeout
+	netSignTimeout = -8034
+	// netSignConnectFailed is the NetSign code of a failed connection, returned
+	// negated by the API calls and as is by the status check
+	netSignConnectFailed = -8003
+
+	defaultSessionCacheSize    = 10
+	defaultFailureThreshold    = 3
+	defaultOpenCircuitTimeout  = 30 * time.Second
+	defaultHealthCheckInterval = 30 * time.Second
+)
+
+// dataCenters lists the data centers in failover order
+var dataCenters = []string{DataCenterBJ, DataCenterSH, DataCenterBAK}
+
+// netSignServer is a NetSign server along with the state of its circuit breaker
+type netSignServer struct {
+	config *NetSignConfig
+
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func (s *netSignServer) address() string {
+	return net.JoinHostPort(s.config.Ip, s.config.Port)
+}
+
+// available returns false while the circuit of the server is open
+func (s *netSignServer) available(now time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return !now.Before(s.openUntil)
+}
+
+func (s *netSignServer) succeeded() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures = 0
+	s.openUntil = time.Time{}
+}
+
+// failed records a failure, opening the circuit once threshold consecutive failures are reached
+func (s *netSignServer) failed(threshold int, openTimeout time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures++
+	if s.failures >= threshold {
+		if s.openUntil.IsZero() || !time.Now().Before(s.openUntil) {
+			logger.Warningf("LOGGER-CONN-SIGNAGENT-FAIL: NetSign server [%s] of data center [%s] is unavailable for %s", s.address(), s.config.DataCenter, openTimeout)
+		}
+		s.openUntil = time.Now().Add(openTimeout)
+	}
+}
+
+// netSignPool caches NetSign sessions across the servers of all data centers
+type netSignPool struct {
+	servers  []*netSignServer
+	sessions chan *NetSignSesssion
+
+	failureThreshold   int
+	openCircuitTimeout time.Duration
+
+	open   func(server *netSignServer) (*NetSignSesssion, error)
+	probe  func(addresses []string) []error
+	signer bccsp.RemoteSigner
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newNetSignPool(configs []*NetSignConfig, opts CNCC_GMOpts, signer bccsp.RemoteSigner) (*netSignPool, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("no NetSign server configured")
+	}
+
+	var servers []*netSignServer
+	for _, config := range configs {
+		if _, err := strconv.Atoi(config.Port); err != nil {
+			return nil, errors.Wrapf(err, "invalid port [%s] for NetSign server [%s]", config.Port, config.Ip)
+		}
+		servers = append(servers, &netSignServer{config: config})
+	}
+
+	sessionCacheSize := opts.SessionCacheSize
+	if sessionCacheSize <= 0 {
+		sessionCacheSize = defaultSessionCacheSize
+	}
+	pool := &netSignPool{
+		servers:            servers,
+		sessions:           make(chan *NetSignSesssion, sessionCacheSize),
+		failureThreshold:   opts.FailureThreshold,
+		openCircuitTimeout: opts.OpenCircuitTimeout,
+		open: func(server *netSignServer) (*NetSignSesssion, error) {
+			remote, err := signer.OpenSession(server.address(), server.config.Passwd)
+			if err != nil {
+				return nil, err
+			}
+			return &NetSignSesssion{NSC: server.config, remote: remote, server: server}, nil
+		},
+		probe:  signer.CheckStatus,
+		signer: signer,
+		stop:   make(chan struct{}),
+	}
+	if pool.failureThreshold <= 0 {
+		pool.failureThreshold = defaultFailureThreshold
+	}
+	if pool.openCircuitTimeout <= 0 {
+		pool.openCircuitTimeout = defaultOpenCircuitTimeout
+	}
+	return pool, nil
+}
+
+// fill opens up to count sessions, failing if none could be opened
+func (p *netSignPool) fill(count int) error {
+	for i := 0; i < count; i++ {
+		session, err := p.openSession(nil)
+		if err != nil {
+			if i == 0 {
+				return err
+			}
+			return nil
+		}
+		p.returnSession(session)
+	}
+	return nil
+}
+
+// getSession returns a cached session of an available server, or opens a new one on the
+// first available server in data center order
+func (p *netSignPool) getSession() (*NetSignSesssion, error) {
+	return p.getSessionExcluding(nil)
+}
+
+// getSessionExcluding is getSession skipping the servers in excluded
+func (p *netSignPool) getSessionExcluding(excluded map[*netSignServer]bool) (*NetSignSesssion, error) {
+	// cached sessions are only scanned once, those of excluded servers are put back afterwards
+	var skipped []*NetSignSesssion
+	defer func() {
+		for _, session := range skipped {
+			p.returnSession(session)
+		}
+	}()
+
+	for i := cap(p.sessions); i > 0; i-- {
+		select {
+		case session := <-p.sessions:
+			if !session.server.available(time.Now()) {
+				// the circuit of the server opened since the session was cached
+				p.closeSession(session)
+				continue
+			}
+			if excluded[session.server] {
+				skipped = append(skipped, session)
+				continue
+			}
+			logger.Debugf("Reusing existing session on [%s]", session.server.address())
+			return session, nil
+		default:
+			return p.openSession(excluded)
+		}
+	}
+	return p.openSession(excluded)
+}
+
+func (p *netSignPool) openSession(excluded map[*netSignServer]bool) (*NetSignSesssion, error) {
+	now := time.Now()
+	for _, server := range p.servers {
+		if excluded[server] || !server.available(now) {
+			continue
+		}
+		session, err := p.open(server)
+		if err != nil {
+			logger.Errorf("%s", err)
+			server.failed(p.failureThreshold, p.openCircuitTimeout)
+			continue
+		}
+		// the failure count is only reset by a successful call, since a server that
+		// accepts connections may still time out
+		logger.Debugf("Created new session on [%s] of data center [%s]", server.address(), server.config.DataCenter)
+		return session, nil
+	}
+	return nil, errors.New("LOGGER-CONN-SIGNAGENT-FAIL: no NetSign server available in any data center")
+}
+
+// returnSession puts the session back in the cache, or closes it if the cache is full
+// or the pool is closed
+func (p *netSignPool) returnSession(session *NetSignSesssion) {
+	select {
+	case <-p.stop:
+		p.closeSession(session)
+		return
+	default:
+	}
+	select {
+	case p.sessions <- session:
+	default:
+		p.closeSession(session)
+	}
+}
+
+func (p *netSignPool) closeSession(session *NetSignSesssion) {
+	if session.remote == nil {
+		return
+	}
+	if err := session.remote.Close(); err != nil {
+		logger.Debugf("Failed closing session on [%s]: %s", session.server.address(), err)
+	}
+}
+
+// releaseSession returns the session after a call that returned err. Sessions whose
+// call failed are evicted; timeouts and connection errors also count against the
+// circuit breaker of the server.
+func (p *netSignPool) releaseSession(session *NetSignSesssion, err error) {
+	switch {
+	case err == nil:
+		session.server.succeeded()
+		p.returnSession(session)
+	case bccsp.IsRemoteSignerUnavailable(err):
+		session.server.failed(p.failureThreshold, p.openCircuitTimeout)
+		p.closeSession(session)
+	default:
+		p.closeSession(session)
+	}
+}
+
+// do runs call with a session, failing over to the next available server when the call times out
+// or the connection fails. The last such error is returned once no server is left to fail over to.
+func (p *netSignPool) do(call func(session *NetSignSesssion) error) (*NetSignSesssion, error) {
+	var last *NetSignSesssion
+	var lastErr error
+	unavailable := make(map[*netSignServer]bool)
+	for {
+		session, err := p.getSessionExcluding(unavailable)
+		if err != nil {
+			if last != nil {
+				return last, lastErr
+			}
+			return nil, err
+		}
+		err = call(session)
+		p.releaseSession(session, err)
+		if !bccsp.IsRemoteSignerUnavailable(err) {
+			return session, err
+		}
+		last, lastErr = session, err
+		unavailable[session.server] = true
+	}
+}
+
+// checkHealth probes all the servers, closing the circuit of the ones that are up and
+// opening the circuit of the ones that are down
+func (p *netSignPool) checkHealth() {
+	addresses := make([]string, len(p.servers))
+	for i, server := range p.servers {
+		addresses[i] = server.address()
+	}
+
+	status := p.probe(addresses)
+	for i, server := range p.servers {
+		if i < len(status) && status[i] == nil {
+			server.succeeded()
+			continue
+		}
+		logger.Warningf("LOGGER-CONN-SIGNAGENT-FAIL: health check failed for NetSign server [%s] of data center [%s]", server.address(), server.config.DataCenter)
+		server.failed(1, p.openCircuitTimeout)
+	}
+}
+
+// startHealthCheck probes the servers every interval until the pool is closed
+func (p *netSignPool) startHealthCheck(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.checkHealth()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// close stops the health check and closes the cached sessions
+func (p *netSignPool) close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		for {
+			select {
+			case session := <-p.sessions:
+				p.closeSession(session)
+			default:
+				p.closeSigner()
+				return
+			}
+		}
+	})
+}
+
+// closeSigner releases the resources of the remote signer, e.g. the connections of the gRPC signer
+func (p *netSignPool) closeSigner() {
+	closer, ok := p.signer.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		logger.Debugf("Failed closing remote signer: %s", err)
+	}
+}
+
+// String describes the servers of the pool, without their passwords
+func (p *netSignPool) String() string {
+	s := ""
+	for _, server := range p.servers {
+		s += fmt.Sprintf("%s[%s] ", server.config.DataCenter, server.address())
+	}
+	return s
+}
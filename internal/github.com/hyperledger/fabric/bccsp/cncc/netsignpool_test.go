@@ -0,0 +1,329 @@
+package cncc
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+)
+
+// testRemoteSession is a bccsp.RemoteSignerSession whose calls all succeed
+type testRemoteSession struct{}
+
+func (s *testRemoteSession) GenerateKeyPair(keyLabel, subject string) ([]byte, error) {
+	return []byte("csr"), nil
+}
+
+func (s *testRemoteSession) BindCertificate(keyLabel string, cert []byte) error { return nil }
+
+func (s *testRemoteSession) Sign(keyLabel string, digest []byte) ([]byte, error) {
+	return []byte("signature"), nil
+}
+
+func (s *testRemoteSession) Verify(keyLabel string, digest, signature []byte) (bool, error) {
+	return true, nil
+}
+
+func (s *testRemoteSession) DeleteKeyPair(keyLabel string) error { return nil }
+
+func (s *testRemoteSession) Close() error { return nil }
+
+// newTestPool returns a pool over one server per data center; servers listed in down fail to open
+func newTestPool(t *testing.T, down map[string]bool) *netSignPool {
+	configs := []*NetSignConfig{
+		{Ip: "10.0.0.1", Port: "50060", DataCenter: DataCenterBJ},
+		{Ip: "10.1.0.1", Port: "50060", DataCenter: DataCenterSH},
+		{Ip: "10.2.0.1", Port: "50060", DataCenter: DataCenterBAK},
+	}
+	pool, err := newNetSignPool(configs, CNCC_GMOpts{SessionCacheSize: 2, FailureThreshold: 2, OpenCircuitTimeout: time.Hour}, NewNetSignSigner())
+	if err != nil {
+		t.Fatalf("newNetSignPool failed: %s", err)
+	}
+	pool.open = func(server *netSignServer) (*NetSignSesssion, error) {
+		if down[server.config.DataCenter] {
+			return nil, errors.New("connection refused")
+		}
+		return &NetSignSesssion{NSC: server.config, remote: &testRemoteSession{}, server: server}, nil
+	}
+	return pool
+}
+
+func TestNetSignPoolFailover(t *testing.T) {
+	down := map[string]bool{DataCenterBJ: true}
+	pool := newTestPool(t, down)
+
+	session, err := pool.getSession()
+	if err != nil {
+		t.Fatalf("getSession failed: %s", err)
+	}
+	if session.NSC.DataCenter != DataCenterSH {
+		t.Fatalf("expected failover to %s, got %s", DataCenterSH, session.NSC.DataCenter)
+	}
+	pool.returnSession(session)
+
+	down[DataCenterSH] = true
+	if _, err := pool.getSession(); err != nil {
+		t.Fatalf("cached session should be reused: %s", err)
+	}
+	session, err = pool.getSession()
+	if err != nil {
+		t.Fatalf("getSession failed: %s", err)
+	}
+	if session.NSC.DataCenter != DataCenterBAK {
+		t.Fatalf("expected failover to %s, got %s", DataCenterBAK, session.NSC.DataCenter)
+	}
+
+	down[DataCenterBAK] = true
+	if _, err := pool.getSession(); err == nil {
+		t.Fatal("expected error when every data center is down")
+	}
+}
+
+func TestNetSignPoolCircuitBreaker(t *testing.T) {
+	pool := newTestPool(t, map[string]bool{})
+	bj := pool.servers[0]
+
+	// timeouts evict the session and open the circuit after the threshold
+	for i := 0; i < pool.failureThreshold; i++ {
+		session, err := pool.getSession()
+		if err != nil {
+			t.Fatalf("getSession failed: %s", err)
+		}
+		if session.server != bj {
+			t.Fatalf("expected session on %s, got %s", DataCenterBJ, session.NSC.DataCenter)
+		}
+		pool.releaseSession(session, &netSignError{operation: "sign", ret: netSignTimeout})
+	}
+	if bj.available(time.Now()) {
+		t.Fatal("circuit should be open after consecutive timeouts")
+	}
+	if len(pool.sessions) != 0 {
+		t.Fatal("sessions that timed out should be evicted")
+	}
+
+	session, err := pool.getSession()
+	if err != nil {
+		t.Fatalf("getSession failed: %s", err)
+	}
+	if session.NSC.DataCenter != DataCenterSH {
+		t.Fatalf("expected failover to %s, got %s", DataCenterSH, session.NSC.DataCenter)
+	}
+
+	// other errors evict the session without counting against the server
+	pool.releaseSession(session, &netSignError{operation: "sign", ret: 1})
+	if len(pool.sessions) != 0 || !pool.servers[1].available(time.Now()) {
+		t.Fatal("failed session should be evicted without opening the circuit")
+	}
+}
+
+func TestNetSignPoolDo(t *testing.T) {
+	pool := newTestPool(t, map[string]bool{})
+
+	var dataCentersTried []string
+	session, err := pool.do(func(session *NetSignSesssion) error {
+		dataCentersTried = append(dataCentersTried, session.NSC.DataCenter)
+		if session.NSC.DataCenter == DataCenterBJ {
+			return &netSignError{operation: "sign", ret: netSignTimeout}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("do failed: %s", err)
+	}
+	if session.NSC.DataCenter != DataCenterSH {
+		t.Fatalf("expected call to fail over to %s, got %s", DataCenterSH, session.NSC.DataCenter)
+	}
+	if len(dataCentersTried) != 2 {
+		t.Fatalf("expected 2 attempts, got %v", dataCentersTried)
+	}
+
+	// the timeout is returned once every server timed out
+	dataCentersTried = nil
+	session, err = pool.do(func(session *NetSignSesssion) error {
+		dataCentersTried = append(dataCentersTried, session.NSC.DataCenter)
+		return &netSignError{operation: "sign", ret: netSignTimeout}
+	})
+	if session == nil || !bccsp.IsRemoteSignerTimeout(err) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if len(dataCentersTried) != len(pool.servers) {
+		t.Fatalf("expected one attempt per server, got %v", dataCentersTried)
+	}
+}
+
+func TestNetSignPoolDoConnectionErrors(t *testing.T) {
+	connErrs := map[string]error{
+		DataCenterBJ: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+		DataCenterSH: &netSignError{operation: "sign", ret: netSignConnectFailed},
+	}
+	pool := newTestPool(t, map[string]bool{})
+
+	var dataCentersTried []string
+	session, err := pool.do(func(session *NetSignSesssion) error {
+		dataCentersTried = append(dataCentersTried, session.NSC.DataCenter)
+		return connErrs[session.NSC.DataCenter]
+	})
+	if err != nil {
+		t.Fatalf("do failed: %s", err)
+	}
+	if session.NSC.DataCenter != DataCenterBAK {
+		t.Fatalf("expected call to fail over to %s, got %s", DataCenterBAK, session.NSC.DataCenter)
+	}
+	if len(dataCentersTried) != 3 {
+		t.Fatalf("expected 3 attempts, got %v", dataCentersTried)
+	}
+
+	if pool.servers[0].failures != 1 || pool.servers[1].failures != 1 {
+		t.Fatal("connection errors should count against the circuit breaker")
+	}
+
+	// so do connection resets
+	session, err = pool.getSession()
+	if err != nil {
+		t.Fatalf("getSession failed: %s", err)
+	}
+	pool.releaseSession(session, syscall.ECONNRESET)
+	if session.server.failures != 1 || len(pool.sessions) != 0 {
+		t.Fatal("reset session should be evicted and counted against the circuit breaker")
+	}
+}
+
+func TestNetSignPoolHealthCheck(t *testing.T) {
+	pool := newTestPool(t, map[string]bool{})
+	session, err := pool.getSession()
+	if err != nil {
+		t.Fatalf("getSession failed: %s", err)
+	}
+	pool.returnSession(session)
+
+	status := []error{errors.New("connection refused"), nil, nil}
+	pool.probe = func(addresses []string) []error {
+		if len(addresses) != len(pool.servers) {
+			t.Fatalf("expected %d addresses, got %d", len(pool.servers), len(addresses))
+		}
+		return status
+	}
+	pool.checkHealth()
+	if pool.servers[0].available(time.Now()) {
+		t.Fatal("server failing its health check should be skipped")
+	}
+
+	// the cached session of the unhealthy server is dropped
+	session, err = pool.getSession()
+	if err != nil {
+		t.Fatalf("getSession failed: %s", err)
+	}
+	if session.NSC.DataCenter != DataCenterSH {
+		t.Fatalf("expected session on %s, got %s", DataCenterSH, session.NSC.DataCenter)
+	}
+
+	status = []error{nil, nil, nil}
+	pool.checkHealth()
+	if !pool.servers[0].available(time.Now()) {
+		t.Fatal("server passing its health check should be available again")
+	}
+
+	pool.startHealthCheck(time.Millisecond)
+	pool.close()
+	pool.close()
+}
+
+// countingRemoteSession counts the sessions closed
+type countingRemoteSession struct {
+	testRemoteSession
+	closed *int
+}
+
+func (s *countingRemoteSession) Close() error {
+	*s.closed++
+	return nil
+}
+
+// closingSigner counts the times it is closed
+type closingSigner struct {
+	NetSignSigner
+	closed int
+}
+
+func (s *closingSigner) Close() error {
+	s.closed++
+	return nil
+}
+
+func TestNetSignPoolClose(t *testing.T) {
+	pool := newTestPool(t, nil)
+	signer := &closingSigner{}
+	pool.signer = signer
+	closed := 0
+	pool.open = func(server *netSignServer) (*NetSignSesssion, error) {
+		return &NetSignSesssion{NSC: server.config, remote: &countingRemoteSession{closed: &closed}, server: server}, nil
+	}
+	if err := pool.fill(2); err != nil {
+		t.Fatalf("fill failed: %s", err)
+	}
+	session, err := pool.getSession()
+	if err != nil {
+		t.Fatalf("getSession failed: %s", err)
+	}
+
+	pool.startHealthCheck(time.Millisecond)
+	pool.close()
+	if closed != 1 {
+		t.Fatalf("expected the cached session to be closed, %d closed", closed)
+	}
+	if signer.closed != 1 {
+		t.Fatalf("expected the remote signer to be closed once, closed %d times", signer.closed)
+	}
+
+	// a session in use when the pool is closed is closed once released
+	pool.releaseSession(session, nil)
+	if closed != 2 {
+		t.Fatalf("expected the released session to be closed, %d closed", closed)
+	}
+}
+
+func TestNewNetSignPoolInvalidPort(t *testing.T) {
+	_, err := newNetSignPool([]*NetSignConfig{{Ip: "10.0.0.1", Port: "abc", DataCenter: DataCenterBJ}}, CNCC_GMOpts{}, NewNetSignSigner())
+	if err == nil {
+		t.Fatal("expected error for invalid port")
+	}
+	_, err = newNetSignPool(nil, CNCC_GMOpts{}, NewNetSignSigner())
+	if err == nil {
+		t.Fatal("expected error without servers")
+	}
+}
+
+func TestNewRemoteSigner(t *testing.T) {
+	for _, name := range []string{"", "netsign", "HTTP", "grpc"} {
+		signer, err := newRemoteSigner(CNCC_GMOpts{RemoteSigner: name})
+		if err != nil || signer == nil {
+			t.Fatalf("newRemoteSigner(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"http", "grpc"} {
+		insecure := CNCC_GMOpts{RemoteSigner: name, RemoteSignerTLS: &RemoteSignerTLSOpts{Insecure: true}}
+		signer, err := newRemoteSigner(insecure)
+		if err != nil {
+			t.Fatalf("newRemoteSigner(%q) failed: %v", name, err)
+		}
+		// passwords are never sent without TLS
+		if _, err := signer.OpenSession("127.0.0.1:1", "11111111"); err == nil || bccsp.IsRemoteSignerTimeout(err) {
+			t.Fatalf("expected the password to be refused by the insecure %s signer, got %v", name, err)
+		}
+
+		missingCA := CNCC_GMOpts{RemoteSigner: name, RemoteSignerTLS: &RemoteSignerTLSOpts{CACertFiles: []string{"missing.pem"}}}
+		if _, err := newRemoteSigner(missingCA); err == nil {
+			t.Fatalf("expected error for missing CA certificate of the %s signer", name)
+		}
+	}
+	if _, err := newRemoteSigner(CNCC_GMOpts{RemoteSigner: "pkcs11"}); err == nil {
+		t.Fatal("expected error for unsupported remote signer")
+	}
+	if _, err := NewWithRemoteSigner(CNCC_GMOpts{}, nil, nil); err == nil {
+		t.Fatal("expected error for nil remote signer")
+	}
+}
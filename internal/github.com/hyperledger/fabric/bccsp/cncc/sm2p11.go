@@ -4,8 +4,10 @@ import (
 	"encoding/asn1"
 	"encoding/base64"
 	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
-	"strings"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
 )
 
 /**
@@ -38,149 +40,196 @@ func (csp *Impl) getSM2Key(ski []byte) (pubKey *sm2.PublicKey, isPriv bool, err
 }
 
 func (csp *Impl) generateSM2Key(ephemeral bool) (ski []byte, pubKey *sm2.PublicKey, err error) {
-	netsign := csp.netsign
-	session := csp.getSession()
-	defer csp.returnSession(session)
-
 	//生成密钥的索引
 	id := RandStringInt()
 	keyLbel := fmt.Sprintf("SM2SignKey%s", id)
 	ski = []byte(keyLbel)
 
-	p10, ret := netsign.GenP10(session.NS_sesion, "CN=CNCC", keyLbel, "SM2")
-
-	if ret == -8034 {
-		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: generate P10 error: %d", ret)
-		return []byte(id), nil, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: generate P10 error: %d", ret)
-	} else if (ret != -8034) && (ret != 0) {
-		logger.Errorf("generate P10 error: %d", ret)
-		return []byte(id), nil, fmt.Errorf("generate P10 error: %d", ret)
+	var p10 []byte
+	_, err = csp.pool.do(func(session *NetSignSesssion) (err error) {
+		p10, err = session.remote.GenerateKeyPair(keyLbel, "CN=CNCC")
+		return err
+	})
+	if bccsp.IsRemoteSignerTimeout(err) {
+		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: generate P10 error: %s", err)
+		return []byte(id), nil, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: generate P10 error: %s", err)
+	} else if err != nil {
+		logger.Errorf("generate P10 error: %s", err)
+		return []byte(id), nil, fmt.Errorf("generate P10 error: %s", err)
 	}
-	replace1 := strings.Replace(string(p10), "-----BEGIN CERTIFICATE REQUEST-----", "", -1)
-	replace2 := strings.Replace(replace1, "-----END CERTIFICATE REQUEST-----", "", -1)
-	replace := strings.Replace(replace2, "\n", "", -1)
-	certificateRequest, err := base64.StdEncoding.DecodeString(replace)
+	request, err := sm2.ParseCertificateRequest(p10)
 	if err != nil {
-		logger.Errorf("base64 decode error: %s", err.Error())
-		return []byte(id), nil, fmt.Errorf("base64 decode error: %s", err.Error())
+		logger.Errorf("parse certificate request error: %s", err.Error())
+		return []byte(id), nil, fmt.Errorf("parse certificate request error: %s", err.Error())
+	}
+	pubKey, ok := request.PublicKey.(*sm2.PublicKey)
+	if !ok {
+		return []byte(id), nil, fmt.Errorf("unexpected public key type %T in certificate request", request.PublicKey)
 	}
-	request, err := sm2.ParseCertificateRequest(certificateRequest)
-	pubKey = (request.PublicKey).(*sm2.PublicKey)
 
 	logger.Infof("KeyLabel[%s], SKI[%s], Ephemeral[%t]", keyLbel, id, ephemeral)
 
 	return []byte(id), pubKey, nil
 }
 
-func (csp *Impl) signP11SM2(ski []byte, msg []byte) (sig []byte, err error) {
-	ns := csp.netsign
-	session := csp.getSession()
+func (csp *Impl) signP11SM2(ski []byte, msg []byte, uid []byte) (sig []byte, err error) {
 	keylabel := fmt.Sprintf("SM2SignKey%s", string(ski))
 
-	sig, ret := ns.Sign(session.NS_sesion, 0, msg, keylabel, "sm3")
-	if ret == 0 {
-		logger.Debugf("KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%+v]", keylabel,
+	session, err := csp.pool.do(func(session *NetSignSesssion) (err error) {
+		sig, err = remoteSign(session.remote, keylabel, msg, uid)
+		return err
+	})
+	if err == nil {
+		logger.Debugf("KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%s]", keylabel,
 			base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig), session.NSC)
-		csp.returnSession(session)
 		return sig, nil
-	} else if ret == -8034 {
-		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], NetSignConfig[%+v], NetSign: sign failed, "+
+	} else if session == nil {
+		return nil, err
+	} else if bccsp.IsRemoteSignerTimeout(err) {
+		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], NetSignConfig[%s], NetSign: sign failed, "+
 			"connect to netsign timeout", keylabel, base64.StdEncoding.EncodeToString(msg), session.NSC)
-		return nil, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], NetSignConfig[%+v], "+
+		return nil, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], NetSignConfig[%s], "+
 			"NetSign: sign failed, connect to netsign timeout", keylabel, base64.StdEncoding.EncodeToString(msg), session.NSC)
 	} else {
-		logger.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], NetSignConfig[%+v], NetSign: sign failed [%d]", keylabel,
-			base64.StdEncoding.EncodeToString(msg), session.NSC, ret)
-		//csp.returnSession(session)
-		return nil, fmt.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], NetSignConfig[%+v], NetSign: sign failed [%d]", keylabel,
-			base64.StdEncoding.EncodeToString(msg), session.NSC, ret)
+		logger.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], NetSignConfig[%s], NetSign: sign failed [%s]", keylabel,
+			base64.StdEncoding.EncodeToString(msg), session.NSC, err)
+		return nil, fmt.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], NetSignConfig[%s], NetSign: sign failed [%s]", keylabel,
+			base64.StdEncoding.EncodeToString(msg), session.NSC, err)
 	}
 }
 func (csp *Impl) uploadCert(ski []byte, certBytes []byte) (err error) {
-	ns := csp.netsign
-	session := csp.getSession()
-	defer csp.returnSession(session)
 	keylabel := fmt.Sprintf("SM2SignKey%s", string(ski))
 
-	ret := ns.UploadCert(session.NS_sesion, keylabel, certBytes)
-
-	if ret == 0 {
+	_, err = csp.pool.do(func(session *NetSignSesssion) error {
+		return session.remote.BindCertificate(keylabel, certBytes)
+	})
+	if err == nil {
 		logger.Infof("KeyLabel[%s], upload cert complete!", keylabel)
 		return nil
-	} else if ret == -8034 {
-		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: upload cert error %d", ret)
-		return fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: upload cert error %d", ret)
+	} else if bccsp.IsRemoteSignerTimeout(err) {
+		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: upload cert error %s", err)
+		return fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: upload cert error %s", err)
 	} else {
-		logger.Errorf("upload cert error %d", ret)
-		return fmt.Errorf("upload cert error %d", ret)
+		logger.Errorf("upload cert error %s", err)
+		return fmt.Errorf("upload cert error %s", err)
 	}
 }
 
-func (csp *Impl) verifyP11SM2(ski, msg []byte, sig []byte) (valid bool, err error) {
-	ns := csp.netsign
-	session := csp.getSession()
+func (csp *Impl) verifyP11SM2(ski, msg []byte, sig []byte, uid []byte) (valid bool, err error) {
 	keylabel := fmt.Sprintf("SM2SignKey%s", string(ski))
 
-	ret := ns.Verify(session.NS_sesion, 1, msg, sig, keylabel, "sm3")
-
-	if ret == 0 {
-		logger.Debugf("KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%+v]", keylabel,
-			base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig), session.NSC)
-		csp.returnSession(session)
-		return true, nil
-	} else if ret == -8034 {
-		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%+v], "+
+	session, err := csp.pool.do(func(session *NetSignSesssion) (err error) {
+		valid, err = remoteVerify(session.remote, keylabel, msg, sig, uid)
+		return err
+	})
+	if err == nil {
+		logger.Debugf("KeyLabel[%s], Msg[%s], Signature[%s], Valid[%t], NetSignConfig[%s]", keylabel,
+			base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig), valid, session.NSC)
+		return valid, nil
+	} else if session == nil {
+		return false, err
+	} else if bccsp.IsRemoteSignerTimeout(err) {
+		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%s], "+
 			"NetSign: verify failed, connect to netsign timeout",
 			keylabel, base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig), session.NSC)
-		return false, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%+v], NetSign: verify failed, connect to netsign timeout",
+		return false, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%s], NetSign: verify failed, connect to netsign timeout",
 			keylabel, base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig), session.NSC)
 	} else {
-		//csp.returnSession(session)
-		logger.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%+v], NetSign: verify failed [%d]", keylabel, base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig),
-			session.NSC, ret)
-		return false, fmt.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%+v], "+
-			"NetSign: verify failed [%d]", keylabel, base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig), session.NSC, ret)
+		logger.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%s], NetSign: verify failed [%s]", keylabel, base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig),
+			session.NSC, err)
+		return false, fmt.Errorf("LOGGER-SIGNVERIFY: KeyLabel[%s], Msg[%s], Signature[%s], NetSignConfig[%s], "+
+			"NetSign: verify failed [%s]", keylabel, base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig), session.NSC, err)
+	}
+}
+//以软件方式验证签名，与 GM 实现一致，签名直接作用于摘要
+func verifySM2(pub *sm2.PublicKey, signature, digest []byte, uid []byte) (bool, error) {
+	r, s, err := UnmarshalSM2Signature(signature)
+	if err != nil {
+		return false, err
+	}
+	if uid != nil {
+		return sm2.Sm2Verify(pub, digest, uid, r, s), nil
 	}
+	return sm2.Verify(pub, digest, r, s), nil
 }
+
+//uid 不为空时 msg 为消息原文，由签名服务器以 SM3(ZA || msg) 签名，需要签名服务器实现 bccsp.RemoteUIDSigner
+func remoteSign(remote bccsp.RemoteSignerSession, keyLabel string, msg, uid []byte) ([]byte, error) {
+	if uid == nil {
+		return remote.Sign(keyLabel, msg)
+	}
+	signer, ok := remote.(bccsp.RemoteUIDSigner)
+	if !ok {
+		return nil, fmt.Errorf("the signing server does not support signing with an SM2 user ID")
+	}
+	return signer.SignWithUID(keyLabel, uid, msg)
+}
+
+func remoteVerify(remote bccsp.RemoteSignerSession, keyLabel string, msg, sig, uid []byte) (bool, error) {
+	if uid == nil {
+		return remote.Verify(keyLabel, msg, sig)
+	}
+	verifier, ok := remote.(bccsp.RemoteUIDSigner)
+	if !ok {
+		return false, fmt.Errorf("the signing server does not support verifying with an SM2 user ID")
+	}
+	return verifier.VerifyWithUID(keyLabel, uid, msg, sig)
+}
+
+//SM2SignerOpts 指定的用户标识，未指定时返回 nil
+func signerUID(opts bccsp.SignerOpts) []byte {
+	if o, ok := opts.(*bccsp.SM2SignerOpts); ok {
+		return o.UserID()
+	}
+	return nil
+}
+
+//签名服务不支持哈希时在本地计算 SM3
 func (csp *Impl) hash(msg []byte) (digest []byte, err error) {
-	ns := csp.netsign
-	session := csp.getSession()
-	digest, ret := ns.Hash(session.NS_sesion, "sm3", msg)
-	if ret == 0 {
+	session, err := csp.pool.do(func(session *NetSignSesssion) (err error) {
+		hasher, ok := session.remote.(bccsp.RemoteHasher)
+		if !ok {
+			digest = sm3.Sm3Sum(msg)
+			return nil
+		}
+		digest, err = hasher.Hash(msg)
+		return err
+	})
+	if err == nil {
 		logger.Infof("Msg[%s]", base64.StdEncoding.EncodeToString(msg))
 		return digest, nil
-	} else if ret == -8034 {
-		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: Msg[%s], NetSignConfig[%+v], NetSign: hash failed, connect to netsign timeout",
+	} else if session == nil {
+		return nil, err
+	} else if bccsp.IsRemoteSignerTimeout(err) {
+		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: Msg[%s], NetSignConfig[%s], NetSign: hash failed, connect to netsign timeout",
 			base64.StdEncoding.EncodeToString(msg), session.NSC)
-		return nil, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: Msg[%s], NetSignConfig[%+v], NetSign: hash failed, connect to netsign timeout",
+		return nil, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: Msg[%s], NetSignConfig[%s], NetSign: hash failed, connect to netsign timeout",
 			base64.StdEncoding.EncodeToString(msg), session.NSC)
 	} else {
-		//csp.returnSession(session)
-		logger.Errorf("Msg[%s], NetSignConfig[%+v], NetSign: verify failed [%d]", base64.StdEncoding.EncodeToString(msg),
-			session.NSC, ret)
-		return nil, fmt.Errorf("Msg[%s], NetSignConfig[%+v], NetSign: verify failed [%d]", base64.StdEncoding.EncodeToString(msg),
-			session.NSC, ret)
+		logger.Errorf("Msg[%s], NetSignConfig[%s], NetSign: hash failed [%s]", base64.StdEncoding.EncodeToString(msg),
+			session.NSC, err)
+		return nil, fmt.Errorf("Msg[%s], NetSignConfig[%s], NetSign: hash failed [%s]", base64.StdEncoding.EncodeToString(msg),
+			session.NSC, err)
 	}
 }
 func (csp *Impl) deleteKeyPair(ski []byte) (valid bool, err error) {
-	ns := csp.netsign
-	session := csp.getSession()
 	keylabel := fmt.Sprintf("SM2SignKey%s", string(ski))
 
-	err, ret := ns.DeleteKeyPair(session.NS_sesion, keylabel)
-
-	if ret == 0 {
+	session, err := csp.pool.do(func(session *NetSignSesssion) error {
+		return session.remote.DeleteKeyPair(keylabel)
+	})
+	if err == nil {
 		logger.Infof("KeyLabel[%s], delete key pair success", keylabel)
-		csp.returnSession(session)
 		return true, nil
-	} else if ret == -8034 {
-		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], NetSignConfig[%+v], NetSign: delete key pair failed, connect to netsign timeout",
+	} else if session == nil {
+		return false, err
+	} else if bccsp.IsRemoteSignerTimeout(err) {
+		logger.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], NetSignConfig[%s], NetSign: delete key pair failed, connect to netsign timeout",
 			keylabel, session.NSC)
-		return false, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], NetSignConfig[%+v], NetSign: delete key pair failed, connect to netsign timeout",
+		return false, fmt.Errorf("LOGGER-CONN-SIGNAGENT-TIMEOUT: KeyLabel[%s], NetSignConfig[%s], NetSign: delete key pair failed, connect to netsign timeout",
 			keylabel, session.NSC)
 	} else {
-		//csp.returnSession(session)
-		logger.Errorf("KeyLabel[%s], NetSignConfig[%+v], NetSign: delete key pair failed [%d]", keylabel, session.NSC, ret)
-		return false, fmt.Errorf("KeyLabel[%s], NetSignConfig[%+v], NetSign: delete key pair failed [%d]", keylabel, session.NSC, ret)
+		logger.Errorf("KeyLabel[%s], NetSignConfig[%s], NetSign: delete key pair failed [%s]", keylabel, session.NSC, err)
+		return false, fmt.Errorf("KeyLabel[%s], NetSignConfig[%s], NetSign: delete key pair failed [%s]", keylabel, session.NSC, err)
 	}
 }
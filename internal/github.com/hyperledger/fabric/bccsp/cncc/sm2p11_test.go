@@ -0,0 +1,79 @@
+package cncc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+)
+
+// testUIDRemoteSession is a testRemoteSession that also signs with an SM2 user ID
+type testUIDRemoteSession struct {
+	testRemoteSession
+	uid, msg []byte
+}
+
+func (s *testUIDRemoteSession) SignWithUID(keyLabel string, uid, msg []byte) ([]byte, error) {
+	s.uid, s.msg = uid, msg
+	return []byte("uid signature"), nil
+}
+
+func (s *testUIDRemoteSession) VerifyWithUID(keyLabel string, uid, msg, signature []byte) (bool, error) {
+	return bytes.Equal(uid, s.uid), nil
+}
+
+func TestRemoteSignWithUID(t *testing.T) {
+	msg := []byte("msg")
+
+	// without a user ID the digest is signed by any signing server
+	sig, err := remoteSign(&testRemoteSession{}, "label", msg, nil)
+	if err != nil || string(sig) != "signature" {
+		t.Fatalf("remoteSign without user ID failed: %s, %v", sig, err)
+	}
+	if _, err := remoteSign(&testRemoteSession{}, "label", msg, []byte("uid")); err == nil {
+		t.Fatal("remoteSign with user ID should fail when the signing server does not support it")
+	}
+	if _, err := remoteVerify(&testRemoteSession{}, "label", msg, sig, []byte("uid")); err == nil {
+		t.Fatal("remoteVerify with user ID should fail when the signing server does not support it")
+	}
+
+	session := &testUIDRemoteSession{}
+	sig, err = remoteSign(session, "label", msg, []byte("uid"))
+	if err != nil || string(sig) != "uid signature" {
+		t.Fatalf("remoteSign with user ID failed: %s, %v", sig, err)
+	}
+	if !bytes.Equal(session.uid, []byte("uid")) || !bytes.Equal(session.msg, msg) {
+		t.Fatalf("remoteSign passed [%s] [%s] to the signing server", session.uid, session.msg)
+	}
+	if valid, err := remoteVerify(session, "label", msg, sig, []byte("uid")); err != nil || !valid {
+		t.Fatalf("remoteVerify with user ID failed: %v", err)
+	}
+}
+
+func TestVerifySM2WithUID(t *testing.T) {
+	priv, err := sm2.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %s", err)
+	}
+	msg := []byte("msg")
+	uid := signerUID(&bccsp.SM2SignerOpts{UID: []byte("user1@org1")})
+	r, s, err := sm2.Sm2Sign(priv, msg, uid)
+	if err != nil {
+		t.Fatalf("Sm2Sign failed: %s", err)
+	}
+	sig, err := sm2.SignDigitToSignData(r, s)
+	if err != nil {
+		t.Fatalf("SignDigitToSignData failed: %s", err)
+	}
+
+	if valid, err := verifySM2(&priv.PublicKey, sig, msg, uid); err != nil || !valid {
+		t.Fatalf("verifySM2 with user ID failed: %v", err)
+	}
+	if valid, _ := verifySM2(&priv.PublicKey, sig, msg, signerUID(&bccsp.SM2SignerOpts{})); valid {
+		t.Fatal("verifySM2 should fail with the default user ID")
+	}
+	if signerUID(nil) != nil {
+		t.Fatal("signerUID should be nil without SM2 signer options")
+	}
+}
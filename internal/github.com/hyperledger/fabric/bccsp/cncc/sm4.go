@@ -6,6 +6,7 @@ import (
 	"fmt"
 	
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/gm"
 	"github.com/tjfoc/gmsm/sm4"
 )
 
@@ -63,26 +64,14 @@ func SM4Decrypt(key, src []byte) ([]byte, error) {
 
 type gmsm4Encryptor struct{}
 
-//实现 Encryptor 接口
+//实现 Encryptor 接口，加密模式由 opts 指定，与 GM 实现一致
 func (*gmsm4Encryptor) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) (ciphertext []byte, err error) {
-	
-	return SM4Encrypt(k.(*gmsm4PrivateKey).privKey, plaintext)
-	//return AESCBCPKCS7Encrypt(k.(*sm4PrivateKey).privKey, plaintext)
-	
-	// key := k.(*gmsm4PrivateKey).privKey
-	// var en = make([]byte, 16)
-	// sms4(plaintext, 16, key, en, 1)
-	// return en, nil
+	return gm.SM4EncryptWithOpts(k.(*gmsm4PrivateKey).privKey, plaintext, opts)
 }
 
 type gmsm4Decryptor struct{}
 
-//实现 Decryptor 接口
+//实现 Decryptor 接口，解密模式由 opts 指定，与 GM 实现一致
 func (*gmsm4Decryptor) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) (plaintext []byte, err error) {
-	
-	return SM4Decrypt(k.(*gmsm4PrivateKey).privKey, ciphertext)
-	// var dc = make([]byte, 16)
-	// key := k.(*gmsm4PrivateKey).privKey
-	// sms4(ciphertext, 16, key, dc, 0)
-	// return dc, nil
+	return gm.SM4DecryptWithOpts(k.(*gmsm4PrivateKey).privKey, ciphertext, opts)
 }
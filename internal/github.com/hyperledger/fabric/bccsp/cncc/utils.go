@@ -6,11 +6,13 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 	"io/ioutil"
 	"math/big"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -30,12 +32,13 @@ func RandStringInt() string {
 }
 /**
 环境变量格式：
-CORE_PEER_BCCSP_CNCC_GM_IP=111.63.61.21,111.63.61.22;17.63.61.21,17.63.61.22
-CORE_PEER_BCCSP_CNCC_GM_PORT=50060,50061;50060,50061
-CORE_PEER_BCCSP_CNCC_GM_password=123456,123456;123456,123456
-理论上用  “;”  来区分 北京和上海的签名服务器配置
+CORE_PEER_BCCSP_CNCC_GM_IP=111.63.61.21,111.63.61.22;17.63.61.21,17.63.61.22;10.63.61.21
+CORE_PEER_BCCSP_CNCC_GM_PORT=50060,50061;50060,50061;50060
+CORE_PEER_BCCSP_CNCC_GM_password=123456,123456;123456,123456;123456
+用  “;”  来区分 北京（主）、上海（备）和灾备中心的签名服务器配置，上海和灾备中心可省略
+返回的签名服务器按 BJ、SH、BAK 的顺序排列
 */
-func FindPKCS11Lib(opts CNCC_GMOpts) {
+func LoadNetSignConfig(opts CNCC_GMOpts) ([]*NetSignConfig, error) {
 	var ip, port, passwd string
 	ip = os.Getenv("CORE_PEER_BCCSP_CNCC_GM_IP")
 	if ip == "" {
@@ -53,56 +56,54 @@ func FindPKCS11Lib(opts CNCC_GMOpts) {
 	ip = strings.Trim(ip, ",;")
 	port = strings.Trim(port, ",;")
 	passwd = strings.Trim(passwd, ",;")
+	if ip == "" {
+		return nil, errors.New("netsign config error: no netsign server configured")
+	}
 	
 	split1 := strings.Split(ip, ";")
 	split2 := strings.Split(port, ";")
 	split3 := strings.Split(passwd, ";")
 	
-	if len(split1) != len(split2) || len(split1) != len(split3) || len(split2) != len(split3) {
-		panic("netsign config error")
+	if len(split1) != len(split2) || len(split1) != len(split3) {
+		return nil, errors.New("netsign config error: ip, port and password must list the same data centers")
+	}
+	if len(split1) > len(dataCenters) {
+		return nil, fmt.Errorf("netsign config error: at most %d data centers are supported", len(dataCenters))
 	}
-	////根据 签名服务器中心，来设置全局变量
-	//data_centor := os.Getenv("NETSIGN_CENTOR")
-	//if strings.EqualFold("beijing", data_centor) {
-	if len(split1) == 1 {
-		BJ_NetSignConfig = parseNetsigns(split1[0], split2[0], split3[0])
-	} else if len(split1) == 2 {
-		BJ_NetSignConfig = parseNetsigns(split1[0], split2[0], split3[0])
-		SH_NetSignConfig = parseNetsigns(split1[1], split2[1], split3[1])
-	} else {
-		panic("netsign config error")
+	
+	var configs []*NetSignConfig
+	for i, dataCenter := range dataCenters[:len(split1)] {
+		signs, err := parseNetsigns(dataCenter, split1[i], split2[i], split3[i])
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, signs...)
 	}
-	//}else{
-	//	if len(split1) == 1 {
-	//		BJ_NetSignConfig = parseNetsigns(split1[0], split2[0], split3[0])
-	//	} else if len(split1) == 2 {
-	//		BJ_NetSignConfig = parseNetsigns(split1[1], split2[1], split3[1])
-	//		SH_NetSignConfig = parseNetsigns(split1[0], split2[0], split3[0])
-	//	} else {
-	//		panic("netsign config error")
-	//	}
-	//
-	//}
+	return configs, nil
 }
 
-func parseNetsigns(ip, port, passwd string) []*NetSignConfig {
+func parseNetsigns(dataCenter, ip, port, passwd string) ([]*NetSignConfig, error) {
 	var signs []*NetSignConfig
 	
 	ips := strings.Split(ip, ",")
 	ports := strings.Split(port, ",")
 	passwds := strings.Split(passwd, ",")
-	if len(ips) != len(ports) || len(ips) != len(passwds) || len(ports) != len(passwds) {
-		panic("netsign config error")
+	if len(ips) != len(ports) || len(ips) != len(passwds) {
+		return nil, fmt.Errorf("netsign config error: ip, port and password of data center [%s] must have the same length", dataCenter)
 	}
 	for i, ip := range ips {
+		if _, err := strconv.Atoi(ports[i]); err != nil {
+			return nil, fmt.Errorf("netsign config error: invalid port [%s] for netsign server [%s] of data center [%s]", ports[i], ip, dataCenter)
+		}
 		net := &NetSignConfig{
-			Ip:     ip,
-			Port:   ports[i],
-			Passwd: passwds[i],
+			Ip:         ip,
+			Port:       ports[i],
+			Passwd:     passwds[i],
+			DataCenter: dataCenter,
 		}
 		signs = append(signs, net)
 	}
-	return signs
+	return signs, nil
 }
 func SaveSKI(path, ski string) error {
 	if ski == "" {
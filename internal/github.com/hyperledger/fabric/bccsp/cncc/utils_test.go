@@ -76,11 +76,35 @@ func TestRandStringInt(t *testing.T) {
 	fmt.Println(len([]byte("SM2SignKey32200623148637695498943547498760925770749")))
 }
 
-func TestFindPKCS11Lib(t *testing.T) {
-	
-	lib, i, s := FindPKCS11Lib()
-	
-	fmt.Println(lib)
-	fmt.Println(i)
-	fmt.Println(s)
-}
\ No newline at end of file
+func TestLoadNetSignConfig(t *testing.T) {
+	opts := CNCC_GMOpts{
+		Ip:       "10.0.0.1,10.0.0.2;10.1.0.1;10.2.0.1",
+		Port:     "50060,50061;50060;50060",
+		Password: "a,b;c;d",
+	}
+	configs, err := LoadNetSignConfig(opts)
+	if err != nil {
+		t.Fatalf("LoadNetSignConfig failed: %s", err)
+	}
+	expected := []string{DataCenterBJ, DataCenterBJ, DataCenterSH, DataCenterBAK}
+	if len(configs) != len(expected) {
+		t.Fatalf("expected %d netsign servers, got %d", len(expected), len(configs))
+	}
+	for i, config := range configs {
+		if config.DataCenter != expected[i] {
+			t.Fatalf("expected data center %s for server %d, got %s", expected[i], i, config.DataCenter)
+		}
+	}
+	if configs[1].Ip != "10.0.0.2" || configs[1].Port != "50061" || configs[1].Passwd != "b" {
+		t.Fatalf("unexpected netsign server config %+v", configs[1])
+	}
+
+	opts.Port = "50060,abc;50060;50060"
+	if _, err := LoadNetSignConfig(opts); err == nil {
+		t.Fatal("expected error for invalid port")
+	}
+	opts.Port = "50060;50060"
+	if _, err := LoadNetSignConfig(opts); err == nil {
+		t.Fatal("expected error for mismatched data centers")
+	}
+}
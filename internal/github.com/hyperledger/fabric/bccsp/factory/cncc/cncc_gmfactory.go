@@ -33,7 +33,7 @@ func (f *CNCC_GMFactory) Get(gmOpts *cncc.CNCC_GMOpts) (bccsp.BCCSP, error) {
 	case gmOpts.Ephemeral:
 		ks = cncc.NewDummyKeyStore()
 	case gmOpts.FileKeystore != nil:
-		fks, err := cncc.NewFileBasedKeyStore(nil, gmOpts.FileKeystore.KeyStorePath, false)
+		fks, err := cncc.NewFileBasedKeyStore(gmOpts.FileKeystore.Password, gmOpts.FileKeystore.KeyStorePath, false)
 		if err != nil {
 			return nil, fmt.Errorf("Failed to initialize software key store: %s", err)
 		}
@@ -31,7 +31,7 @@ func (f *GMFactory) Get(gmOpts *GmOpts) (bccsp.BCCSP, error) {
 	case gmOpts.Ephemeral:
 		ks = gm.NewDummyKeyStore()
 	case gmOpts.FileKeystore != nil:
-		fks, err := gm.NewFileBasedKeyStore(nil, gmOpts.FileKeystore.KeyStorePath, false)
+		fks, err := gm.NewFileBasedKeyStore(gmOpts.FileKeystore.Password, gmOpts.FileKeystore.KeyStorePath, false)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Failed to initialize gm software key store")
 		}
@@ -62,6 +62,8 @@ type GmOpts struct {
 // Pluggable Keystores, could add JKS, P12, etc..
 type FileKeystoreOpts struct {
 	KeyStorePath string `mapstructure:"keystore" yaml:"KeyStore"`
+	// Password encrypts the keys written to KeyStorePath. Keys are stored in plaintext if it is empty.
+	Password []byte `mapstructure:"password,omitempty" json:"-" yaml:"Password"`
 }
 
 type DummyKeystoreOpts struct{}
@@ -50,7 +50,7 @@ func (f *SWFactory) Get(swOpts *SwOpts) (bccsp.BCCSP, error) {
 	case swOpts.Ephemeral:
 		ks = sw.NewDummyKeyStore()
 	case swOpts.FileKeystore != nil:
-		fks, err := sw.NewFileBasedKeyStore(nil, swOpts.FileKeystore.KeyStorePath, false)
+		fks, err := sw.NewFileBasedKeyStore(swOpts.FileKeystore.Password, swOpts.FileKeystore.KeyStorePath, false)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Failed to initialize software key store")
 		}
@@ -81,6 +81,8 @@ type SwOpts struct {
 // Pluggable Keystores, could add JKS, P12, etc..
 type FileKeystoreOpts struct {
 	KeyStorePath string `mapstructure:"keystore" yaml:"KeyStore"`
+	// Password encrypts the keys written to KeyStorePath. Keys are stored in plaintext if it is empty.
+	Password []byte `mapstructure:"password,omitempty" json:"-" yaml:"Password"`
 }
 
 type DummyKeystoreOpts struct{}
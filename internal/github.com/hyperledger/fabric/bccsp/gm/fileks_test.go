@@ -0,0 +1,91 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gm
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+)
+
+func TestEncryptedFileKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gmfileks")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pwd := []byte("keystore passphrase")
+	ks, err := NewFileBasedKeyStore(pwd, dir, false)
+	if err != nil {
+		t.Fatalf("Failed creating keystore: %s", err)
+	}
+	csp, err := New(256, "GMSM3", ks)
+	if err != nil {
+		t.Fatalf("Failed initializing GM BCCSP: %s", err)
+	}
+	sk, err := csp.KeyGen(&bccsp.GMSM2KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key: %s", err)
+	}
+	sm4Key, err := csp.KeyGen(&bccsp.GMSM4KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed generating SM4 key: %s", err)
+	}
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, hexSKI(sk)+"_sk"))
+	if err != nil {
+		t.Fatalf("Private key should be stored: %s", err)
+	}
+	if !bytes.Contains(raw, []byte("ENCRYPTED PRIVATE KEY")) {
+		t.Fatalf("Private key should be stored encrypted, got %s", raw)
+	}
+
+	loaded, err := ks.GetKey(sk.SKI())
+	if err != nil || !bytes.Equal(loaded.SKI(), sk.SKI()) {
+		t.Fatalf("Failed loading private key: %v", err)
+	}
+	loaded, err = ks.GetKey(sm4Key.SKI())
+	if err != nil || !bytes.Equal(loaded.SKI(), sm4Key.SKI()) {
+		t.Fatalf("Failed loading SM4 key: %v", err)
+	}
+
+	for _, wrong := range [][]byte{nil, []byte("wrong passphrase")} {
+		other, err := NewFileBasedKeyStore(wrong, dir, true)
+		if err != nil {
+			t.Fatalf("Failed opening keystore: %s", err)
+		}
+		if _, err := other.GetKey(sk.SKI()); err == nil {
+			t.Fatalf("Loading the private key with password %q should fail", wrong)
+		}
+		if _, err := other.GetKey(sm4Key.SKI()); err == nil {
+			t.Fatalf("Loading the SM4 key with password %q should fail", wrong)
+		}
+	}
+
+	// public keys are stored in clear
+	pk, err := sk.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key: %s", err)
+	}
+	if err := ks.StoreKey(pk); err != nil {
+		t.Fatalf("Failed storing public key: %s", err)
+	}
+	raw, err = ioutil.ReadFile(filepath.Join(dir, hexSKI(sk)+"_pk"))
+	if err != nil || bytes.Contains(raw, []byte("ENCRYPTED")) {
+		t.Fatalf("Public key should be stored in clear: %v", err)
+	}
+}
+
+func hexSKI(k bccsp.Key) string {
+	return fmt.Sprintf("%x", k.SKI())
+}
@@ -16,9 +16,12 @@ limitations under the License.
 package gm
 
 import (
+	"crypto/hmac"
 	"hash"
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+	"github.com/pkg/errors"
 )
 
 // 定义hasher 结构体，实现内部的一个 Hasher 接口
@@ -35,3 +38,27 @@ func (c *hasher) Hash(msg []byte, opts bccsp.HashOpts) (hash []byte, err error)
 func (c *hasher) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
 	return c.hash(), nil
 }
+
+// 定义 hmacHasher 结构体，使用 SM3HMACOpts 中的 SM4 key 计算 HMAC-SM3
+type hmacHasher struct{}
+
+func (c *hmacHasher) Hash(msg []byte, opts bccsp.HashOpts) (hash []byte, err error) {
+	h, err := c.GetHash(opts)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(msg)
+	return h.Sum(nil), nil
+}
+
+func (c *hmacHasher) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
+	hmacOpts, ok := opts.(*bccsp.SM3HMACOpts)
+	if !ok {
+		return nil, errors.Errorf("Unsupported 'HashOpt' provided [%v]", opts)
+	}
+	k, ok := hmacOpts.Key.(*gmsm4PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("Unsupported HMAC key provided [%v]. Expected an SM4 key", hmacOpts.Key)
+	}
+	return hmac.New(sm3.New, k.privKey), nil
+}
@@ -66,11 +66,14 @@ func New(securityLevel int, hashFamily string, keyStore bccsp.KeyStore) (bccsp.B
 
 	// Set the encryptors
 	encryptors := make(map[reflect.Type]Encryptor)
-	encryptors[reflect.TypeOf(&gmsm4PrivateKey{})] = &gmsm4Encryptor{} // sm4 加密选项
+	encryptors[reflect.TypeOf(&gmsm4PrivateKey{})] = &gmsm4Encryptor{}           // sm4 加密选项
+	encryptors[reflect.TypeOf(&gmsm2PublicKey{})] = &gmsm2PublicKeyEncryptor{}   // sm2 公钥加密选项
+	encryptors[reflect.TypeOf(&gmsm2PrivateKey{})] = &gmsm2PrivateKeyEncryptor{} // sm2 公钥加密选项
 
 	// Set the decryptors
 	decryptors := make(map[reflect.Type]Decryptor)
 	decryptors[reflect.TypeOf(&gmsm4PrivateKey{})] = &gmsm4Decryptor{} // sm4 解密选项
+	decryptors[reflect.TypeOf(&gmsm2PrivateKey{})] = &gmsm2Decryptor{} // sm2 私钥解密选项
 
 	// Set the signers
 	signers := make(map[reflect.Type]Signer)
@@ -92,6 +95,7 @@ func New(securityLevel int, hashFamily string, keyStore bccsp.KeyStore) (bccsp.B
 	hashers[reflect.TypeOf(&bccsp.SHA384Opts{})] = &hasher{hash: sha512.New384}
 	hashers[reflect.TypeOf(&bccsp.SHA3_256Opts{})] = &hasher{hash: sha3.New256}
 	hashers[reflect.TypeOf(&bccsp.SHA3_384Opts{})] = &hasher{hash: sha3.New384}
+	hashers[reflect.TypeOf(&bccsp.SM3HMACOpts{})] = &hmacHasher{} // sm3 HMAC选项
 
 	impl := &impl{
 		conf:       conf,
@@ -105,11 +109,12 @@ func New(securityLevel int, hashFamily string, keyStore bccsp.KeyStore) (bccsp.B
 	// Set the key generators
 	keyGenerators := make(map[reflect.Type]KeyGenerator)
 	keyGenerators[reflect.TypeOf(&bccsp.GMSM2KeyGenOpts{})] = &gmsm2KeyGenerator{}
-	keyGenerators[reflect.TypeOf(&bccsp.GMSM4KeyGenOpts{})] = &gmsm4KeyGenerator{length: 32}
+	keyGenerators[reflect.TypeOf(&bccsp.GMSM4KeyGenOpts{})] = &gmsm4KeyGenerator{length: 16}
 	impl.keyGenerators = keyGenerators
 
 	// Set the key derivers
 	keyDerivers := make(map[reflect.Type]KeyDeriver)
+	keyDerivers[reflect.TypeOf(&gmsm4PrivateKey{})] = &gmsm4KeyDeriver{}
 	impl.keyDerivers = keyDerivers
 
 	// Set the key importers
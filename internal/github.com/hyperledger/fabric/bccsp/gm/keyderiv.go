@@ -16,11 +16,18 @@ limitations under the License.
 package gm
 
 import (
-	"errors"
+	"crypto/hmac"
+	"io"
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
 )
 
+// sm4KeyLength 派生出的 SM4 密钥长度
+const sm4KeyLength = 16
+
 //定义国密 Key的驱动 ，实现 KeyDeriver 接口
 type smPublicKeyKeyDeriver struct{}
 
@@ -29,3 +36,47 @@ func (kd *smPublicKeyKeyDeriver) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts)
 	return nil, errors.New("Not implemented")
 
 }
+
+//定义国密 SM4 Key的驱动，以 SM3 为哈希函数派生新的 SM4 key
+type gmsm4KeyDeriver struct{}
+
+func (kd *gmsm4KeyDeriver) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (dk bccsp.Key, err error) {
+	key, err := SM3DeriveKey(k.(*gmsm4PrivateKey).privKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &gmsm4PrivateKey{key, false}, nil
+}
+
+// HMACSM3 returns HMAC-SM3(key, msg)
+func HMACSM3(key, msg []byte) []byte {
+	mac := hmac.New(sm3.New, key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// HKDFSM3 expands secret into length bytes with HKDF (RFC 5869) using SM3
+func HKDFSM3(secret, salt, info []byte, length int) ([]byte, error) {
+	key := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sm3.New, secret, salt, info), key); err != nil {
+		return nil, errors.Wrap(err, "failed expanding key with HKDF-SM3")
+	}
+	return key, nil
+}
+
+// SM3DeriveKey derives the raw SM4 key from the raw key material according to
+// HMACSM3DeriveKeyOpts or HKDFSM3DeriveKeyOpts
+func SM3DeriveKey(key []byte, opts bccsp.KeyDerivOpts) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errors.New("Invalid key. It must not be empty.")
+	}
+
+	switch o := opts.(type) {
+	case *bccsp.HMACSM3DeriveKeyOpts:
+		return HMACSM3(key, o.Argument())[:sm4KeyLength], nil
+	case *bccsp.HKDFSM3DeriveKeyOpts:
+		return HKDFSM3(key, o.Salt, o.Info, sm4KeyLength)
+	default:
+		return nil, errors.Errorf("Unsupported 'KeyDerivOpts' provided [%v]", opts)
+	}
+}
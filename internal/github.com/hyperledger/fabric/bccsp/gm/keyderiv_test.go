@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+)
+
+func TestHKDFSM3(t *testing.T) {
+	secret := []byte("input keying material")
+	salt := []byte("salt")
+	info := []byte("mychannel")
+
+	// RFC 5869: PRK = HMAC(salt, IKM), T(1) = HMAC(PRK, info || 0x01)
+	prk := HMACSM3(salt, secret)
+	expected := HMACSM3(prk, append(append([]byte{}, info...), 1))
+
+	key, err := HKDFSM3(secret, salt, info, 16)
+	if err != nil {
+		t.Fatalf("Failed expanding key: %s", err)
+	}
+	if !bytes.Equal(key, expected[:16]) {
+		t.Fatalf("Unexpected HKDF-SM3 output %x, expected %x", key, expected[:16])
+	}
+	if _, err := HKDFSM3(secret, salt, info, 255*32+1); err == nil {
+		t.Fatal("Expanding more than 255 blocks should fail")
+	}
+}
+
+func TestSM3KeyDeriv(t *testing.T) {
+	csp, err := New(256, "GMSM3", NewInMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("Failed initializing GM BCCSP: %s", err)
+	}
+	raw := bytes.Repeat([]byte{1}, 16)
+	k, err := csp.KeyImport(raw, &bccsp.GMSM4ImportKeyOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing SM4 key: %s", err)
+	}
+
+	hkdfKey, err := HKDFSM3(raw, nil, []byte("mychannel"), 16)
+	if err != nil {
+		t.Fatalf("Failed expanding key: %s", err)
+	}
+	for _, test := range []struct {
+		opts     bccsp.KeyDerivOpts
+		expected []byte
+	}{
+		{&bccsp.HMACSM3DeriveKeyOpts{Arg: []byte("mychannel")}, HMACSM3(raw, []byte("mychannel"))[:16]},
+		{&bccsp.HKDFSM3DeriveKeyOpts{Info: []byte("mychannel")}, hkdfKey},
+	} {
+		dk, err := csp.KeyDeriv(k, test.opts)
+		if err != nil {
+			t.Fatalf("Failed deriving key with %T: %s", test.opts, err)
+		}
+		if !dk.Symmetric() || !bytes.Equal(dk.(*gmsm4PrivateKey).privKey, test.expected) {
+			t.Fatalf("Unexpected key derived with %T", test.opts)
+		}
+
+		// derived keys are stored in the keystore unless they are ephemeral
+		stored, err := csp.GetKey(dk.SKI())
+		if err != nil || !bytes.Equal(stored.SKI(), dk.SKI()) {
+			t.Fatalf("Derived key should be in the keystore: %v", err)
+		}
+
+		// and are SM4 keys
+		ciphertext, err := csp.Encrypt(stored, []byte("payload"), &bccsp.SM4GCMModeOpts{})
+		if err != nil {
+			t.Fatalf("Failed encrypting with derived key: %s", err)
+		}
+		if _, err := csp.Decrypt(dk, ciphertext, &bccsp.SM4GCMModeOpts{}); err != nil {
+			t.Fatalf("Failed decrypting with derived key: %s", err)
+		}
+	}
+
+	if _, err := csp.KeyDeriv(k, &bccsp.HMACDeriveKeyOpts{}); err == nil {
+		t.Fatal("Deriving with non SM3 opts should fail")
+	}
+	priv, err := csp.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key: %s", err)
+	}
+	if _, err := csp.KeyDeriv(priv, &bccsp.HKDFSM3DeriveKeyOpts{}); err == nil {
+		t.Fatal("Deriving from an SM2 key should fail")
+	}
+}
+
+func TestSM3HMAC(t *testing.T) {
+	csp, err := New(256, "GMSM3", NewDummyKeyStore())
+	if err != nil {
+		t.Fatalf("Failed initializing GM BCCSP: %s", err)
+	}
+	raw := bytes.Repeat([]byte{1}, 16)
+	k, err := csp.KeyImport(raw, &bccsp.GMSM4ImportKeyOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing SM4 key: %s", err)
+	}
+
+	msg := []byte("message to authenticate")
+	mac, err := csp.Hash(msg, &bccsp.SM3HMACOpts{Key: k})
+	if err != nil {
+		t.Fatalf("Failed computing HMAC: %s", err)
+	}
+	if len(mac) != 32 || !bytes.Equal(mac, HMACSM3(raw, msg)) {
+		t.Fatalf("Unexpected HMAC-SM3 %x", mac)
+	}
+
+	h, err := csp.GetHash(&bccsp.SM3HMACOpts{Key: k})
+	if err != nil {
+		t.Fatalf("Failed getting HMAC: %s", err)
+	}
+	h.Write(msg)
+	if !bytes.Equal(h.Sum(nil), mac) {
+		t.Fatal("GetHash and Hash should give the same HMAC")
+	}
+
+	priv, err := csp.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key: %s", err)
+	}
+	if _, err := csp.Hash(msg, &bccsp.SM3HMACOpts{Key: priv}); err == nil {
+		t.Fatal("HMAC with an SM2 key should fail")
+	}
+	if _, err := csp.Hash(msg, &bccsp.SM3HMACOpts{}); err == nil {
+		t.Fatal("HMAC without a key should fail")
+	}
+}
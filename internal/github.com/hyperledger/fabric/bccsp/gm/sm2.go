@@ -77,11 +77,26 @@ func UnmarshalSM2Signature(raw []byte) (*big.Int, *big.Int, error) {
 }
 
 func signGMSM2(k *sm2.PrivateKey, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	// 带用户标识的签名，digest 为消息原文，签名值基于 SM3(ZA || msg) 计算
+	if o, ok := opts.(*bccsp.SM2SignerOpts); ok {
+		r, s, err := sm2.Sm2Sign(k, digest, o.UserID())
+		if err != nil {
+			return nil, err
+		}
+		return MarshalSM2Signature(r, s)
+	}
 	signature, err = k.Sign(rand.Reader, digest, opts)
 	return
 }
 
 func verifyGMSM2(k *sm2.PublicKey, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	if o, ok := opts.(*bccsp.SM2SignerOpts); ok {
+		r, s, err := UnmarshalSM2Signature(signature)
+		if err != nil {
+			return false, err
+		}
+		return sm2.Sm2Verify(k, digest, o.UserID(), r, s), nil
+	}
 	valid = k.Verify(digest, signature)
 	return
 }
@@ -191,3 +206,24 @@ func IsLowS(k *ecdsa.PublicKey, s *big.Int) (bool, error) {
 	return s.Cmp(halfOrder) != 1, nil
 
 }
+
+type gmsm2PublicKeyEncryptor struct{}
+
+// 实现 Encryptor 接口，使用 sm2 公钥加密
+func (*gmsm2PublicKeyEncryptor) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) ([]byte, error) {
+	return bccsp.EncryptSM2(k.(*gmsm2PublicKey).pubKey, plaintext, opts)
+}
+
+type gmsm2PrivateKeyEncryptor struct{}
+
+// 实现 Encryptor 接口，使用 sm2 私钥对应的公钥加密
+func (*gmsm2PrivateKeyEncryptor) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) ([]byte, error) {
+	return bccsp.EncryptSM2(&k.(*gmsm2PrivateKey).privKey.PublicKey, plaintext, opts)
+}
+
+type gmsm2Decryptor struct{}
+
+// 实现 Decryptor 接口，使用 sm2 私钥解密
+func (*gmsm2Decryptor) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) ([]byte, error) {
+	return bccsp.DecryptSM2(k.(*gmsm2PrivateKey).privKey, ciphertext, opts)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+)
+
+func TestSM2EncryptDecrypt(t *testing.T) {
+	csp, err := New(256, "GMSM3", NewDummyKeyStore())
+	if err != nil {
+		t.Fatalf("Failed initializing GM BCCSP: %s", err)
+	}
+	priv, err := csp.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key: %s", err)
+	}
+	pub, err := priv.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key: %s", err)
+	}
+
+	msg := []byte("transient data for Org1MSP")
+	for _, format := range []bccsp.SM2CiphertextFormat{bccsp.SM2C1C3C2, bccsp.SM2C1C2C3, bccsp.SM2ASN1} {
+		ciphertext, err := csp.Encrypt(pub, msg, &bccsp.SM2EncrypterOpts{Format: format})
+		if err != nil {
+			t.Fatalf("Failed encrypting to format [%d]: %s", format, err)
+		}
+		plaintext, err := csp.Decrypt(priv, ciphertext, &bccsp.SM2DecrypterOpts{Format: format})
+		if err != nil || !bytes.Equal(plaintext, msg) {
+			t.Fatalf("Failed decrypting format [%d]: %s, %v", format, plaintext, err)
+		}
+	}
+
+	// nil opts use the C1C3C2 format, and the private key encrypts with its public key
+	ciphertext, err := csp.Encrypt(priv, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed encrypting with nil opts: %s", err)
+	}
+	plaintext, err := csp.Decrypt(priv, ciphertext, bccsp.SM2DecrypterOpts{Format: bccsp.SM2C1C3C2})
+	if err != nil || !bytes.Equal(plaintext, msg) {
+		t.Fatalf("Failed decrypting with nil opts: %s, %v", plaintext, err)
+	}
+
+	if _, err := csp.Decrypt(priv, ciphertext, &bccsp.SM2DecrypterOpts{Format: bccsp.SM2ASN1}); err == nil {
+		t.Fatal("Decrypting with the wrong format should fail")
+	}
+	if _, err := csp.Encrypt(pub, msg, &bccsp.SM2EncrypterOpts{Format: 42}); err == nil {
+		t.Fatal("Encrypting to an unknown format should fail")
+	}
+	if _, err := csp.Encrypt(pub, msg, &bccsp.AESCBCPKCS7ModeOpts{}); err == nil {
+		t.Fatal("Encrypting with non SM2 opts should fail")
+	}
+	if _, err := csp.Decrypt(pub, ciphertext, nil); err == nil {
+		t.Fatal("Decrypting with a public key should fail")
+	}
+}
+
+func TestSM2SignVerifyWithUID(t *testing.T) {
+	csp, err := New(256, "GMSM3", NewDummyKeyStore())
+	if err != nil {
+		t.Fatalf("Failed initializing GM BCCSP: %s", err)
+	}
+	priv, err := csp.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key: %s", err)
+	}
+	pub, err := priv.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key: %s", err)
+	}
+
+	msg := []byte("proposal signed by user1@org1")
+	opts := &bccsp.SM2SignerOpts{UID: []byte("user1@org1")}
+	signature, err := csp.Sign(priv, msg, opts)
+	if err != nil {
+		t.Fatalf("Failed signing with user ID: %s", err)
+	}
+	valid, err := csp.Verify(pub, signature, msg, opts)
+	if err != nil || !valid {
+		t.Fatalf("Failed verifying with user ID: %v", err)
+	}
+
+	// the user ID is part of the signed data
+	if valid, _ := csp.Verify(pub, signature, msg, &bccsp.SM2SignerOpts{}); valid {
+		t.Fatal("Signature should not verify with the default user ID")
+	}
+	if valid, _ := csp.Verify(pub, signature, msg, nil); valid {
+		t.Fatal("Signature should not verify as a signature of the digest")
+	}
+
+	// empty options sign with the default user ID
+	signature, err = csp.Sign(priv, msg, &bccsp.SM2SignerOpts{})
+	if err != nil {
+		t.Fatalf("Failed signing with the default user ID: %s", err)
+	}
+	valid, err = csp.Verify(pub, signature, msg, &bccsp.SM2SignerOpts{UID: []byte(bccsp.DefaultSM2UID)})
+	if err != nil || !valid {
+		t.Fatalf("Failed verifying with the default user ID: %v", err)
+	}
+}
+
+// 通过 BCCSP 签名与验签的性能测试，与 tjfoc/gmsm/sm2 中和 ECDSA 对比的测试互为参照
+func BenchmarkSM2SignVerify(b *testing.B) {
+	csp, err := New(256, "GMSM3", NewDummyKeyStore())
+	if err != nil {
+		b.Fatalf("Failed initializing GM BCCSP: %s", err)
+	}
+	priv, err := csp.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		b.Fatalf("Failed generating SM2 key: %s", err)
+	}
+	pub, err := priv.PublicKey()
+	if err != nil {
+		b.Fatalf("Failed getting public key: %s", err)
+	}
+	msg := []byte("proposal signed by user1@org1")
+	opts := &bccsp.SM2SignerOpts{UID: []byte("user1@org1")}
+	signature, err := csp.Sign(priv, msg, opts)
+	if err != nil {
+		b.Fatalf("Failed signing: %s", err)
+	}
+
+	b.Run("Sign", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := csp.Sign(priv, msg, opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Verify", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if valid, err := csp.Verify(pub, signature, msg, opts); err != nil || !valid {
+				b.Fatalf("Failed verifying: %v", err)
+			}
+		}
+	})
+}
@@ -16,9 +16,12 @@ limitations under the License.
 package gm
 
 import (
+	"bytes"
+	"crypto/cipher"
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm4"
@@ -68,28 +71,244 @@ func SM4Decrypt(key, src []byte) ([]byte, error) {
 	return dst, nil
 }
 
+// SM4 GCM nonce and tag sizes
+const (
+	sm4GCMNonceSize = 12
+	sm4GCMTagSize   = 16
+)
+
+func sm4PKCS7Padding(src []byte) []byte {
+	padding := sm4.BlockSize - len(src)%sm4.BlockSize
+	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
+	return append(src, padtext...)
+}
+
+func sm4PKCS7UnPadding(src []byte) ([]byte, error) {
+	length := len(src)
+	if length == 0 {
+		return nil, errors.New("Invalid pkcs7 padding (empty plaintext)")
+	}
+	unpadding := int(src[length-1])
+
+	if unpadding > sm4.BlockSize || unpadding == 0 {
+		return nil, errors.New("Invalid pkcs7 padding (unpadding > sm4.BlockSize || unpadding == 0)")
+	}
+
+	pad := src[len(src)-unpadding:]
+	for i := 0; i < unpadding; i++ {
+		if pad[i] != byte(unpadding) {
+			return nil, errors.New("Invalid pkcs7 padding (pad[i] != unpadding)")
+		}
+	}
+
+	return src[:(length - unpadding)], nil
+}
+
+// sm4IV returns iv if set, or a random IV of size bytes read from prng, or from crypto/rand if prng is nil
+func sm4IV(iv []byte, prng io.Reader, size int) ([]byte, error) {
+	if len(iv) != 0 && prng != nil {
+		return nil, errors.New("Invalid options. Either IV or PRNG should be different from nil, or both nil.")
+	}
+	if len(iv) != 0 {
+		if len(iv) != size {
+			return nil, fmt.Errorf("Invalid IV. It must have length [%d]", size)
+		}
+		return iv, nil
+	}
+	if prng == nil {
+		prng = rand.Reader
+	}
+	iv = make([]byte, size)
+	if _, err := io.ReadFull(prng, iv); err != nil {
+		return nil, err
+	}
+	return iv, nil
+}
+
+// SM4CBCPKCS7EncryptWithIV combines CBC encryption and PKCS7 padding, the IV used is the one passed to the function.
+// The IV is prepended to the ciphertext.
+func SM4CBCPKCS7EncryptWithIV(IV []byte, key, src []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(IV) != sm4.BlockSize {
+		return nil, errors.New("Invalid IV. It must have length the block size")
+	}
+
+	tmp := sm4PKCS7Padding(append([]byte{}, src...))
+	ciphertext := make([]byte, sm4.BlockSize+len(tmp))
+	copy(ciphertext[:sm4.BlockSize], IV)
+
+	mode := cipher.NewCBCEncrypter(block, IV)
+	mode.CryptBlocks(ciphertext[sm4.BlockSize:], tmp)
+
+	return ciphertext, nil
+}
+
+// SM4CBCPKCS7EncryptWithRand combines CBC encryption and PKCS7 padding using as prng the passed to the function
+func SM4CBCPKCS7EncryptWithRand(prng io.Reader, key, src []byte) ([]byte, error) {
+	iv, err := sm4IV(nil, prng, sm4.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return SM4CBCPKCS7EncryptWithIV(iv, key, src)
+}
+
+// SM4CBCPKCS7Decrypt combines CBC decryption and PKCS7 unpadding of a ciphertext prefixed with its IV
+func SM4CBCPKCS7Decrypt(key, src []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(src) < 2*sm4.BlockSize || len(src)%sm4.BlockSize != 0 {
+		return nil, errors.New("Invalid ciphertext. It must be a multiple of the block size")
+	}
+	iv := src[:sm4.BlockSize]
+	pt := make([]byte, len(src)-sm4.BlockSize)
+
+	mode := cipher.NewCBCDecrypter(block, iv)
+	mode.CryptBlocks(pt, src[sm4.BlockSize:])
+
+	return sm4PKCS7UnPadding(pt)
+}
+
+// SM4CTREncryptWithIV encrypts src in CTR mode starting from the counter block IV.
+// The IV is prepended to the ciphertext.
+func SM4CTREncryptWithIV(IV []byte, key, src []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(IV) != sm4.BlockSize {
+		return nil, errors.New("Invalid IV. It must have length the block size")
+	}
+
+	ciphertext := make([]byte, sm4.BlockSize+len(src))
+	copy(ciphertext[:sm4.BlockSize], IV)
+	cipher.NewCTR(block, IV).XORKeyStream(ciphertext[sm4.BlockSize:], src)
+
+	return ciphertext, nil
+}
+
+// SM4CTRDecrypt decrypts a CTR ciphertext prefixed with its IV
+func SM4CTRDecrypt(key, src []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) < sm4.BlockSize {
+		return nil, errors.New("Invalid ciphertext. It must be longer than the block size")
+	}
+
+	pt := make([]byte, len(src)-sm4.BlockSize)
+	cipher.NewCTR(block, src[:sm4.BlockSize]).XORKeyStream(pt, src[sm4.BlockSize:])
+
+	return pt, nil
+}
+
+// SM4GCMEncryptWithNonce encrypts and authenticates src, and authenticates additionalData, in GCM mode.
+// The nonce is prepended to the ciphertext and the tag appended to it.
+func SM4GCMEncryptWithNonce(nonce []byte, key, src, additionalData []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != sm4GCMNonceSize {
+		return nil, fmt.Errorf("Invalid nonce. It must have length [%d]", sm4GCMNonceSize)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, sm4GCMNonceSize, sm4GCMNonceSize+len(src)+sm4GCMTagSize)
+	copy(ciphertext, nonce)
+	return aead.Seal(ciphertext, nonce, src, additionalData), nil
+}
+
+// SM4GCMDecrypt authenticates and decrypts a GCM ciphertext prefixed with its nonce
+func SM4GCMDecrypt(key, src, additionalData []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) < sm4GCMNonceSize+sm4GCMTagSize {
+		return nil, errors.New("Invalid ciphertext. It is shorter than nonce and tag")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Open(nil, src[:sm4GCMNonceSize], src[sm4GCMNonceSize:], additionalData)
+}
+
+// SM4EncryptWithOpts encrypts src with key in the mode of opts. Nil opts keep the
+// original single block encryption of SM4Encrypt.
+func SM4EncryptWithOpts(key, src []byte, opts bccsp.EncrypterOpts) ([]byte, error) {
+	switch o := opts.(type) {
+	case nil:
+		return SM4Encrypt(key, src)
+	case *bccsp.SM4CBCPKCS7ModeOpts:
+		iv, err := sm4IV(o.IV, o.PRNG, sm4.BlockSize)
+		if err != nil {
+			return nil, err
+		}
+		return SM4CBCPKCS7EncryptWithIV(iv, key, src)
+	case bccsp.SM4CBCPKCS7ModeOpts:
+		return SM4EncryptWithOpts(key, src, &o)
+	case *bccsp.SM4CTRModeOpts:
+		iv, err := sm4IV(o.IV, o.PRNG, sm4.BlockSize)
+		if err != nil {
+			return nil, err
+		}
+		return SM4CTREncryptWithIV(iv, key, src)
+	case bccsp.SM4CTRModeOpts:
+		return SM4EncryptWithOpts(key, src, &o)
+	case *bccsp.SM4GCMModeOpts:
+		nonce, err := sm4IV(o.Nonce, o.PRNG, sm4GCMNonceSize)
+		if err != nil {
+			return nil, err
+		}
+		return SM4GCMEncryptWithNonce(nonce, key, src, o.AdditionalData)
+	case bccsp.SM4GCMModeOpts:
+		return SM4EncryptWithOpts(key, src, &o)
+	default:
+		return nil, fmt.Errorf("Mode not recognized [%s]", opts)
+	}
+}
+
+// SM4DecryptWithOpts decrypts src with key in the mode of opts. Nil opts keep the
+// original single block decryption of SM4Decrypt.
+func SM4DecryptWithOpts(key, src []byte, opts bccsp.DecrypterOpts) ([]byte, error) {
+	switch o := opts.(type) {
+	case nil:
+		return SM4Decrypt(key, src)
+	case *bccsp.SM4CBCPKCS7ModeOpts, bccsp.SM4CBCPKCS7ModeOpts:
+		return SM4CBCPKCS7Decrypt(key, src)
+	case *bccsp.SM4CTRModeOpts, bccsp.SM4CTRModeOpts:
+		return SM4CTRDecrypt(key, src)
+	case *bccsp.SM4GCMModeOpts:
+		return SM4GCMDecrypt(key, src, o.AdditionalData)
+	case bccsp.SM4GCMModeOpts:
+		return SM4GCMDecrypt(key, src, o.AdditionalData)
+	default:
+		return nil, fmt.Errorf("Mode not recognized [%s]", opts)
+	}
+}
+
 type gmsm4Encryptor struct{}
 
 // 实现 Encryptor 接口
 func (*gmsm4Encryptor) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) (ciphertext []byte, err error) {
-
-	return SM4Encrypt(k.(*gmsm4PrivateKey).privKey, plaintext)
-	// return AESCBCPKCS7Encrypt(k.(*sm4PrivateKey).privKey, plaintext)
-
-	// key := k.(*gmsm4PrivateKey).privKey
-	// var en = make([]byte, 16)
-	// sms4(plaintext, 16, key, en, 1)
-	// return en, nil
+	return SM4EncryptWithOpts(k.(*gmsm4PrivateKey).privKey, plaintext, opts)
 }
 
 type gmsm4Decryptor struct{}
 
 // 实现 Decryptor 接口
 func (*gmsm4Decryptor) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) (plaintext []byte, err error) {
-
-	return SM4Decrypt(k.(*gmsm4PrivateKey).privKey, ciphertext)
-	// var dc = make([]byte, 16)
-	// key := k.(*gmsm4PrivateKey).privKey
-	// sms4(ciphertext, 16, key, dc, 0)
-	// return dc, nil
+	return SM4DecryptWithOpts(k.(*gmsm4PrivateKey).privKey, ciphertext, opts)
 }
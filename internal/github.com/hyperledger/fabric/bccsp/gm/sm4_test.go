@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+)
+
+func TestSM4Modes(t *testing.T) {
+	csp, err := New(256, "GMSM3", NewDummyKeyStore())
+	if err != nil {
+		t.Fatalf("Failed initializing GM BCCSP: %s", err)
+	}
+	k, err := csp.KeyGen(&bccsp.GMSM4KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating SM4 key: %s", err)
+	}
+
+	msg := []byte("private data transient field, not block aligned")
+	modes := []struct {
+		name     string
+		encOpts  bccsp.EncrypterOpts
+		decOpts  bccsp.DecrypterOpts
+		overhead int
+	}{
+		{"CBC", &bccsp.SM4CBCPKCS7ModeOpts{}, &bccsp.SM4CBCPKCS7ModeOpts{}, 16 + 16 - len(msg)%16},
+		{"CBC by value", bccsp.SM4CBCPKCS7ModeOpts{}, bccsp.SM4CBCPKCS7ModeOpts{}, 16 + 16 - len(msg)%16},
+		{"CTR", &bccsp.SM4CTRModeOpts{}, &bccsp.SM4CTRModeOpts{}, 16},
+		{"GCM", &bccsp.SM4GCMModeOpts{AdditionalData: []byte("tx1")}, &bccsp.SM4GCMModeOpts{AdditionalData: []byte("tx1")}, 12 + 16},
+	}
+	for _, mode := range modes {
+		ciphertext, err := csp.Encrypt(k, msg, mode.encOpts)
+		if err != nil {
+			t.Fatalf("%s: Failed encrypting: %s", mode.name, err)
+		}
+		if len(ciphertext) != len(msg)+mode.overhead {
+			t.Fatalf("%s: unexpected ciphertext length %d", mode.name, len(ciphertext))
+		}
+		plaintext, err := csp.Decrypt(k, ciphertext, mode.decOpts)
+		if err != nil || !bytes.Equal(plaintext, msg) {
+			t.Fatalf("%s: Failed decrypting: %s, %v", mode.name, plaintext, err)
+		}
+		other, err := csp.Encrypt(k, msg, mode.encOpts)
+		if err != nil || bytes.Equal(other, ciphertext) {
+			t.Fatalf("%s: random IVs should give different ciphertexts: %v", mode.name, err)
+		}
+	}
+
+	// GCM authenticates the ciphertext and the additional data
+	ciphertext, err := csp.Encrypt(k, msg, &bccsp.SM4GCMModeOpts{AdditionalData: []byte("tx1")})
+	if err != nil {
+		t.Fatalf("Failed encrypting: %s", err)
+	}
+	if _, err := csp.Decrypt(k, ciphertext, &bccsp.SM4GCMModeOpts{AdditionalData: []byte("tx2")}); err == nil {
+		t.Fatal("Decrypting with other additional data should fail")
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := csp.Decrypt(k, ciphertext, &bccsp.SM4GCMModeOpts{AdditionalData: []byte("tx1")}); err == nil {
+		t.Fatal("Decrypting a tampered ciphertext should fail")
+	}
+}
+
+func TestSM4ModesWithIV(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	iv := bytes.Repeat([]byte{2}, 16)
+	msg := []byte("0123456789abcdef")
+
+	ciphertext, err := SM4EncryptWithOpts(key, msg, &bccsp.SM4CBCPKCS7ModeOpts{IV: iv})
+	if err != nil {
+		t.Fatalf("Failed encrypting: %s", err)
+	}
+	if !bytes.Equal(ciphertext[:16], iv) || len(ciphertext) != 48 {
+		t.Fatal("CBC ciphertext should be prefixed with the IV and padded with a full block")
+	}
+	again, err := SM4EncryptWithOpts(key, msg, &bccsp.SM4CBCPKCS7ModeOpts{IV: iv})
+	if err != nil || !bytes.Equal(again, ciphertext) {
+		t.Fatalf("Encrypting with the same IV should be deterministic: %v", err)
+	}
+
+	ciphertext, err = SM4EncryptWithOpts(key, msg, &bccsp.SM4CTRModeOpts{PRNG: bytes.NewReader(iv)})
+	if err != nil || !bytes.Equal(ciphertext[:16], iv) {
+		t.Fatalf("CTR IV should be read from the PRNG: %v", err)
+	}
+
+	nonce := iv[:12]
+	ciphertext, err = SM4EncryptWithOpts(key, msg, bccsp.SM4GCMModeOpts{Nonce: nonce})
+	if err != nil || !bytes.Equal(ciphertext[:12], nonce) {
+		t.Fatalf("GCM ciphertext should be prefixed with the nonce: %v", err)
+	}
+
+	invalid := []bccsp.EncrypterOpts{
+		&bccsp.SM4CBCPKCS7ModeOpts{IV: iv[:8]},
+		&bccsp.SM4CBCPKCS7ModeOpts{IV: iv, PRNG: bytes.NewReader(iv)},
+		&bccsp.SM4CTRModeOpts{IV: iv[:15]},
+		&bccsp.SM4GCMModeOpts{Nonce: iv},
+		&bccsp.SM2EncrypterOpts{},
+	}
+	for _, opts := range invalid {
+		if _, err := SM4EncryptWithOpts(key, msg, opts); err == nil {
+			t.Fatalf("Encrypting with invalid opts %#v should fail", opts)
+		}
+	}
+	if _, err := SM4EncryptWithOpts(bytes.Repeat([]byte{1}, 32), msg, &bccsp.SM4CTRModeOpts{}); err == nil {
+		t.Fatal("SM4 keys must have 16 bytes")
+	}
+	if _, err := SM4DecryptWithOpts(key, iv, &bccsp.SM4CBCPKCS7ModeOpts{}); err == nil {
+		t.Fatal("Decrypting a truncated CBC ciphertext should fail")
+	}
+	if _, err := SM4DecryptWithOpts(key, iv, &bccsp.SM4GCMModeOpts{}); err == nil {
+		t.Fatal("Decrypting a truncated GCM ciphertext should fail")
+	}
+}
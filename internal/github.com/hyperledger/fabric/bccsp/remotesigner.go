@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bccsp
+
+import (
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// RemoteSigner is a signing appliance holding key pairs addressed by key label,
+// such as a NetSign server, a KMS-style service or an in-house signer.
+// Private keys never leave the appliance: BCCSP implementations built on
+// a RemoteSigner only handle key labels and public keys.
+type RemoteSigner interface {
+
+	// OpenSession opens a session on the appliance at address (host:port).
+	OpenSession(address, password string) (RemoteSignerSession, error)
+
+	// CheckStatus probes the appliances at addresses. The returned slice holds,
+	// for every address, nil if the appliance is available or the probe error.
+	CheckStatus(addresses []string) []error
+}
+
+// RemoteSignerSession is a session opened on a RemoteSigner.
+// Errors for which IsRemoteSignerUnavailable returns true are counted against the
+// availability of the appliance, so that callers can fail over to another one.
+type RemoteSignerSession interface {
+
+	// GenerateKeyPair generates a key pair under keyLabel and returns a
+	// DER encoded PKCS#10 certificate request for it, with the given subject DN.
+	GenerateKeyPair(keyLabel, subject string) (csr []byte, err error)
+
+	// BindCertificate binds the DER encoded certificate issued for the
+	// key pair under keyLabel to it.
+	BindCertificate(keyLabel string, cert []byte) error
+
+	// Sign signs digest with the private key under keyLabel and returns the
+	// DER encoded signature.
+	Sign(keyLabel string, digest []byte) (signature []byte, err error)
+
+	// Verify verifies signature over digest with the public key under keyLabel.
+	Verify(keyLabel string, digest, signature []byte) (valid bool, err error)
+
+	// DeleteKeyPair deletes the key pair under keyLabel.
+	DeleteKeyPair(keyLabel string) error
+
+	// Close closes the session.
+	Close() error
+}
+
+// RemoteHasher is implemented by the sessions of the RemoteSigners that can hash messages.
+type RemoteHasher interface {
+
+	// Hash hashes msg with the default hash function of the appliance.
+	Hash(msg []byte) (digest []byte, err error)
+}
+
+// RemoteUIDSigner is implemented by the sessions of the RemoteSigners that can sign
+// with the user ID of the signer (see SM2SignerOpts): the appliance signs SM3(ZA || msg).
+type RemoteUIDSigner interface {
+
+	// SignWithUID signs msg with the private key under keyLabel and the user ID uid.
+	SignWithUID(keyLabel string, uid, msg []byte) (signature []byte, err error)
+
+	// VerifyWithUID verifies signature over msg with the public key under keyLabel and the user ID uid.
+	VerifyWithUID(keyLabel string, uid, msg, signature []byte) (valid bool, err error)
+}
+
+// IsRemoteSignerTimeout returns true if err reports that a RemoteSigner did not answer in time
+func IsRemoteSignerTimeout(err error) bool {
+	t, ok := errors.Cause(err).(interface {
+		Timeout() bool
+	})
+	return ok && t.Timeout()
+}
+
+// IsRemoteSignerUnavailable returns true if err reports that a RemoteSigner timed out
+// or could not be reached, e.g. because the connection was refused or reset
+func IsRemoteSignerUnavailable(err error) bool {
+	if IsRemoteSignerTimeout(err) {
+		return true
+	}
+	switch cause := errors.Cause(err).(type) {
+	case interface {
+		Unreachable() bool
+	}:
+		return cause.Unreachable()
+	case *net.OpError:
+		return true
+	case *os.SyscallError:
+		return isConnectionErrno(cause.Err)
+	default:
+		return isConnectionErrno(cause)
+	}
+}
+
+func isConnectionErrno(err error) bool {
+	switch err {
+	case syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE:
+		return true
+	}
+	return false
+}
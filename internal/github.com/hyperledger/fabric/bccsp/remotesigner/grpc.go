@@ -0,0 +1,182 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remotesigner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the name of the gRPC service of the signing service
+const ServiceName = "remotesigner.RemoteSigner"
+
+// Codec is the gRPC codec of the messages of the signing service; servers
+// implementing the service must use it, e.g. with grpc.CustomCodec(Codec{})
+type Codec struct{}
+
+// Marshal marshals v to JSON
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal unmarshals JSON data into v
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name returns the name of the codec
+func (Codec) Name() string {
+	return "json"
+}
+
+// String returns the name of the codec
+func (c Codec) String() string {
+	return c.Name()
+}
+
+// GRPCSigner is a bccsp.RemoteSigner calling a signing service over gRPC with TLS.
+// A connection is kept per address until the signer is closed.
+type GRPCSigner struct {
+	timeout     time.Duration
+	creds       credentials.TransportCredentials // nil for insecure connections
+	dialOptions []grpc.DialOption
+
+	mutex sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// GRPCOption configures a GRPCSigner
+type GRPCOption func(*GRPCSigner) error
+
+// WithGRPCTimeout sets the timeout of the calls, 10s by default
+func WithGRPCTimeout(timeout time.Duration) GRPCOption {
+	return func(s *GRPCSigner) error {
+		s.timeout = timeout
+		return nil
+	}
+}
+
+// WithGRPCTLSConfig calls the service over TLS with the given TLS config
+func WithGRPCTLSConfig(config *tls.Config) GRPCOption {
+	return func(s *GRPCSigner) error {
+		if config == nil {
+			return errors.New("TLS config must not be nil")
+		}
+		s.creds = credentials.NewTLS(config)
+		return nil
+	}
+}
+
+// WithInsecureGRPC calls the service without TLS. Since the password would be sent
+// in cleartext, sessions can then only be opened without a password.
+func WithInsecureGRPC() GRPCOption {
+	return func(s *GRPCSigner) error {
+		s.creds = nil
+		return nil
+	}
+}
+
+// WithDialOptions adds options used to dial the services, e.g. keepalive parameters.
+// The transport security is set with WithGRPCTLSConfig or WithInsecureGRPC.
+func WithDialOptions(opts ...grpc.DialOption) GRPCOption {
+	return func(s *GRPCSigner) error {
+		s.dialOptions = append(s.dialOptions, opts...)
+		return nil
+	}
+}
+
+// NewGRPCSigner returns a bccsp.RemoteSigner calling a signing service over gRPC with TLS,
+// or without TLS with WithInsecureGRPC
+func NewGRPCSigner(opts ...GRPCOption) (*GRPCSigner, error) {
+	s := &GRPCSigner{
+		timeout: defaultTimeout,
+		creds:   credentials.NewTLS(&tls.Config{}),
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, errors.WithMessage(err, "failed to configure gRPC signer")
+		}
+	}
+	return s, nil
+}
+
+// OpenSession opens a session on the service at address. Passwords are never sent without TLS.
+func (s *GRPCSigner) OpenSession(address, password string) (bccsp.RemoteSignerSession, error) {
+	if password != "" && s.creds == nil {
+		return nil, errors.Errorf("refusing to send the password of remote signer [%s] without TLS", address)
+	}
+	return openSession(s.invoke, address, password)
+}
+
+// CheckStatus probes the services at addresses
+func (s *GRPCSigner) CheckStatus(addresses []string) []error {
+	return checkStatus(s.invoke, addresses)
+}
+
+// Close closes the connections to the services
+func (s *GRPCSigner) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var closeErr error
+	for address, conn := range s.conns {
+		if err := conn.Close(); err != nil && closeErr == nil {
+			closeErr = errors.Wrapf(err, "failed to close connection to remote signer [%s]", address)
+		}
+		delete(s.conns, address)
+	}
+	return closeErr
+}
+
+func (s *GRPCSigner) conn(address string) (*grpc.ClientConn, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if conn, ok := s.conns[address]; ok {
+		return conn, nil
+	}
+	transport := grpc.WithInsecure()
+	if s.creds != nil {
+		transport = grpc.WithTransportCredentials(s.creds)
+	}
+	conn, err := grpc.Dial(address, append([]grpc.DialOption{transport}, s.dialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	s.conns[address] = conn
+	return conn, nil
+}
+
+func (s *GRPCSigner) invoke(address, method string, req *Request) (*Response, error) {
+	conn, err := s.conn(address)
+	if err != nil {
+		return nil, &Error{Method: method, Address: address, Err: err, Unavailable: true}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	resp := &Response{}
+	err = conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp, grpc.CallCustomCodec(Codec{}))
+	if err != nil {
+		code := status.Code(err)
+		unavailable := code == codes.Unavailable || code == codes.DeadlineExceeded
+		return nil, &Error{Method: method, Address: address, Err: err, Unavailable: unavailable}
+	}
+	return resp, nil
+}
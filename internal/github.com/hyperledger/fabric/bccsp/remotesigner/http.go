@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remotesigner
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/pkg/errors"
+)
+
+// HTTPSigner is a bccsp.RemoteSigner calling a signing service over HTTP(S)
+type HTTPSigner struct {
+	client *http.Client
+	scheme string
+	path   string
+}
+
+// HTTPOption configures an HTTPSigner
+type HTTPOption func(*HTTPSigner) error
+
+// WithHTTPTimeout sets the timeout of the calls, 10s by default
+func WithHTTPTimeout(timeout time.Duration) HTTPOption {
+	return func(s *HTTPSigner) error {
+		s.client.Timeout = timeout
+		return nil
+	}
+}
+
+// WithTLSConfig calls the service over HTTPS with the given TLS config
+func WithTLSConfig(config *tls.Config) HTTPOption {
+	return func(s *HTTPSigner) error {
+		s.client.Transport = &http.Transport{TLSClientConfig: config}
+		s.scheme = "https"
+		return nil
+	}
+}
+
+// WithInsecureHTTP calls the service over plain HTTP. Since the password would be sent
+// in cleartext, sessions can then only be opened without a password.
+func WithInsecureHTTP() HTTPOption {
+	return func(s *HTTPSigner) error {
+		s.scheme = "http"
+		return nil
+	}
+}
+
+// WithHTTPClient calls the service with the given client, e.g. to add authentication
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(s *HTTPSigner) error {
+		if client == nil {
+			return errors.New("HTTP client must not be nil")
+		}
+		s.client = client
+		return nil
+	}
+}
+
+// WithPath sets the base path of the methods of the service, e.g. /signer/v1
+func WithPath(path string) HTTPOption {
+	return func(s *HTTPSigner) error {
+		s.path = "/" + strings.Trim(path, "/")
+		return nil
+	}
+}
+
+// NewHTTPSigner returns a bccsp.RemoteSigner calling a signing service over HTTPS,
+// or over plain HTTP with WithInsecureHTTP
+func NewHTTPSigner(opts ...HTTPOption) (*HTTPSigner, error) {
+	s := &HTTPSigner{
+		client: &http.Client{Timeout: defaultTimeout},
+		scheme: "https",
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, errors.WithMessage(err, "failed to configure HTTP signer")
+		}
+	}
+	return s, nil
+}
+
+// OpenSession opens a session on the service at address. Passwords are never sent over plain HTTP.
+func (s *HTTPSigner) OpenSession(address, password string) (bccsp.RemoteSignerSession, error) {
+	if password != "" && s.scheme != "https" {
+		return nil, errors.Errorf("refusing to send the password of remote signer [%s] over plain HTTP", address)
+	}
+	return openSession(s.invoke, address, password)
+}
+
+// CheckStatus probes the services at addresses
+func (s *HTTPSigner) CheckStatus(addresses []string) []error {
+	return checkStatus(s.invoke, addresses)
+}
+
+func (s *HTTPSigner) invoke(address, method string, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal %s request", method)
+	}
+
+	url := s.scheme + "://" + address + s.path + "/" + method
+	httpResp, err := s.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, &Error{Method: method, Address: address, Err: err, Unavailable: true}
+	}
+	defer httpResp.Body.Close()
+
+	content, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, &Error{Method: method, Address: address, Err: err, Unavailable: true}
+	}
+
+	resp := &Response{}
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, resp); err != nil && httpResp.StatusCode/100 == 2 {
+			return nil, &Error{Method: method, Address: address, Err: errors.Wrap(err, "invalid response")}
+		}
+	}
+	if httpResp.StatusCode/100 != 2 {
+		msg := resp.Error
+		if msg == "" {
+			msg = httpResp.Status
+		}
+		unavailable := httpResp.StatusCode == http.StatusServiceUnavailable || httpResp.StatusCode == http.StatusGatewayTimeout
+		return nil, &Error{Method: method, Address: address, Err: errors.New(msg), Unavailable: unavailable}
+	}
+	return resp, nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package remotesigner implements bccsp.RemoteSigner for signing services reached over
+// HTTP or gRPC, so that KMS-style services and in-house signers can back the cncc_gm BCCSP.
+//
+// Both transports carry the same JSON messages, byte fields being base64 encoded:
+//
+//	Method          Request                                   Response
+//	OpenSession     {password}                                {session}
+//	CloseSession    {session}                                 {}
+//	GenerateKeyPair {session, keyLabel, subject}              {csr}
+//	BindCertificate {session, keyLabel, certificate}          {}
+//	Sign            {session, keyLabel, digest}               {signature}
+//	                {session, keyLabel, uid, msg}             {signature}
+//	Verify          {session, keyLabel, digest, signature}    {valid}
+//	                {session, keyLabel, uid, msg, signature}  {valid}
+//	Hash            {session, msg}                            {digest}
+//	DeleteKeyPair   {session, keyLabel}                       {}
+//	Status          {}                                        {}
+//
+// Sign and Verify requests carrying an SM2 user ID sign the message itself, as SM3(ZA || msg).
+//
+// Over HTTP every method is a POST to <base URL>/<Method>, failures being reported with a non 2xx
+// status and an {error} body. HTTPS is used unless plain HTTP is explicitly requested. Over gRPC every method is a unary call of the
+// remotesigner.RemoteSigner service using the "json" codec, over TLS unless an insecure connection is explicitly requested.
+// Passwords are only sent over TLS.
+package remotesigner
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/pkg/errors"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Methods of the remote signing service
+const (
+	OpenSession     = "OpenSession"
+	CloseSession    = "CloseSession"
+	GenerateKeyPair = "GenerateKeyPair"
+	BindCertificate = "BindCertificate"
+	Sign            = "Sign"
+	Verify          = "Verify"
+	Hash            = "Hash"
+	DeleteKeyPair   = "DeleteKeyPair"
+	Status          = "Status"
+)
+
+// Request is the request of every method, only the fields of the method being set
+type Request struct {
+	Password    string `json:"password,omitempty"`
+	Session     string `json:"session,omitempty"`
+	KeyLabel    string `json:"keyLabel,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	Certificate []byte `json:"certificate,omitempty"`
+	Digest      []byte `json:"digest,omitempty"`
+	Signature   []byte `json:"signature,omitempty"`
+	Msg         []byte `json:"msg,omitempty"`
+	UID         []byte `json:"uid,omitempty"`
+}
+
+// Response is the response of every method, only the fields of the method being set
+type Response struct {
+	Session   string `json:"session,omitempty"`
+	CSR       []byte `json:"csr,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+	Valid     bool   `json:"valid,omitempty"`
+	Digest    []byte `json:"digest,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Error is the error of a failed call. Calls that could not reach the service
+// or timed out are reported as timeouts, see bccsp.IsRemoteSignerTimeout.
+type Error struct {
+	Method      string
+	Address     string
+	Err         error
+	Unavailable bool
+}
+
+func (e *Error) Error() string {
+	return "remote signer [" + e.Address + "] " + e.Method + " failed: " + e.Err.Error()
+}
+
+// Timeout returns true if the service could not be reached or did not answer in time
+func (e *Error) Timeout() bool {
+	return e.Unavailable
+}
+
+// invoker calls method on the service at address
+type invoker func(address, method string, req *Request) (*Response, error)
+
+// session is a session opened on a signing service, whatever the transport
+type session struct {
+	invoke  invoker
+	address string
+	id      string
+}
+
+func openSession(invoke invoker, address, password string) (bccsp.RemoteSignerSession, error) {
+	resp, err := invoke(address, OpenSession, &Request{Password: password})
+	if err != nil {
+		return nil, err
+	}
+	return &session{invoke: invoke, address: address, id: resp.Session}, nil
+}
+
+func checkStatus(invoke invoker, addresses []string) []error {
+	errs := make([]error, len(addresses))
+	for i, address := range addresses {
+		_, errs[i] = invoke(address, Status, &Request{})
+	}
+	return errs
+}
+
+func (s *session) call(method string, req *Request) (*Response, error) {
+	req.Session = s.id
+	return s.invoke(s.address, method, req)
+}
+
+func (s *session) GenerateKeyPair(keyLabel, subject string) ([]byte, error) {
+	resp, err := s.call(GenerateKeyPair, &Request{KeyLabel: keyLabel, Subject: subject})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.CSR) == 0 {
+		return nil, errors.Errorf("remote signer [%s] returned no certificate request", s.address)
+	}
+	return resp.CSR, nil
+}
+
+func (s *session) BindCertificate(keyLabel string, cert []byte) error {
+	_, err := s.call(BindCertificate, &Request{KeyLabel: keyLabel, Certificate: cert})
+	return err
+}
+
+func (s *session) Sign(keyLabel string, digest []byte) ([]byte, error) {
+	resp, err := s.call(Sign, &Request{KeyLabel: keyLabel, Digest: digest})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Signature) == 0 {
+		return nil, errors.Errorf("remote signer [%s] returned no signature", s.address)
+	}
+	return resp.Signature, nil
+}
+
+func (s *session) Verify(keyLabel string, digest, signature []byte) (bool, error) {
+	resp, err := s.call(Verify, &Request{KeyLabel: keyLabel, Digest: digest, Signature: signature})
+	if err != nil {
+		return false, err
+	}
+	return resp.Valid, nil
+}
+
+func (s *session) SignWithUID(keyLabel string, uid, msg []byte) ([]byte, error) {
+	resp, err := s.call(Sign, &Request{KeyLabel: keyLabel, UID: uid, Msg: msg})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Signature) == 0 {
+		return nil, errors.Errorf("remote signer [%s] returned no signature", s.address)
+	}
+	return resp.Signature, nil
+}
+
+func (s *session) VerifyWithUID(keyLabel string, uid, msg, signature []byte) (bool, error) {
+	resp, err := s.call(Verify, &Request{KeyLabel: keyLabel, UID: uid, Msg: msg, Signature: signature})
+	if err != nil {
+		return false, err
+	}
+	return resp.Valid, nil
+}
+
+func (s *session) Hash(msg []byte) ([]byte, error) {
+	resp, err := s.call(Hash, &Request{Msg: msg})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Digest, nil
+}
+
+func (s *session) DeleteKeyPair(keyLabel string) error {
+	_, err := s.call(DeleteKeyPair, &Request{KeyLabel: keyLabel})
+	return err
+}
+
+func (s *session) Close() error {
+	_, err := s.call(CloseSession, &Request{})
+	return err
+}
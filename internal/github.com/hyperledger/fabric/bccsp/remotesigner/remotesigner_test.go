@@ -0,0 +1,389 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+const testPassword = "11111111"
+
+// testService is a signing service "signing" digests by reversing them
+type testService struct {
+	mutex    sync.Mutex
+	sessions map[string]bool
+	keys     map[string][]byte
+}
+
+func newTestService() *testService {
+	return &testService{sessions: make(map[string]bool), keys: make(map[string][]byte)}
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// signed returns what the test service signs: the digest, or the user ID and the message
+func signed(req *Request) []byte {
+	if req.UID != nil {
+		return append(append([]byte{}, req.UID...), req.Msg...)
+	}
+	return req.Digest
+}
+
+func (s *testService) call(method string, req *Request) (*Response, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch method {
+	case OpenSession:
+		if req.Password != testPassword {
+			return nil, errors.New("invalid password")
+		}
+		id := string(rune('a' + len(s.sessions)))
+		s.sessions[id] = true
+		return &Response{Session: id}, nil
+	case Status:
+		return &Response{}, nil
+	}
+
+	if !s.sessions[req.Session] {
+		return nil, errors.Errorf("unknown session [%s]", req.Session)
+	}
+	switch method {
+	case CloseSession:
+		delete(s.sessions, req.Session)
+		return &Response{}, nil
+	case GenerateKeyPair:
+		s.keys[req.KeyLabel] = nil
+		return &Response{CSR: []byte(req.Subject)}, nil
+	case Hash:
+		return &Response{Digest: reverse(req.Msg)}, nil
+	}
+
+	cert, ok := s.keys[req.KeyLabel]
+	if !ok {
+		return nil, errors.Errorf("unknown key [%s]", req.KeyLabel)
+	}
+	switch method {
+	case BindCertificate:
+		s.keys[req.KeyLabel] = req.Certificate
+		return &Response{}, nil
+	case Sign:
+		if cert == nil {
+			return nil, errors.Errorf("no certificate bound to key [%s]", req.KeyLabel)
+		}
+		return &Response{Signature: reverse(signed(req))}, nil
+	case Verify:
+		return &Response{Valid: bytes.Equal(req.Signature, reverse(signed(req)))}, nil
+	case DeleteKeyPair:
+		delete(s.keys, req.KeyLabel)
+		return &Response{}, nil
+	}
+	return nil, errors.Errorf("unknown method [%s]", method)
+}
+
+func (s *testService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/signer/")
+	req := &Request{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	resp, err := s.call(method, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		resp = &Response{Error: err.Error()}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serviceDesc describes the test service to the gRPC server
+func (s *testService) serviceDesc() *grpc.ServiceDesc {
+	desc := &grpc.ServiceDesc{ServiceName: ServiceName, HandlerType: (*interface{})(nil)}
+	for _, method := range []string{OpenSession, CloseSession, GenerateKeyPair, BindCertificate, Sign, Verify, Hash, DeleteKeyPair, Status} {
+		method := method
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: method,
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &Request{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				resp, err := s.call(method, req)
+				if err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+				return resp, nil
+			},
+		})
+	}
+	return desc
+}
+
+func testSigner(t *testing.T, signer bccsp.RemoteSigner, address string) {
+	if _, err := signer.OpenSession(address, "wrong"); err == nil || bccsp.IsRemoteSignerTimeout(err) {
+		t.Fatalf("expected non timeout error for invalid password, got %v", err)
+	}
+
+	session, err := signer.OpenSession(address, testPassword)
+	if err != nil {
+		t.Fatalf("OpenSession failed: %s", err)
+	}
+
+	csr, err := session.GenerateKeyPair("key1", "CN=peer0")
+	if err != nil || string(csr) != "CN=peer0" {
+		t.Fatalf("GenerateKeyPair failed: %s, %v", csr, err)
+	}
+	if _, err := session.Sign("key1", []byte{1, 2, 3}); err == nil {
+		t.Fatal("expected error signing before binding the certificate")
+	}
+	if err := session.BindCertificate("key1", []byte("cert")); err != nil {
+		t.Fatalf("BindCertificate failed: %s", err)
+	}
+
+	digest := []byte{1, 2, 3}
+	signature, err := session.Sign("key1", digest)
+	if err != nil {
+		t.Fatalf("Sign failed: %s", err)
+	}
+	valid, err := session.Verify("key1", digest, signature)
+	if err != nil || !valid {
+		t.Fatalf("Verify failed: %t, %v", valid, err)
+	}
+	valid, err = session.Verify("key1", []byte{4}, signature)
+	if err != nil || valid {
+		t.Fatalf("expected invalid signature, got %t, %v", valid, err)
+	}
+
+	hasher, ok := session.(bccsp.RemoteHasher)
+	if !ok {
+		t.Fatal("session should implement bccsp.RemoteHasher")
+	}
+	if h, err := hasher.Hash(digest); err != nil || !bytes.Equal(h, reverse(digest)) {
+		t.Fatalf("Hash failed: %v, %v", h, err)
+	}
+
+	uidSigner, ok := session.(bccsp.RemoteUIDSigner)
+	if !ok {
+		t.Fatal("session should implement bccsp.RemoteUIDSigner")
+	}
+	signature, err = uidSigner.SignWithUID("key1", []byte("uid"), digest)
+	if err != nil || !bytes.Equal(signature, reverse([]byte("uid\x01\x02\x03"))) {
+		t.Fatalf("SignWithUID failed: %v, %v", signature, err)
+	}
+	valid, err = uidSigner.VerifyWithUID("key1", []byte("uid"), digest, signature)
+	if err != nil || !valid {
+		t.Fatalf("VerifyWithUID failed: %t, %v", valid, err)
+	}
+	valid, err = uidSigner.VerifyWithUID("key1", []byte("other"), digest, signature)
+	if err != nil || valid {
+		t.Fatalf("expected invalid signature for another user ID, got %t, %v", valid, err)
+	}
+
+	if err := session.DeleteKeyPair("key1"); err != nil {
+		t.Fatalf("DeleteKeyPair failed: %s", err)
+	}
+	if _, err := session.Sign("key1", digest); err == nil {
+		t.Fatal("expected error signing with deleted key")
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+	if _, err := session.GenerateKeyPair("key2", "CN=peer0"); err == nil {
+		t.Fatal("expected error on closed session")
+	}
+
+	errs := signer.CheckStatus([]string{address})
+	if len(errs) != 1 || errs[0] != nil {
+		t.Fatalf("CheckStatus failed: %v", errs)
+	}
+}
+
+// unusedAddress returns an address nothing listens on
+func unusedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %s", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+func TestHTTPSigner(t *testing.T) {
+	server := httptest.NewTLSServer(newTestService())
+	defer server.Close()
+
+	signer, err := NewHTTPSigner(WithHTTPClient(server.Client()), WithPath("/signer/"), WithHTTPTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("NewHTTPSigner failed: %s", err)
+	}
+	testSigner(t, signer, strings.TrimPrefix(server.URL, "https://"))
+
+	address := unusedAddress(t)
+	if _, err := signer.OpenSession(address, testPassword); !bccsp.IsRemoteSignerTimeout(err) {
+		t.Fatalf("expected timeout for unreachable service, got %v", err)
+	}
+	if errs := signer.CheckStatus([]string{address}); !bccsp.IsRemoteSignerTimeout(errs[0]) {
+		t.Fatalf("expected timeout for unreachable service, got %v", errs[0])
+	}
+
+	if _, err := NewHTTPSigner(WithHTTPClient(nil)); err == nil {
+		t.Fatal("expected error for nil HTTP client")
+	}
+}
+
+func TestHTTPSignerUnavailable(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	signer, err := NewHTTPSigner(WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("NewHTTPSigner failed: %s", err)
+	}
+	_, err = signer.OpenSession(strings.TrimPrefix(server.URL, "https://"), testPassword)
+	if !bccsp.IsRemoteSignerTimeout(err) {
+		t.Fatalf("expected timeout for unavailable service, got %v", err)
+	}
+}
+
+func TestHTTPSignerPlainHTTP(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"session":"a"}`))
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	// HTTPS is used by default
+	signer, err := NewHTTPSigner(WithHTTPTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("NewHTTPSigner failed: %s", err)
+	}
+	if _, err := signer.OpenSession(address, testPassword); err == nil {
+		t.Fatal("expected HTTPS call to a plain HTTP service to fail")
+	}
+
+	// passwords are never sent in cleartext
+	signer, err = NewHTTPSigner(WithInsecureHTTP())
+	if err != nil {
+		t.Fatalf("NewHTTPSigner failed: %s", err)
+	}
+	_, err = signer.OpenSession(address, testPassword)
+	if err == nil || !strings.Contains(err.Error(), "over plain HTTP") {
+		t.Fatalf("expected password to be refused over plain HTTP, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("expected no request to be sent, got %d", requests)
+	}
+	if _, err := signer.OpenSession(address, ""); err != nil {
+		t.Fatalf("OpenSession without password failed: %s", err)
+	}
+}
+
+// newTestTLSConfigs returns the TLS config of a server and the one of a client trusting it
+func newTestTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	return &tls.Config{Certificates: server.TLS.Certificates}, &tls.Config{RootCAs: roots}
+}
+
+// startGRPCService serves a test service over gRPC, with TLS if serverConfig is not nil
+func startGRPCService(t *testing.T, serverConfig *tls.Config) (*grpc.Server, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %s", err)
+	}
+	opts := []grpc.ServerOption{grpc.CustomCodec(Codec{})}
+	if serverConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(serverConfig)))
+	}
+	service := newTestService()
+	server := grpc.NewServer(opts...)
+	server.RegisterService(service.serviceDesc(), service)
+	go server.Serve(listener)
+	return server, listener.Addr().String()
+}
+
+func TestGRPCSigner(t *testing.T) {
+	serverConfig, clientConfig := newTestTLSConfigs(t)
+	server, address := startGRPCService(t, serverConfig)
+	defer server.Stop()
+
+	signer, err := NewGRPCSigner(WithGRPCTimeout(time.Second), WithGRPCTLSConfig(clientConfig))
+	if err != nil {
+		t.Fatalf("NewGRPCSigner failed: %s", err)
+	}
+	defer signer.Close()
+	testSigner(t, signer, address)
+
+	if _, err := signer.OpenSession(unusedAddress(t), testPassword); !bccsp.IsRemoteSignerTimeout(err) {
+		t.Fatalf("expected timeout for unreachable service, got %v", err)
+	}
+
+	if _, err := NewGRPCSigner(WithGRPCTLSConfig(nil)); err == nil {
+		t.Fatal("expected error for nil TLS config")
+	}
+}
+
+func TestGRPCSignerInsecure(t *testing.T) {
+	server, address := startGRPCService(t, nil)
+	defer server.Stop()
+
+	// TLS is used by default
+	signer, err := NewGRPCSigner(WithGRPCTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("NewGRPCSigner failed: %s", err)
+	}
+	defer signer.Close()
+	if errs := signer.CheckStatus([]string{address}); errs[0] == nil {
+		t.Fatal("expected TLS call to an insecure service to fail")
+	}
+
+	// passwords are never sent in cleartext
+	signer, err = NewGRPCSigner(WithGRPCTimeout(time.Second), WithInsecureGRPC())
+	if err != nil {
+		t.Fatalf("NewGRPCSigner failed: %s", err)
+	}
+	defer signer.Close()
+	_, err = signer.OpenSession(address, testPassword)
+	if err == nil || !strings.Contains(err.Error(), "without TLS") {
+		t.Fatalf("expected password to be refused without TLS, got %v", err)
+	}
+	if errs := signer.CheckStatus([]string{address}); errs[0] != nil {
+		t.Fatalf("CheckStatus failed: %s", errs[0])
+	}
+}
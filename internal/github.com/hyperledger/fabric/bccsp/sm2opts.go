@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bccsp
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+)
+
+// SM2CiphertextFormat is the encoding of an SM2 ciphertext
+type SM2CiphertextFormat int
+
+const (
+	// SM2C1C3C2 is the layout of GM/T 0003-2012: 0x04 || C1 || C3 || C2.
+	// It is the default format.
+	SM2C1C3C2 SM2CiphertextFormat = iota
+
+	// SM2C1C2C3 is the layout of the draft standard still used by some devices:
+	// 0x04 || C1 || C2 || C3.
+	SM2C1C2C3
+
+	// SM2ASN1 is the DER encoding of GM/T 0009-2012.
+	SM2ASN1
+)
+
+// SM2EncrypterOpts contains options for SM2 public key encryption.
+// Nil opts encrypt to the SM2C1C3C2 format.
+type SM2EncrypterOpts struct {
+	Format SM2CiphertextFormat
+}
+
+// SM2DecrypterOpts contains options for SM2 private key decryption.
+// Nil opts decrypt the SM2C1C3C2 format.
+type SM2DecrypterOpts struct {
+	Format SM2CiphertextFormat
+}
+
+// DefaultSM2UID is the user ID of GB/T 35276-2017, used when no user ID is given
+const DefaultSM2UID = "1234567812345678"
+
+// SM2SignerOpts contains options for signing and verifying with the user ID
+// of the signer, as specified by GB/T 32918.2: the signature is computed over
+// SM3(ZA || msg), ZA binding the user ID to the public key of the signer.
+// With these opts Sign and Verify take the message itself instead of its digest.
+// Without them, the digest passed is signed as is.
+type SM2SignerOpts struct {
+	// UID is the distinguishing identifier of the signer. DefaultSM2UID is used if empty.
+	UID []byte
+}
+
+// HashFunc returns 0: the message is hashed, with ZA, by the signer
+func (opts *SM2SignerOpts) HashFunc() crypto.Hash {
+	return 0
+}
+
+// UserID returns the user ID to sign or verify with
+func (opts *SM2SignerOpts) UserID() []byte {
+	if len(opts.UID) == 0 {
+		return []byte(DefaultSM2UID)
+	}
+	return opts.UID
+}
+
+// EncryptSM2 encrypts plaintext with the SM2 public key k to the ciphertext format of opts,
+// SM2C1C3C2 if opts is nil
+func EncryptSM2(k *sm2.PublicKey, plaintext []byte, opts EncrypterOpts) ([]byte, error) {
+	format := SM2C1C3C2
+	switch o := opts.(type) {
+	case nil:
+	case *SM2EncrypterOpts:
+		format = o.Format
+	case SM2EncrypterOpts:
+		format = o.Format
+	default:
+		return nil, fmt.Errorf("Unsupported EncrypterOpts provided [%T]", opts)
+	}
+
+	switch format {
+	case SM2C1C3C2:
+		return sm2.EncryptWithMode(k, plaintext, sm2.C1C3C2)
+	case SM2C1C2C3:
+		return sm2.EncryptWithMode(k, plaintext, sm2.C1C2C3)
+	case SM2ASN1:
+		return sm2.EncryptAsn1(k, plaintext)
+	default:
+		return nil, fmt.Errorf("Unsupported SM2 ciphertext format [%d]", format)
+	}
+}
+
+// DecryptSM2 decrypts the ciphertext, in the format of opts (SM2C1C3C2 if opts is nil),
+// with the SM2 private key k
+func DecryptSM2(k *sm2.PrivateKey, ciphertext []byte, opts DecrypterOpts) ([]byte, error) {
+	format := SM2C1C3C2
+	switch o := opts.(type) {
+	case nil:
+	case *SM2DecrypterOpts:
+		format = o.Format
+	case SM2DecrypterOpts:
+		format = o.Format
+	default:
+		return nil, fmt.Errorf("Unsupported DecrypterOpts provided [%T]", opts)
+	}
+
+	switch format {
+	case SM2C1C3C2:
+		return sm2.DecryptWithMode(k, ciphertext, sm2.C1C3C2)
+	case SM2C1C2C3:
+		return sm2.DecryptWithMode(k, ciphertext, sm2.C1C2C3)
+	case SM2ASN1:
+		return sm2.DecryptAsn1(k, ciphertext)
+	default:
+		return nil, fmt.Errorf("Unsupported SM2 ciphertext format [%d]", format)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bccsp
+
+const (
+	// HMACSM3 keyed-hash message authentication code using SM3
+	HMACSM3 = "HMAC_SM3"
+	// HKDFSM3 HMAC-based key derivation function (RFC 5869) using SM3
+	HKDFSM3 = "HKDF_SM3"
+)
+
+// HMACSM3DeriveKeyOpts contains options for HMAC-SM3 key derivation.
+// The derived key is the SM4 key made of the first 16 bytes of
+// HMAC-SM3(k, Arg).
+type HMACSM3DeriveKeyOpts struct {
+	Temporary bool
+	Arg       []byte
+}
+
+// Algorithm returns the key derivation algorithm identifier (to be used).
+func (opts *HMACSM3DeriveKeyOpts) Algorithm() string {
+	return HMACSM3
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *HMACSM3DeriveKeyOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// Argument returns the argument to be passed to the HMAC
+func (opts *HMACSM3DeriveKeyOpts) Argument() []byte {
+	return opts.Arg
+}
+
+// HKDFSM3DeriveKeyOpts contains options for HKDF-SM3 key derivation.
+// The derived key is a 16 bytes SM4 key expanded from k with Salt and Info.
+type HKDFSM3DeriveKeyOpts struct {
+	Temporary bool
+	// Salt is optional. A nil salt is replaced by a string of zeros.
+	Salt []byte
+	// Info binds the derived key to its context, e.g. a channel or a purpose.
+	Info []byte
+}
+
+// Algorithm returns the key derivation algorithm identifier (to be used).
+func (opts *HKDFSM3DeriveKeyOpts) Algorithm() string {
+	return HKDFSM3
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *HKDFSM3DeriveKeyOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// SM3HMACOpts contains options for computing HMAC-SM3 with Hash and GetHash.
+// Key must be a symmetric key of the BCCSP, e.g. an SM4 key generated,
+// imported or derived by it. The keys of an SDK crypto suite are given
+// with the HMAC options of the suite, which unwraps them.
+type SM3HMACOpts struct {
+	Key Key
+}
+
+// Algorithm returns the hash algorithm identifier (to be used).
+func (opts *SM3HMACOpts) Algorithm() string {
+	return HMACSM3
+}
@@ -0,0 +1,51 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bccsp
+
+import "io"
+
+// SM4CBCPKCS7ModeOpts contains options for SM4 encryption in CBC mode
+// with PKCS7 padding. The ciphertext is prefixed with the IV.
+// Notice that both IV and PRNG can be nil. In that case, the BCCSP implementation
+// is supposed to sample the IV using a cryptographic secure PRNG.
+// Notice also that either IV or PRNG can be different from nil.
+type SM4CBCPKCS7ModeOpts struct {
+	// IV is the initialization vector to be used by the underlying cipher.
+	// The length of IV must be the same as the Block's block size.
+	// It is used only if different from nil.
+	IV []byte
+	// PRNG is an instance of a PRNG to be used by the underlying cipher.
+	// It is used only if different from nil.
+	PRNG io.Reader
+}
+
+// SM4CTRModeOpts contains options for SM4 encryption in CTR mode.
+// The ciphertext is prefixed with the IV and has the length of the plaintext.
+// IV and PRNG are used as in SM4CBCPKCS7ModeOpts; an IV must never be reused with the same key.
+type SM4CTRModeOpts struct {
+	// IV is the initial counter block. Its length must be the block size.
+	// It is used only if different from nil.
+	IV []byte
+	// PRNG is an instance of a PRNG used to sample the IV.
+	// It is used only if different from nil.
+	PRNG io.Reader
+}
+
+// SM4GCMModeOpts contains options for authenticated SM4 encryption in GCM mode.
+// The ciphertext is prefixed with the nonce and followed by the 16 bytes tag.
+// Nonce and PRNG are used as IV and PRNG in SM4CBCPKCS7ModeOpts; a nonce must
+// never be reused with the same key.
+type SM4GCMModeOpts struct {
+	// Nonce is the 12 bytes nonce. It is used only if different from nil.
+	Nonce []byte
+	// AdditionalData is authenticated but not encrypted. The same data must
+	// be passed for decryption.
+	AdditionalData []byte
+	// PRNG is an instance of a PRNG used to sample the nonce.
+	// It is used only if different from nil.
+	PRNG io.Reader
+}
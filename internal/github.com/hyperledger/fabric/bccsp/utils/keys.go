@@ -30,6 +30,8 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 )
 
 // struct to hold info required for PKCS#8
@@ -146,8 +148,13 @@ func PrivateKeyToPEM(privateKey interface{}, pwd []byte) ([]byte, error) {
 				Bytes: raw,
 			},
 		), nil
+	case *sm2.PrivateKey:
+		if k == nil {
+			return nil, errors.New("Invalid sm2 private key. It must be different from nil.")
+		}
+		return sm2.WritePrivateKeytoMem(k, nil)
 	default:
-		return nil, errors.New("Invalid key type. It must be *ecdsa.PrivateKey or *rsa.PrivateKey")
+		return nil, errors.New("Invalid key type. It must be *ecdsa.PrivateKey, *rsa.PrivateKey or *sm2.PrivateKey")
 	}
 }
 
@@ -162,27 +169,30 @@ func PrivateKeyToEncryptedPEM(privateKey interface{}, pwd []byte) ([]byte, error
 		if k == nil {
 			return nil, errors.New("Invalid ecdsa private key. It must be different from nil.")
 		}
-		raw, err := x509.MarshalECPrivateKey(k)
-
+		raw, err := x509.MarshalPKCS8PrivateKey(k)
 		if err != nil {
 			return nil, err
 		}
 
-		block, err := x509.EncryptPEMBlock(
-			rand.Reader,
-			"PRIVATE KEY",
-			raw,
-			pwd,
-			x509.PEMCipherAES256)
-
+		// ecdsa keys are encrypted in PKCS#8 with PBES2, as the sm2 keys
+		encrypted, err := sm2.EncryptPKCS8PrivateKey(raw, pwd)
 		if err != nil {
 			return nil, err
 		}
 
-		return pem.EncodeToMemory(block), nil
-
+		return pem.EncodeToMemory(
+			&pem.Block{
+				Type:  "ENCRYPTED PRIVATE KEY",
+				Bytes: encrypted,
+			},
+		), nil
+	case *sm2.PrivateKey:
+		if k == nil {
+			return nil, errors.New("Invalid sm2 private key. It must be different from nil.")
+		}
+		return sm2.WritePrivateKeytoMem(k, pwd)
 	default:
-		return nil, errors.New("Invalid key type. It must be *ecdsa.PrivateKey")
+		return nil, errors.New("Invalid key type. It must be *ecdsa.PrivateKey or *sm2.PrivateKey")
 	}
 }
 
@@ -206,7 +216,15 @@ func DERToPrivateKey(der []byte) (key interface{}, err error) {
 		return
 	}
 
-	return nil, errors.New("Invalid key type. The DER must contain an rsa.PrivateKey or ecdsa.PrivateKey")
+	if key, err = sm2.ParsePKCS8UnecryptedPrivateKey(der); err == nil {
+		return
+	}
+
+	if key, err = sm2.ParseSm2PrivateKey(der); err == nil {
+		return
+	}
+
+	return nil, errors.New("Invalid key type. The DER must contain an rsa.PrivateKey, ecdsa.PrivateKey or sm2.PrivateKey")
 }
 
 // PEMtoPrivateKey unmarshals a pem to private key
@@ -221,6 +239,22 @@ func PEMtoPrivateKey(raw []byte, pwd []byte) (interface{}, error) {
 
 	// TODO: derive from header the type of the key
 
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		// PKCS#8 encrypted with PBES2, the legacy encrypted PEM blocks below are still read
+		if len(pwd) == 0 {
+			return nil, errors.New("Encrypted Key. Need a password")
+		}
+		decrypted, err := sm2.DecryptPKCS8PrivateKey(block.Bytes, pwd)
+		if err != nil {
+			return nil, fmt.Errorf("Failed PKCS#8 decryption [%s]", err)
+		}
+		key, err := DERToPrivateKey(decrypted)
+		if err != nil {
+			return nil, fmt.Errorf("Failed PKCS#8 decryption [%s]", err)
+		}
+		return key, nil
+	}
+
 	if x509.IsEncryptedPEMBlock(block) {
 		if len(pwd) == 0 {
 			return nil, errors.New("Encrypted Key. Need a password")
@@ -21,6 +21,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	factory "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/sdkpatch/cryptosuitebridge"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	m "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/pkg/errors"
@@ -171,6 +172,21 @@ func (msp *bccspmsp) getTLSCertFromPem(idBytes []byte) (*x509.Certificate, error
 	return cert, nil
 }
 
+// getSM2TLSCertFromPem returns the TLS certificate of idBytes if it is an SM2 one, which
+// crypto/x509 does not parse, and nil otherwise
+func getSM2TLSCertFromPem(idBytes []byte) *verifier.Certificate {
+	pemCert, _ := pem.Decode(idBytes)
+	if pemCert == nil {
+		return nil
+	}
+
+	cert, err := verifier.ParseCertificate(pemCert.Bytes)
+	if err != nil || cert.Algorithm != verifier.SM2 {
+		return nil
+	}
+	return cert
+}
+
 func (msp *bccspmsp) getIdentityFromConf(idBytes []byte) (Identity, core.Key, error) {
 	// get a cert
 	cert, err := msp.getCertFromPem(idBytes)
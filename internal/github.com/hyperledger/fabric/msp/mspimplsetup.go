@@ -20,6 +20,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	bccsp "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/sdkpatch/cryptosuitebridge"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	m "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	errors "github.com/pkg/errors"
 )
@@ -324,11 +325,19 @@ func (msp *bccspmsp) setupOUs(conf *m.FabricMSPConfig) error {
 func (msp *bccspmsp) setupTLSCAs(conf *m.FabricMSPConfig) error {
 
 	opts := &x509.VerifyOptions{Roots: x509.NewCertPool(), Intermediates: x509.NewCertPool()}
+	// SM2 TLS CAs are not supported by crypto/x509, the verifier validates them
+	var sm2RootCerts, sm2IntermediateCerts []*verifier.Certificate
 
 	// Load TLS root and intermediate CA identities
 	msp.tlsRootCerts = make([][]byte, len(conf.TlsRootCerts))
 	rootCerts := make([]*x509.Certificate, len(conf.TlsRootCerts))
 	for i, trustedCert := range conf.TlsRootCerts {
+		if sm2Cert := getSM2TLSCertFromPem(trustedCert); sm2Cert != nil {
+			sm2RootCerts = append(sm2RootCerts, sm2Cert)
+			msp.tlsRootCerts[i] = trustedCert
+			continue
+		}
+
 		cert, err := msp.getTLSCertFromPem(trustedCert)
 		if err != nil {
 			return err
@@ -343,6 +352,12 @@ func (msp *bccspmsp) setupTLSCAs(conf *m.FabricMSPConfig) error {
 	msp.tlsIntermediateCerts = make([][]byte, len(conf.TlsIntermediateCerts))
 	intermediateCerts := make([]*x509.Certificate, len(conf.TlsIntermediateCerts))
 	for i, trustedCert := range conf.TlsIntermediateCerts {
+		if sm2Cert := getSM2TLSCertFromPem(trustedCert); sm2Cert != nil {
+			sm2IntermediateCerts = append(sm2IntermediateCerts, sm2Cert)
+			msp.tlsIntermediateCerts[i] = trustedCert
+			continue
+		}
+
 		cert, err := msp.getTLSCertFromPem(trustedCert)
 		if err != nil {
 			return err
@@ -371,6 +386,19 @@ func (msp *bccspmsp) setupTLSCAs(conf *m.FabricMSPConfig) error {
 		}
 	}
 
+	for _, cert := range append(append([]*verifier.Certificate{}, sm2RootCerts...), sm2IntermediateCerts...) {
+		if !cert.SM2().IsCA {
+			return errors.Errorf("CA Certificate did not have the CA attribute, (SN: %x)", cert.SerialNumber)
+		}
+		if _, err := getSubjectKeyIdentifierFromCert(cert.SM2()); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("CA Certificate problem with Subject Key Identifier extension, (SN: %x)", cert.SerialNumber))
+		}
+
+		if err := msp.validateSM2TLSCAIdentity(cert, sm2RootCerts, sm2IntermediateCerts); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("CA Certificate is not valid, (SN: %s)", cert.SerialNumber))
+		}
+	}
+
 	return nil
 }
 
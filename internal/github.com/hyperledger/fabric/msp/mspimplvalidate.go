@@ -20,6 +20,7 @@ import (
 	"time"
 	
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/pkg/errors"
 )
 
@@ -76,6 +77,47 @@ func (msp *bccspmsp) validateTLSCAIdentity(cert *x509.Certificate, opts *x509.Ve
 	return msp.validateTLSCertAgainstChain(cert, validationChain)
 }
 
+// validateSM2TLSCAIdentity validates an SM2 TLS CA, whose chain and CRL signatures are checked by the verifier
+func (msp *bccspmsp) validateSM2TLSCAIdentity(cert *verifier.Certificate, roots, intermediates []*verifier.Certificate) error {
+	validationChain, err := cert.Verify(roots, intermediates)
+	if err != nil {
+		return errors.WithMessage(err, "could not obtain certification chain")
+	}
+	if len(validationChain) == 1 {
+		// validationChain[0] is the root CA certificate
+		return nil
+	}
+
+	// identify the SKI of the CA that signed this cert
+	SKI, err := getSubjectKeyIdentifierFromCert(validationChain[1].SM2())
+	if err != nil {
+		return errors.WithMessage(err, "could not obtain Subject Key Identifier for signer cert")
+	}
+
+	for _, crl := range msp.CRL {
+		aki, err := getAuthorityKeyIdentifierFromCrl(crl)
+		if err != nil {
+			return errors.WithMessage(err, "could not obtain Authority Key Identifier for crl")
+		}
+		if !bytes.Equal(aki, SKI) {
+			continue
+		}
+		for _, rc := range crl.TBSCertList.RevokedCertificates {
+			if rc.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+				continue
+			}
+			// as for the other CAs, a CRL not signed by the signer of the cert is skipped
+			if err := validationChain[1].CheckCRLSignature(crl); err != nil {
+				mspLogger.Warningf("Invalid signature over the identified CRL, error %+v", err)
+				continue
+			}
+			return errors.New("The certificate has been revoked")
+		}
+	}
+
+	return nil
+}
+
 func (msp *bccspmsp) validateIdentityAgainstChain(id *identity, validationChain []*sm2.Certificate) error {
 	return msp.validateCertAgainstChain(id.cert, validationChain)
 }
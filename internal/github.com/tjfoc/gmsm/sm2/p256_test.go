@@ -0,0 +1,218 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sm2
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func randFieldInt(t *testing.T) *big.Int {
+	n, err := rand.Int(rand.Reader, P256Sm2().Params().P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// 域运算与 big.Int 的计算结果对比
+func TestP256FieldOps(t *testing.T) {
+	p := P256Sm2().Params().P
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), pm1}
+	for i := 0; i < 64; i++ {
+		values = append(values, randFieldInt(t))
+	}
+
+	var one sm2P256FieldElement
+	sm2P256FromBig(&one, big.NewInt(1))
+	if one != sm2P256One {
+		t.Fatalf("sm2P256One = %x, want %x", sm2P256One, one)
+	}
+
+	for i, a := range values {
+		b := values[(i+1)%len(values)]
+		var fa, fb, fc sm2P256FieldElement
+		sm2P256FromBig(&fa, a)
+		sm2P256FromBig(&fb, b)
+		if got := sm2P256ToBig(&fa); got.Cmp(a) != 0 {
+			t.Fatalf("round trip of %x = %x", a, got)
+		}
+
+		sm2P256Add(&fc, &fa, &fb)
+		want := new(big.Int).Add(a, b)
+		checkField(t, "add", &fc, want.Mod(want, p))
+
+		sm2P256Sub(&fc, &fa, &fb)
+		want = new(big.Int).Sub(a, b)
+		checkField(t, "sub", &fc, want.Mod(want, p))
+
+		sm2P256Mul(&fc, &fa, &fb)
+		want = new(big.Int).Mul(a, b)
+		checkField(t, "mul", &fc, want.Mod(want, p))
+
+		sm2P256Square(&fc, &fa)
+		want = new(big.Int).Mul(a, a)
+		checkField(t, "square", &fc, want.Mod(want, p))
+
+		sm2P256Invert(&fc, &fa)
+		want = new(big.Int).ModInverse(a, p)
+		if want == nil {
+			want = new(big.Int)
+		}
+		checkField(t, "invert", &fc, want)
+	}
+}
+
+func checkField(t *testing.T, op string, got *sm2P256FieldElement, want *big.Int) {
+	t.Helper()
+	for i := 3; i >= 0; i-- {
+		if got[i] != sm2P256Prime[i] {
+			if got[i] > sm2P256Prime[i] {
+				t.Fatalf("%s: result %x is not fully reduced", op, *got)
+			}
+			break
+		}
+	}
+	if g := sm2P256ToBig(got); g.Cmp(want) != 0 {
+		t.Fatalf("%s = %x, want %x", op, g, want)
+	}
+}
+
+// 点运算及标量乘与 elliptic.CurveParams 的通用实现对比
+func TestP256PointOps(t *testing.T) {
+	c := P256Sm2()
+	generic := c.Params()
+	n := generic.N
+
+	scalars := [][]byte{
+		{0}, {1}, {2}, {15}, {16},
+		new(big.Int).Sub(n, big.NewInt(1)).Bytes(),
+		n.Bytes(),
+		new(big.Int).Add(n, big.NewInt(5)).Bytes(),
+		append(make([]byte, 8), 7),
+	}
+	for i := 0; i < 16; i++ {
+		k := make([]byte, 32)
+		if _, err := rand.Read(k); err != nil {
+			t.Fatal(err)
+		}
+		scalars = append(scalars, k)
+	}
+
+	px, py := generic.ScalarBaseMult([]byte{0x12, 0x34, 0x56})
+	for _, k := range scalars {
+		x1, y1 := c.ScalarBaseMult(k)
+		x2, y2 := generic.ScalarBaseMult(k)
+		checkPoint(t, "ScalarBaseMult", x1, y1, x2, y2)
+
+		x1, y1 = c.ScalarMult(px, py, k)
+		x2, y2 = generic.ScalarMult(px, py, k)
+		checkPoint(t, "ScalarMult", x1, y1, x2, y2)
+
+		x1, y1 = c.Double(x2, y2)
+		x3, y3 := generic.Double(x2, y2)
+		checkPoint(t, "Double", x1, y1, x3, y3)
+
+		x1, y1 = c.Add(x2, y2, px, py)
+		x3, y3 = generic.Add(x2, y2, px, py)
+		checkPoint(t, "Add", x1, y1, x3, y3)
+
+		x1, y1 = c.(sm2P256Curve).combinedMult(px, py, k, []byte{0x9a})
+		x3, y3 = generic.ScalarBaseMult(k)
+		x4, y4 := generic.ScalarMult(px, py, []byte{0x9a})
+		x3, y3 = generic.Add(x3, y3, x4, y4)
+		checkPoint(t, "combinedMult", x1, y1, x3, y3)
+	}
+
+	// 相同的点、互为相反数的点以及无穷远点
+	x1, y1 := c.Add(px, py, px, py)
+	x2, y2 := generic.Double(px, py)
+	checkPoint(t, "Add(P, P)", x1, y1, x2, y2)
+	x1, y1 = c.Add(px, py, px, new(big.Int).Sub(generic.P, py))
+	checkPoint(t, "Add(P, -P)", x1, y1, new(big.Int), new(big.Int))
+	x1, y1 = c.Add(px, py, new(big.Int), new(big.Int))
+	checkPoint(t, "Add(P, O)", x1, y1, px, py)
+	x1, y1 = c.Add(new(big.Int), new(big.Int), px, py)
+	checkPoint(t, "Add(O, P)", x1, y1, px, py)
+	if !c.IsOnCurve(px, py) || c.IsOnCurve(px, new(big.Int).Add(py, big.NewInt(1))) {
+		t.Fatal("IsOnCurve returned a wrong answer")
+	}
+}
+
+func checkPoint(t *testing.T, op string, x1, y1, x2, y2 *big.Int) {
+	t.Helper()
+	if x1.Cmp(x2) != 0 || y1.Cmp(y2) != 0 {
+		t.Fatalf("%s = (%x, %x), want (%x, %x)", op, x1, y1, x2, y2)
+	}
+}
+
+func TestP256GetScalar(t *testing.T) {
+	n := P256Sm2().Params().N
+	max := new(big.Int).Lsh(big.NewInt(1), 512)
+	values := [][]byte{
+		nil,
+		{1},
+		new(big.Int).Sub(n, big.NewInt(1)).Bytes(),
+		n.Bytes(),
+		new(big.Int).Add(n, big.NewInt(1)).Bytes(),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)).Bytes(),
+		append(make([]byte, 8), n.Bytes()...),
+	}
+	for i := 0; i < 64; i++ {
+		v, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v.Bytes()[:len(v.Bytes())*(i%4+1)/4])
+	}
+
+	for _, a := range values {
+		var got [32]byte
+		sm2P256GetScalar(&got, a)
+		want := new(big.Int).Mod(new(big.Int).SetBytes(a), n).Bytes()
+		var wantLE [32]byte
+		for i, v := range want {
+			wantLE[len(want)-1-i] = v
+		}
+		if got != wantLE {
+			t.Fatalf("sm2P256GetScalar(%x) = %x, want %x", a, got, wantLE)
+		}
+	}
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("round trip")
+	for i := 0; i < 8; i++ {
+		r, s, err := Sm2Sign(priv, msg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Sm2Verify(&priv.PublicKey, msg, nil, r, s) {
+			t.Fatal("Sm2Verify failed")
+		}
+		if Sm2Verify(&priv.PublicKey, []byte("tampered"), nil, r, s) {
+			t.Fatal("Sm2Verify accepted a tampered message")
+		}
+
+		r, s, err = Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(&priv.PublicKey, msg, r, s) {
+			t.Fatal("Verify failed")
+		}
+		if Verify(&priv.PublicKey, msg, s, r) {
+			t.Fatal("Verify accepted a swapped signature")
+		}
+	}
+}
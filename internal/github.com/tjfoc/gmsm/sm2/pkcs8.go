@@ -30,6 +30,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"hash"
+	"io"
 	"io/ioutil"
 	"math/big"
 	"os"
@@ -223,6 +224,20 @@ func ParsePKCS8UnecryptedPrivateKey(der []byte) (*PrivateKey, error) {
 }
 
 func ParsePKCS8EcryptedPrivateKey(der, pwd []byte) (*PrivateKey, error) {
+	decrypted, err := DecryptPKCS8PrivateKey(der, pwd)
+	if err != nil {
+		return nil, err
+	}
+	rKey, err := ParsePKCS8UnecryptedPrivateKey(decrypted)
+	if err != nil {
+		return nil, errors.New("pkcs8: incorrect password")
+	}
+	return rKey, nil
+}
+
+// DecryptPKCS8PrivateKey decrypts a PBES2 encrypted PKCS#8 private key, whatever its algorithm,
+// and returns the unencrypted PKCS#8 der
+func DecryptPKCS8PrivateKey(der, pwd []byte) ([]byte, error) {
 	var keyInfo EncryptedPrivateKeyInfo
 
 	_, err := asn1.Unmarshal(der, &keyInfo)
@@ -242,24 +257,27 @@ func ParsePKCS8EcryptedPrivateKey(der, pwd []byte) (*PrivateKey, error) {
 		!reflect.DeepEqual(encryptionScheme.EncryAlgo, oidAES256CBC) {
 		return nil, errors.New("x509: unknow encryption algorithm")
 	}
+	keyLen := 32
+	if reflect.DeepEqual(encryptionScheme.EncryAlgo, oidAES128CBC) {
+		keyLen = 16
+	}
 	iv := encryptionScheme.IV
 	salt := pkdf2Params.Salt
 	iter := pkdf2Params.IterationCount
-	encryptedKey := keyInfo.EncryptedData
+	if len(keyInfo.EncryptedData) == 0 || len(keyInfo.EncryptedData)%aes.BlockSize != 0 || len(iv) != aes.BlockSize {
+		return nil, errors.New("x509: invalid encrypted private key")
+	}
+	encryptedKey := make([]byte, len(keyInfo.EncryptedData))
 	var key []byte
 	switch {
 	case pkdf2Params.Prf.Algorithm.Equal(oidKEYMD5):
-		key = pbkdf(pwd, salt, iter, 32, md5.New)
-		break
+		key = pbkdf(pwd, salt, iter, keyLen, md5.New)
 	case pkdf2Params.Prf.Algorithm.Equal(oidKEYSHA1):
-		key = pbkdf(pwd, salt, iter, 32, sha1.New)
-		break
+		key = pbkdf(pwd, salt, iter, keyLen, sha1.New)
 	case pkdf2Params.Prf.Algorithm.Equal(oidKEYSHA256):
-		key = pbkdf(pwd, salt, iter, 32, sha256.New)
-		break
+		key = pbkdf(pwd, salt, iter, keyLen, sha256.New)
 	case pkdf2Params.Prf.Algorithm.Equal(oidKEYSHA512):
-		key = pbkdf(pwd, salt, iter, 32, sha512.New)
-		break
+		key = pbkdf(pwd, salt, iter, keyLen, sha512.New)
 	default:
 		return nil, errors.New("x509: unknown hash algorithm")
 	}
@@ -268,12 +286,18 @@ func ParsePKCS8EcryptedPrivateKey(der, pwd []byte) (*PrivateKey, error) {
 		return nil, err
 	}
 	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(encryptedKey, encryptedKey)
-	rKey, err := ParsePKCS8UnecryptedPrivateKey(encryptedKey)
-	if err != nil {
+	mode.CryptBlocks(encryptedKey, keyInfo.EncryptedData)
+	// 校验 PKCS#7 填充，密码错误时填充通常不合法
+	padding := int(encryptedKey[len(encryptedKey)-1])
+	if padding == 0 || padding > aes.BlockSize {
 		return nil, errors.New("pkcs8: incorrect password")
 	}
-	return rKey, nil
+	for _, b := range encryptedKey[len(encryptedKey)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("pkcs8: incorrect password")
+		}
+	}
+	return encryptedKey[:len(encryptedKey)-padding], nil
 }
 
 func ParsePKCS8PrivateKey(der, pwd []byte) (*PrivateKey, error) {
@@ -308,12 +332,22 @@ func MarshalSm2EcryptedPrivateKey(PrivKey *PrivateKey, pwd []byte) ([]byte, erro
 	if err != nil {
 		return nil, err
 	}
-	iter := 2048
-	salt := make([]byte, 8)
-	iv := make([]byte, 16)
-	rand.Reader.Read(salt)
-	rand.Reader.Read(iv)
-	key := pbkdf(pwd, salt, iter, 32, sha1.New) // 默认是SHA1
+	return EncryptPKCS8PrivateKey(der, pwd)
+}
+
+// EncryptPKCS8PrivateKey encrypts an unencrypted PKCS#8 private key der, whatever its algorithm,
+// with PBES2 (PBKDF2-HMAC-SHA256 and AES-256-CBC)
+func EncryptPKCS8PrivateKey(der, pwd []byte) ([]byte, error) {
+	iter := 10000
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	key := pbkdf(pwd, salt, iter, 32, sha256.New) // PBKDF2-HMAC-SHA256
 	padding := aes.BlockSize - len(der)%aes.BlockSize
 	if padding > 0 {
 		n := len(der)
@@ -330,7 +364,7 @@ func MarshalSm2EcryptedPrivateKey(PrivKey *PrivateKey, pwd []byte) ([]byte, erro
 	mode := cipher.NewCBCEncrypter(block, iv)
 	mode.CryptBlocks(encryptedKey, der)
 	var algorithmIdentifier pkix.AlgorithmIdentifier
-	algorithmIdentifier.Algorithm = oidKEYSHA1
+	algorithmIdentifier.Algorithm = oidKEYSHA256
 	algorithmIdentifier.Parameters.Tag = 5
 	algorithmIdentifier.Parameters.IsCompound = false
 	algorithmIdentifier.Parameters.FullBytes = []byte{5, 0}
@@ -30,7 +30,7 @@ import (
 	"io"
 	"math/big"
 
-	"github.com/tjfoc/gmsm/sm3"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
 )
 
 const (
@@ -249,10 +249,7 @@ func Verify(pub *PublicKey, hash []byte, r, s *big.Int) bool {
 		return false
 	}
 
-	var x *big.Int
-	x1, y1 := c.ScalarBaseMult(s.Bytes())
-	x2, y2 := c.ScalarMult(pub.X, pub.Y, t.Bytes())
-	x, _ = c.Add(x1, y1, x2, y2)
+	x := verifyX(pub, s, t)
 
 	e := new(big.Int).SetBytes(hash)
 	x.Add(x, e)
@@ -328,16 +325,26 @@ func Sm2Verify(pub *PublicKey, msg, uid []byte, r, s *big.Int) bool {
 	if t.Sign() == 0 {
 		return false
 	}
-	var x *big.Int
-	x1, y1 := c.ScalarBaseMult(s.Bytes())
-	x2, y2 := c.ScalarMult(pub.X, pub.Y, t.Bytes())
-	x, _ = c.Add(x1, y1, x2, y2)
+	x := verifyX(pub, s, t)
 
 	x.Add(x, e)
 	x.Mod(x, N)
 	return x.Cmp(r) == 0
 }
 
+// verifyX 计算 s·G + t·P 的 x 坐标，SM2 曲线上两次标量乘在雅可比坐标下合并，只需一次求逆
+func verifyX(pub *PublicKey, s, t *big.Int) *big.Int {
+	if curve, ok := pub.Curve.(sm2P256Curve); ok {
+		x, _ := curve.combinedMult(pub.X, pub.Y, s.Bytes(), t.Bytes())
+		return x
+	}
+	c := pub.Curve
+	x1, y1 := c.ScalarBaseMult(s.Bytes())
+	x2, y2 := c.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x, _ := c.Add(x1, y1, x2, y2)
+	return x
+}
+
 func msgHash(za, msg []byte) (*big.Int, error) {
 	e := sm3.New()
 	e.Write(za)
@@ -440,6 +447,9 @@ func Encrypt(pub *PublicKey, data []byte) ([]byte, error) {
 }
 
 func Decrypt(priv *PrivateKey, data []byte) ([]byte, error) {
+	if len(data) < 97 || data[0] != 0x04 {
+		return nil, errors.New("Decrypt: invalid ciphertext")
+	}
 	data = data[1:]
 	length := len(data) - 96
 	curve := priv.Curve
@@ -472,6 +482,128 @@ func Decrypt(priv *PrivateKey, data []byte) ([]byte, error) {
 	return c, nil
 }
 
+// sm2Cipher is the ASN.1 form of an SM2 ciphertext as defined in GM/T 0009.
+type sm2Cipher struct {
+	XCoordinate *big.Int
+	YCoordinate *big.Int
+	HASH        []byte
+	CipherText  []byte
+}
+
+// CipherMarshal converts a C1C3C2 ciphertext produced by Encrypt into its
+// ASN.1 DER form.
+func CipherMarshal(data []byte) ([]byte, error) {
+	if len(data) < 97 || data[0] != 0x04 {
+		return nil, errors.New("CipherMarshal: invalid ciphertext")
+	}
+	data = data[1:]
+	return asn1.Marshal(sm2Cipher{
+		XCoordinate: new(big.Int).SetBytes(data[:32]),
+		YCoordinate: new(big.Int).SetBytes(data[32:64]),
+		HASH:        data[64:96],
+		CipherText:  data[96:],
+	})
+}
+
+// CipherUnmarshal converts an ASN.1 DER ciphertext into the C1C3C2 form
+// accepted by Decrypt.
+func CipherUnmarshal(data []byte) ([]byte, error) {
+	var ct sm2Cipher
+	rest, err := asn1.Unmarshal(data, &ct)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 || len(ct.HASH) != 32 ||
+		ct.XCoordinate == nil || ct.YCoordinate == nil {
+		return nil, errors.New("CipherUnmarshal: invalid ciphertext")
+	}
+	x := ct.XCoordinate.Bytes()
+	y := ct.YCoordinate.Bytes()
+	if len(x) > 32 || len(y) > 32 {
+		return nil, errors.New("CipherUnmarshal: invalid ciphertext")
+	}
+	c := []byte{0x04}
+	c = append(c, zeroByteSlice()[:32-len(x)]...)
+	c = append(c, x...)
+	c = append(c, zeroByteSlice()[:32-len(y)]...)
+	c = append(c, y...)
+	c = append(c, ct.HASH...)
+	c = append(c, ct.CipherText...)
+	return c, nil
+}
+
+// EncryptAsn1 encrypts data and returns the ciphertext in ASN.1 DER form.
+func EncryptAsn1(pub *PublicKey, data []byte) ([]byte, error) {
+	c, err := Encrypt(pub, data)
+	if err != nil {
+		return nil, err
+	}
+	return CipherMarshal(c)
+}
+
+// DecryptAsn1 decrypts an ASN.1 DER ciphertext.
+func DecryptAsn1(priv *PrivateKey, data []byte) ([]byte, error) {
+	c, err := CipherUnmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(priv, c)
+}
+
+// Ciphertext layouts: C1C3C2 is the layout of GM/T 0003-2012 produced by
+// Encrypt, C1C2C3 the layout of the draft standard still used by some devices.
+const (
+	C1C3C2 = iota
+	C1C2C3
+)
+
+// EncryptWithMode encrypts data and returns the ciphertext in the given layout.
+func EncryptWithMode(pub *PublicKey, data []byte, mode int) ([]byte, error) {
+	c, err := Encrypt(pub, data)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case C1C3C2:
+		return c, nil
+	case C1C2C3:
+		return swapC2C3(c), nil
+	default:
+		return nil, errors.New("EncryptWithMode: unsupported mode")
+	}
+}
+
+// DecryptWithMode decrypts a ciphertext in the given layout.
+func DecryptWithMode(priv *PrivateKey, data []byte, mode int) ([]byte, error) {
+	switch mode {
+	case C1C3C2:
+		return Decrypt(priv, data)
+	case C1C2C3:
+		if len(data) < 97 || data[0] != 0x04 {
+			return nil, errors.New("DecryptWithMode: invalid ciphertext")
+		}
+		return Decrypt(priv, unswapC2C3(data))
+	default:
+		return nil, errors.New("DecryptWithMode: unsupported mode")
+	}
+}
+
+// swapC2C3 converts a C1C3C2 ciphertext into the C1C2C3 layout.
+func swapC2C3(data []byte) []byte {
+	c := make([]byte, 0, len(data))
+	c = append(c, data[:65]...)
+	c = append(c, data[97:]...)
+	return append(c, data[65:97]...)
+}
+
+// unswapC2C3 converts a C1C2C3 ciphertext into the C1C3C2 layout.
+func unswapC2C3(data []byte) []byte {
+	c := make([]byte, 0, len(data))
+	c = append(c, data[:65]...)
+	c = append(c, data[len(data)-32:]...)
+	return append(c, data[65:len(data)-32]...)
+}
+
 type zr struct {
 	io.Reader
 }
@@ -16,7 +16,11 @@ limitations under the License.
 package sm2
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"fmt"
@@ -27,6 +31,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
 )
 
 func TestSm2(t *testing.T) {
@@ -217,3 +223,170 @@ func BenchmarkSM2(t *testing.B) {
 		}
 	}
 }
+
+func TestEncryptWithMode(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("transient data")
+
+	c1c3c2, err := EncryptWithMode(&priv.PublicKey, msg, C1C3C2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1c2c3, err := EncryptWithMode(&priv.PublicKey, msg, C1C2C3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(swapC2C3(unswapC2C3(c1c2c3)), c1c2c3) || !bytes.Equal(unswapC2C3(swapC2C3(c1c3c2)), c1c3c2) {
+		t.Fatal("layout conversions should be inverse")
+	}
+
+	for mode, c := range map[int][]byte{C1C3C2: c1c3c2, C1C2C3: c1c2c3} {
+		d, err := DecryptWithMode(priv, c, mode)
+		if err != nil || !bytes.Equal(d, msg) {
+			t.Fatalf("mode %d: decrypt failed: %s, %v", mode, d, err)
+		}
+	}
+	if _, err := DecryptWithMode(priv, c1c2c3, C1C3C2); err == nil {
+		t.Fatal("expected error decrypting with the wrong layout")
+	}
+	if _, err := DecryptWithMode(priv, c1c3c2[:96], C1C2C3); err == nil {
+		t.Fatal("expected error for truncated ciphertext")
+	}
+	if _, err := Decrypt(priv, nil); err == nil {
+		t.Fatal("expected error for empty ciphertext")
+	}
+	if _, err := EncryptWithMode(&priv.PublicKey, msg, 2); err == nil {
+		t.Fatal("expected error for unsupported mode")
+	}
+}
+
+func TestEncryptedPrivateKey(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pwd := []byte("passphrase")
+
+	pemBytes, err := WritePrivateKeytoMem(priv, pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(pemBytes, []byte("ENCRYPTED PRIVATE KEY")) {
+		t.Fatalf("expected an encrypted PKCS#8 PEM, got %s", pemBytes)
+	}
+	key, err := ReadPrivateKeyFromMem(pemBytes, pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.D.Cmp(priv.D) != 0 {
+		t.Fatal("decrypted key should be the original key")
+	}
+	// the DER is not modified by a failed attempt
+	if _, err := ReadPrivateKeyFromMem(pemBytes, []byte("wrong")); err == nil {
+		t.Fatal("expected error with the wrong password")
+	}
+	if _, err := ReadPrivateKeyFromMem(pemBytes, pwd); err != nil {
+		t.Fatalf("decrypting again should succeed: %s", err)
+	}
+}
+
+// 与标准库 ECDSA P-256 对比的性能测试，go test -bench . -benchmem
+
+var benchMsg = []byte("proposal response payload and endorser identity")
+
+func BenchmarkSign(b *testing.B) {
+	b.Run("SM2", func(b *testing.B) {
+		priv, _ := GenerateKey()
+		digest := sm3.Sm3Sum(benchMsg)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := Sign(priv, digest); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("SM2WithUID", func(b *testing.B) {
+		priv, _ := GenerateKey()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := Sm2Sign(priv, benchMsg, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ECDSA-P256", func(b *testing.B) {
+		priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		digest := sha256.Sum256(benchMsg)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := ecdsa.Sign(rand.Reader, priv, digest[:]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkVerify(b *testing.B) {
+	b.Run("SM2", func(b *testing.B) {
+		priv, _ := GenerateKey()
+		digest := sm3.Sm3Sum(benchMsg)
+		r, s, _ := Sign(priv, digest)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if !Verify(&priv.PublicKey, digest, r, s) {
+				b.Fatal("verification failed")
+			}
+		}
+	})
+	b.Run("SM2WithUID", func(b *testing.B) {
+		priv, _ := GenerateKey()
+		r, s, _ := Sm2Sign(priv, benchMsg, nil)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if !Sm2Verify(&priv.PublicKey, benchMsg, nil, r, s) {
+				b.Fatal("verification failed")
+			}
+		}
+	})
+	b.Run("ECDSA-P256", func(b *testing.B) {
+		priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		digest := sha256.Sum256(benchMsg)
+		r, s, _ := ecdsa.Sign(rand.Reader, priv, digest[:])
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if !ecdsa.Verify(&priv.PublicKey, digest[:], r, s) {
+				b.Fatal("verification failed")
+			}
+		}
+	})
+}
+
+func BenchmarkScalarBaseMult(b *testing.B) {
+	benchmarkScalarMult(b, func(c elliptic.Curve, k []byte) { c.ScalarBaseMult(k) })
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	benchmarkScalarMult(b, func(c elliptic.Curve, k []byte) { c.ScalarMult(c.Params().Gx, c.Params().Gy, k) })
+}
+
+func benchmarkScalarMult(b *testing.B, mult func(c elliptic.Curve, k []byte)) {
+	for _, c := range []elliptic.Curve{P256Sm2(), elliptic.P256()} {
+		b.Run(c.Params().Name, func(b *testing.B) {
+			k, _ := randFieldElement(c, rand.Reader)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				mult(c, k.Bytes())
+			}
+		})
+	}
+}
@@ -42,7 +42,7 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/tjfoc/gmsm/sm3"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
 	"golang.org/x/crypto/ripemd160"
 	"golang.org/x/crypto/sha3"
 )
@@ -2050,7 +2050,12 @@ func CreateCertificate(rand io.Reader, template, parent *Certificate, pub, priv
 	}
 
 	var signature []byte
-	signature, err = key.Sign(rand, digest, signerOpts)
+	if priv, ok := key.(*PrivateKey); ok && hashFunc == SM3 {
+		// GM/T 0015: SM2 certificates are signed over SM3(ZA || tbsCertificate)
+		signature, err = signWithDefaultUID(priv, tbsCertContents)
+	} else {
+		signature, err = key.Sign(rand, digest, signerOpts)
+	}
 	if err != nil {
 		return
 	}
@@ -2063,6 +2068,18 @@ func CreateCertificate(rand io.Reader, template, parent *Certificate, pub, priv
 	})
 }
 
+// defaultUID is the user ID of GM/T 0009 used when none is agreed between the parties
+var defaultUID = []byte("1234567812345678")
+
+// signWithDefaultUID returns the DER encoded SM2 signature of msg with the Z value of the default user ID
+func signWithDefaultUID(priv *PrivateKey, msg []byte) ([]byte, error) {
+	r, s, err := Sm2Sign(priv, msg, defaultUID)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(sm2Signature{r, s})
+}
+
 // pemCRLPrefix is the magic string that indicates that we have a PEM encoded
 // CRL.
 var pemCRLPrefix = []byte("-----BEGIN X509 CRL")
@@ -2145,7 +2162,12 @@ func (c *Certificate) CreateCRL(rand io.Reader, priv interface{}, revokedCerts [
 	digest := h.Sum(nil)
 
 	var signature []byte
-	signature, err = key.Sign(rand, digest, hashFunc)
+	if priv, ok := key.(*PrivateKey); ok && hashFunc == SM3 {
+		// CRLs are signed over SM3(ZA || tbsCertList), as the certificates
+		signature, err = signWithDefaultUID(priv, tbsCertListContents)
+	} else {
+		signature, err = key.Sign(rand, digest, hashFunc)
+	}
 	if err != nil {
 		return
 	}
@@ -18,94 +18,114 @@ package sm3
 import (
 	"encoding/binary"
 	"hash"
+	"math/bits"
+)
+
+const (
+	size      = 32
+	blockSize = 64
+
+	init0 = 0x7380166f
+	init1 = 0x4914b2b9
+	init2 = 0x172442d7
+	init3 = 0xda8a0600
+	init4 = 0xa96f30bc
+	init5 = 0x163138aa
+	init6 = 0xe38dee4d
+	init7 = 0xb0fb0e4e
 )
 
 type SM3 struct {
-	digest      [8]uint32 // digest represents the partial evaluation of V
-	length      uint64    // length of the message
-	unhandleMsg []byte    // uint8  //
+	digest [8]uint32       // digest represents the partial evaluation of V
+	length uint64          // length of the message
+	buf    [blockSize]byte // unhandled tail of the message
+	nx     int             // number of bytes in buf
 }
 
-func (sm3 *SM3) ff0(x, y, z uint32) uint32 { return x ^ y ^ z }
-
-func (sm3 *SM3) ff1(x, y, z uint32) uint32 { return (x & y) | (x & z) | (y & z) }
-
-func (sm3 *SM3) gg0(x, y, z uint32) uint32 { return x ^ y ^ z }
-
-func (sm3 *SM3) gg1(x, y, z uint32) uint32 { return (x & y) | (^x & z) }
-
-func (sm3 *SM3) p0(x uint32) uint32 { return x ^ sm3.leftRotate(x, 9) ^ sm3.leftRotate(x, 17) }
-
-func (sm3 *SM3) p1(x uint32) uint32 { return x ^ sm3.leftRotate(x, 15) ^ sm3.leftRotate(x, 23) }
+// tRotated 为各轮的常量 T_j 循环左移 j 位的结果
+var tRotated [64]uint32
 
-func (sm3 *SM3) leftRotate(x uint32, i uint32) uint32 { return (x<<(i%32) | x>>(32-i%32)) }
-
-func (sm3 *SM3) pad() []byte {
-	msg := sm3.unhandleMsg
-	msg = append(msg, 0x80) // Append '1'
-	blockSize := 64         // Append until the resulting message length (in bits) is congruent to 448 (mod 512)
-	for len(msg)%blockSize != 56 {
-		msg = append(msg, 0x00)
-	}
-	// append message length
-	msg = append(msg, uint8(sm3.length>>56&0xff))
-	msg = append(msg, uint8(sm3.length>>48&0xff))
-	msg = append(msg, uint8(sm3.length>>40&0xff))
-	msg = append(msg, uint8(sm3.length>>32&0xff))
-	msg = append(msg, uint8(sm3.length>>24&0xff))
-	msg = append(msg, uint8(sm3.length>>16&0xff))
-	msg = append(msg, uint8(sm3.length>>8&0xff))
-	msg = append(msg, uint8(sm3.length>>0&0xff))
-
-	if len(msg)%64 != 0 {
-		panic("------SM3 Pad: error msgLen =")
+func init() {
+	for i := range tRotated {
+		tj := uint32(0x79cc4519)
+		if i >= 16 {
+			tj = 0x7a879d8a
+		}
+		tRotated[i] = bits.RotateLeft32(tj, i%32)
 	}
-	return msg
 }
 
-func (sm3 *SM3) update(msg []byte, nblocks int) {
+func p0(x uint32) uint32 { return x ^ bits.RotateLeft32(x, 9) ^ bits.RotateLeft32(x, 17) }
+
+func p1(x uint32) uint32 { return x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23) }
+
+// block 压缩 msg 中的若干个整块，msg 的长度须为 blockSize 的整数倍
+func block(dig *[8]uint32, msg []byte) {
 	var w [68]uint32
-	var w1 [64]uint32
 
-	a, b, c, d, e, f, g, h := sm3.digest[0], sm3.digest[1], sm3.digest[2], sm3.digest[3], sm3.digest[4], sm3.digest[5], sm3.digest[6], sm3.digest[7]
-	for len(msg) >= 64 {
+	a, b, c, d, e, f, g, h := dig[0], dig[1], dig[2], dig[3], dig[4], dig[5], dig[6], dig[7]
+	for len(msg) >= blockSize {
 		for i := 0; i < 16; i++ {
-			w[i] = binary.BigEndian.Uint32(msg[4*i : 4*(i+1)])
+			w[i] = binary.BigEndian.Uint32(msg[4*i:])
 		}
 		for i := 16; i < 68; i++ {
-			w[i] = sm3.p1(w[i-16]^w[i-9]^sm3.leftRotate(w[i-3], 15)) ^ sm3.leftRotate(w[i-13], 7) ^ w[i-6]
-		}
-		for i := 0; i < 64; i++ {
-			w1[i] = w[i] ^ w[i+4]
+			w[i] = p1(w[i-16]^w[i-9]^bits.RotateLeft32(w[i-3], 15)) ^ bits.RotateLeft32(w[i-13], 7) ^ w[i-6]
 		}
+
+		// 每次迭代执行 4 轮，通过轮换变量的角色省去每轮的寄存器移位
 		A, B, C, D, E, F, G, H := a, b, c, d, e, f, g, h
-		for i := 0; i < 16; i++ {
-			SS1 := sm3.leftRotate(sm3.leftRotate(A, 12)+E+sm3.leftRotate(0x79cc4519, uint32(i)), 7)
-			SS2 := SS1 ^ sm3.leftRotate(A, 12)
-			TT1 := sm3.ff0(A, B, C) + D + SS2 + w1[i]
-			TT2 := sm3.gg0(E, F, G) + H + SS1 + w[i]
-			D = C
-			C = sm3.leftRotate(B, 9)
-			B = A
-			A = TT1
-			H = G
-			G = sm3.leftRotate(F, 19)
-			F = E
-			E = sm3.p0(TT2)
+		var a12, ss1 uint32
+		for i := 0; i < 16; i += 4 {
+			a12 = bits.RotateLeft32(A, 12)
+			ss1 = bits.RotateLeft32(a12+E+tRotated[i], 7)
+			D += (A ^ B ^ C) + (ss1 ^ a12) + (w[i] ^ w[i+4])
+			H = p0((E ^ F ^ G) + H + ss1 + w[i])
+			B = bits.RotateLeft32(B, 9)
+			F = bits.RotateLeft32(F, 19)
+			a12 = bits.RotateLeft32(D, 12)
+			ss1 = bits.RotateLeft32(a12+H+tRotated[i+1], 7)
+			C += (D ^ A ^ B) + (ss1 ^ a12) + (w[i+1] ^ w[i+5])
+			G = p0((H ^ E ^ F) + G + ss1 + w[i+1])
+			A = bits.RotateLeft32(A, 9)
+			E = bits.RotateLeft32(E, 19)
+			a12 = bits.RotateLeft32(C, 12)
+			ss1 = bits.RotateLeft32(a12+G+tRotated[i+2], 7)
+			B += (C ^ D ^ A) + (ss1 ^ a12) + (w[i+2] ^ w[i+6])
+			F = p0((G ^ H ^ E) + F + ss1 + w[i+2])
+			D = bits.RotateLeft32(D, 9)
+			H = bits.RotateLeft32(H, 19)
+			a12 = bits.RotateLeft32(B, 12)
+			ss1 = bits.RotateLeft32(a12+F+tRotated[i+3], 7)
+			A += (B ^ C ^ D) + (ss1 ^ a12) + (w[i+3] ^ w[i+7])
+			E = p0((F ^ G ^ H) + E + ss1 + w[i+3])
+			C = bits.RotateLeft32(C, 9)
+			G = bits.RotateLeft32(G, 19)
 		}
-		for i := 16; i < 64; i++ {
-			SS1 := sm3.leftRotate(sm3.leftRotate(A, 12)+E+sm3.leftRotate(0x7a879d8a, uint32(i)), 7)
-			SS2 := SS1 ^ sm3.leftRotate(A, 12)
-			TT1 := sm3.ff1(A, B, C) + D + SS2 + w1[i]
-			TT2 := sm3.gg1(E, F, G) + H + SS1 + w[i]
-			D = C
-			C = sm3.leftRotate(B, 9)
-			B = A
-			A = TT1
-			H = G
-			G = sm3.leftRotate(F, 19)
-			F = E
-			E = sm3.p0(TT2)
+		for i := 16; i < 64; i += 4 {
+			a12 = bits.RotateLeft32(A, 12)
+			ss1 = bits.RotateLeft32(a12+E+tRotated[i], 7)
+			D += ((A & B) | (C & (A | B))) + (ss1 ^ a12) + (w[i] ^ w[i+4])
+			H = p0((((F ^ G) & E) ^ G) + H + ss1 + w[i])
+			B = bits.RotateLeft32(B, 9)
+			F = bits.RotateLeft32(F, 19)
+			a12 = bits.RotateLeft32(D, 12)
+			ss1 = bits.RotateLeft32(a12+H+tRotated[i+1], 7)
+			C += ((D & A) | (B & (D | A))) + (ss1 ^ a12) + (w[i+1] ^ w[i+5])
+			G = p0((((E ^ F) & H) ^ F) + G + ss1 + w[i+1])
+			A = bits.RotateLeft32(A, 9)
+			E = bits.RotateLeft32(E, 19)
+			a12 = bits.RotateLeft32(C, 12)
+			ss1 = bits.RotateLeft32(a12+G+tRotated[i+2], 7)
+			B += ((C & D) | (A & (C | D))) + (ss1 ^ a12) + (w[i+2] ^ w[i+6])
+			F = p0((((H ^ E) & G) ^ E) + F + ss1 + w[i+2])
+			D = bits.RotateLeft32(D, 9)
+			H = bits.RotateLeft32(H, 19)
+			a12 = bits.RotateLeft32(B, 12)
+			ss1 = bits.RotateLeft32(a12+F+tRotated[i+3], 7)
+			A += ((B & C) | (D & (B | C))) + (ss1 ^ a12) + (w[i+3] ^ w[i+7])
+			E = p0((((G ^ H) & F) ^ H) + E + ss1 + w[i+3])
+			C = bits.RotateLeft32(C, 9)
+			G = bits.RotateLeft32(G, 19)
 		}
 		a ^= A
 		b ^= B
@@ -115,9 +135,9 @@ func (sm3 *SM3) update(msg []byte, nblocks int) {
 		f ^= F
 		g ^= G
 		h ^= H
-		msg = msg[64:]
+		msg = msg[blockSize:]
 	}
-	sm3.digest[0], sm3.digest[1], sm3.digest[2], sm3.digest[3], sm3.digest[4], sm3.digest[5], sm3.digest[6], sm3.digest[7] = a, b, c, d, e, f, g, h
+	dig[0], dig[1], dig[2], dig[3], dig[4], dig[5], dig[6], dig[7] = a, b, c, d, e, f, g, h
 }
 
 func New() hash.Hash {
@@ -132,27 +152,20 @@ func New() hash.Hash {
 // The Write method must be able to accept any amount
 // of data, but it may operate more efficiently if all writes
 // are a multiple of the block size.
-func (sm3 *SM3) BlockSize() int { return 64 }
+func (sm3 *SM3) BlockSize() int { return blockSize }
 
 // Size, required by the hash.Hash interface.
 // Size returns the number of bytes Sum will return.
-func (sm3 *SM3) Size() int { return 32 }
+func (sm3 *SM3) Size() int { return size }
 
 // Reset clears the internal state by zeroing bytes in the state buffer.
 // This can be skipped for a newly-created hash state; the default zero-allocated state is correct.
 func (sm3 *SM3) Reset() {
 	// Reset digest
-	sm3.digest[0] = 0x7380166f
-	sm3.digest[1] = 0x4914b2b9
-	sm3.digest[2] = 0x172442d7
-	sm3.digest[3] = 0xda8a0600
-	sm3.digest[4] = 0xa96f30bc
-	sm3.digest[5] = 0x163138aa
-	sm3.digest[6] = 0xe38dee4d
-	sm3.digest[7] = 0xb0fb0e4e
+	sm3.digest = [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
 
 	sm3.length = 0 // Reset numberic states
-	sm3.unhandleMsg = []byte{}
+	sm3.nx = 0
 }
 
 // Write, required by the hash.Hash interface.
@@ -160,15 +173,25 @@ func (sm3 *SM3) Reset() {
 // It never returns an error.
 func (sm3 *SM3) Write(p []byte) (int, error) {
 	toWrite := len(p)
-	sm3.length += uint64(len(p) * 8)
-
-	msg := append(sm3.unhandleMsg, p...)
-	nblocks := len(msg) / sm3.BlockSize()
-	sm3.update(msg, nblocks)
-
-	// Update unhandleMsg
-	sm3.unhandleMsg = msg[nblocks*sm3.BlockSize():]
-
+	sm3.length += uint64(len(p))
+
+	if sm3.nx > 0 {
+		n := copy(sm3.buf[sm3.nx:], p)
+		sm3.nx += n
+		if sm3.nx == blockSize {
+			block(&sm3.digest, sm3.buf[:])
+			sm3.nx = 0
+		}
+		p = p[n:]
+	}
+	if len(p) >= blockSize {
+		n := len(p) &^ (blockSize - 1)
+		block(&sm3.digest, p[:n])
+		p = p[n:]
+	}
+	if len(p) > 0 {
+		sm3.nx = copy(sm3.buf[:], p)
+	}
 	return toWrite, nil
 }
 
@@ -176,26 +199,37 @@ func (sm3 *SM3) Write(p []byte) (int, error) {
 // Sum appends the current hash to b and returns the resulting slice.
 // It does not change the underlying hash state.
 func (sm3 *SM3) Sum(in []byte) []byte {
-	sm3.Write(in)
-	msg := sm3.pad()
-
-	// Finialize
-	sm3.update(msg, len(msg)/sm3.BlockSize())
-
-	// save hash to in
-	needed := sm3.Size()
-	if cap(in)-len(in) < needed {
-		newIn := make([]byte, len(in), len(in)+needed)
-		copy(newIn, in)
-		in = newIn
+	// 在副本上填充，调用方可以继续写入
+	d := *sm3
+	hash := d.checkSum()
+	return append(in, hash[:]...)
+}
+
+func (sm3 *SM3) checkSum() [size]byte {
+	length := sm3.length
+
+	// Padding: append '1', then zeros until the message length (in bits) is
+	// congruent to 448 (mod 512), then the message length in bits.
+	var tmp [blockSize + 8]byte
+	tmp[0] = 0x80
+	var padLen uint64
+	if length%blockSize < 56 {
+		padLen = 56 - length%blockSize
+	} else {
+		padLen = blockSize + 56 - length%blockSize
 	}
-	out := in[len(in) : len(in)+needed]
+	binary.BigEndian.PutUint64(tmp[padLen:], length<<3)
+	sm3.Write(tmp[:padLen+8])
 
-	for i := 0; i < 8; i++ {
-		binary.BigEndian.PutUint32(out[i*4:], sm3.digest[i])
+	if sm3.nx != 0 {
+		panic("sm3: internal error, unexpected buffered data after padding")
 	}
-	return out
 
+	var digest [size]byte
+	for i, v := range sm3.digest {
+		binary.BigEndian.PutUint32(digest[i*4:], v)
+	}
+	return digest
 }
 
 func Sm3Sum(data []byte) []byte {
@@ -203,5 +237,6 @@ func Sm3Sum(data []byte) []byte {
 
 	sm3.Reset()
 	sm3.Write(data)
-	return sm3.Sum(nil)
+	hash := sm3.checkSum()
+	return hash[:]
 }
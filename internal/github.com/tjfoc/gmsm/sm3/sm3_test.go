@@ -16,6 +16,9 @@ limitations under the License.
 package sm3
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -53,6 +56,34 @@ func TestSm3(t *testing.T) {
 
 }
 
+// GB/T 32905-2016 附录 A 的示例
+func TestSm3Vectors(t *testing.T) {
+	vectors := []struct {
+		msg  string
+		hash string
+	}{
+		{"abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+		{"abcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcd", "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+	}
+	for _, v := range vectors {
+		if got := hex.EncodeToString(Sm3Sum([]byte(v.msg))); got != v.hash {
+			t.Errorf("Sm3Sum(%q) = %s, want %s", v.msg, got, v.hash)
+		}
+
+		// 分段写入，且 Sum 不改变内部状态
+		h := New()
+		for i := 0; i < len(v.msg); i++ {
+			h.Write([]byte{v.msg[i]})
+			h.Sum(nil)
+		}
+		prefix := []byte("prefix")
+		sum := h.Sum(prefix)
+		if !bytes.HasPrefix(sum, prefix) || hex.EncodeToString(sum[len(prefix):]) != v.hash {
+			t.Errorf("Sum(prefix) of %q = %x, want prefix followed by %s", v.msg, sum, v.hash)
+		}
+	}
+}
+
 func BenchmarkSm3(t *testing.B) {
 	t.ReportAllocs()
 	msg := []byte("test")
@@ -63,3 +94,31 @@ func BenchmarkSm3(t *testing.B) {
 		Sm3Sum(msg)
 	}
 }
+
+// 与标准库 SHA-256 对比的性能测试，go test -bench . -benchmem
+
+func BenchmarkHash(b *testing.B) {
+	for _, size := range []int{64, 1024, 8192} {
+		data := make([]byte, size)
+		b.Run(fmt.Sprintf("SM3-%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			h := New()
+			for i := 0; i < b.N; i++ {
+				h.Reset()
+				h.Write(data)
+				h.Sum(nil)
+			}
+		})
+		b.Run(fmt.Sprintf("SHA256-%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			h := sha256.New()
+			for i := 0; i < b.N; i++ {
+				h.Reset()
+				h.Write(data)
+				h.Sum(nil)
+			}
+		})
+	}
+}
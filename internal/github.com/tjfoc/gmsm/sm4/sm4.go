@@ -22,6 +22,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/x509"
+	"encoding/binary"
 	"encoding/pem"
 	"errors"
 	"io/ioutil"
@@ -35,11 +36,11 @@ type SM4Key []byte
 
 type KeySizeError int
 
-// Cipher is an instance of SM4 encryption.
+// Cipher is an instance of SM4 encryption. It holds no per-call scratch
+// state and is safe for concurrent use.
 type Sm4Cipher struct {
-	subkeys []uint32
-	block1  []uint32
-	block2  []byte
+	enc [32]uint32 // 加密轮密钥
+	dec [32]uint32 // 解密轮密钥，即逆序的加密轮密钥
 }
 
 // sm4密钥参量
@@ -166,77 +167,56 @@ func p(a uint32) uint32 {
 	return (uint32(sbox[a>>24]) << 24) ^ (uint32(sbox[(a>>16)&0xff]) << 16) ^ (uint32(sbox[(a>>8)&0xff]) << 8) ^ uint32(sbox[(a)&0xff])
 }
 
-func permuteInitialBlock(b []uint32, block []byte) {
-	for i := 0; i < 4; i++ {
-		b[i] = (uint32(block[i*4]) << 24) | (uint32(block[i*4+1]) << 16) |
-			(uint32(block[i*4+2]) << 8) | (uint32(block[i*4+3]))
-	}
+// t 为合成置换 T = L(τ(.))，以查表实现
+func t(x uint32) uint32 {
+	return sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
 }
 
-func permuteFinalBlock(b []byte, block []uint32) {
-	for i := 0; i < 4; i++ {
-		b[i*4] = uint8(block[i] >> 24)
-		b[i*4+1] = uint8(block[i] >> 16)
-		b[i*4+2] = uint8(block[i] >> 8)
-		b[i*4+3] = uint8(block[i])
+// 修改后的加密核心函数，每次迭代执行 4 轮，状态保存在局部变量中
+func cryptBlock(rk *[32]uint32, dst, src []byte) {
+	_ = src[15] // bounds check hint to compiler
+	b0 := binary.BigEndian.Uint32(src[0:4])
+	b1 := binary.BigEndian.Uint32(src[4:8])
+	b2 := binary.BigEndian.Uint32(src[8:12])
+	b3 := binary.BigEndian.Uint32(src[12:16])
+
+	for i := 0; i < 32; i += 4 {
+		b0 ^= t(b1 ^ b2 ^ b3 ^ rk[i])
+		b1 ^= t(b0 ^ b2 ^ b3 ^ rk[i+1])
+		b2 ^= t(b0 ^ b1 ^ b3 ^ rk[i+2])
+		b3 ^= t(b0 ^ b1 ^ b2 ^ rk[i+3])
 	}
-}
 
-//修改后的加密核心函数
-func cryptBlock(subkeys []uint32, b []uint32, r []byte, dst, src []byte, decrypt bool) {
-	var x uint32
-	permuteInitialBlock(b, src)
-	if decrypt {
-		for i := 0; i < 8; i++ {
-			x = b[1] ^ b[2] ^ b[3] ^ subkeys[31-4*i]
-			b[0] = b[0] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-			x = b[0] ^ b[2] ^ b[3] ^ subkeys[31-4*i-1]
-			b[1] = b[1] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-			x = b[0] ^ b[1] ^ b[3] ^ subkeys[31-4*i-2]
-			b[2] = b[2] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-			x = b[1] ^ b[2] ^ b[0] ^ subkeys[31-4*i-3]
-			b[3] = b[3] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-		}
-	} else {
-		for i := 0; i < 8; i++ {
-			x = b[1] ^ b[2] ^ b[3] ^ subkeys[4*i]
-			b[0] = b[0] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-			x = b[0] ^ b[2] ^ b[3] ^ subkeys[4*i+1]
-			b[1] = b[1] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-			x = b[0] ^ b[1] ^ b[3] ^ subkeys[4*i+2]
-			b[2] = b[2] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-			x = b[1] ^ b[2] ^ b[0] ^ subkeys[4*i+3]
-			b[3] = b[3] ^ sbox0[x&0xff] ^ sbox1[(x>>8)&0xff] ^ sbox2[(x>>16)&0xff] ^ sbox3[(x>>24)&0xff]
-		}
-	}
-	b[0], b[1], b[2], b[3] = b[3], b[2], b[1], b[0]
-	permuteFinalBlock(r, b)
-	copy(dst, r)
+	_ = dst[15] // bounds check hint to compiler
+	binary.BigEndian.PutUint32(dst[0:4], b3)
+	binary.BigEndian.PutUint32(dst[4:8], b2)
+	binary.BigEndian.PutUint32(dst[8:12], b1)
+	binary.BigEndian.PutUint32(dst[12:16], b0)
 }
 
-func generateSubKeys(key []byte) []uint32 {
-	subkeys := make([]uint32, 32)
-	b := make([]uint32, 4)
-	permuteInitialBlock(b, key)
-	b[0] ^= fk[0]
-	b[1] ^= fk[1]
-	b[2] ^= fk[2]
-	b[3] ^= fk[3]
+// generateSubKeys 生成加密轮密钥 enc 及解密轮密钥 dec
+func generateSubKeys(key []byte, enc, dec *[32]uint32) {
+	b0 := binary.BigEndian.Uint32(key[0:4]) ^ fk[0]
+	b1 := binary.BigEndian.Uint32(key[4:8]) ^ fk[1]
+	b2 := binary.BigEndian.Uint32(key[8:12]) ^ fk[2]
+	b3 := binary.BigEndian.Uint32(key[12:16]) ^ fk[3]
 	for i := 0; i < 32; i++ {
-		subkeys[i] = feistel0(b[0], b[1], b[2], b[3], ck[i])
-		b[0], b[1], b[2], b[3] = b[1], b[2], b[3], subkeys[i]
+		enc[i] = feistel0(b0, b1, b2, b3, ck[i])
+		dec[31-i] = enc[i]
+		b0, b1, b2, b3 = b1, b2, b3, enc[i]
 	}
-	return subkeys
 }
 
 func EncryptBlock(key SM4Key, dst, src []byte) {
-	subkeys := generateSubKeys(key)
-	cryptBlock(subkeys, make([]uint32, 4), make([]byte, 16), dst, src, false)
+	var c Sm4Cipher
+	generateSubKeys(key, &c.enc, &c.dec)
+	cryptBlock(&c.enc, dst, src)
 }
 
 func DecryptBlock(key SM4Key, dst, src []byte) {
-	subkeys := generateSubKeys(key)
-	cryptBlock(subkeys, make([]uint32, 4), make([]byte, 16), dst, src, true)
+	var c Sm4Cipher
+	generateSubKeys(key, &c.enc, &c.dec)
+	cryptBlock(&c.dec, dst, src)
 }
 
 func ReadKeyFromMem(data []byte, pwd []byte) (SM4Key, error) {
@@ -323,9 +303,7 @@ func NewCipher(key []byte) (cipher.Block, error) {
 		return nil, KeySizeError(len(key))
 	}
 	c := new(Sm4Cipher)
-	c.subkeys = generateSubKeys(key)
-	c.block1 = make([]uint32, 4)
-	c.block2 = make([]byte, 16)
+	generateSubKeys(key, &c.enc, &c.dec)
 	return c, nil
 }
 
@@ -334,9 +312,9 @@ func (c *Sm4Cipher) BlockSize() int {
 }
 
 func (c *Sm4Cipher) Encrypt(dst, src []byte) {
-	cryptBlock(c.subkeys, c.block1, c.block2, dst, src, false)
+	cryptBlock(&c.enc, dst, src)
 }
 
 func (c *Sm4Cipher) Decrypt(dst, src []byte) {
-	cryptBlock(c.subkeys, c.block1, c.block2, dst, src, true)
+	cryptBlock(&c.dec, dst, src)
 }
@@ -16,9 +16,14 @@ limitations under the License.
 package sm4
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -70,6 +75,70 @@ func BenchmarkSM4(t *testing.B) {
 	}
 }
 
+// GB/T 32907-2016 附录 A 的示例
+func TestSM4Vectors(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	want, _ := hex.DecodeString("681edf34d206965e86b3e94f536e4246")
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]byte, BlockSize)
+	c.Encrypt(dst, key)
+	if !bytes.Equal(dst, want) {
+		t.Fatalf("Encrypt = %x, want %x", dst, want)
+	}
+	c.Decrypt(dst, dst)
+	if !bytes.Equal(dst, key) {
+		t.Fatalf("Decrypt = %x, want %x", dst, key)
+	}
+
+	EncryptBlock(key, dst, key)
+	if !bytes.Equal(dst, want) {
+		t.Fatalf("EncryptBlock = %x, want %x", dst, want)
+	}
+	DecryptBlock(key, dst, dst)
+	if !bytes.Equal(dst, key) {
+		t.Fatalf("DecryptBlock = %x, want %x", dst, key)
+	}
+
+	// 同一明文反复加密 1000000 次
+	want, _ = hex.DecodeString("595298c7c6fd271f0402f804c33d3f66")
+	copy(dst, key)
+	for i := 0; i < 1000000; i++ {
+		c.Encrypt(dst, dst)
+	}
+	if !bytes.Equal(dst, want) {
+		t.Fatalf("1000000 rounds of Encrypt = %x, want %x", dst, want)
+	}
+}
+
+// 同一个 cipher.Block 可以被并发使用
+func TestSM4Concurrent(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	want, _ := hex.DecodeString("681edf34d206965e86b3e94f536e4246")
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dst := make([]byte, BlockSize)
+			for j := 0; j < 1000; j++ {
+				c.Encrypt(dst, key)
+				if !bytes.Equal(dst, want) {
+					t.Errorf("Encrypt = %x, want %x", dst, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func TestErrKeyLen(t *testing.T) {
 	fmt.Printf("\n--------------test key len------------------")
 	key := []byte("1234567890abcdefg")
@@ -100,3 +169,53 @@ func testCompare(key1, key2 []byte) bool {
 	}
 	return true
 }
+
+// 与标准库 AES-128 对比的性能测试，go test -bench . -benchmem
+
+func BenchmarkBlock(b *testing.B) {
+	key := []byte("1234567890abcdef")
+	for name, newCipher := range map[string]func([]byte) (cipher.Block, error){"SM4": NewCipher, "AES128": aes.NewCipher} {
+		c, err := newCipher(key)
+		if err != nil {
+			b.Fatal(err)
+		}
+		buf := make([]byte, BlockSize)
+		b.Run(name+"-Encrypt", func(b *testing.B) {
+			b.SetBytes(BlockSize)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Encrypt(buf, buf)
+			}
+		})
+		b.Run(name+"-Decrypt", func(b *testing.B) {
+			b.SetBytes(BlockSize)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Decrypt(buf, buf)
+			}
+		})
+	}
+}
+
+func BenchmarkGCM(b *testing.B) {
+	key := []byte("1234567890abcdef")
+	for name, newCipher := range map[string]func([]byte) (cipher.Block, error){"SM4": NewCipher, "AES128": aes.NewCipher} {
+		c, err := newCipher(key)
+		if err != nil {
+			b.Fatal(err)
+		}
+		aead, err := cipher.NewGCM(c)
+		if err != nil {
+			b.Fatal(err)
+		}
+		nonce := make([]byte, aead.NonceSize())
+		buf := make([]byte, 1024, 1024+aead.Overhead())
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(buf)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				aead.Seal(buf[:0], nonce, buf, nil)
+			}
+		})
+	}
+}
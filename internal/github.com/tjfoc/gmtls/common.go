@@ -29,7 +29,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 )
 
 const (
@@ -37,6 +37,9 @@ const (
 	VersionTLS10 = 0x0301
 	VersionTLS11 = 0x0302
 	VersionTLS12 = 0x0303
+
+	// VersionGMSSL is the protocol version of GM/T 0024-2014 (SSL VPN).
+	VersionGMSSL = 0x0101
 )
 
 const (
@@ -518,6 +521,11 @@ type Config struct {
 	// used for debugging.
 	KeyLogWriter io.Writer
 
+	// GMSupport, if not nil, switches the connection to the GM/T 0024
+	// protocol. In that mode Certificates[0] is the signing certificate and
+	// Certificates[1] the encryption certificate of the endpoint.
+	GMSupport *GMSupport
+
 	serverInitOnce sync.Once // guards calling (*Config).serverInit
 
 	// mutex protects sessionTicketKeys and originalConfig.
@@ -594,6 +602,7 @@ func (c *Config) Clone() *Config {
 		DynamicRecordSizingDisabled: c.DynamicRecordSizingDisabled,
 		Renegotiation:               c.Renegotiation,
 		KeyLogWriter:                c.KeyLogWriter,
+		GMSupport:                   c.GMSupport,
 		sessionTicketKeys:           sessionTicketKeys,
 		// originalConfig is deliberately not duplicated.
 	}
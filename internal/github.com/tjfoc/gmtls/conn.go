@@ -27,7 +27,7 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 )
 
 // A Conn represents a secured connection.
@@ -320,7 +320,7 @@ func (hc *halfConn) decrypt(b *block) (ok bool, prefixLen int, alertValue alert)
 			b.resize(recordHeaderLen + explicitIVLen + len(payload))
 		case cbcMode:
 			blockSize := c.BlockSize()
-			if hc.version >= VersionTLS11 {
+			if hc.version >= VersionTLS11 || hc.version == VersionGMSSL {
 				explicitIVLen = blockSize
 			}
 
@@ -868,7 +868,7 @@ func (c *Conn) writeRecordLocked(typ recordType, data []byte) (int, error) {
 		explicitIVIsSeq := false
 
 		var cbc cbcMode
-		if c.out.version >= VersionTLS11 {
+		if c.out.version >= VersionTLS11 || c.out.version == VersionGMSSL {
 			var ok bool
 			if cbc, ok = c.out.cipher.(cbcMode); ok {
 				explicitIVLen = cbc.BlockSize()
@@ -898,6 +898,9 @@ func (c *Conn) writeRecordLocked(typ recordType, data []byte) (int, error) {
 			// Some TLS servers fail if the record version is
 			// greater than TLS 1.0 for the initial ClientHello.
 			vers = VersionTLS10
+			if c.config.GMSupport != nil {
+				vers = VersionGMSSL
+			}
 		}
 		b.data[1] = byte(vers >> 8)
 		b.data[2] = byte(vers)
@@ -1064,7 +1067,7 @@ func (c *Conn) Write(b []byte) (int, error) {
 	// http://www.imperialviolet.org/2012/01/15/beastfollowup.html
 
 	var m int
-	if len(b) > 1 && c.vers <= VersionTLS10 {
+	if len(b) > 1 && c.vers <= VersionTLS10 && c.vers != VersionGMSSL {
 		if _, ok := c.out.cipher.(cipher.BlockMode); ok {
 			n, err := c.writeRecordLocked(recordTypeApplicationData, b[:1])
 			if err != nil {
@@ -1312,7 +1315,13 @@ func (c *Conn) Handshake() error {
 		panic("handshake should not have been able to complete after handshakeCond was set")
 	}
 
-	if c.isClient {
+	if c.config.GMSupport != nil {
+		if c.isClient {
+			c.handshakeErr = c.clientHandshakeGM()
+		} else {
+			c.handshakeErr = c.serverHandshakeGM()
+		}
+	} else if c.isClient {
 		c.handshakeErr = c.clientHandshake()
 	} else {
 		c.handshakeErr = c.serverHandshake()
@@ -0,0 +1,271 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gmtls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+)
+
+// clientHandshakeGM performs a GM/T 0024 handshake as a client. Session
+// resumption and renegotiation are not supported in GM mode.
+// c.out.Mutex <= L; c.handshakeMutex <= L.
+func (c *Conn) clientHandshakeGM() error {
+	if c.handshakes > 0 {
+		c.sendAlert(alertNoRenegotiation)
+		return errors.New("tls: renegotiation is not supported in GM mode")
+	}
+
+	if len(c.config.ServerName) == 0 && !c.config.InsecureSkipVerify {
+		return errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
+	}
+
+	for _, proto := range c.config.NextProtos {
+		if l := len(proto); l == 0 || l > 255 {
+			return errors.New("tls: invalid NextProtos value")
+		}
+	}
+
+	hello := &clientHelloMsg{
+		vers:               VersionGMSSL,
+		compressionMethods: []uint8{compressionNone},
+		random:             make([]byte, 32),
+		cipherSuites:       c.config.GMSupport.cipherSuites(),
+		serverName:         hostnameInSNI(c.config.ServerName),
+		alpnProtocols:      c.config.NextProtos,
+	}
+
+	if _, err := io.ReadFull(c.config.rand(), hello.random); err != nil {
+		c.sendAlert(alertInternalError)
+		return errors.New("tls: short read from Rand: " + err.Error())
+	}
+
+	if _, err := c.writeRecord(recordTypeHandshake, hello.marshal()); err != nil {
+		return err
+	}
+
+	msg, err := c.readHandshake()
+	if err != nil {
+		return err
+	}
+	serverHello, ok := msg.(*serverHelloMsg)
+	if !ok {
+		c.sendAlert(alertUnexpectedMessage)
+		return unexpectedMessageError(serverHello, msg)
+	}
+
+	if serverHello.vers != VersionGMSSL {
+		c.sendAlert(alertProtocolVersion)
+		return fmt.Errorf("tls: server selected unsupported protocol version %x", serverHello.vers)
+	}
+	c.vers = serverHello.vers
+	c.haveVers = true
+
+	suite := getGMCipherSuite(serverHello.cipherSuite)
+	if suite == nil {
+		c.sendAlert(alertHandshakeFailure)
+		return errors.New("tls: server chose an unconfigured cipher suite")
+	}
+
+	hs := &clientHandshakeState{
+		c:            c,
+		serverHello:  serverHello,
+		hello:        hello,
+		suite:        suite,
+		finishedHash: newFinishedHash(c.vers, suite),
+	}
+	hs.finishedHash.Write(hs.hello.marshal())
+	hs.finishedHash.Write(hs.serverHello.marshal())
+
+	c.buffering = true
+	if _, err := hs.processServerHello(); err != nil {
+		return err
+	}
+	if err := hs.doFullHandshakeGM(); err != nil {
+		return err
+	}
+	if err := hs.establishKeys(); err != nil {
+		return err
+	}
+	if err := hs.sendFinished(c.clientFinished[:]); err != nil {
+		return err
+	}
+	if _, err := c.flush(); err != nil {
+		return err
+	}
+	c.clientFinishedIsFirst = true
+	if err := hs.readFinished(c.serverFinished[:]); err != nil {
+		return err
+	}
+
+	c.didResume = false
+	c.handshakeComplete = true
+	c.cipherSuite = suite.id
+	return nil
+}
+
+func (hs *clientHandshakeState) doFullHandshakeGM() error {
+	c := hs.c
+
+	msg, err := c.readHandshake()
+	if err != nil {
+		return err
+	}
+	certMsg, ok := msg.(*certificateMsg)
+	if !ok || len(certMsg.certificates) < 2 {
+		c.sendAlert(alertUnexpectedMessage)
+		return unexpectedMessageError(certMsg, msg)
+	}
+	hs.finishedHash.Write(certMsg.marshal())
+
+	// The server sends its signing certificate, its encryption certificate
+	// and then the rest of the chain.
+	certs := make([]*sm2.Certificate, len(certMsg.certificates))
+	for i, asn1Data := range certMsg.certificates {
+		cert, err := sm2.ParseCertificate(asn1Data)
+		if err != nil {
+			c.sendAlert(alertBadCertificate)
+			return errors.New("tls: failed to parse certificate from server: " + err.Error())
+		}
+		certs[i] = cert
+	}
+	signCert, encCert := certs[0], certs[1]
+
+	if !c.config.InsecureSkipVerify {
+		opts := sm2.VerifyOptions{
+			Roots:         c.config.RootCAs,
+			CurrentTime:   c.config.time(),
+			DNSName:       c.config.ServerName,
+			Intermediates: sm2.NewCertPool(),
+		}
+		for _, cert := range certs[2:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		c.verifiedChains, err = signCert.Verify(opts)
+		if err != nil {
+			c.sendAlert(alertBadCertificate)
+			return err
+		}
+
+		opts.DNSName = ""
+		if _, err = encCert.Verify(opts); err != nil {
+			c.sendAlert(alertBadCertificate)
+			return errors.New("tls: failed to verify server's encryption certificate: " + err.Error())
+		}
+	}
+
+	if c.config.VerifyPeerCertificate != nil {
+		if err := c.config.VerifyPeerCertificate(certMsg.certificates, c.verifiedChains); err != nil {
+			c.sendAlert(alertBadCertificate)
+			return err
+		}
+	}
+
+	for _, cert := range []*sm2.Certificate{signCert, encCert} {
+		if _, ok := sm2PublicKey(cert.PublicKey); !ok {
+			c.sendAlert(alertUnsupportedCertificate)
+			return fmt.Errorf("tls: server's certificate contains an unsupported type of public key: %T", cert.PublicKey)
+		}
+	}
+	c.peerCertificates = certs
+
+	msg, err = c.readHandshake()
+	if err != nil {
+		return err
+	}
+
+	keyAgreement := &eccKeyAgreementGM{version: c.vers, encCertDER: encCert.Raw}
+
+	skx, ok := msg.(*serverKeyExchangeMsg)
+	if !ok {
+		c.sendAlert(alertUnexpectedMessage)
+		return unexpectedMessageError(skx, msg)
+	}
+	hs.finishedHash.Write(skx.marshal())
+	if err := keyAgreement.processServerKeyExchange(c.config, hs.hello, hs.serverHello, signCert, skx); err != nil {
+		c.sendAlert(alertUnexpectedMessage)
+		return err
+	}
+
+	msg, err = c.readHandshake()
+	if err != nil {
+		return err
+	}
+
+	var certRequested bool
+	certReq, ok := msg.(*certificateRequestMsg)
+	if ok {
+		certRequested = true
+		hs.finishedHash.Write(certReq.marshal())
+
+		msg, err = c.readHandshake()
+		if err != nil {
+			return err
+		}
+	}
+
+	shd, ok := msg.(*serverHelloDoneMsg)
+	if !ok {
+		c.sendAlert(alertUnexpectedMessage)
+		return unexpectedMessageError(shd, msg)
+	}
+	hs.finishedHash.Write(shd.marshal())
+
+	// In GM mode a client identity consists of a signing and an encryption
+	// certificate, configured as the first two entries of Certificates.
+	var signChain *Certificate
+	if certRequested {
+		certMsg = new(certificateMsg)
+		if len(c.config.Certificates) > 0 {
+			signChain = &c.config.Certificates[0]
+			certMsg.certificates = append(certMsg.certificates, signChain.Certificate[0])
+			if len(c.config.Certificates) > 1 {
+				certMsg.certificates = append(certMsg.certificates, c.config.Certificates[1].Certificate[0])
+			}
+			certMsg.certificates = append(certMsg.certificates, signChain.Certificate[1:]...)
+		}
+		hs.finishedHash.Write(certMsg.marshal())
+		if _, err := c.writeRecord(recordTypeHandshake, certMsg.marshal()); err != nil {
+			return err
+		}
+	}
+
+	preMasterSecret, ckx, err := keyAgreement.generateClientKeyExchange(c.config, hs.hello, encCert)
+	if err != nil {
+		c.sendAlert(alertInternalError)
+		return err
+	}
+	hs.finishedHash.Write(ckx.marshal())
+	if _, err := c.writeRecord(recordTypeHandshake, ckx.marshal()); err != nil {
+		return err
+	}
+
+	if signChain != nil {
+		certVerify := new(certificateVerifyMsg)
+		certVerify.signature, err = signGM(c.config.rand(), signChain.PrivateKey, hs.finishedHash.Sum())
+		if err != nil {
+			c.sendAlert(alertInternalError)
+			return errors.New("tls: failed to sign handshake with client certificate: " + err.Error())
+		}
+
+		hs.finishedHash.Write(certVerify.marshal())
+		if _, err := c.writeRecord(recordTypeHandshake, certVerify.marshal()); err != nil {
+			return err
+		}
+	}
+
+	hs.masterSecret = masterFromPreMasterSecret(c.vers, hs.suite, preMasterSecret, hs.hello.random, hs.serverHello.random)
+	if err := c.config.writeKeyLog(hs.hello.random, hs.masterSecret); err != nil {
+		c.sendAlert(alertInternalError)
+		return errors.New("tls: failed to write to key log: " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gmtls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// serverHandshakeGM performs a GM/T 0024 handshake as a server.
+// Config.Certificates must hold the signing certificate followed by the
+// encryption certificate. Session resumption is not supported in GM mode.
+// c.out.Mutex <= L; c.handshakeMutex <= L.
+func (c *Conn) serverHandshakeGM() error {
+	if len(c.config.Certificates) < 2 {
+		c.sendAlert(alertInternalError)
+		return errors.New("tls: GM mode requires a signing and an encryption certificate")
+	}
+
+	hs := serverHandshakeState{
+		c:    c,
+		cert: &c.config.Certificates[0],
+	}
+	encCert := &c.config.Certificates[1]
+
+	if err := hs.readClientHelloGM(); err != nil {
+		return err
+	}
+
+	c.buffering = true
+	if err := hs.doFullHandshakeGM(encCert); err != nil {
+		return err
+	}
+	if err := hs.establishKeys(); err != nil {
+		return err
+	}
+	if err := hs.readFinished(c.clientFinished[:]); err != nil {
+		return err
+	}
+	c.clientFinishedIsFirst = true
+	c.buffering = true
+	if err := hs.sendFinished(nil); err != nil {
+		return err
+	}
+	if _, err := c.flush(); err != nil {
+		return err
+	}
+	c.handshakeComplete = true
+
+	return nil
+}
+
+// readClientHelloGM reads a ClientHello message from the client and selects
+// a GM cipher suite.
+func (hs *serverHandshakeState) readClientHelloGM() error {
+	c := hs.c
+
+	msg, err := c.readHandshake()
+	if err != nil {
+		return err
+	}
+	var ok bool
+	hs.clientHello, ok = msg.(*clientHelloMsg)
+	if !ok {
+		c.sendAlert(alertUnexpectedMessage)
+		return unexpectedMessageError(hs.clientHello, msg)
+	}
+
+	if hs.clientHello.vers != VersionGMSSL {
+		c.sendAlert(alertProtocolVersion)
+		return fmt.Errorf("tls: client offered an unsupported protocol version of %x", hs.clientHello.vers)
+	}
+	c.vers = VersionGMSSL
+	c.haveVers = true
+
+	foundCompression := false
+	for _, compression := range hs.clientHello.compressionMethods {
+		if compression == compressionNone {
+			foundCompression = true
+			break
+		}
+	}
+	if !foundCompression {
+		c.sendAlert(alertHandshakeFailure)
+		return errors.New("tls: client does not support uncompressed connections")
+	}
+
+	for _, id := range hs.clientHello.cipherSuites {
+		if hs.suite = getGMCipherSuite(id); hs.suite != nil {
+			break
+		}
+	}
+	if hs.suite == nil {
+		c.sendAlert(alertHandshakeFailure)
+		return errors.New("tls: no cipher suite supported by both client and server")
+	}
+
+	hs.hello = &serverHelloMsg{
+		vers:              c.vers,
+		random:            make([]byte, 32),
+		cipherSuite:       hs.suite.id,
+		compressionMethod: compressionNone,
+	}
+	if _, err := io.ReadFull(c.config.rand(), hs.hello.random); err != nil {
+		c.sendAlert(alertInternalError)
+		return err
+	}
+
+	if len(hs.clientHello.serverName) > 0 {
+		c.serverName = hs.clientHello.serverName
+	}
+	if len(hs.clientHello.alpnProtocols) > 0 {
+		if selectedProto, fallback := mutualProtocol(hs.clientHello.alpnProtocols, c.config.NextProtos); !fallback {
+			hs.hello.alpnProtocol = selectedProto
+			c.clientProtocol = selectedProto
+		}
+	}
+
+	return nil
+}
+
+func (hs *serverHandshakeState) doFullHandshakeGM(encCert *Certificate) error {
+	c := hs.c
+
+	hs.finishedHash = newFinishedHash(c.vers, hs.suite)
+	hs.finishedHash.Write(hs.clientHello.marshal())
+	hs.finishedHash.Write(hs.hello.marshal())
+	if _, err := c.writeRecord(recordTypeHandshake, hs.hello.marshal()); err != nil {
+		return err
+	}
+
+	// The Certificate message carries the signing certificate, the
+	// encryption certificate and then the rest of the signing chain.
+	certMsg := new(certificateMsg)
+	certMsg.certificates = append(certMsg.certificates, hs.cert.Certificate[0], encCert.Certificate[0])
+	certMsg.certificates = append(certMsg.certificates, hs.cert.Certificate[1:]...)
+	hs.finishedHash.Write(certMsg.marshal())
+	if _, err := c.writeRecord(recordTypeHandshake, certMsg.marshal()); err != nil {
+		return err
+	}
+
+	keyAgreement := &eccKeyAgreementGM{
+		version:    c.vers,
+		encCertDER: encCert.Certificate[0],
+		encPrivKey: encCert.PrivateKey,
+	}
+	skx, err := keyAgreement.generateServerKeyExchange(c.config, hs.cert, hs.clientHello, hs.hello)
+	if err != nil {
+		c.sendAlert(alertHandshakeFailure)
+		return err
+	}
+	hs.finishedHash.Write(skx.marshal())
+	if _, err := c.writeRecord(recordTypeHandshake, skx.marshal()); err != nil {
+		return err
+	}
+
+	if c.config.ClientAuth >= RequestClientCert {
+		certReq := new(certificateRequestMsg)
+		certReq.certificateTypes = []byte{byte(certTypeECDSASign)}
+		if c.config.ClientCAs != nil {
+			certReq.certificateAuthorities = c.config.ClientCAs.Subjects()
+		}
+		hs.finishedHash.Write(certReq.marshal())
+		if _, err := c.writeRecord(recordTypeHandshake, certReq.marshal()); err != nil {
+			return err
+		}
+	}
+
+	helloDone := new(serverHelloDoneMsg)
+	hs.finishedHash.Write(helloDone.marshal())
+	if _, err := c.writeRecord(recordTypeHandshake, helloDone.marshal()); err != nil {
+		return err
+	}
+
+	if _, err := c.flush(); err != nil {
+		return err
+	}
+
+	msg, err := c.readHandshake()
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	if c.config.ClientAuth >= RequestClientCert {
+		if certMsg, ok = msg.(*certificateMsg); !ok {
+			c.sendAlert(alertUnexpectedMessage)
+			return unexpectedMessageError(certMsg, msg)
+		}
+		hs.finishedHash.Write(certMsg.marshal())
+
+		if len(certMsg.certificates) == 0 {
+			switch c.config.ClientAuth {
+			case RequireAnyClientCert, RequireAndVerifyClientCert:
+				c.sendAlert(alertBadCertificate)
+				return errors.New("tls: client didn't provide a certificate")
+			}
+		}
+
+		if _, err = hs.processCertsFromClient(certMsg.certificates); err != nil {
+			return err
+		}
+
+		msg, err = c.readHandshake()
+		if err != nil {
+			return err
+		}
+	}
+
+	ckx, ok := msg.(*clientKeyExchangeMsg)
+	if !ok {
+		c.sendAlert(alertUnexpectedMessage)
+		return unexpectedMessageError(ckx, msg)
+	}
+	hs.finishedHash.Write(ckx.marshal())
+
+	preMasterSecret, err := keyAgreement.processClientKeyExchange(c.config, hs.cert, ckx, c.vers)
+	if err != nil {
+		c.sendAlert(alertHandshakeFailure)
+		return err
+	}
+	hs.masterSecret = masterFromPreMasterSecret(c.vers, hs.suite, preMasterSecret, hs.clientHello.random, hs.hello.random)
+	if err := c.config.writeKeyLog(hs.clientHello.random, hs.masterSecret); err != nil {
+		c.sendAlert(alertInternalError)
+		return err
+	}
+
+	// A client that sent a certificate proves possession of its signing key
+	// with a CertificateVerify over the SM3 hash of the handshake so far.
+	if len(c.peerCertificates) > 0 {
+		digest := hs.finishedHash.Sum()
+
+		msg, err = c.readHandshake()
+		if err != nil {
+			return err
+		}
+		certVerify, ok := msg.(*certificateVerifyMsg)
+		if !ok {
+			c.sendAlert(alertUnexpectedMessage)
+			return unexpectedMessageError(certVerify, msg)
+		}
+
+		if err := verifyGM(c.peerCertificates[0].PublicKey, digest, certVerify.signature); err != nil {
+			c.sendAlert(alertBadCertificate)
+			return errors.New("tls: could not validate signature of connection nonces: " + err.Error())
+		}
+
+		hs.finishedHash.Write(certVerify.marshal())
+	}
+
+	return nil
+}
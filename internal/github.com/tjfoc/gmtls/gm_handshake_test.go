@@ -0,0 +1,399 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gmtls_test
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm4"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls"
+)
+
+// The values below are taken from GM/T 0024-2014 and GB/T 32905-2016, not from the
+// gmtls package, so that the handshake is checked against the standard rather than
+// against the implementation under test.
+const (
+	gmVersion             = 0x0101
+	eccSM4SM3             = 0xe013
+	recordChangeCipher    = 20
+	recordHandshake       = 22
+	recordApplicationData = 23
+	typeClientHello       = 1
+	typeServerHello       = 2
+	typeCertificate       = 11
+	typeServerKeyExch     = 12
+	typeServerHelloDone   = 14
+	typeClientKeyExch     = 16
+	typeFinished          = 20
+	macLen                = 32
+	keyLen                = 16
+	ivLen                 = 16
+	verifyDataLen         = 12
+)
+
+// sm3Abc is the SM3 digest of "abc" given in GB/T 32905-2016, appendix A
+const sm3Abc = "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"
+
+var defaultUID = []byte("1234567812345678")
+
+// TestGMHandshakeWireFormat runs the gmtls client against a GM/T 0024 server written
+// in this test from the standard, with its own message encoding, PRF and record
+// protection. Only the SM2, SM3 and SM4 primitives are shared with the client.
+func TestGMHandshakeWireFormat(t *testing.T) {
+	if digest := sm3.Sm3Sum([]byte("abc")); hex.EncodeToString(digest) != sm3Abc {
+		t.Fatalf("SM3 does not match the GB/T 32905 test vector: %x", digest)
+	}
+
+	ca, signKey, signCert, encKey, encCert := gmServerCerts(t, "peer0.example.com")
+	roots := sm2.NewCertPool()
+	roots.AddCert(ca)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	clientErr := make(chan error, 1)
+	go func() {
+		client := gmtls.Client(clientConn, &gmtls.Config{
+			GMSupport:  gmtls.NewGMSupport(),
+			RootCAs:    roots,
+			ServerName: "peer0.example.com",
+		})
+		if _, err := client.Write([]byte("ping")); err != nil {
+			clientErr <- err
+			return
+		}
+		reply := make([]byte, 4)
+		if _, err := io.ReadFull(client, reply); err != nil {
+			clientErr <- err
+			return
+		}
+		if string(reply) != "pong" {
+			clientErr <- io.ErrUnexpectedEOF
+			return
+		}
+		clientErr <- nil
+	}()
+
+	s := &gmServer{t: t, conn: serverConn}
+	s.handshake(signKey, signCert, encKey, encCert)
+
+	if msg := s.readProtected(recordApplicationData); string(msg) != "ping" {
+		t.Fatalf("expected application data [ping] but got [%s]", msg)
+	}
+	s.writeProtected(recordApplicationData, []byte("pong"))
+
+	select {
+	case err := <-clientErr:
+		if err != nil {
+			t.Fatalf("client failed: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the client")
+	}
+}
+
+// gmServer is the server side of a GM/T 0024 connection with the ECC_SM4_SM3 suite
+type gmServer struct {
+	t          *testing.T
+	conn       net.Conn
+	transcript bytes.Buffer
+
+	clientMAC, serverMAC []byte
+	clientKey, serverKey []byte
+	readSeq, writeSeq    uint64
+}
+
+func (s *gmServer) handshake(signKey *sm2.PrivateKey, signCert *sm2.Certificate, encKey *sm2.PrivateKey, encCert *sm2.Certificate) {
+	t := s.t
+
+	clientHello := s.readHandshake(typeClientHello)
+	if v := binary.BigEndian.Uint16(clientHello[4:]); v != gmVersion {
+		t.Fatalf("expected ClientHello version %#x but got %#x", gmVersion, v)
+	}
+	clientRandom := clientHello[6:38]
+	if !offersSuite(t, clientHello[38:], eccSM4SM3) {
+		t.Fatalf("ClientHello does not offer ECC_SM4_SM3")
+	}
+
+	serverRandom := make([]byte, 32)
+	if _, err := rand.Read(serverRandom); err != nil {
+		t.Fatalf("failed to generate server random: %s", err)
+	}
+
+	var flight []byte
+	serverHello := []byte{gmVersion >> 8, gmVersion & 0xff}
+	serverHello = append(serverHello, serverRandom...)
+	serverHello = append(serverHello, 0, eccSM4SM3>>8, eccSM4SM3&0xff, 0)
+	flight = append(flight, s.handshakeMsg(typeServerHello, serverHello)...)
+
+	certs := append(uint24(len(signCert.Raw)), signCert.Raw...)
+	certs = append(certs, uint24(len(encCert.Raw))...)
+	certs = append(certs, encCert.Raw...)
+	flight = append(flight, s.handshakeMsg(typeCertificate, append(uint24(len(certs)), certs...))...)
+
+	// the signature covers both randoms and the encryption certificate
+	signed := append(append([]byte{}, clientRandom...), serverRandom...)
+	signed = append(signed, uint24(len(encCert.Raw))...)
+	signed = append(signed, encCert.Raw...)
+	r, ss, err := sm2.Sm2Sign(signKey, signed, defaultUID)
+	if err != nil {
+		t.Fatalf("failed to sign ServerKeyExchange: %s", err)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, ss})
+	if err != nil {
+		t.Fatalf("failed to encode signature: %s", err)
+	}
+	flight = append(flight, s.handshakeMsg(typeServerKeyExch, append(uint16Bytes(len(sig)), sig...))...)
+	flight = append(flight, s.handshakeMsg(typeServerHelloDone, nil)...)
+	s.writeRecord(recordHandshake, flight)
+
+	ckx := s.readHandshake(typeClientKeyExch)
+	body := ckx[4:]
+	if n := int(binary.BigEndian.Uint16(body)); n != len(body)-2 {
+		t.Fatalf("ClientKeyExchange ciphertext length %d does not match the message", n)
+	}
+	preMaster, err := sm2.DecryptAsn1(encKey, body[2:])
+	if err != nil {
+		t.Fatalf("failed to decrypt the pre-master secret with the encryption key: %s", err)
+	}
+	if len(preMaster) != 48 || binary.BigEndian.Uint16(preMaster) != gmVersion {
+		t.Fatalf("invalid pre-master secret %x", preMaster)
+	}
+
+	master := prfSM3(preMaster, "master secret", append(append([]byte{}, clientRandom...), serverRandom...), 48)
+	keyBlock := prfSM3(master, "key expansion", append(append([]byte{}, serverRandom...), clientRandom...), 2*(macLen+keyLen+ivLen))
+	s.clientMAC, keyBlock = keyBlock[:macLen], keyBlock[macLen:]
+	s.serverMAC, keyBlock = keyBlock[:macLen], keyBlock[macLen:]
+	s.clientKey, keyBlock = keyBlock[:keyLen], keyBlock[keyLen:]
+	s.serverKey = keyBlock[:keyLen]
+
+	if typ, ccs := s.readRecord(); typ != recordChangeCipher || !bytes.Equal(ccs, []byte{1}) {
+		t.Fatalf("expected ChangeCipherSpec but got record %d %x", typ, ccs)
+	}
+
+	expected := prfSM3(master, "client finished", sm3.Sm3Sum(s.transcript.Bytes()), verifyDataLen)
+	finished := s.readProtected(recordHandshake)
+	if !bytes.Equal(finished, append([]byte{typeFinished, 0, 0, verifyDataLen}, expected...)) {
+		t.Fatalf("unexpected client Finished %x", finished)
+	}
+	s.transcript.Write(finished)
+
+	s.writeRecord(recordChangeCipher, []byte{1})
+	verifyData := prfSM3(master, "server finished", sm3.Sm3Sum(s.transcript.Bytes()), verifyDataLen)
+	s.writeProtected(recordHandshake, append([]byte{typeFinished, 0, 0, verifyDataLen}, verifyData...))
+}
+
+func (s *gmServer) readRecord() (byte, []byte) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(s.conn, header); err != nil {
+		s.t.Fatalf("failed to read record header: %s", err)
+	}
+	if v := binary.BigEndian.Uint16(header[1:]); v != gmVersion {
+		s.t.Fatalf("expected record version %#x but got %#x", gmVersion, v)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(header[3:]))
+	if _, err := io.ReadFull(s.conn, body); err != nil {
+		s.t.Fatalf("failed to read record: %s", err)
+	}
+	return header[0], body
+}
+
+func (s *gmServer) writeRecord(typ byte, body []byte) {
+	record := append([]byte{typ, gmVersion >> 8, gmVersion & 0xff}, uint16Bytes(len(body))...)
+	if _, err := s.conn.Write(append(record, body...)); err != nil {
+		s.t.Fatalf("failed to write record: %s", err)
+	}
+}
+
+// readHandshake reads a plaintext record holding a single handshake message of the given type
+func (s *gmServer) readHandshake(msgType byte) []byte {
+	typ, msg := s.readRecord()
+	if typ != recordHandshake || len(msg) < 4 || msg[0] != msgType {
+		s.t.Fatalf("expected handshake message %d but got record %d %x", msgType, typ, msg)
+	}
+	if n := int(msg[1])<<16 | int(msg[2])<<8 | int(msg[3]); n != len(msg)-4 {
+		s.t.Fatalf("handshake message %d length %d does not match the record", msgType, n)
+	}
+	s.transcript.Write(msg)
+	return msg
+}
+
+func (s *gmServer) handshakeMsg(msgType byte, body []byte) []byte {
+	msg := append([]byte{msgType}, uint24(len(body))...)
+	msg = append(msg, body...)
+	s.transcript.Write(msg)
+	return msg
+}
+
+// readProtected reads a record protected with SM4-CBC, an explicit IV and HMAC-SM3
+func (s *gmServer) readProtected(expectedType byte) []byte {
+	typ, body := s.readRecord()
+	if typ != expectedType || len(body) < 2*sm4.BlockSize || len(body)%sm4.BlockSize != 0 {
+		s.t.Fatalf("expected protected record %d but got record %d of %d bytes", expectedType, typ, len(body))
+	}
+	block, err := sm4.NewCipher(s.clientKey)
+	if err != nil {
+		s.t.Fatalf("failed to create SM4 cipher: %s", err)
+	}
+	plaintext := make([]byte, len(body)-sm4.BlockSize)
+	cipher.NewCBCDecrypter(block, body[:sm4.BlockSize]).CryptBlocks(plaintext, body[sm4.BlockSize:])
+
+	padding := int(plaintext[len(plaintext)-1]) + 1
+	if padding+macLen > len(plaintext) {
+		s.t.Fatalf("invalid padding in protected record")
+	}
+	plaintext = plaintext[:len(plaintext)-padding]
+	data, mac := plaintext[:len(plaintext)-macLen], plaintext[len(plaintext)-macLen:]
+	if !hmac.Equal(mac, recordMAC(s.clientMAC, s.readSeq, typ, data)) {
+		s.t.Fatalf("invalid HMAC-SM3 in protected record")
+	}
+	s.readSeq++
+	return data
+}
+
+func (s *gmServer) writeProtected(typ byte, data []byte) {
+	plaintext := append(append([]byte{}, data...), recordMAC(s.serverMAC, s.writeSeq, typ, data)...)
+	s.writeSeq++
+	padding := sm4.BlockSize - len(plaintext)%sm4.BlockSize
+	plaintext = append(plaintext, bytes.Repeat([]byte{byte(padding - 1)}, padding)...)
+
+	iv := make([]byte, sm4.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		s.t.Fatalf("failed to generate IV: %s", err)
+	}
+	block, err := sm4.NewCipher(s.serverKey)
+	if err != nil {
+		s.t.Fatalf("failed to create SM4 cipher: %s", err)
+	}
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+	s.writeRecord(typ, append(iv, ciphertext...))
+}
+
+// recordMAC is HMAC-SM3(key, seq_num || type || version || length || data)
+func recordMAC(key []byte, seq uint64, typ byte, data []byte) []byte {
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint64(header, seq)
+	header[8] = typ
+	binary.BigEndian.PutUint16(header[9:], gmVersion)
+	binary.BigEndian.PutUint16(header[11:], uint16(len(data)))
+	mac := hmac.New(sm3.New, key)
+	mac.Write(header)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// prfSM3 is PRF(secret, label, seed) = P_SM3(secret, label + seed)
+func prfSM3(secret []byte, label string, seed []byte, length int) []byte {
+	labelAndSeed := append([]byte(label), seed...)
+	var result []byte
+	a := labelAndSeed
+	for len(result) < length {
+		mac := hmac.New(sm3.New, secret)
+		mac.Write(a)
+		a = mac.Sum(nil)
+
+		mac = hmac.New(sm3.New, secret)
+		mac.Write(a)
+		mac.Write(labelAndSeed)
+		result = append(result, mac.Sum(nil)...)
+	}
+	return result[:length]
+}
+
+// offersSuite parses the session ID and cipher suites of the rest of a ClientHello
+func offersSuite(t *testing.T, rest []byte, suite uint16) bool {
+	rest = rest[1+int(rest[0]):]
+	n := int(binary.BigEndian.Uint16(rest))
+	if n%2 != 0 || n+2 > len(rest) {
+		t.Fatalf("invalid cipher suites length %d", n)
+	}
+	for i := 2; i < n+2; i += 2 {
+		if binary.BigEndian.Uint16(rest[i:]) == suite {
+			return true
+		}
+	}
+	return false
+}
+
+func uint16Bytes(n int) []byte {
+	return []byte{byte(n >> 8), byte(n)}
+}
+
+func uint24(n int) []byte {
+	return []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+}
+
+// gmServerCerts generates an SM2 CA along with the signing and encryption key pairs of a server
+func gmServerCerts(t *testing.T, host string) (*sm2.Certificate, *sm2.PrivateKey, *sm2.Certificate, *sm2.PrivateKey, *sm2.Certificate) {
+	caKey, err := sm2.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %s", err)
+	}
+	caTemplate := &sm2.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              sm2.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SignatureAlgorithm:    sm2.SM2WithSM3,
+	}
+	caDER, err := sm2.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %s", err)
+	}
+	ca, err := sm2.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %s", err)
+	}
+
+	newCert := func(serial int64, usage sm2.KeyUsage) (*sm2.PrivateKey, *sm2.Certificate) {
+		key, err := sm2.GenerateKey()
+		if err != nil {
+			t.Fatalf("failed to generate key: %s", err)
+		}
+		template := &sm2.Certificate{
+			SerialNumber:       big.NewInt(serial),
+			Subject:            pkix.Name{CommonName: host},
+			DNSNames:           []string{host},
+			NotBefore:          time.Now().Add(-time.Hour),
+			NotAfter:           time.Now().Add(time.Hour),
+			KeyUsage:           usage,
+			ExtKeyUsage:        []sm2.ExtKeyUsage{sm2.ExtKeyUsageServerAuth},
+			SignatureAlgorithm: sm2.SM2WithSM3,
+		}
+		der, err := sm2.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("failed to create certificate: %s", err)
+		}
+		cert, err := sm2.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("failed to parse certificate: %s", err)
+		}
+		return key, cert
+	}
+	signKey, signCert := newCert(2, sm2.KeyUsageDigitalSignature)
+	encKey, encCert := newCert(3, sm2.KeyUsageKeyEncipherment|sm2.KeyUsageDataEncipherment)
+	return ca, signKey, signCert, encKey, encCert
+}
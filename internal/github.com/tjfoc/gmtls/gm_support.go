@@ -0,0 +1,252 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gmtls
+
+import (
+	"crypto"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"errors"
+	"io"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm4"
+)
+
+// GM/T 0024 cipher suites. Only the ECC (SM2 key transport) suite is
+// implemented.
+const (
+	GMTLS_SM2_WITH_SM4_SM3 uint16 = 0xe013
+)
+
+// defaultSM2UID is the signer identity used when computing ZA for the
+// handshake signatures, as mandated by GM/T 0009.
+var defaultSM2UID = []byte("1234567812345678")
+
+// GMSupport enables the GM/T 0024 handshake on a Config.
+type GMSupport struct{}
+
+// NewGMSupport returns a GMSupport that can be set on Config.GMSupport.
+func NewGMSupport() *GMSupport {
+	return &GMSupport{}
+}
+
+// GetVersion returns the protocol version negotiated in GM mode.
+func (support *GMSupport) GetVersion() uint16 {
+	return VersionGMSSL
+}
+
+// IsAvailable reports whether GM mode is usable.
+func (support *GMSupport) IsAvailable() bool {
+	return true
+}
+
+// cipherSuites returns the cipher suites offered in GM mode.
+func (support *GMSupport) cipherSuites() []uint16 {
+	return []uint16{GMTLS_SM2_WITH_SM4_SM3}
+}
+
+var gmCipherSuites = []*cipherSuite{
+	{GMTLS_SM2_WITH_SM4_SM3, 16, 32, 16, eccGMKA, suiteECDSA, cipherSM4, macSM3, nil},
+}
+
+// getGMCipherSuite returns the GM cipher suite with the given id, or nil.
+func getGMCipherSuite(id uint16) *cipherSuite {
+	for _, suite := range gmCipherSuites {
+		if suite.id == id {
+			return suite
+		}
+	}
+	return nil
+}
+
+func cipherSM4(key, iv []byte, isRead bool) interface{} {
+	block, _ := sm4.NewCipher(key)
+	if isRead {
+		return cipher.NewCBCDecrypter(block, iv)
+	}
+	return cipher.NewCBCEncrypter(block, iv)
+}
+
+// macSM3 returns an HMAC-SM3 macFunction.
+func macSM3(version uint16, key []byte) macFunction {
+	return tls10MAC{hmac.New(sm3.New, key)}
+}
+
+func eccGMKA(version uint16) keyAgreement {
+	return &eccKeyAgreementGM{version: version}
+}
+
+// eccKeyAgreementGM implements the ECC key exchange of GM/T 0024: the client
+// encrypts the pre-master secret to the server's encryption certificate and
+// the server proves possession of its signing key by signing both randoms
+// together with the encryption certificate.
+type eccKeyAgreementGM struct {
+	version uint16
+	// encCertDER is the server's encryption certificate as sent in the
+	// Certificate message; it is covered by the ServerKeyExchange signature.
+	encCertDER []byte
+	// encPrivKey is the private key of the encryption certificate, only set
+	// on the server side.
+	encPrivKey crypto.PrivateKey
+}
+
+func (ka *eccKeyAgreementGM) signedParams(clientHello *clientHelloMsg, hello *serverHelloMsg) []byte {
+	n := len(ka.encCertDER)
+	msg := make([]byte, 0, len(clientHello.random)+len(hello.random)+3+n)
+	msg = append(msg, clientHello.random...)
+	msg = append(msg, hello.random...)
+	msg = append(msg, byte(n>>16), byte(n>>8), byte(n))
+	return append(msg, ka.encCertDER...)
+}
+
+func (ka *eccKeyAgreementGM) generateServerKeyExchange(config *Config, cert *Certificate, clientHello *clientHelloMsg, hello *serverHelloMsg) (*serverKeyExchangeMsg, error) {
+	sig, err := signGM(config.rand(), cert.PrivateKey, ka.signedParams(clientHello, hello))
+	if err != nil {
+		return nil, errors.New("tls: failed to sign ECC parameters: " + err.Error())
+	}
+
+	skx := new(serverKeyExchangeMsg)
+	skx.key = make([]byte, 2+len(sig))
+	skx.key[0] = byte(len(sig) >> 8)
+	skx.key[1] = byte(len(sig))
+	copy(skx.key[2:], sig)
+	return skx, nil
+}
+
+func (ka *eccKeyAgreementGM) processClientKeyExchange(config *Config, cert *Certificate, ckx *clientKeyExchangeMsg, version uint16) ([]byte, error) {
+	if len(ckx.ciphertext) < 2 {
+		return nil, errClientKeyExchange
+	}
+	ciphertextLen := int(ckx.ciphertext[0])<<8 | int(ckx.ciphertext[1])
+	if ciphertextLen != len(ckx.ciphertext)-2 {
+		return nil, errClientKeyExchange
+	}
+	ciphertext := ckx.ciphertext[2:]
+
+	var preMasterSecret []byte
+	var err error
+	switch priv := ka.encPrivKey.(type) {
+	case *sm2.PrivateKey:
+		preMasterSecret, err = sm2.DecryptAsn1(priv, ciphertext)
+	case crypto.Decrypter:
+		preMasterSecret, err = priv.Decrypt(config.rand(), ciphertext, nil)
+	default:
+		return nil, errors.New("tls: encryption certificate private key does not support SM2 decryption")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(preMasterSecret) != 48 {
+		return nil, errClientKeyExchange
+	}
+	return preMasterSecret, nil
+}
+
+// processServerKeyExchange verifies skx against the server's signing
+// certificate.
+func (ka *eccKeyAgreementGM) processServerKeyExchange(config *Config, clientHello *clientHelloMsg, serverHello *serverHelloMsg, cert *sm2.Certificate, skx *serverKeyExchangeMsg) error {
+	if len(skx.key) < 2 {
+		return errServerKeyExchange
+	}
+	sigLen := int(skx.key[0])<<8 | int(skx.key[1])
+	if sigLen != len(skx.key)-2 {
+		return errServerKeyExchange
+	}
+	return verifyGM(cert.PublicKey, ka.signedParams(clientHello, serverHello), skx.key[2:])
+}
+
+// generateClientKeyExchange encrypts a fresh pre-master secret to the server's
+// encryption certificate.
+func (ka *eccKeyAgreementGM) generateClientKeyExchange(config *Config, clientHello *clientHelloMsg, cert *sm2.Certificate) ([]byte, *clientKeyExchangeMsg, error) {
+	pub, ok := sm2PublicKey(cert.PublicKey)
+	if !ok {
+		return nil, nil, errors.New("tls: encryption certificate does not contain an SM2 public key")
+	}
+
+	preMasterSecret := make([]byte, 48)
+	preMasterSecret[0] = byte(VersionGMSSL >> 8)
+	preMasterSecret[1] = byte(VersionGMSSL & 0xff)
+	if _, err := io.ReadFull(config.rand(), preMasterSecret[2:]); err != nil {
+		return nil, nil, err
+	}
+
+	encrypted, err := sm2.EncryptAsn1(pub, preMasterSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	ckx := new(clientKeyExchangeMsg)
+	ckx.ciphertext = make([]byte, len(encrypted)+2)
+	ckx.ciphertext[0] = byte(len(encrypted) >> 8)
+	ckx.ciphertext[1] = byte(len(encrypted))
+	copy(ckx.ciphertext[2:], encrypted)
+	return preMasterSecret, ckx, nil
+}
+
+// sm2PublicKey returns pub as an SM2 public key if it is one.
+func sm2PublicKey(pub interface{}) (*sm2.PublicKey, bool) {
+	switch key := pub.(type) {
+	case *sm2.PublicKey:
+		return key, true
+	case *ecdsa.PublicKey:
+		if key.Curve == sm2.P256Sm2() {
+			return &sm2.PublicKey{Curve: key.Curve, X: key.X, Y: key.Y}, true
+		}
+	}
+	return nil, false
+}
+
+// signGM produces an SM2 signature over msg with the default user ID. The
+// digest SM3(ZA || msg) is computed here so that any crypto.Signer backed by
+// an SM2 key, including hardware modules, can be used.
+func signGM(rand io.Reader, key crypto.PrivateKey, msg []byte) ([]byte, error) {
+	if priv, ok := key.(*sm2.PrivateKey); ok {
+		r, s, err := sm2.Sm2Sign(priv, msg, defaultSM2UID)
+		if err != nil {
+			return nil, err
+		}
+		return sm2.SignDigitToSignData(r, s)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("tls: certificate private key does not implement crypto.Signer")
+	}
+	pub, ok := sm2PublicKey(signer.Public())
+	if !ok {
+		return nil, errors.New("tls: certificate private key is not an SM2 key")
+	}
+	za, err := sm2.ZA(pub, defaultSM2UID)
+	if err != nil {
+		return nil, err
+	}
+	h := sm3.New()
+	h.Write(za)
+	h.Write(msg)
+	return signer.Sign(rand, h.Sum(nil), nil)
+}
+
+// verifyGM checks an ASN.1 encoded SM2 signature over msg.
+func verifyGM(pub interface{}, msg, sig []byte) error {
+	key, ok := sm2PublicKey(pub)
+	if !ok {
+		return errors.New("tls: certificate does not contain an SM2 public key")
+	}
+	r, s, err := sm2.SignDataToSignDigit(sig)
+	if err != nil {
+		return err
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return errors.New("tls: SM2 signature contained zero or negative values")
+	}
+	if !sm2.Sm2Verify(key, msg, defaultSM2UID, r, s) {
+		return errors.New("tls: SM2 verification failure")
+	}
+	return nil
+}
@@ -7,9 +7,9 @@ import (
 	"net"
 	"testing"
 
-	"github.com/tjfoc/gmsm/sm2"
-	"github.com/tjfoc/gmtls"
-	"github.com/tjfoc/gmtls/gmcredentials/echo"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls/gmcredentials/echo"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
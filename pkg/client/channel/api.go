@@ -29,6 +29,7 @@ type requestOptions struct {
 	Timeouts      map[fab.TimeoutType]time.Duration //timeout options for channel client operations
 	ParentContext reqContext.Context                //parent grpc context for channel client operations (query, execute, invokehandler)
 	CCFilter      invoke.CCFilter
+	CommitRetry   *invoke.CommitRetryOpts
 }
 
 // RequestOption func for each Opts argument
@@ -156,6 +157,24 @@ func WithParentContext(parentContext reqContext.Context) RequestOption {
 	}
 }
 
+// WithCommitRetry option to execute again, with a new transaction ID, the transactions invalidated
+// at commit with a retryable validation class. The re-executions are bounded by the execute timeout.
+// It cannot be combined with WithRetry, whose retryable codes may include the same validation codes,
+// as each retry would then execute the transaction again up to the commit retry attempts. Commit
+// retries only apply to Execute: Submit, SubmitBatch and SubmitSignedTransaction reject them.
+func WithCommitRetry(commitRetryOpt invoke.CommitRetryOpts) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if commitRetryOpt.Attempts < 1 {
+			return errors.New("commit retry attempts must be at least 1")
+		}
+		if commitRetryOpt.BackoffFactor < 1 {
+			return errors.New("commit retry backoff factor must be at least 1")
+		}
+		o.CommitRetry = &commitRetryOpt
+		return nil
+	}
+}
+
 //WithChaincodeFilter adds a chaincode filter for figuring out additional endorsers
 func WithChaincodeFilter(ccFilter invoke.CCFilter) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
@@ -0,0 +1,323 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultEndorsementConcurrency = 10
+	defaultBroadcastConcurrency   = 2
+	defaultCommitConcurrency      = 100
+)
+
+// BatchResult is the result of a request submitted in a batch
+type BatchResult struct {
+	Response Response
+	Err      error
+}
+
+type batchOptions struct {
+	endorsementConcurrency int
+	broadcastConcurrency   int
+	commitConcurrency      int
+	requestOptions         []RequestOption
+}
+
+// BatchOption func for each batchOptions argument
+type BatchOption func(opts *batchOptions) error
+
+// WithEndorsementConcurrency sets the number of requests endorsed concurrently
+func WithEndorsementConcurrency(n int) BatchOption {
+	return func(opts *batchOptions) error {
+		if n < 1 {
+			return errors.New("endorsement concurrency must be at least 1")
+		}
+		opts.endorsementConcurrency = n
+		return nil
+	}
+}
+
+// WithBroadcastConcurrency sets the number of transactions sent concurrently to the orderer
+func WithBroadcastConcurrency(n int) BatchOption {
+	return func(opts *batchOptions) error {
+		if n < 1 {
+			return errors.New("broadcast concurrency must be at least 1")
+		}
+		opts.broadcastConcurrency = n
+		return nil
+	}
+}
+
+// WithCommitConcurrency sets the maximum number of transactions awaiting their commit.
+// Once it is reached, no transaction is sent until a pending one is committed.
+func WithCommitConcurrency(n int) BatchOption {
+	return func(opts *batchOptions) error {
+		if n < 1 {
+			return errors.New("commit concurrency must be at least 1")
+		}
+		opts.commitConcurrency = n
+		return nil
+	}
+}
+
+// WithBatchRequestOptions sets the request options applied to each request of the batch
+func WithBatchRequestOptions(options ...RequestOption) BatchOption {
+	return func(opts *batchOptions) error {
+		opts.requestOptions = append(opts.requestOptions, options...)
+		return nil
+	}
+}
+
+// broadcasterProvider is implemented by transactors able to send transactions on reused broadcast streams
+type broadcasterProvider interface {
+	NewBroadcaster() (*txn.Broadcaster, error)
+}
+
+// batchItem is a request of the batch once endorsed
+type batchItem struct {
+	index    int
+	response invoke.Response
+}
+
+// SubmitBatch executes the requests, pipelining their endorsement, their broadcast to the orderer
+// and their commit. The stages are run by worker pools, whose size is set by the batch options,
+// and a stage blocks until the next one accepts more work. The requests are endorsed concurrently,
+// the broadcast streams to the orderers are reused and the commit of the submitted transactions
+// is tracked with one event registration for the channel (see Submit).
+//  Parameters:
+//  requests holds the requests to execute
+//  options holds optional batch options
+//
+//  Returns:
+//  the result of each request, in the order of the requests
+func (cc *Client) SubmitBatch(requests []Request, options ...BatchOption) ([]BatchResult, error) {
+	opts := batchOptions{
+		endorsementConcurrency: defaultEndorsementConcurrency,
+		broadcastConcurrency:   defaultBroadcastConcurrency,
+		commitConcurrency:      defaultCommitConcurrency,
+	}
+	for _, option := range options {
+		if err := option(&opts); err != nil {
+			return nil, errors.WithMessage(err, "Failed to read batch opts")
+		}
+	}
+
+	requestOptions := append([]RequestOption{}, opts.requestOptions...)
+	requestOptions = append(requestOptions, addDefaultTimeout(fab.Execute))
+	requestOptions = append(requestOptions, addDefaultTargetFilter(cc.context, filter.EndorsingPeer))
+	requestOptions = append(requestOptions, rejectCommitRetry("SubmitBatch"))
+
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, requestOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, closeSender, err := cc.newBatchSender(txnOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSender()
+
+	b := &batch{
+		cc:             cc,
+		requests:       requests,
+		requestOptions: requestOptions,
+		commitTimeout:  txnOpts.Timeouts[fab.Execute],
+		sender:         sender,
+		results:        make([]BatchResult, len(requests)),
+		commitSlots:    make(chan struct{}, opts.commitConcurrency),
+	}
+	b.run(opts)
+
+	return b.results, nil
+}
+
+// newBatchSender returns the sender of the transactions of a batch, which reuses the broadcast
+// streams if the transactor supports it
+func (cc *Client) newBatchSender(txnOpts requestOptions) (fab.Sender, func(), error) {
+	reqCtx, cancel := cc.createReqContext(&txnOpts)
+	defer cancel()
+
+	transactor, err := cc.context.ChannelService().Transactor(reqCtx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to create transactor")
+	}
+
+	provider, ok := transactor.(broadcasterProvider)
+	if !ok {
+		return &requestSender{cc: cc, txnOpts: txnOpts}, func() {}, nil
+	}
+
+	broadcaster, err := provider.NewBroadcaster()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to create broadcaster")
+	}
+	return broadcaster, broadcaster.Close, nil
+}
+
+// requestSender sends each transaction with a transactor created for the request
+type requestSender struct {
+	cc      *Client
+	txnOpts requestOptions
+}
+
+func (s *requestSender) transactor() (fab.Transactor, func(), error) {
+	txnOpts := s.txnOpts
+	reqCtx, cancel := s.cc.createReqContext(&txnOpts)
+
+	transactor, err := s.cc.context.ChannelService().Transactor(reqCtx)
+	if err != nil {
+		cancel()
+		return nil, nil, errors.WithMessage(err, "failed to create transactor")
+	}
+	return transactor, cancel, nil
+}
+
+// CreateTransaction create a transaction with proposal response.
+func (s *requestSender) CreateTransaction(request fab.TransactionRequest) (*fab.Transaction, error) {
+	transactor, cancel, err := s.transactor()
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	return transactor.CreateTransaction(request)
+}
+
+// SendTransaction send a transaction to the chain’s orderer service
+func (s *requestSender) SendTransaction(tx *fab.Transaction) (*fab.TransactionResponse, error) {
+	transactor, cancel, err := s.transactor()
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	return transactor.SendTransaction(tx)
+}
+
+// batch holds the state of a batch submission
+type batch struct {
+	cc             *Client
+	requests       []Request
+	requestOptions []RequestOption
+	commitTimeout  time.Duration
+	sender         fab.Sender
+	results        []BatchResult
+	// commitSlots bounds the number of transactions awaiting their commit
+	commitSlots chan struct{}
+	// done is released once the result of each request is known
+	done sync.WaitGroup
+}
+
+func (b *batch) run(opts batchOptions) {
+	b.done.Add(len(b.requests))
+
+	endorseCh := make(chan int, opts.endorsementConcurrency)
+	broadcastCh := make(chan batchItem, opts.broadcastConcurrency)
+
+	go func() {
+		for i := range b.requests {
+			endorseCh <- i
+		}
+		close(endorseCh)
+	}()
+
+	var endorsers sync.WaitGroup
+	endorsers.Add(opts.endorsementConcurrency)
+	for i := 0; i < opts.endorsementConcurrency; i++ {
+		go func() {
+			defer endorsers.Done()
+			for index := range endorseCh {
+				b.endorse(index, broadcastCh)
+			}
+		}()
+	}
+	go func() {
+		endorsers.Wait()
+		close(broadcastCh)
+	}()
+
+	var broadcasters sync.WaitGroup
+	broadcasters.Add(opts.broadcastConcurrency)
+	for i := 0; i < opts.broadcastConcurrency; i++ {
+		go func() {
+			defer broadcasters.Done()
+			for item := range broadcastCh {
+				b.submit(item)
+			}
+		}()
+	}
+
+	broadcasters.Wait()
+	b.done.Wait()
+}
+
+func (b *batch) endorse(index int, broadcastCh chan<- batchItem) {
+	handler := invoke.NewSelectAndEndorseHandler(
+		invoke.NewEndorsementValidationHandler(
+			invoke.NewSignatureValidationHandler(),
+		),
+	)
+
+	response, err := b.cc.InvokeHandler(handler, b.requests[index], b.requestOptions...)
+	if err != nil {
+		b.results[index] = BatchResult{Response: response, Err: err}
+		b.done.Done()
+		return
+	}
+
+	broadcastCh <- batchItem{index: index, response: invoke.Response(response)}
+}
+
+// submit sends the endorsed transaction once a commit slot is available, the slot is
+// released once the transaction is committed
+func (b *batch) submit(item batchItem) {
+	b.commitSlots <- struct{}{}
+
+	var once sync.Once
+	finish := func(result BatchResult) {
+		once.Do(func() {
+			b.results[item.index] = result
+			<-b.commitSlots
+			b.done.Done()
+		})
+	}
+
+	tx, err := b.sender.CreateTransaction(fab.TransactionRequest{
+		Proposal:          item.response.Proposal,
+		ProposalResponses: item.response.Responses,
+	})
+	if err != nil {
+		finish(BatchResult{Response: Response(item.response), Err: errors.WithMessage(err, "CreateTransaction failed")})
+		return
+	}
+
+	txnID := item.response.TransactionID
+	err = b.cc.commitTracker.Track(txnID, b.commitTimeout, func(txStatus *fab.TxStatusEvent, err error) {
+		response := Response(item.response)
+		response.TxValidationCode, err = txStatusResult(txStatus, err)
+		finish(BatchResult{Response: response, Err: err})
+	})
+	if err != nil {
+		finish(BatchResult{Response: Response(item.response), Err: errors.WithMessage(err, "error tracking transaction commit")})
+		return
+	}
+
+	if _, err := b.sender.SendTransaction(tx); err != nil {
+		b.cc.commitTracker.Untrack(txnID)
+		finish(BatchResult{Response: Response(item.response), Err: errors.WithMessage(err, "SendTransaction failed")})
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/orderer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
+)
+
+var batchRequest = Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+// commitEnvelopes publishes a filtered block for each envelope broadcasted to the mock orderer,
+// the validation code of the n-th transaction is returned by code
+func commitEnvelopes(t testing.TB, envelopes <-chan *fab.SignedEnvelope, eventService *fcmocks.MockEventService,
+	code func(n int) pb.TxValidationCode, done <-chan struct{}) {
+
+	for n := 0; ; n++ {
+		select {
+		case envelope := <-envelopes:
+			payload := &common.Payload{}
+			if !assert.NoError(t, proto.Unmarshal(envelope.Payload, payload)) {
+				continue
+			}
+			chdr, err := protos_utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+			if !assert.NoError(t, err) {
+				continue
+			}
+
+			eventService.PublishFilteredBlock(&pb.FilteredBlock{
+				Number:               uint64(n),
+				FilteredTransactions: []*pb.FilteredTransaction{{Txid: chdr.TxId, TxValidationCode: code(n)}},
+			})
+		case <-done:
+			return
+		}
+	}
+}
+
+func TestSubmitBatch(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = []byte("value")
+	envelopes := make(chan *fab.SignedEnvelope, 10)
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{fcmocks.NewMockOrderer("", envelopes)}, t)
+	mockEventService := chClient.eventService.(*fcmocks.MockEventService)
+
+	done := make(chan struct{})
+	defer close(done)
+	go commitEnvelopes(t, envelopes, mockEventService, func(n int) pb.TxValidationCode {
+		if n == 1 {
+			return pb.TxValidationCode_MVCC_READ_CONFLICT
+		}
+		return pb.TxValidationCode_VALID
+	}, done)
+
+	requests := []Request{batchRequest, {}, batchRequest, batchRequest}
+	results, err := chClient.SubmitBatch(requests, WithEndorsementConcurrency(2), WithBroadcastConcurrency(1), WithCommitConcurrency(2))
+	require.NoError(t, err)
+	require.Len(t, results, len(requests), "expected a result for each request")
+
+	// the results are in the order of the requests
+	require.Error(t, results[1].Err)
+	assert.Contains(t, results[1].Err.Error(), "ChaincodeID and Fcn are required")
+
+	txIDs := make(map[fab.TransactionID]bool)
+	codes := make(map[pb.TxValidationCode]int)
+	for _, i := range []int{0, 2, 3} {
+		result := results[i]
+		assert.Equal(t, []byte("value"), result.Response.Payload)
+		assert.NotEmpty(t, result.Response.TransactionID)
+		txIDs[result.Response.TransactionID] = true
+		codes[result.Response.TxValidationCode]++
+
+		if result.Response.TxValidationCode == pb.TxValidationCode_VALID {
+			assert.NoError(t, result.Err)
+			continue
+		}
+		statusError, ok := status.FromError(result.Err)
+		assert.True(t, ok, "Expected status error got %+v", result.Err)
+		assert.Equal(t, status.EventServerStatus, statusError.Group)
+	}
+	assert.Len(t, txIDs, 3, "expected a transaction for each endorsed request")
+	assert.Equal(t, 2, codes[pb.TxValidationCode_VALID])
+	assert.Equal(t, 1, codes[pb.TxValidationCode_MVCC_READ_CONFLICT])
+}
+
+func TestSubmitBatchSendError(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	mockOrderer := fcmocks.NewMockOrderer("", nil)
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{mockOrderer}, t)
+	mockEventService := chClient.eventService.(*fcmocks.MockEventService)
+
+	testErr := status.New(status.OrdererServerStatus, int32(common.Status_SERVICE_UNAVAILABLE), "service unavailable", nil)
+	mockOrderer.EnqueueSendBroadcastError(testErr)
+	mockOrderer.EnqueueSendBroadcastError(testErr)
+
+	results, err := chClient.SubmitBatch([]Request{batchRequest, batchRequest})
+	require.NoError(t, err)
+	for _, result := range results {
+		require.Error(t, result.Err)
+		assert.Contains(t, result.Err.Error(), "SendTransaction failed")
+		statusError, ok := status.FromError(result.Err)
+		assert.True(t, ok, "Expected status error got %+v", result.Err)
+		assert.Equal(t, status.OrdererServerStatus, statusError.Group)
+	}
+
+	// the transactions which could not be sent are not tracked
+	deadline := time.Now().Add(5 * time.Second)
+	for active, _ := mockEventService.FilteredBlockRegistrations(); active > 0; active, _ = mockEventService.FilteredBlockRegistrations() {
+		if time.Now().After(deadline) {
+			t.Fatal("filtered block registration should have been released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubmitBatchTimeout(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	results, err := chClient.SubmitBatch([]Request{batchRequest}, WithBatchRequestOptions(WithTimeout(fab.Execute, 100*time.Millisecond)))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	statusError, ok := status.FromError(results[0].Err)
+	assert.True(t, ok, "Expected status error got %+v", results[0].Err)
+	assert.EqualValues(t, status.Timeout.ToInt32(), statusError.Code)
+}
+
+func TestSubmitBatchOptions(t *testing.T) {
+	chClient := setupChannelClient(nil, t)
+
+	results, err := chClient.SubmitBatch(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	for _, option := range []BatchOption{WithEndorsementConcurrency(0), WithBroadcastConcurrency(0), WithCommitConcurrency(-1)} {
+		_, err := chClient.SubmitBatch([]Request{batchRequest}, option)
+		assert.Error(t, err, "concurrency below 1 should be rejected")
+	}
+
+	opts := batchOptions{}
+	require.NoError(t, WithEndorsementConcurrency(4)(&opts))
+	require.NoError(t, WithBroadcastConcurrency(3)(&opts))
+	require.NoError(t, WithCommitConcurrency(2)(&opts))
+	require.NoError(t, WithBatchRequestOptions(WithTimeout(fab.Execute, time.Second))(&opts))
+	assert.Equal(t, 4, opts.endorsementConcurrency)
+	assert.Equal(t, 3, opts.broadcastConcurrency)
+	assert.Equal(t, 2, opts.commitConcurrency)
+	assert.Len(t, opts.requestOptions, 1)
+}
+
+// BenchmarkSubmitBatch submits batches of transactions to the mock endorser and broadcast servers,
+// sequentially then pipelined
+func BenchmarkSubmitBatch(b *testing.B) {
+	endorserServer := &fcmocks.MockEndorserServer{}
+	endorserAddr := endorserServer.Start("127.0.0.1:0")
+	defer endorserServer.Stop()
+
+	deliveries := make(chan *pb.FilteredBlock, 100)
+	broadcastServer := &fcmocks.MockBroadcastServer{FilteredDeliveries: deliveries}
+	ordererAddr := broadcastServer.Start("127.0.0.1:0")
+	defer broadcastServer.Stop()
+
+	endorser, err := peer.New(fcmocks.NewMockEndpointConfig(), peer.WithURL("grpc://"+endorserAddr), peer.WithInsecure())
+	require.NoError(b, err)
+	ord, err := orderer.New(fcmocks.NewMockEndpointConfig(), orderer.WithURL("grpc://"+ordererAddr), orderer.WithInsecure())
+	require.NoError(b, err)
+
+	chClient := setupChannelClientWithNodes([]fab.Peer{endorser}, []fab.Orderer{ord}, b)
+	mockEventService := chClient.eventService.(*fcmocks.MockEventService)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case fblock := <-deliveries:
+				mockEventService.PublishFilteredBlock(fblock)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	const batchSize = 100
+	requests := make([]Request, batchSize)
+	for i := range requests {
+		requests[i] = batchRequest
+	}
+
+	benchmarks := []struct {
+		name    string
+		options []BatchOption
+	}{
+		{"Sequential", []BatchOption{WithEndorsementConcurrency(1), WithBroadcastConcurrency(1), WithCommitConcurrency(1)}},
+		{"Pipelined", nil},
+	}
+	for _, bm := range benchmarks {
+		b.Run(fmt.Sprintf("%s-%d", bm.name, batchSize), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				results, err := chClient.SubmitBatch(requests, bm.options...)
+				if err != nil {
+					b.Fatalf("SubmitBatch failed: %s", err)
+				}
+				for _, result := range results {
+					if result.Err != nil {
+						b.Fatalf("transaction failed: %s", result.Err)
+					}
+				}
+			}
+		})
+	}
+}
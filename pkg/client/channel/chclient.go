@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/greylist"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
 	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
@@ -38,11 +39,12 @@ import (
 // An application that requires interaction with multiple channels should create a separate
 // instance of the channel client for each channel. Channel client supports non-admin functions only.
 type Client struct {
-	context      context.Channel
-	membership   fab.ChannelMembership
-	eventService fab.EventService
-	greylist     *greylist.Filter
-	metrics      *metrics.ClientMetrics
+	context       context.Channel
+	membership    fab.ChannelMembership
+	eventService  fab.EventService
+	commitTracker *invoke.CommitTracker
+	greylist      *greylist.Filter
+	metrics       *metrics.ClientMetrics
 }
 
 // ClientOption describes a functional parameter for the New constructor
@@ -113,6 +115,32 @@ func (cc *Client) Execute(request Request, options ...RequestOption) (Response,
 	return callExecute(cc, request, options...)
 }
 
+// Submit prepares and submits a transaction using request and optional request options.
+// Unlike Execute, it returns once the orderer has accepted the transaction. The commit of
+// the submitted transactions is tracked with one event registration for the channel, shared by
+// the channel clients of its event service.
+//  Parameters:
+//  request holds info about mandatory chaincode ID and function
+//  options holds optional request options, the execute timeout also bounds the commit of the transaction
+//
+//  Returns:
+//  the handle of the transaction, notified once the transaction is committed
+func (cc *Client) Submit(request Request, options ...RequestOption) (*TxHandle, error) {
+	options = append(options, addDefaultTimeout(fab.Execute))
+	options = append(options, addDefaultTargetFilter(cc.context, filter.EndorsingPeer))
+
+	options = append(options, rejectCommitRetry("Submit"))
+
+	handle := newTxHandle()
+	response, err := cc.InvokeHandler(invoke.NewSubmitHandler(cc.commitTracker, handle.complete), request, options...)
+	if err != nil {
+		return nil, err
+	}
+	handle.response = response
+
+	return handle, nil
+}
+
 // addDefaultTargetFilter adds default target filter if target filter is not specified
 func addDefaultTargetFilter(chCtx context.Channel, ft filter.EndpointType) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
@@ -259,6 +287,9 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 		Transactor:   transactor,
 		EventService: cc.eventService,
 	}
+	if sm2UIDs, ok := cc.context.IdentityConfig().(verifier.SM2UIDResolver); ok {
+		clientContext.SM2UIDs = sm2UIDs
+	}
 
 	requestContext := &invoke.RequestContext{
 		Request:         invoke.Request(request),
@@ -282,9 +313,23 @@ func (cc *Client) prepareOptsFromOptions(ctx context.Client, options ...RequestO
 			return txnOpts, errors.WithMessage(err, "Failed to read opts")
 		}
 	}
+	if txnOpts.CommitRetry != nil && txnOpts.Retry.Attempts > 0 {
+		return txnOpts, errors.New("commit retry cannot be combined with retry")
+	}
 	return txnOpts, nil
 }
 
+// rejectCommitRetry rejects the commit retry options for the operations not awaiting the
+// commit of the transactions, which cannot execute them again
+func rejectCommitRetry(operation string) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if o.CommitRetry != nil {
+			return errors.Errorf("commit retry is not supported by %s", operation)
+		}
+		return nil
+	}
+}
+
 // RegisterChaincodeEvent registers for chaincode events. Unregister must be called when the registration is no longer needed.
 //  Parameters:
 //  chaincodeID is the chaincode ID for which events are to be received
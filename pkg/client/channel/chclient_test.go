@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package channel
 
 import (
+	reqContext "context"
 	"fmt"
 	"strings"
 	"testing"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
@@ -383,6 +385,65 @@ func TestTransactionValidationError(t *testing.T) {
 	assert.EqualValues(t, validationCode, status.ToTransactionValidationCode(statusError.Code))
 }
 
+func TestExecuteCommitRetry(t *testing.T) {
+	mockEventService := fcmocks.NewMockEventService()
+	mockEventService.TxValidationCode = pb.TxValidationCode_MVCC_READ_CONFLICT
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = []byte("value")
+	peers := []fab.Peer{testPeer1}
+
+	chClient := setupChannelClient(peers, t)
+	chClient.eventService = mockEventService
+
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+	_, err := chClient.Execute(request, WithCommitRetry(invoke.CommitRetryOpts{}))
+	assert.Error(t, err, "commit retry attempts should be required")
+
+	commitRetry := invoke.DefaultCommitRetryOpts
+	commitRetry.InitialBackoff = time.Millisecond
+
+	// the retries would each execute the transaction again up to the commit retry attempts
+	_, err = chClient.Execute(request, WithRetry(retry.DefaultChannelOpts), WithCommitRetry(commitRetry))
+	assert.EqualError(t, err, "commit retry cannot be combined with retry")
+
+	// the commit of submitted transactions is not awaited
+	_, err = chClient.Submit(request, WithCommitRetry(commitRetry))
+	assert.EqualError(t, err, "Failed to read opts: commit retry is not supported by Submit")
+	_, err = chClient.SubmitBatch([]Request{request}, WithBatchRequestOptions(WithCommitRetry(commitRetry)))
+	assert.EqualError(t, err, "Failed to read opts: commit retry is not supported by SubmitBatch")
+	assert.Equal(t, 0, testPeer1.ProcessProposalCalls)
+
+	commitRetry.BeforeRetry = func(attempt int, request *invoke.Request, err error) error {
+		class, ok := status.TxValidationClassFromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, status.TxValidationConflict, class)
+
+		// the read conflict is resolved before the second retry
+		if attempt == 2 {
+			mockEventService.TxValidationCode = pb.TxValidationCode_VALID
+		}
+		request.Args = [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("2")}
+		return nil
+	}
+
+	response, err := chClient.Execute(request, WithCommitRetry(commitRetry))
+	require.NoError(t, err)
+	assert.Equal(t, pb.TxValidationCode_VALID, response.TxValidationCode)
+	assert.Equal(t, []byte("value"), response.Payload)
+	assert.Equal(t, 3, testPeer1.ProcessProposalCalls, "the transaction should be endorsed for each execution")
+	assert.Equal(t, "1", string(request.Args[3]), "the request of the caller should not be modified")
+
+	// the attempts are exhausted
+	mockEventService.TxValidationCode = pb.TxValidationCode_PHANTOM_READ_CONFLICT
+	commitRetry.BeforeRetry = nil
+	commitRetry.Attempts = 1
+	_, err = chClient.Execute(request, WithCommitRetry(commitRetry))
+	statusError, ok := status.FromError(err)
+	require.True(t, ok, "Expected status error got %+v", err)
+	assert.EqualValues(t, pb.TxValidationCode_PHANTOM_READ_CONFLICT, status.ToTransactionValidationCode(statusError.Code))
+	assert.Equal(t, 5, testPeer1.ProcessProposalCalls)
+}
+
 func TestTransactionTimeout(t *testing.T) {
 	mockEventService := fcmocks.NewMockEventService()
 	mockEventService.Timeout = true
@@ -401,6 +462,85 @@ func TestTransactionTimeout(t *testing.T) {
 	assert.EqualValues(t, statusError.Code, status.Timeout)
 }
 
+func TestSubmit(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = []byte("value")
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+	mockEventService := chClient.eventService.(*fcmocks.MockEventService)
+
+	_, err := chClient.Submit(Request{})
+	assert.Error(t, err, "Should have failed for empty invoke request")
+
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+	handle1, err := chClient.Submit(request)
+	require.NoError(t, err)
+	handle2, err := chClient.Submit(request)
+	require.NoError(t, err)
+	assert.NotEmpty(t, handle1.TransactionID())
+	assert.NotEqual(t, handle1.TransactionID(), handle2.TransactionID())
+	assert.Equal(t, []byte("value"), handle1.Response().Payload)
+
+	active, total := mockEventService.FilteredBlockRegistrations()
+	assert.Equal(t, 1, active, "transactions should share the registration")
+	assert.Equal(t, 1, total)
+
+	select {
+	case <-handle1.Done():
+		t.Fatal("transaction should not be committed yet")
+	default:
+	}
+
+	mockEventService.PublishFilteredBlock(&pb.FilteredBlock{
+		Number: 1,
+		FilteredTransactions: []*pb.FilteredTransaction{
+			{Txid: string(handle1.TransactionID()), TxValidationCode: pb.TxValidationCode_VALID},
+			{Txid: string(handle2.TransactionID()), TxValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT},
+		},
+	})
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := handle1.Wait(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, pb.TxValidationCode_VALID, response.TxValidationCode)
+	assert.Equal(t, handle1.TransactionID(), response.TransactionID)
+	assert.Equal(t, []byte("value"), response.Payload)
+
+	response, err = handle2.Wait(ctx)
+	assert.Error(t, err, "expected invalid transaction error")
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, response.TxValidationCode)
+	statusError, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error got %+v", err)
+	assert.Equal(t, status.EventServerStatus, statusError.Group)
+	assert.EqualValues(t, pb.TxValidationCode_MVCC_READ_CONFLICT, status.ToTransactionValidationCode(statusError.Code))
+}
+
+func TestSubmitTimeout(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+	handle, err := chClient.Submit(request)
+	require.NoError(t, err)
+
+	// the wait is bounded by the context
+	ctx, cancel := reqContext.WithCancel(reqContext.Background())
+	cancel()
+	_, err = handle.Wait(ctx)
+	statusError, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error got %+v", err)
+	assert.EqualValues(t, status.Timeout.ToInt32(), statusError.Code)
+
+	// the commit is bounded by the execute timeout
+	handle, err = chClient.Submit(request, WithTimeout(fab.Execute, 50*time.Millisecond))
+	require.NoError(t, err)
+	_, err = handle.Wait(reqContext.Background())
+	statusError, ok = status.FromError(err)
+	assert.True(t, ok, "Expected status error got %+v", err)
+	assert.EqualValues(t, status.Timeout.ToInt32(), statusError.Code)
+}
+
 func TestExecuteTxWithRetries(t *testing.T) {
 	testStatus := status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "test", nil)
 	testResp := []byte("test")
@@ -531,7 +671,7 @@ func setupTestContext() context.Client {
 	return ctx
 }
 
-func setupCustomTestContext(t *testing.T, selectionService fab.SelectionService, discoveryService fab.DiscoveryService, orderers []fab.Orderer) context.ClientProvider {
+func setupCustomTestContext(t testing.TB, selectionService fab.SelectionService, discoveryService fab.DiscoveryService, orderers []fab.Orderer) context.ClientProvider {
 	user := mspmocks.NewMockSigningIdentity("test", "test")
 	ctx := fcmocks.NewMockContext(user)
 
@@ -580,7 +720,7 @@ func setupChannelClientWithError(discErr error, selectionErr error, peers []fab.
 }
 
 func setupChannelClientWithNodes(peers []fab.Peer,
-	orderers []fab.Orderer, t *testing.T) *Client {
+	orderers []fab.Orderer, t testing.TB) *Client {
 
 	fabCtx := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil, peers...), txnmocks.NewMockDiscoveryService(nil), orderers)
 
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package channel
 
 import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/greylist"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -14,11 +15,12 @@ import (
 
 func newClient(channelContext context.Channel, membership fab.ChannelMembership, eventService fab.EventService, greylistProvider *greylist.Filter) Client {
 	channelClient := Client{
-		membership:   membership,
-		eventService: eventService,
-		greylist:     greylistProvider,
-		context:      channelContext,
-		metrics:      channelContext.GetMetrics(),
+		membership:    membership,
+		eventService:  eventService,
+		commitTracker: invoke.NewCommitTracker(eventService),
+		greylist:      greylistProvider,
+		context:       channelContext,
+		metrics:       channelContext.GetMetrics(),
 	}
 	return channelClient
 }
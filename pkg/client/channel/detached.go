@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+)
+
+// UnsignedProposal is a transaction proposal to be signed outside of the SDK by its creator
+type UnsignedProposal struct {
+	Request  Request
+	Proposal *fab.TransactionProposal
+	// Bytes are the bytes of the proposal to be signed
+	Bytes []byte
+}
+
+// UnsignedTransaction is an endorsed transaction to be signed outside of the SDK by its creator
+type UnsignedTransaction struct {
+	// Response is the endorsement response of the transaction
+	Response Response
+	// Bytes are the payload bytes of the transaction envelope to be signed
+	Bytes []byte
+}
+
+// envelopeSender is implemented by transactors able to send envelopes signed outside of the SDK
+type envelopeSender interface {
+	SendEnvelope(envelope *fab.SignedEnvelope) (*fab.TransactionResponse, error)
+}
+
+// CreateUnsignedProposal creates the proposal of the request on behalf of the creator, the proposal
+// is signed outside of the SDK then sent for endorsement with EndorseSignedProposal
+//  Parameters:
+//  request holds info about mandatory chaincode ID and function
+//  creator is the serialized identity of the creator of the transaction
+//
+//  Returns:
+//  the unsigned proposal, holding the bytes to be signed by the creator
+func (cc *Client) CreateUnsignedProposal(request Request, creator []byte) (*UnsignedProposal, error) {
+	if len(creator) == 0 {
+		return nil, errors.New("creator is required")
+	}
+
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context)
+	if err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancel := cc.createReqContext(&txnOpts)
+	defer cancel()
+
+	transactor, err := cc.context.ChannelService().Transactor(reqCtx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create transactor")
+	}
+
+	txh, err := transactor.CreateTransactionHeader(fab.WithCreator(creator))
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating transaction header failed")
+	}
+
+	proposal, err := txn.CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{
+		ChaincodeID:  request.ChaincodeID,
+		Fcn:          request.Fcn,
+		Args:         request.Args,
+		TransientMap: request.TransientMap,
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating transaction proposal failed")
+	}
+
+	proposalBytes, err := proto.Marshal(proposal.Proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal proposal failed")
+	}
+
+	return &UnsignedProposal{Request: request, Proposal: proposal, Bytes: proposalBytes}, nil
+}
+
+// EndorseSignedProposal sends the proposal signed by its creator to the endorsers selected for the request
+//  Parameters:
+//  proposal is the proposal created by CreateUnsignedProposal
+//  signature is the signature of the proposal bytes by the creator
+//  options holds optional request options
+//
+//  Returns:
+//  the proposal responses from peer(s)
+func (cc *Client) EndorseSignedProposal(proposal *UnsignedProposal, signature []byte, options ...RequestOption) (Response, error) {
+	if proposal == nil || proposal.Proposal == nil {
+		return Response{}, errors.New("proposal is required")
+	}
+	if len(signature) == 0 {
+		return Response{}, errors.New("signature is required")
+	}
+
+	options = append(options, addDefaultTimeout(fab.Execute))
+	options = append(options, addDefaultTargetFilter(cc.context, filter.EndorsingPeer))
+
+	handler := invoke.NewSelectAndEndorseSignedHandler(
+		&invoke.SignedProposal{
+			Proposal:      proposal.Proposal,
+			ProposalBytes: proposal.Bytes,
+			Signature:     signature,
+		},
+		invoke.NewEndorsementValidationHandler(
+			invoke.NewSignatureValidationHandler(),
+		),
+	)
+
+	return cc.InvokeHandler(handler, proposal.Request, options...)
+}
+
+// CreateUnsignedTransaction creates the transaction envelope of the endorsed proposal, the
+// envelope is signed outside of the SDK then submitted with SubmitSignedTransaction
+//  Parameters:
+//  response is the response returned by EndorseSignedProposal
+//
+//  Returns:
+//  the unsigned transaction, holding the bytes to be signed by the creator
+func (cc *Client) CreateUnsignedTransaction(response Response) (*UnsignedTransaction, error) {
+	tx, err := txn.New(fab.TransactionRequest{
+		Proposal:          response.Proposal,
+		ProposalResponses: response.Responses,
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "CreateTransaction failed")
+	}
+
+	payload, err := txn.CreateTransactionPayload(tx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating transaction payload failed")
+	}
+
+	payloadBytes, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal payload failed")
+	}
+
+	return &UnsignedTransaction{Response: response, Bytes: payloadBytes}, nil
+}
+
+// SubmitSignedTransaction sends the transaction signed by its creator to the orderer. As Submit,
+// it returns once the orderer has accepted the transaction.
+//  Parameters:
+//  tx is the transaction created by CreateUnsignedTransaction
+//  signature is the signature of the transaction bytes by the creator
+//  options holds optional request options, the execute timeout bounds the commit of the transaction
+//
+//  Returns:
+//  the handle of the transaction, notified once the transaction is committed
+func (cc *Client) SubmitSignedTransaction(tx *UnsignedTransaction, signature []byte, options ...RequestOption) (*TxHandle, error) {
+	if tx == nil || len(tx.Bytes) == 0 {
+		return nil, errors.New("transaction is required")
+	}
+	if len(signature) == 0 {
+		return nil, errors.New("signature is required")
+	}
+
+	options = append(options, addDefaultTimeout(fab.Execute))
+	options = append(options, rejectCommitRetry("SubmitSignedTransaction"))
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancel := cc.createReqContext(&txnOpts)
+	defer cancel()
+
+	transactor, err := cc.context.ChannelService().Transactor(reqCtx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create transactor")
+	}
+	sender, ok := transactor.(envelopeSender)
+	if !ok {
+		return nil, errors.New("transactor does not support sending signed envelopes")
+	}
+
+	handle := newTxHandle()
+	handle.response = tx.Response
+
+	txnID := tx.Response.TransactionID
+	if err := cc.commitTracker.Track(txnID, txnOpts.Timeouts[fab.Execute], handle.complete); err != nil {
+		return nil, errors.WithMessage(err, "error tracking transaction commit")
+	}
+
+	if _, err := sender.SendEnvelope(&fab.SignedEnvelope{Payload: tx.Bytes, Signature: signature}); err != nil {
+		cc.commitTracker.Untrack(txnID)
+		return nil, errors.WithMessage(err, "SendEnvelope failed")
+	}
+
+	return handle, nil
+}
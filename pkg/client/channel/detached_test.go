@@ -0,0 +1,118 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestDetachedSigning(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = []byte("value")
+	envelopes := make(chan *fab.SignedEnvelope, 1)
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{fcmocks.NewMockOrderer("", envelopes)}, t)
+	mockEventService := chClient.eventService.(*fcmocks.MockEventService)
+
+	creator, err := mspmocks.NewMockSigningIdentity("offline", "Org1MSP").Serialize()
+	require.NoError(t, err)
+
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	// 1. the unsigned proposal is created on behalf of the creator
+	_, err = chClient.CreateUnsignedProposal(request, nil)
+	assert.Error(t, err, "creator should be required")
+
+	proposal, err := chClient.CreateUnsignedProposal(request, creator)
+	require.NoError(t, err)
+	assert.NotEmpty(t, proposal.Proposal.TxnID)
+	expectedBytes, err := proto.Marshal(proposal.Proposal.Proposal)
+	require.NoError(t, err)
+	assert.Equal(t, expectedBytes, proposal.Bytes)
+
+	header, err := protos_utils.GetHeader(proposal.Proposal.Proposal.Header)
+	require.NoError(t, err)
+	signatureHeader, err := protos_utils.GetSignatureHeader(header.SignatureHeader)
+	require.NoError(t, err)
+	assert.Equal(t, creator, signatureHeader.Creator)
+
+	// 2. the externally signed proposal is endorsed
+	_, err = chClient.EndorseSignedProposal(proposal, nil)
+	assert.Error(t, err, "signature should be required")
+
+	response, err := chClient.EndorseSignedProposal(proposal, []byte("proposal signature"))
+	require.NoError(t, err)
+	assert.Equal(t, proposal.Proposal.TxnID, response.TransactionID)
+	assert.Equal(t, []byte("value"), response.Payload)
+	assert.Equal(t, 1, testPeer1.ProcessProposalCalls)
+
+	// 3. the unsigned transaction is created from the endorsements
+	tx, err := chClient.CreateUnsignedTransaction(response)
+	require.NoError(t, err)
+	payload := &common.Payload{}
+	require.NoError(t, proto.Unmarshal(tx.Bytes, payload))
+	chdr, err := protos_utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	assert.Equal(t, string(response.TransactionID), chdr.TxId)
+
+	// 4. the externally signed transaction is submitted
+	_, err = chClient.SubmitSignedTransaction(tx, nil)
+	assert.Error(t, err, "signature should be required")
+
+	handle, err := chClient.SubmitSignedTransaction(tx, []byte("transaction signature"))
+	require.NoError(t, err)
+
+	envelope := <-envelopes
+	assert.Equal(t, tx.Bytes, envelope.Payload)
+	assert.Equal(t, []byte("transaction signature"), envelope.Signature)
+
+	mockEventService.PublishFilteredBlock(&pb.FilteredBlock{
+		Number:               1,
+		FilteredTransactions: []*pb.FilteredTransaction{{Txid: chdr.TxId, TxValidationCode: pb.TxValidationCode_VALID}},
+	})
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 5*time.Second)
+	defer cancel()
+	committed, err := handle.Wait(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, pb.TxValidationCode_VALID, committed.TxValidationCode)
+	assert.Equal(t, response.TransactionID, committed.TransactionID)
+}
+
+func TestSubmitSignedTransactionSendError(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	mockOrderer := fcmocks.NewMockOrderer("", nil)
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{mockOrderer}, t)
+
+	creator, err := mspmocks.NewMockSigningIdentity("offline", "Org1MSP").Serialize()
+	require.NoError(t, err)
+	proposal, err := chClient.CreateUnsignedProposal(batchRequest, creator)
+	require.NoError(t, err)
+	response, err := chClient.EndorseSignedProposal(proposal, []byte("proposal signature"))
+	require.NoError(t, err)
+	tx, err := chClient.CreateUnsignedTransaction(response)
+	require.NoError(t, err)
+
+	mockOrderer.EnqueueSendBroadcastError(errors.New("broadcast failed"))
+	handle, err := chClient.SubmitSignedTransaction(tx, []byte("transaction signature"))
+	assert.Nil(t, handle)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broadcast failed")
+}
@@ -81,6 +81,26 @@ func ExampleClient_Execute() {
 	// Output: Chaincode transaction completed
 }
 
+func ExampleClient_Submit() {
+	c, err := New(mockChannelProvider("mychannel"))
+	if err != nil {
+		fmt.Println("failed to create client")
+	}
+
+	handle, err := c.Submit(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	// the commit of the transaction is awaited with handle.Wait or handle.Done
+	if handle != nil {
+		fmt.Println("Chaincode transaction submitted")
+	}
+
+	// Output: Chaincode transaction submitted
+}
+
 func ExampleClient_RegisterChaincodeEvent() {
 	c, err := New(mockChannelProvider("mychannel"))
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -32,6 +33,7 @@ type Opts struct {
 	Timeouts      map[fab.TimeoutType]time.Duration
 	ParentContext reqContext.Context //parent grpc context
 	CCFilter      CCFilter
+	CommitRetry   *CommitRetryOpts
 }
 
 // Request contains the parameters to execute transaction
@@ -75,6 +77,8 @@ type ClientContext struct {
 	Membership   fab.ChannelMembership
 	Transactor   fab.Transactor
 	EventService fab.EventService
+	// SM2UIDs resolves the SM2 user IDs of the endorsers (optional)
+	SM2UIDs verifier.SM2UIDResolver
 }
 
 //RequestContext contains request, opts, response parameters for handler execution
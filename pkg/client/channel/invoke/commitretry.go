@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+)
+
+// BeforeCommitRetryHandler is called before a transaction invalidated at commit is executed again,
+// with the number of the retry and the validation error of the previous execution. The request
+// may be updated for the next execution, returning an error stops the retries.
+type BeforeCommitRetryHandler func(attempt int, request *Request, err error) error
+
+// CommitRetryOpts defines the re-execution of transactions invalidated at commit
+type CommitRetryOpts struct {
+	// Attempts the maximum number of re-executions
+	Attempts int
+	// InitialBackoff the backoff before the first re-execution
+	InitialBackoff time.Duration
+	// MaxBackoff the maximum backoff between re-executions, no maximum if zero
+	MaxBackoff time.Duration
+	// BackoffFactor the factor by which the backoff increases between re-executions
+	BackoffFactor float64
+	// RetryableClasses the validation classes of the transactions to execute again,
+	// status.TxValidationConflict if empty
+	RetryableClasses []status.TxValidationClass
+	// BeforeRetry is called before each re-execution
+	BeforeRetry BeforeCommitRetryHandler
+}
+
+// DefaultCommitRetryOpts executes again the transactions invalidated by a read conflict
+var DefaultCommitRetryOpts = CommitRetryOpts{
+	Attempts:         retry.DefaultAttempts,
+	InitialBackoff:   retry.DefaultInitialBackoff,
+	MaxBackoff:       retry.DefaultMaxBackoff,
+	BackoffFactor:    retry.DefaultBackoffFactor,
+	RetryableClasses: []status.TxValidationClass{status.TxValidationConflict},
+}
+
+// retryable returns true if err reports a transaction invalidated with a retryable class
+func (o *CommitRetryOpts) retryable(err error) bool {
+	class, ok := status.TxValidationClassFromError(err)
+	if !ok {
+		return false
+	}
+	if len(o.RetryableClasses) == 0 {
+		return class == status.TxValidationConflict
+	}
+	for _, c := range o.RetryableClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the backoff before the given re-execution
+func (o *CommitRetryOpts) backoff(attempt int) time.Duration {
+	backoff := float64(o.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= o.BackoffFactor
+		if o.MaxBackoff > 0 && backoff >= float64(o.MaxBackoff) {
+			return o.MaxBackoff
+		}
+	}
+	return time.Duration(backoff)
+}
+
+//CommitRetryHandler executes the next handlers again, with a new transaction ID, while the transaction
+//is invalidated at commit with a retryable validation class
+type CommitRetryHandler struct {
+	next Handler
+}
+
+//Handle executes the next handlers as configured by the commit retry options of the request
+func (h *CommitRetryHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	opts := requestContext.Opts.CommitRetry
+	if opts == nil {
+		h.next.Handle(requestContext, clientContext)
+		return
+	}
+
+	targets := requestContext.Opts.Targets
+	for attempt := 1; ; attempt++ {
+		h.next.Handle(requestContext, clientContext)
+		if attempt > opts.Attempts || !opts.retryable(requestContext.Error) {
+			return
+		}
+
+		backoff := opts.backoff(attempt)
+		logger.Debugf("transaction [%s] invalidated at commit, retry %d in %s: %s",
+			requestContext.Response.TransactionID, attempt, backoff, requestContext.Error)
+
+		select {
+		case <-time.After(backoff):
+		case <-requestContext.Ctx.Done():
+			// the execute timeout elapsed, the validation error is returned
+			return
+		}
+
+		if opts.BeforeRetry != nil {
+			if err := opts.BeforeRetry(attempt, &requestContext.Request, requestContext.Error); err != nil {
+				requestContext.Error = errors.WithMessage(err, "before commit retry failed")
+				return
+			}
+		}
+
+		requestContext.Opts.Targets = targets
+		requestContext.Response = Response{}
+		requestContext.Error = nil
+	}
+}
+
+//NewCommitRetryHandler returns a handler that executes the next handlers again when the transaction is invalidated at commit
+func NewCommitRetryHandler(next ...Handler) *CommitRetryHandler {
+	return &CommitRetryHandler{next: getNext(next)}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// committingHandler mocks the execute handlers, the n-th execution commits with the n-th validation code
+type committingHandler struct {
+	codes    []pb.TxValidationCode
+	requests []Request
+	targets  [][]fab.Peer
+}
+
+func (h *committingHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	n := len(h.requests)
+	h.requests = append(h.requests, requestContext.Request)
+	h.targets = append(h.targets, requestContext.Opts.Targets)
+
+	// the endorsement selects its own targets
+	requestContext.Opts.Targets = append(requestContext.Opts.Targets, fcmocks.NewMockPeer("selected", ""))
+	requestContext.Response.TransactionID = fab.TransactionID(string(rune('a' + n)))
+
+	code := h.codes[n]
+	requestContext.Response.TxValidationCode = code
+	if code != pb.TxValidationCode_VALID {
+		requestContext.Error = status.New(status.EventServerStatus, int32(code), "received invalid transaction", nil)
+	}
+}
+
+func commitRetryOpts() *CommitRetryOpts {
+	opts := DefaultCommitRetryOpts
+	opts.InitialBackoff = time.Millisecond
+	return &opts
+}
+
+func TestCommitRetryHandler(t *testing.T) {
+	next := &committingHandler{codes: []pb.TxValidationCode{
+		pb.TxValidationCode_MVCC_READ_CONFLICT,
+		pb.TxValidationCode_PHANTOM_READ_CONFLICT,
+		pb.TxValidationCode_VALID,
+	}}
+	opts := commitRetryOpts()
+	var attempts []int
+	opts.BeforeRetry = func(attempt int, request *Request, err error) error {
+		attempts = append(attempts, attempt)
+		class, ok := status.TxValidationClassFromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, status.TxValidationConflict, class)
+		request.Args = [][]byte{{byte(attempt)}}
+		return nil
+	}
+
+	targets := []fab.Peer{fcmocks.NewMockPeer("p1", "")}
+	requestContext := prepareRequestContext(Request{ChaincodeID: "test", Fcn: "invoke"}, Opts{Targets: targets, CommitRetry: opts}, t)
+	NewCommitRetryHandler(next).Handle(requestContext, setupChannelClientContext(nil, nil, nil, t))
+
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, pb.TxValidationCode_VALID, requestContext.Response.TxValidationCode)
+	assert.Equal(t, fab.TransactionID("c"), requestContext.Response.TransactionID)
+	assert.Equal(t, []int{1, 2}, attempts)
+
+	require.Len(t, next.requests, 3)
+	assert.Nil(t, next.requests[0].Args)
+	assert.Equal(t, [][]byte{{1}}, next.requests[1].Args, "the request should be updated before the retry")
+	assert.Equal(t, [][]byte{{2}}, next.requests[2].Args, "the request should be updated before the retry")
+	for _, executionTargets := range next.targets {
+		assert.Equal(t, targets, executionTargets, "the targets should be reset before the retry")
+	}
+}
+
+func TestCommitRetryHandlerStops(t *testing.T) {
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+	request := Request{ChaincodeID: "test", Fcn: "invoke"}
+
+	// without commit retry options the transaction is executed once
+	next := &committingHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_MVCC_READ_CONFLICT}}
+	requestContext := prepareRequestContext(request, Opts{}, t)
+	NewCommitRetryHandler(next).Handle(requestContext, clientContext)
+	assert.Error(t, requestContext.Error)
+	assert.Len(t, next.requests, 1)
+
+	// the validation class is not retryable
+	next = &committingHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE}}
+	requestContext = prepareRequestContext(request, Opts{CommitRetry: commitRetryOpts()}, t)
+	NewCommitRetryHandler(next).Handle(requestContext, clientContext)
+	assert.Error(t, requestContext.Error)
+	assert.Len(t, next.requests, 1)
+
+	// the validation class is made retryable
+	next = &committingHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE, pb.TxValidationCode_VALID}}
+	opts := commitRetryOpts()
+	opts.RetryableClasses = []status.TxValidationClass{status.TxValidationConflict, status.TxValidationEndorsement}
+	requestContext = prepareRequestContext(request, Opts{CommitRetry: opts}, t)
+	NewCommitRetryHandler(next).Handle(requestContext, clientContext)
+	assert.NoError(t, requestContext.Error)
+	assert.Len(t, next.requests, 2)
+
+	// the attempts are exhausted
+	next = &committingHandler{codes: []pb.TxValidationCode{
+		pb.TxValidationCode_MVCC_READ_CONFLICT,
+		pb.TxValidationCode_MVCC_READ_CONFLICT,
+		pb.TxValidationCode_MVCC_READ_CONFLICT,
+	}}
+	opts = commitRetryOpts()
+	opts.Attempts = 2
+	requestContext = prepareRequestContext(request, Opts{CommitRetry: opts}, t)
+	NewCommitRetryHandler(next).Handle(requestContext, clientContext)
+	statusError, ok := status.FromError(requestContext.Error)
+	require.True(t, ok, "Expected status error got %+v", requestContext.Error)
+	assert.EqualValues(t, pb.TxValidationCode_MVCC_READ_CONFLICT, status.ToTransactionValidationCode(statusError.Code))
+	assert.Len(t, next.requests, 3)
+
+	// the hook stops the retries
+	next = &committingHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_VALID}}
+	opts = commitRetryOpts()
+	opts.BeforeRetry = func(attempt int, request *Request, err error) error {
+		return errors.New("conflict not resolved")
+	}
+	requestContext = prepareRequestContext(request, Opts{CommitRetry: opts}, t)
+	NewCommitRetryHandler(next).Handle(requestContext, clientContext)
+	require.Error(t, requestContext.Error)
+	assert.Contains(t, requestContext.Error.Error(), "conflict not resolved")
+	assert.Len(t, next.requests, 1)
+
+	// the request context is done during the backoff
+	next = &committingHandler{codes: []pb.TxValidationCode{pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_VALID}}
+	opts = commitRetryOpts()
+	opts.InitialBackoff = time.Minute
+	requestContext = prepareRequestContext(request, Opts{CommitRetry: opts}, t)
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 10*time.Millisecond)
+	defer cancel()
+	requestContext.Ctx = ctx
+	NewCommitRetryHandler(next).Handle(requestContext, clientContext)
+	assert.Error(t, requestContext.Error)
+	assert.Len(t, next.requests, 1)
+}
+
+func TestCommitRetryBackoff(t *testing.T) {
+	opts := CommitRetryOpts{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, BackoffFactor: 2}
+	assert.Equal(t, time.Second, opts.backoff(1))
+	assert.Equal(t, 2*time.Second, opts.backoff(2))
+	assert.Equal(t, 4*time.Second, opts.backoff(3))
+	assert.Equal(t, 5*time.Second, opts.backoff(4))
+
+	opts.MaxBackoff = 0
+	assert.Equal(t, 8*time.Second, opts.backoff(4))
+}
@@ -0,0 +1,182 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+// TxStatusCallback is notified of the status of a committed transaction,
+// or of the error preventing the status from being known
+type TxStatusCallback func(txStatus *fab.TxStatusEvent, err error)
+
+// CommitTracker notifies the status of submitted transactions once they are committed.
+// The commit trackers of an event service, i.e. of a channel, share one filtered block
+// registration, which is released once none of their transactions is pending.
+type CommitTracker struct {
+	eventService fab.EventService
+}
+
+// commitTrackers keeps the transactions pending for each event service
+var commitTrackers = struct {
+	sync.Mutex
+	byEventService map[fab.EventService]*trackedCommits
+}{byEventService: make(map[fab.EventService]*trackedCommits)}
+
+// trackedCommits are the transactions pending for an event service and their registration
+type trackedCommits struct {
+	reg     fab.Registration
+	pending map[string]*pendingTx
+}
+
+type pendingTx struct {
+	callback TxStatusCallback
+	timer    *time.Timer
+}
+
+// NewCommitTracker returns a commit tracker receiving the blocks from the event service
+func NewCommitTracker(eventService fab.EventService) *CommitTracker {
+	return &CommitTracker{eventService: eventService}
+}
+
+// Track notifies the callback of the status of the transaction, or of a timeout error if the
+// transaction is not committed within the timeout. The transaction must be tracked before it
+// is sent to the orderer.
+func (t *CommitTracker) Track(txID fab.TransactionID, timeout time.Duration, callback TxStatusCallback) error {
+	commitTrackers.Lock()
+	defer commitTrackers.Unlock()
+
+	commits, ok := commitTrackers.byEventService[t.eventService]
+	if !ok {
+		reg, eventch, err := t.eventService.RegisterFilteredBlockEvent()
+		if err != nil {
+			return errors.WithMessage(err, "error registering for filtered block events")
+		}
+		commits = &trackedCommits{reg: reg, pending: make(map[string]*pendingTx)}
+		commitTrackers.byEventService[t.eventService] = commits
+		go t.listen(commits, eventch)
+	}
+
+	if _, ok := commits.pending[string(txID)]; ok {
+		return errors.Errorf("transaction [%s] is already tracked", txID)
+	}
+
+	tx := &pendingTx{callback: callback}
+	tx.timer = time.AfterFunc(timeout, func() {
+		if t.remove(string(txID), tx) {
+			callback(nil, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+				"didn't receive block event for transaction", nil))
+		}
+	})
+	commits.pending[string(txID)] = tx
+
+	return nil
+}
+
+// Untrack stops tracking the transaction, e.g. if it could not be sent. The callback is not notified.
+func (t *CommitTracker) Untrack(txID fab.TransactionID) {
+	commitTrackers.Lock()
+	defer commitTrackers.Unlock()
+
+	if commits, ok := commitTrackers.byEventService[t.eventService]; ok {
+		if tx, ok := commits.pending[string(txID)]; ok {
+			tx.timer.Stop()
+			t.removeLocked(commits, string(txID))
+		}
+	}
+}
+
+func (t *CommitTracker) listen(commits *trackedCommits, eventch <-chan *fab.FilteredBlockEvent) {
+	for event := range eventch {
+		if event.FilteredBlock == nil {
+			continue
+		}
+		for _, ftx := range event.FilteredBlock.FilteredTransactions {
+			tx := t.complete(commits, ftx.Txid)
+			if tx == nil {
+				continue
+			}
+			tx.callback(&fab.TxStatusEvent{
+				TxID:             ftx.Txid,
+				TxValidationCode: ftx.TxValidationCode,
+				BlockNumber:      event.FilteredBlock.Number,
+				SourceURL:        event.SourceURL,
+			}, nil)
+		}
+	}
+
+	// the event channel is closed when the registration is released, or by the event service
+	// in which case the pending transactions fail as their status cannot be known anymore
+	for _, tx := range t.close(commits) {
+		tx.callback(nil, status.New(status.ClientStatus, status.ConnectionFailed.ToInt32(),
+			"event service closed the filtered block registration of the transaction", nil))
+	}
+}
+
+// close forgets the registration closed by the event service and returns its pending transactions
+func (t *CommitTracker) close(commits *trackedCommits) []*pendingTx {
+	commitTrackers.Lock()
+	defer commitTrackers.Unlock()
+
+	if commitTrackers.byEventService[t.eventService] != commits {
+		return nil
+	}
+	logger.Debugf("Filtered block registration of commit tracker has been closed by the event service")
+	delete(commitTrackers.byEventService, t.eventService)
+
+	var txs []*pendingTx
+	for txID, tx := range commits.pending {
+		tx.timer.Stop()
+		delete(commits.pending, txID)
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// complete removes the pending transaction, if any, once it is committed
+func (t *CommitTracker) complete(commits *trackedCommits, txID string) *pendingTx {
+	commitTrackers.Lock()
+	defer commitTrackers.Unlock()
+
+	tx, ok := commits.pending[txID]
+	if !ok {
+		return nil
+	}
+	tx.timer.Stop()
+	t.removeLocked(commits, txID)
+	return tx
+}
+
+// remove removes the pending transaction unless it has already been completed
+func (t *CommitTracker) remove(txID string, tx *pendingTx) bool {
+	commitTrackers.Lock()
+	defer commitTrackers.Unlock()
+
+	commits, ok := commitTrackers.byEventService[t.eventService]
+	if !ok || commits.pending[txID] != tx {
+		return false
+	}
+	t.removeLocked(commits, txID)
+	return true
+}
+
+// removeLocked removes the pending transaction, the registration is released once no transaction is pending
+func (t *CommitTracker) removeLocked(commits *trackedCommits, txID string) {
+	delete(commits.pending, txID)
+	if len(commits.pending) > 0 || commitTrackers.byEventService[t.eventService] != commits {
+		return
+	}
+
+	delete(commitTrackers.byEventService, t.eventService)
+	// events are drained by listen until the event channel is closed
+	go t.eventService.Unregister(commits.reg)
+}
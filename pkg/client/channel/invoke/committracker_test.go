@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+type txStatusResult struct {
+	txStatus *fab.TxStatusEvent
+	err      error
+}
+
+func newTxStatusCallback() (TxStatusCallback, chan txStatusResult) {
+	results := make(chan txStatusResult, 1)
+	return func(txStatus *fab.TxStatusEvent, err error) {
+		results <- txStatusResult{txStatus: txStatus, err: err}
+	}, results
+}
+
+func receiveTxStatus(t *testing.T, results chan txStatusResult) txStatusResult {
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(testTimeOut):
+		t.Fatal("timed out waiting for transaction status")
+		return txStatusResult{}
+	}
+}
+
+func filteredBlock(number uint64, txs map[string]pb.TxValidationCode) *pb.FilteredBlock {
+	fblock := &pb.FilteredBlock{Number: number}
+	for txID, code := range txs {
+		fblock.FilteredTransactions = append(fblock.FilteredTransactions, &pb.FilteredTransaction{Txid: txID, TxValidationCode: code})
+	}
+	return fblock
+}
+
+// waitForFilteredBlockRegistrations waits until the number of active registrations is reached,
+// as registrations are released asynchronously
+func waitForFilteredBlockRegistrations(t *testing.T, eventService *fcmocks.MockEventService, expected int) {
+	deadline := time.Now().Add(testTimeOut)
+	for {
+		active, _ := eventService.FilteredBlockRegistrations()
+		if active == expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d filtered block registrations, got %d", expected, active)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCommitTracker(t *testing.T) {
+	eventService := fcmocks.NewMockEventService()
+	tracker := NewCommitTracker(eventService)
+
+	callback1, results1 := newTxStatusCallback()
+	callback2, results2 := newTxStatusCallback()
+	require.NoError(t, tracker.Track("tx1", testTimeOut, callback1))
+	require.NoError(t, tracker.Track("tx2", testTimeOut, callback2))
+	assert.Error(t, tracker.Track("tx2", testTimeOut, callback2), "tracking a transaction twice should fail")
+
+	active, total := eventService.FilteredBlockRegistrations()
+	assert.Equal(t, 1, active, "transactions should share the registration")
+	assert.Equal(t, 1, total)
+
+	eventService.PublishFilteredBlock(filteredBlock(1, map[string]pb.TxValidationCode{
+		"tx1":   pb.TxValidationCode_VALID,
+		"other": pb.TxValidationCode_VALID,
+	}))
+	result := receiveTxStatus(t, results1)
+	require.NoError(t, result.err)
+	assert.Equal(t, "tx1", result.txStatus.TxID)
+	assert.Equal(t, pb.TxValidationCode_VALID, result.txStatus.TxValidationCode)
+	assert.EqualValues(t, 1, result.txStatus.BlockNumber)
+
+	eventService.PublishFilteredBlock(filteredBlock(2, map[string]pb.TxValidationCode{
+		"tx2": pb.TxValidationCode_MVCC_READ_CONFLICT,
+	}))
+	result = receiveTxStatus(t, results2)
+	require.NoError(t, result.err)
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, result.txStatus.TxValidationCode)
+
+	// the registration is released once no transaction is pending
+	waitForFilteredBlockRegistrations(t, eventService, 0)
+
+	require.NoError(t, tracker.Track("tx3", testTimeOut, callback1))
+	active, total = eventService.FilteredBlockRegistrations()
+	assert.Equal(t, 1, active)
+	assert.Equal(t, 2, total)
+
+	// untracked transactions are not notified
+	tracker.Untrack("tx3")
+	waitForFilteredBlockRegistrations(t, eventService, 0)
+	select {
+	case <-results1:
+		t.Fatal("untracked transaction should not be notified")
+	default:
+	}
+}
+
+func TestCommitTrackerSharedRegistration(t *testing.T) {
+	eventService := fcmocks.NewMockEventService()
+	tracker1 := NewCommitTracker(eventService)
+	tracker2 := NewCommitTracker(eventService)
+
+	callback1, results1 := newTxStatusCallback()
+	callback2, results2 := newTxStatusCallback()
+	require.NoError(t, tracker1.Track("tx1", testTimeOut, callback1))
+	require.NoError(t, tracker2.Track("tx2", testTimeOut, callback2))
+	assert.Error(t, tracker2.Track("tx1", testTimeOut, callback2), "tracking a transaction twice on the event service should fail")
+
+	active, total := eventService.FilteredBlockRegistrations()
+	assert.Equal(t, 1, active, "trackers of the event service should share the registration")
+	assert.Equal(t, 1, total)
+
+	// trackers of another event service register on their own
+	otherEventService := fcmocks.NewMockEventService()
+	require.NoError(t, NewCommitTracker(otherEventService).Track("tx1", testTimeOut, callback1))
+	active, _ = otherEventService.FilteredBlockRegistrations()
+	assert.Equal(t, 1, active)
+	otherEventService.PublishFilteredBlock(filteredBlock(1, map[string]pb.TxValidationCode{"tx1": pb.TxValidationCode_VALID}))
+	require.NoError(t, receiveTxStatus(t, results1).err)
+
+	eventService.PublishFilteredBlock(filteredBlock(1, map[string]pb.TxValidationCode{
+		"tx1": pb.TxValidationCode_VALID,
+		"tx2": pb.TxValidationCode_VALID,
+	}))
+	assert.Equal(t, "tx1", receiveTxStatus(t, results1).txStatus.TxID)
+	assert.Equal(t, "tx2", receiveTxStatus(t, results2).txStatus.TxID)
+
+	waitForFilteredBlockRegistrations(t, eventService, 0)
+	waitForFilteredBlockRegistrations(t, otherEventService, 0)
+}
+
+func TestCommitTrackerEventServiceClosed(t *testing.T) {
+	eventService := fcmocks.NewMockEventService()
+	tracker := NewCommitTracker(eventService)
+
+	callback, results := newTxStatusCallback()
+	require.NoError(t, tracker.Track("tx1", testTimeOut, callback))
+
+	// pending transactions fail without waiting for their timeout
+	eventService.CloseFilteredBlockRegistrations()
+	result := receiveTxStatus(t, results)
+	require.Error(t, result.err)
+	statusError, ok := status.FromError(result.err)
+	assert.True(t, ok, "Expected status error")
+	assert.EqualValues(t, status.ConnectionFailed.ToInt32(), statusError.Code)
+
+	// the next transaction registers again
+	require.NoError(t, tracker.Track("tx2", testTimeOut, callback))
+	active, total := eventService.FilteredBlockRegistrations()
+	assert.Equal(t, 1, active)
+	assert.Equal(t, 2, total)
+	tracker.Untrack("tx2")
+	waitForFilteredBlockRegistrations(t, eventService, 0)
+}
+
+func TestCommitTrackerTimeout(t *testing.T) {
+	eventService := fcmocks.NewMockEventService()
+	tracker := NewCommitTracker(eventService)
+
+	callback, results := newTxStatusCallback()
+	require.NoError(t, tracker.Track("tx1", 10*time.Millisecond, callback))
+
+	result := receiveTxStatus(t, results)
+	require.Error(t, result.err)
+	statusError, ok := status.FromError(result.err)
+	assert.True(t, ok, "Expected status error")
+	assert.EqualValues(t, status.Timeout.ToInt32(), statusError.Code)
+	waitForFilteredBlockRegistrations(t, eventService, 0)
+
+	// late blocks are ignored
+	eventService.PublishFilteredBlock(filteredBlock(1, map[string]pb.TxValidationCode{"tx1": pb.TxValidationCode_VALID}))
+	select {
+	case <-results:
+		t.Fatal("transaction should be notified once")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
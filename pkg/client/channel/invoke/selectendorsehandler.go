@@ -43,6 +43,14 @@ func NewSelectAndEndorseHandler(next ...Handler) Handler {
 	}
 }
 
+// NewSelectAndEndorseSignedHandler returns a new SelectAndEndorseHandler sending a proposal signed outside of the SDK
+func NewSelectAndEndorseSignedHandler(signedProposal *SignedProposal, next ...Handler) Handler {
+	return &SelectAndEndorseHandler{
+		EndorsementHandler: NewSignedEndorsementHandler(signedProposal),
+		next:               getNext(next),
+	}
+}
+
 // Handle selects endorsers and sends proposals to the endorsers
 func (e *SelectAndEndorseHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
 	var ccCalls []*fab.ChaincodeCall
@@ -72,7 +80,7 @@ func (e *SelectAndEndorseHandler) Handle(requestContext *RequestContext, clientC
 			if len(additionalEndorsers) > 0 {
 				requestContext.Opts.Targets = additionalEndorsers
 				logger.Debugf("...getting additional endorsements from %d target(s)", len(additionalEndorsers))
-				additionalResponses, err := clientContext.Transactor.SendTransactionProposal(requestContext.Response.Proposal, peer.PeersToTxnProcessors(additionalEndorsers))
+				additionalResponses, err := e.EndorsementHandler.sendProposal(requestContext, clientContext, requestContext.Response.Proposal, peer.PeersToTxnProcessors(additionalEndorsers))
 				if err != nil {
 					requestContext.Error = errors.WithMessage(err, "error sending transaction proposal")
 					return
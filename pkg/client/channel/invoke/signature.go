@@ -39,16 +39,6 @@ func (f *SignatureValidationHandler) Handle(requestContext *RequestContext, clie
 }
 
 func (f *SignatureValidationHandler) validate(txProposalResponse []*fab.TransactionProposalResponse, ctx *ClientContext) error {
-	for _, r := range txProposalResponse {
-		if err := verifyProposalResponse(r, ctx); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func verifyProposalResponse(res *fab.TransactionProposalResponse, ctx *ClientContext) error {
-	sv := &verifier.Signature{Membership: ctx.Membership}
-	return sv.Verify(res)
+	sv := &verifier.Signature{Membership: ctx.Membership, SM2UIDs: ctx.SM2UIDs}
+	return sv.VerifyBatch(txProposalResponse)
 }
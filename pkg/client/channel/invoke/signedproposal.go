@@ -0,0 +1,40 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// SignedProposal is a transaction proposal signed outside of the SDK by its creator
+type SignedProposal struct {
+	Proposal *fab.TransactionProposal
+	// ProposalBytes are the signed bytes of the proposal
+	ProposalBytes []byte
+	Signature     []byte
+}
+
+// sendSignedProposal sends the signed proposal to the targets within the peer response timeout
+func sendSignedProposal(requestContext *RequestContext, signedProposal *SignedProposal, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, error) {
+	ctx, ok := contextImpl.RequestClientContext(requestContext.Ctx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for sendSignedProposal")
+	}
+
+	reqCtx, cancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeoutType(fab.PeerResponse), contextImpl.WithParent(requestContext.Ctx))
+	defer cancel()
+
+	return txn.SendSignedProposal(reqCtx, &pb.SignedProposal{
+		ProposalBytes: signedProposal.ProposalBytes,
+		Signature:     signedProposal.Signature,
+	}, targets)
+}
@@ -29,6 +29,7 @@ type TxnHeaderOptsProvider func() []fab.TxnHeaderOpt
 type EndorsementHandler struct {
 	next               Handler
 	headerOptsProvider TxnHeaderOptsProvider
+	signedProposal     *SignedProposal
 }
 
 //Handle for endorsing transactions
@@ -40,17 +41,7 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 	}
 
 	// Endorse Tx
-	var TxnHeaderOpts []fab.TxnHeaderOpt
-	if e.headerOptsProvider != nil {
-		TxnHeaderOpts = e.headerOptsProvider()
-	}
-
-	transactionProposalResponses, proposal, err := createAndSendTransactionProposal(
-		clientContext.Transactor,
-		&requestContext.Request,
-		peer.PeersToTxnProcessors(requestContext.Opts.Targets),
-		TxnHeaderOpts...,
-	)
+	transactionProposalResponses, proposal, err := e.endorse(requestContext, clientContext)
 
 	requestContext.Response.Proposal = proposal
 	requestContext.Response.TransactionID = proposal.TxnID // TODO: still needed?
@@ -72,6 +63,35 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 	}
 }
 
+func (e *EndorsementHandler) endorse(requestContext *RequestContext, clientContext *ClientContext) ([]*fab.TransactionProposalResponse, *fab.TransactionProposal, error) {
+	targets := peer.PeersToTxnProcessors(requestContext.Opts.Targets)
+
+	if e.signedProposal != nil {
+		transactionProposalResponses, err := sendSignedProposal(requestContext, e.signedProposal, targets)
+		return transactionProposalResponses, e.signedProposal.Proposal, err
+	}
+
+	var TxnHeaderOpts []fab.TxnHeaderOpt
+	if e.headerOptsProvider != nil {
+		TxnHeaderOpts = e.headerOptsProvider()
+	}
+
+	return createAndSendTransactionProposal(
+		clientContext.Transactor,
+		&requestContext.Request,
+		targets,
+		TxnHeaderOpts...,
+	)
+}
+
+// sendProposal sends the endorsed proposal to additional targets
+func (e *EndorsementHandler) sendProposal(requestContext *RequestContext, clientContext *ClientContext, proposal *fab.TransactionProposal, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, error) {
+	if e.signedProposal != nil {
+		return sendSignedProposal(requestContext, e.signedProposal, targets)
+	}
+	return clientContext.Transactor.SendTransactionProposal(proposal, targets)
+}
+
 //ProposalProcessorHandler for selecting proposal processors
 type ProposalProcessorHandler struct {
 	next Handler
@@ -202,6 +222,37 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 	}
 }
 
+//SubmitTxHandler for submitting transactions without waiting for their commit
+type SubmitTxHandler struct {
+	tracker  *CommitTracker
+	callback TxStatusCallback
+	next     Handler
+}
+
+//Handle submits the transaction to the orderer, the callback is notified once the transaction is committed
+func (c *SubmitTxHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	txnID := requestContext.Response.TransactionID
+
+	//Track the commit within the execute timeout
+	err := c.tracker.Track(txnID, requestContext.Opts.Timeouts[fab.Execute], c.callback)
+	if err != nil {
+		requestContext.Error = errors.WithMessage(err, "error tracking transaction commit")
+		return
+	}
+
+	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
+	if err != nil {
+		c.tracker.Untrack(txnID)
+		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
+		return
+	}
+
+	//Delegate to next step if any
+	if c.next != nil {
+		c.next.Handle(requestContext, clientContext)
+	}
+}
+
 //NewQueryHandler returns query handler with chain of ProposalProcessorHandler, EndorsementHandler, EndorsementValidationHandler and SignatureValidationHandler
 func NewQueryHandler(next ...Handler) Handler {
 	return NewProposalProcessorHandler(
@@ -213,11 +264,22 @@ func NewQueryHandler(next ...Handler) Handler {
 	)
 }
 
-//NewExecuteHandler returns execute handler with chain of SelectAndEndorseHandler, EndorsementValidationHandler, SignatureValidationHandler and CommitHandler
+//NewExecuteHandler returns execute handler with chain of CommitRetryHandler, SelectAndEndorseHandler, EndorsementValidationHandler, SignatureValidationHandler and CommitHandler
 func NewExecuteHandler(next ...Handler) Handler {
+	return NewCommitRetryHandler(
+		NewSelectAndEndorseHandler(
+			NewEndorsementValidationHandler(
+				NewSignatureValidationHandler(NewCommitHandler(next...)),
+			),
+		),
+	)
+}
+
+//NewSubmitHandler returns submit handler with chain of SelectAndEndorseHandler, EndorsementValidationHandler, SignatureValidationHandler and SubmitTxHandler
+func NewSubmitHandler(tracker *CommitTracker, callback TxStatusCallback, next ...Handler) Handler {
 	return NewSelectAndEndorseHandler(
 		NewEndorsementValidationHandler(
-			NewSignatureValidationHandler(NewCommitHandler(next...)),
+			NewSignatureValidationHandler(NewSubmitTxHandler(tracker, callback, next...)),
 		),
 	)
 }
@@ -237,6 +299,11 @@ func NewEndorsementHandlerWithOpts(next Handler, provider TxnHeaderOptsProvider)
 	return &EndorsementHandler{next: next, headerOptsProvider: provider}
 }
 
+//NewSignedEndorsementHandler returns a handler that sends a proposal signed outside of the SDK to the endorsers
+func NewSignedEndorsementHandler(signedProposal *SignedProposal, next ...Handler) *EndorsementHandler {
+	return &EndorsementHandler{next: getNext(next), signedProposal: signedProposal}
+}
+
 //NewEndorsementValidationHandler returns a handler that validates an endorsement
 func NewEndorsementValidationHandler(next ...Handler) *EndorsementValidationHandler {
 	return &EndorsementValidationHandler{next: getNext(next)}
@@ -247,6 +314,11 @@ func NewCommitHandler(next ...Handler) *CommitTxHandler {
 	return &CommitTxHandler{next: getNext(next)}
 }
 
+//NewSubmitTxHandler returns a handler that submits transaction proposal responses, the tracker notifies the callback of the commit
+func NewSubmitTxHandler(tracker *CommitTracker, callback TxStatusCallback, next ...Handler) *SubmitTxHandler {
+	return &SubmitTxHandler{tracker: tracker, callback: callback, next: getNext(next)}
+}
+
 func getNext(next []Handler) Handler {
 	if len(next) > 0 {
 		return next[0]
@@ -10,6 +10,7 @@ import (
 	reqContext "context"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
@@ -158,6 +160,35 @@ func TestExecuteTxHandlerErrors(t *testing.T) {
 	}
 }
 
+func TestSubmitHandler(t *testing.T) {
+
+	//Sample request
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	//Prepare context objects for handler
+	requestContext := prepareRequestContext(request, Opts{}, t)
+
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer1}, t)
+
+	mockEventService := fcmocks.NewMockEventService()
+	callback, results := newTxStatusCallback()
+
+	//The handler returns once the transaction is sent
+	submitHandler := NewSubmitHandler(NewCommitTracker(mockEventService), callback)
+	submitHandler.Handle(requestContext, clientContext)
+	require.NoError(t, requestContext.Error)
+	txnID := requestContext.Response.TransactionID
+	assert.NotEmpty(t, txnID)
+	assert.Len(t, requestContext.Response.Responses, 1)
+
+	mockEventService.PublishFilteredBlock(filteredBlock(1, map[string]pb.TxValidationCode{string(txnID): pb.TxValidationCode_VALID}))
+	result := receiveTxStatus(t, results)
+	require.NoError(t, result.err)
+	assert.Equal(t, string(txnID), result.txStatus.TxID)
+	assert.Equal(t, pb.TxValidationCode_VALID, result.txStatus.TxValidationCode)
+}
+
 func TestEndorsementHandler(t *testing.T) {
 	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
 
@@ -189,6 +220,49 @@ func TestEndorsementHandler(t *testing.T) {
 	assert.Truef(t, optsProviderCalled, "expecting opts provider to be called")
 }
 
+// recordingPeer records the proposals processed by the mock peer
+type recordingPeer struct {
+	*fcmocks.MockPeer
+	mutex    sync.Mutex
+	requests []fab.ProcessProposalRequest
+}
+
+func (p *recordingPeer) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	p.mutex.Lock()
+	p.requests = append(p.requests, request)
+	p.mutex.Unlock()
+	return p.MockPeer.ProcessTransactionProposal(ctx, request)
+}
+
+func TestSignedEndorsementHandler(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+	proposal := &fab.TransactionProposal{TxnID: "txid", Proposal: &pb.Proposal{}}
+	signedProposal := &SignedProposal{Proposal: proposal, ProposalBytes: []byte("proposal"), Signature: []byte("external signature")}
+
+	peer1 := &recordingPeer{MockPeer: fcmocks.NewMockPeer("p1", "peer1.example.com")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{peer1}, t)
+
+	// the client context is required to send the proposal
+	requestContext := prepareRequestContext(request, Opts{}, t)
+	NewSelectAndEndorseSignedHandler(signedProposal).Handle(requestContext, clientContext)
+	assert.Error(t, requestContext.Error)
+
+	requestContext = prepareRequestContext(request, Opts{}, t)
+	ctx, cancel := contextImpl.NewRequest(setupTestContext(), contextImpl.WithTimeout(testTimeOut))
+	defer cancel()
+	requestContext.Ctx = ctx
+
+	NewSelectAndEndorseSignedHandler(signedProposal, NewEndorsementValidationHandler()).Handle(requestContext, clientContext)
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, proposal, requestContext.Response.Proposal)
+	assert.Equal(t, fab.TransactionID("txid"), requestContext.Response.TransactionID)
+	assert.Len(t, requestContext.Response.Responses, 1)
+
+	require.Len(t, peer1.requests, 1)
+	assert.Equal(t, []byte("proposal"), peer1.requests[0].SignedProposal.ProposalBytes)
+	assert.Equal(t, []byte("external signature"), peer1.requests[0].SignedProposal.Signature)
+}
+
 // Target filter
 type filter struct {
 	peer fab.Peer
@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
+)
+
+// NsReadWriteSet is the set of keys read and written by a chaincode namespace during the simulation of a transaction
+type NsReadWriteSet struct {
+	// Namespace is the name of the chaincode
+	Namespace      string
+	Reads          []*kvrwset.KVRead
+	RangeQueries   []*kvrwset.RangeQueryInfo
+	Writes         []*kvrwset.KVWrite
+	MetadataWrites []*kvrwset.KVMetadataWrite
+	// Collections are the hashed read-write sets of the private data collections of the namespace
+	Collections []*CollectionHashedReadWriteSet
+}
+
+// CollectionHashedReadWriteSet is the set of hashed keys read and written in a private data collection
+type CollectionHashedReadWriteSet struct {
+	CollectionName string
+	HashedReads    []*kvrwset.KVReadHash
+	HashedWrites   []*kvrwset.KVWriteHash
+	MetadataWrites []*kvrwset.KVMetadataWriteHash
+	// PvtRwSetHash is the hash of the private read-write set of the collection
+	PvtRwSetHash []byte
+}
+
+// ReadWriteSets decodes the read-write sets of the endorsement of the first peer of the response,
+// the endorsements of a response are validated to be identical
+//  Returns:
+//  the read-write sets of the namespaces accessed by the transaction
+func (r Response) ReadWriteSets() ([]*NsReadWriteSet, error) {
+	if len(r.Responses) == 0 {
+		return nil, errors.New("response has no endorsements")
+	}
+	return ReadWriteSets(r.Responses[0])
+}
+
+// ReadWriteSets decodes the read-write sets of the endorsement of a peer. The endorsements of
+// several peers may be compared to find the cause of an endorsement mismatch.
+//  Parameters:
+//  response is the proposal response of a peer
+//
+//  Returns:
+//  the read-write sets of the namespaces accessed by the transaction
+func ReadWriteSets(response *fab.TransactionProposalResponse) ([]*NsReadWriteSet, error) {
+	if response == nil || response.ProposalResponse == nil {
+		return nil, errors.New("proposal response is required")
+	}
+
+	prp, err := protos_utils.GetProposalResponsePayload(response.Payload)
+	if err != nil {
+		return nil, errors.WithMessage(err, "decoding proposal response payload failed")
+	}
+
+	chaincodeAction, err := protos_utils.GetChaincodeAction(prp.Extension)
+	if err != nil {
+		return nil, errors.WithMessage(err, "decoding chaincode action failed")
+	}
+
+	if len(chaincodeAction.Results) == 0 {
+		return nil, nil
+	}
+
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err := txRWSet.FromProtoBytes(chaincodeAction.Results); err != nil {
+		return nil, errors.Wrap(err, "decoding read-write set failed")
+	}
+
+	rwSets := make([]*NsReadWriteSet, len(txRWSet.NsRwSets))
+	for i, nsRWSet := range txRWSet.NsRwSets {
+		rwSets[i] = newNsReadWriteSet(nsRWSet)
+	}
+	return rwSets, nil
+}
+
+func newNsReadWriteSet(nsRWSet *rwsetutil.NsRwSet) *NsReadWriteSet {
+	rwSet := &NsReadWriteSet{
+		Namespace:      nsRWSet.NameSpace,
+		Reads:          nsRWSet.KvRwSet.GetReads(),
+		RangeQueries:   nsRWSet.KvRwSet.GetRangeQueriesInfo(),
+		Writes:         nsRWSet.KvRwSet.GetWrites(),
+		MetadataWrites: nsRWSet.KvRwSet.GetMetadataWrites(),
+	}
+
+	for _, collRWSet := range nsRWSet.CollHashedRwSets {
+		rwSet.Collections = append(rwSet.Collections, &CollectionHashedReadWriteSet{
+			CollectionName: collRWSet.CollectionName,
+			HashedReads:    collRWSet.HashedRwSet.GetHashedReads(),
+			HashedWrites:   collRWSet.HashedRwSet.GetHashedWrites(),
+			MetadataWrites: collRWSet.HashedRwSet.GetMetadataWrites(),
+			PvtRwSetHash:   collRWSet.PvtRwSetHash,
+		})
+	}
+	return rwSet
+}
@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestReadWriteSets(t *testing.T) {
+	rangeQuery := &kvrwset.RangeQueryInfo{
+		StartKey:     "a",
+		EndKey:       "c",
+		ItrExhausted: true,
+		ReadsInfo:    &kvrwset.RangeQueryInfo_RawReads{RawReads: &kvrwset.QueryReads{KvReads: []*kvrwset.KVRead{{Key: "b"}}}},
+	}
+	ccRWSet := fcmocks.NewRwSet("test")
+	ccRWSet.KvRwSet = &kvrwset.KVRWSet{
+		Reads:            []*kvrwset.KVRead{{Key: "a", Version: &kvrwset.Version{BlockNum: 1, TxNum: 2}}},
+		RangeQueriesInfo: []*kvrwset.RangeQueryInfo{rangeQuery},
+		Writes:           []*kvrwset.KVWrite{{Key: "b", Value: []byte("value")}, {Key: "c", IsDelete: true}},
+		MetadataWrites:   []*kvrwset.KVMetadataWrite{{Key: "b", Entries: []*kvrwset.KVMetadataEntry{{Name: "policy", Value: []byte("p")}}}},
+	}
+	ccRWSet.CollHashedRwSets = []*rwsetutil.CollHashedRwSet{{
+		CollectionName: "coll1",
+		HashedRwSet: &kvrwset.HashedRWSet{
+			HashedReads:  []*kvrwset.KVReadHash{{KeyHash: []byte("hash1")}},
+			HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte("hash2"), ValueHash: []byte("valuehash")}},
+		},
+		PvtRwSetHash: []byte("pvthash"),
+	}}
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.SetRwSets(ccRWSet, fcmocks.NewRwSet("lscc"))
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	response, err := chClient.Query(Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	require.NoError(t, err)
+
+	rwSets, err := response.ReadWriteSets()
+	require.NoError(t, err)
+	require.Len(t, rwSets, 2)
+
+	rwSet := rwSets[0]
+	assert.Equal(t, "test", rwSet.Namespace)
+	require.Len(t, rwSet.Reads, 1)
+	assert.Equal(t, "a", rwSet.Reads[0].Key)
+	assert.Equal(t, uint64(1), rwSet.Reads[0].Version.BlockNum)
+	require.Len(t, rwSet.RangeQueries, 1)
+	assert.Equal(t, "b", rwSet.RangeQueries[0].GetRawReads().KvReads[0].Key)
+	require.Len(t, rwSet.Writes, 2)
+	assert.Equal(t, []byte("value"), rwSet.Writes[0].Value)
+	assert.True(t, rwSet.Writes[1].IsDelete)
+	require.Len(t, rwSet.MetadataWrites, 1)
+	assert.Equal(t, "policy", rwSet.MetadataWrites[0].Entries[0].Name)
+
+	require.Len(t, rwSet.Collections, 1)
+	coll := rwSet.Collections[0]
+	assert.Equal(t, "coll1", coll.CollectionName)
+	assert.Equal(t, []byte("hash1"), coll.HashedReads[0].KeyHash)
+	assert.Equal(t, []byte("valuehash"), coll.HashedWrites[0].ValueHash)
+	assert.Empty(t, coll.MetadataWrites)
+	assert.Equal(t, []byte("pvthash"), coll.PvtRwSetHash)
+
+	assert.Equal(t, "lscc", rwSets[1].Namespace)
+	assert.Empty(t, rwSets[1].Reads)
+	assert.Empty(t, rwSets[1].Collections)
+}
+
+func TestReadWriteSetsErrors(t *testing.T) {
+	_, err := Response{}.ReadWriteSets()
+	assert.Error(t, err, "response without endorsements should fail")
+
+	_, err = ReadWriteSets(nil)
+	assert.Error(t, err, "nil proposal response should fail")
+
+	_, err = ReadWriteSets(&fab.TransactionProposalResponse{ProposalResponse: &pb.ProposalResponse{Payload: []byte("invalid")}})
+	assert.Error(t, err, "invalid payload should fail")
+
+	// a proposal response without simulation results has no read-write sets
+	rwSets, err := ReadWriteSets(&fab.TransactionProposalResponse{ProposalResponse: &pb.ProposalResponse{}})
+	assert.NoError(t, err)
+	assert.Empty(t, rwSets)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// TxHandle is the handle of a transaction submitted to the orderer. It is notified
+// once the transaction is committed, or once the execute timeout has elapsed.
+type TxHandle struct {
+	response Response
+	done     chan struct{}
+	once     sync.Once
+	code     pb.TxValidationCode
+	err      error
+}
+
+func newTxHandle() *TxHandle {
+	return &TxHandle{done: make(chan struct{})}
+}
+
+// TransactionID returns the ID of the submitted transaction
+func (h *TxHandle) TransactionID() fab.TransactionID {
+	return h.response.TransactionID
+}
+
+// Response returns the endorsement response of the submitted transaction
+func (h *TxHandle) Response() Response {
+	return h.response
+}
+
+// Done returns a channel which is closed once the status of the transaction is known
+func (h *TxHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait waits until the status of the transaction is known or the context is done
+//  Parameters:
+//  ctx is the context bounding the wait
+//
+//  Returns:
+//  the response with the validation code of the transaction, and an error if the
+//  transaction is invalid or its status could not be known
+func (h *TxHandle) Wait(ctx reqContext.Context) (Response, error) {
+	select {
+	case <-h.done:
+		response := h.response
+		response.TxValidationCode = h.code
+		return response, h.err
+	case <-ctx.Done():
+		return Response{}, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+			"wait for transaction status timed out or been cancelled", nil)
+	}
+}
+
+// complete records the status of the transaction, it is notified by the commit tracker
+func (h *TxHandle) complete(txStatus *fab.TxStatusEvent, err error) {
+	h.once.Do(func() {
+		h.code, h.err = txStatusResult(txStatus, err)
+		close(h.done)
+	})
+}
+
+// txStatusResult returns the validation code of the committed transaction, and an error
+// if the transaction is invalid or its status could not be known
+func txStatusResult(txStatus *fab.TxStatusEvent, err error) (pb.TxValidationCode, error) {
+	if err != nil {
+		return 0, err
+	}
+	if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
+		return txStatus.TxValidationCode, status.New(status.EventServerStatus, int32(txStatus.TxValidationCode),
+			"received invalid transaction", nil)
+	}
+	return txStatus.TxValidationCode, nil
+}
@@ -25,7 +25,7 @@ type MockTransactor struct {
 
 // CreateTransactionHeader creates a Transaction Header based on the current context.
 func (t *MockTransactor) CreateTransactionHeader(opts ...fab.TxnHeaderOpt) (fab.TransactionHeader, error) {
-	txh, err := txn.NewHeader(t.Ctx, t.ChannelID)
+	txh, err := txn.NewHeader(t.Ctx, t.ChannelID, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "new transaction ID failed")
 	}
@@ -51,3 +51,15 @@ func (t *MockTransactor) SendTransaction(tx *fab.Transaction) (*fab.TransactionR
 	defer cancel()
 	return txn.Send(rqtx, tx, t.Orderers)
 }
+
+// NewBroadcaster returns a broadcaster sending transactions to the orderers on reused broadcast streams.
+func (t *MockTransactor) NewBroadcaster() (*txn.Broadcaster, error) {
+	return txn.NewBroadcaster(t.Ctx, t.Orderers), nil
+}
+
+// SendEnvelope sends a signed envelope to the orderers.
+func (t *MockTransactor) SendEnvelope(envelope *fab.SignedEnvelope) (*fab.TransactionResponse, error) {
+	rqtx, cancel := contextImpl.NewRequest(t.Ctx, contextImpl.WithTimeout(10*time.Second))
+	defer cancel()
+	return txn.BroadcastEnvelope(rqtx, envelope, t.Orderers)
+}
@@ -0,0 +1,299 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifier
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/pkg/errors"
+)
+
+// maxChainLength is the maximum number of certificates (including the root) in a validated chain
+const maxChainLength = 10
+
+// defaultSM2UID is the user ID of GM/T 0009 used to compute the Z value of SM2 signatures
+var defaultSM2UID = []byte("1234567812345678")
+
+// PublicKeyAlgorithm is the algorithm of the public key of a certificate
+type PublicKeyAlgorithm int
+
+const (
+	// UnknownPublicKeyAlgorithm is a key algorithm not supported by the verifier
+	UnknownPublicKeyAlgorithm PublicKeyAlgorithm = iota
+	// ECDSA is an ECDSA key on a NIST curve
+	ECDSA
+	// RSA is an RSA key
+	RSA
+	// SM2 is an SM2 key
+	SM2
+)
+
+func (a PublicKeyAlgorithm) String() string {
+	switch a {
+	case ECDSA:
+		return "ECDSA"
+	case RSA:
+		return "RSA"
+	case SM2:
+		return "SM2"
+	}
+	return "unknown"
+}
+
+// Certificate is an algorithm-agnostic X.509 certificate, so that ECDSA, RSA and SM2
+// certificates can be validated side by side. ECDSA and RSA certificates are checked
+// with crypto/x509 while SM2 certificates are checked with the sm2 package.
+type Certificate struct {
+	// Raw is the DER encoded certificate
+	Raw []byte
+	// Algorithm is the algorithm of the public key of the certificate
+	Algorithm    PublicKeyAlgorithm
+	PublicKey    crypto.PublicKey
+	SerialNumber *big.Int
+	Subject      pkix.Name
+	Issuer       pkix.Name
+	NotBefore    time.Time
+	NotAfter     time.Time
+
+	sm2Cert  *sm2.Certificate
+	x509Cert *x509.Certificate
+}
+
+// ParseCertificate parses a DER encoded certificate of any supported algorithm
+func ParseCertificate(der []byte) (*Certificate, error) {
+	sm2Cert, err := sm2.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+
+	c := &Certificate{
+		Raw:          sm2Cert.Raw,
+		SerialNumber: sm2Cert.SerialNumber,
+		Subject:      sm2Cert.Subject,
+		Issuer:       sm2Cert.Issuer,
+		NotBefore:    sm2Cert.NotBefore,
+		NotAfter:     sm2Cert.NotAfter,
+		sm2Cert:      sm2Cert,
+	}
+
+	switch pub := sm2Cert.PublicKey.(type) {
+	case *sm2.PublicKey:
+		// the sm2 package parses every EC key as an SM2 key; only the curve tells them apart
+		if pub.Curve == sm2.P256Sm2() {
+			c.Algorithm = SM2
+			c.PublicKey = pub
+			return c, nil
+		}
+		c.Algorithm = ECDSA
+	case *rsa.PublicKey:
+		c.Algorithm = RSA
+	default:
+		return nil, errors.Errorf("unsupported public key type %T in certificate [%s]", sm2Cert.PublicKey, sm2Cert.Subject.CommonName)
+	}
+
+	c.x509Cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s certificate", c.Algorithm)
+	}
+	c.PublicKey = c.x509Cert.PublicKey
+	return c, nil
+}
+
+// ParsePEMCertificates parses all the certificates of the given PEM bytes, of any supported algorithm
+func ParsePEMCertificates(pemCerts []byte) ([]*Certificate, error) {
+	var certs []*Certificate
+	for len(pemCerts) > 0 {
+		var block *pem.Block
+		block, pemCerts = pem.Decode(pemCerts)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" || len(block.Headers) != 0 {
+			continue
+		}
+		cert, err := ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// SM2 returns the certificate as parsed by the sm2 package
+func (c *Certificate) SM2() *sm2.Certificate {
+	return c.sm2Cert
+}
+
+// X509 returns the certificate as parsed by crypto/x509, or nil for SM2 certificates
+func (c *Certificate) X509() *x509.Certificate {
+	return c.x509Cert
+}
+
+// ValidateDates checks that the certificate is valid at the current time
+func (c *Certificate) ValidateDates() error {
+	return validateDates(c.NotBefore, c.NotAfter)
+}
+
+// CheckSignatureFrom checks that the certificate is signed by parent, whatever the algorithms of both
+func (c *Certificate) CheckSignatureFrom(parent *Certificate) error {
+	p := parent.sm2Cert
+	if p.Version == 3 && !p.BasicConstraintsValid || p.BasicConstraintsValid && !p.IsCA {
+		return errors.Errorf("certificate [%s] is not a CA certificate", p.Subject.CommonName)
+	}
+	if p.KeyUsage != 0 && p.KeyUsage&sm2.KeyUsageCertSign == 0 {
+		return errors.Errorf("certificate [%s] is not allowed to sign certificates", p.Subject.CommonName)
+	}
+	return parent.CheckSignature(c.sm2Cert.SignatureAlgorithm, c.sm2Cert.RawTBSCertificate, c.sm2Cert.Signature)
+}
+
+// CheckSignature verifies that signature is a valid signature over signed from the key of the certificate
+func (c *Certificate) CheckSignature(algo sm2.SignatureAlgorithm, signed, signature []byte) error {
+	if c.Algorithm == SM2 {
+		return checkSM2Signature(c.PublicKey.(*sm2.PublicKey), algo, signed, signature)
+	}
+
+	x509Algo, ok := x509SignatureAlgorithms[algo]
+	if !ok {
+		return errors.Errorf("signature algorithm %s is not supported with %s keys", algo, c.Algorithm)
+	}
+	return c.x509Cert.CheckSignature(x509Algo, signed, signature)
+}
+
+// CheckCRLSignature checks that the CRL is signed by the key of the certificate
+func (c *Certificate) CheckCRLSignature(crl *pkix.CertificateList) error {
+	if c.Algorithm != SM2 {
+		return c.x509Cert.CheckCRLSignature(crl)
+	}
+
+	algo, ok := sm2SignatureAlgorithms[crl.SignatureAlgorithm.Algorithm.String()]
+	if !ok {
+		return errors.Errorf("signature algorithm %s of the CRL is not supported with SM2 keys", crl.SignatureAlgorithm.Algorithm)
+	}
+	return c.CheckSignature(algo, crl.TBSCertList.Raw, crl.SignatureValue.RightAlign())
+}
+
+// Verify builds a chain from the certificate to one of roots, through intermediates,
+// checking the signature and validity dates of every certificate of the chain
+func (c *Certificate) Verify(roots, intermediates []*Certificate) ([]*Certificate, error) {
+	if err := c.ValidateDates(); err != nil {
+		return nil, err
+	}
+
+	chain := []*Certificate{c}
+	current := c
+	for len(chain) <= maxChainLength {
+		for _, root := range roots {
+			if bytes.Equal(root.Raw, current.Raw) {
+				return chain, nil
+			}
+		}
+		if root := findIssuer(current, roots); root != nil {
+			if err := root.ValidateDates(); err != nil {
+				return nil, errors.WithMessage(err, "root certificate is not valid")
+			}
+			return append(chain, root), nil
+		}
+
+		parent := findIssuer(current, intermediates)
+		if parent == nil {
+			return nil, errors.Errorf("certificate [%s] signed by unknown authority", current.Subject.CommonName)
+		}
+		if err := parent.ValidateDates(); err != nil {
+			return nil, errors.WithMessage(err, "intermediate certificate is not valid")
+		}
+		chain = append(chain, parent)
+		current = parent
+	}
+	return nil, errors.Errorf("certificate chain of [%s] is longer than %d", c.Subject.CommonName, maxChainLength)
+}
+
+// findIssuer returns the candidate that issued cert, if any
+func findIssuer(cert *Certificate, candidates []*Certificate) *Certificate {
+	for _, candidate := range candidates {
+		if !bytes.Equal(candidate.sm2Cert.RawSubject, cert.sm2Cert.RawIssuer) {
+			continue
+		}
+		if err := cert.CheckSignatureFrom(candidate); err != nil {
+			logger.Debugf("certificate [%s] not signed by [%s]: %s", cert.Subject.CommonName, candidate.Subject.CommonName, err)
+			continue
+		}
+		return candidate
+	}
+	return nil
+}
+
+// checkSM2Signature verifies an SM2 signature. SM2 with SM3 signatures are computed over
+// SM3(ZA || signed) as specified by GM/T 0009, ZA being the Z value of the signer for the default user ID.
+func checkSM2Signature(pub *sm2.PublicKey, algo sm2.SignatureAlgorithm, signed, signature []byte) error {
+	r, s, err := sm2.SignDataToSignDigit(signature)
+	if err != nil {
+		return errors.Wrap(err, "failed to unmarshal SM2 signature")
+	}
+
+	var h crypto.Hash
+	switch algo {
+	case sm2.SM2WithSM3:
+		if sm2.Sm2Verify(pub, signed, defaultSM2UID, r, s) {
+			return nil
+		}
+		return errors.New("SM2 signature verification failed")
+	case sm2.SM2WithSHA256:
+		h = crypto.SHA256
+	case sm2.SM2WithSHA1:
+		h = crypto.SHA1
+	default:
+		return errors.Errorf("signature algorithm %s is not supported with SM2 keys", algo)
+	}
+
+	hf := h.New()
+	hf.Write(signed)
+	if !sm2.Verify(pub, hf.Sum(nil), r, s) {
+		return errors.New("SM2 signature verification failed")
+	}
+	return nil
+}
+
+// sm2SignatureAlgorithms are the signature algorithms of SM2 keys, by OID
+var sm2SignatureAlgorithms = map[string]sm2.SignatureAlgorithm{
+	"1.2.156.10197.1.501": sm2.SM2WithSM3,
+	"1.2.156.10197.1.502": sm2.SM2WithSHA1,
+	"1.2.156.10197.1.503": sm2.SM2WithSHA256,
+}
+
+var x509SignatureAlgorithms = map[sm2.SignatureAlgorithm]x509.SignatureAlgorithm{
+	sm2.SHA1WithRSA:      x509.SHA1WithRSA,
+	sm2.SHA256WithRSA:    x509.SHA256WithRSA,
+	sm2.SHA384WithRSA:    x509.SHA384WithRSA,
+	sm2.SHA512WithRSA:    x509.SHA512WithRSA,
+	sm2.SHA256WithRSAPSS: x509.SHA256WithRSAPSS,
+	sm2.SHA384WithRSAPSS: x509.SHA384WithRSAPSS,
+	sm2.SHA512WithRSAPSS: x509.SHA512WithRSAPSS,
+	sm2.ECDSAWithSHA1:    x509.ECDSAWithSHA1,
+	sm2.ECDSAWithSHA256:  x509.ECDSAWithSHA256,
+	sm2.ECDSAWithSHA384:  x509.ECDSAWithSHA384,
+	sm2.ECDSAWithSHA512:  x509.ECDSAWithSHA512,
+}
+
+func validateDates(notBefore, notAfter time.Time) error {
+	if time.Now().UTC().Before(notBefore) {
+		return errors.New("Certificate provided is not valid until later date")
+	}
+
+	if time.Now().UTC().After(notAfter) {
+		return errors.New("Certificate provided has expired")
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifier
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *Certificate
+	key  crypto.Signer
+}
+
+func newKey(t *testing.T, algorithm PublicKeyAlgorithm) crypto.Signer {
+	var key crypto.Signer
+	var err error
+	switch algorithm {
+	case ECDSA:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case RSA:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case SM2:
+		key, err = sm2.GenerateKey()
+	}
+	require.NoError(t, err)
+	return key
+}
+
+// newCert issues a cert for a new key of the given algorithm, signed by parent or self-signed if parent is nil
+func newCert(t *testing.T, cn string, algorithm PublicKeyAlgorithm, isCA bool, notAfter time.Time, parent *testCA) *testCA {
+	key := newKey(t, algorithm)
+	template := &sm2.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage = sm2.KeyUsageCertSign
+	}
+
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert.SM2(), parent.key
+	}
+	der, err := sm2.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+	require.NoError(t, err)
+
+	cert, err := ParseCertificate(der)
+	require.NoError(t, err)
+	assert.Equal(t, algorithm, cert.Algorithm)
+	return &testCA{cert: cert, key: key}
+}
+
+func TestVerifyMixedChains(t *testing.T) {
+	validUntil := time.Now().Add(time.Hour)
+
+	ecdsaRoot := newCert(t, "ecdsa-root", ECDSA, true, validUntil, nil)
+	rsaRoot := newCert(t, "rsa-root", RSA, true, validUntil, nil)
+	sm2Root := newCert(t, "sm2-root", SM2, true, validUntil, nil)
+	roots := []*Certificate{ecdsaRoot.cert, rsaRoot.cert, sm2Root.cert}
+
+	// an SM2 intermediate issued by an ECDSA root, e.g. during a migration
+	sm2Intermediate := newCert(t, "sm2-intermediate", SM2, true, validUntil, ecdsaRoot)
+	intermediates := []*Certificate{sm2Intermediate.cert}
+
+	leaves := []*testCA{
+		newCert(t, "ecdsa-leaf", ECDSA, false, validUntil, ecdsaRoot),
+		newCert(t, "rsa-leaf", RSA, false, validUntil, rsaRoot),
+		newCert(t, "sm2-leaf", SM2, false, validUntil, sm2Root),
+		newCert(t, "ecdsa-by-sm2-leaf", ECDSA, false, validUntil, sm2Root),
+		newCert(t, "ecdsa-by-intermediate-leaf", ECDSA, false, validUntil, sm2Intermediate),
+	}
+	for _, leaf := range leaves {
+		chain, err := leaf.cert.Verify(roots, intermediates)
+		assert.NoError(t, err, "failed to verify %s", leaf.cert.Subject.CommonName)
+		assert.NotEmpty(t, chain)
+	}
+
+	chain, err := leaves[4].cert.Verify(roots, intermediates)
+	require.NoError(t, err)
+	assert.Equal(t, []*Certificate{leaves[4].cert, sm2Intermediate.cert, ecdsaRoot.cert}, chain)
+
+	// missing intermediate
+	_, err = leaves[4].cert.Verify(roots, nil)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "signed by unknown authority"))
+
+	// unknown root
+	otherRoot := newCert(t, "other-root", SM2, true, validUntil, nil)
+	_, err = newCert(t, "other-leaf", SM2, false, validUntil, otherRoot).cert.Verify(roots, intermediates)
+	assert.Error(t, err)
+
+	// a leaf cannot issue certs
+	_, err = newCert(t, "leaf-of-leaf", ECDSA, false, validUntil, leaves[0]).cert.Verify(roots, []*Certificate{leaves[0].cert})
+	assert.Error(t, err)
+}
+
+func TestCheckSignatureTampered(t *testing.T) {
+	validUntil := time.Now().Add(time.Hour)
+	for _, algorithm := range []PublicKeyAlgorithm{ECDSA, RSA, SM2} {
+		root := newCert(t, "root", algorithm, true, validUntil, nil)
+		leaf := newCert(t, "leaf", algorithm, false, validUntil, root)
+		assert.NoError(t, leaf.cert.CheckSignatureFrom(root.cert), "algorithm %s", algorithm)
+
+		tbs := append([]byte{}, leaf.cert.SM2().RawTBSCertificate...)
+		tbs[len(tbs)-1] ^= 0xff
+		err := root.cert.CheckSignature(leaf.cert.SM2().SignatureAlgorithm, tbs, leaf.cert.SM2().Signature)
+		assert.Error(t, err, "algorithm %s", algorithm)
+	}
+}
+
+func TestCheckCRLSignature(t *testing.T) {
+	validUntil := time.Now().Add(time.Hour)
+	for _, algorithm := range []PublicKeyAlgorithm{ECDSA, RSA, SM2} {
+		root := newCert(t, "root", algorithm, true, validUntil, nil)
+		other := newCert(t, "other", algorithm, true, validUntil, nil)
+		der, err := root.cert.SM2().CreateCRL(rand.Reader, root.key, nil, time.Now(), validUntil)
+		require.NoError(t, err)
+		crl, err := sm2.ParseDERCRL(der)
+		require.NoError(t, err)
+
+		assert.NoError(t, root.cert.CheckCRLSignature(crl), "algorithm %s", algorithm)
+		assert.Error(t, other.cert.CheckCRLSignature(crl), "algorithm %s", algorithm)
+	}
+}
+
+func TestVerifyPeerCertificateMixed(t *testing.T) {
+	validUntil := time.Now().Add(time.Hour)
+	sm2Cert := newCert(t, "sm2", SM2, false, validUntil, nil)
+	ecdsaCert := newCert(t, "ecdsa", ECDSA, false, validUntil, nil)
+	rawCerts := [][]byte{sm2Cert.cert.Raw, ecdsaCert.cert.Raw}
+
+	assert.NoError(t, VerifyPeerCertificate(rawCerts, nil))
+	assert.NoError(t, VerifyTLSPeerCertificate(rawCerts, nil))
+
+	expired := newCert(t, "expired", SM2, false, time.Now().Add(-time.Minute), nil)
+	rawCerts = append(rawCerts, expired.cert.Raw)
+	err := VerifyTLSPeerCertificate(rawCerts, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "Certificate provided has expired", err.Error())
+	assert.Error(t, VerifyPeerCertificate(rawCerts, nil))
+}
@@ -9,23 +9,47 @@ package verifier
 
 import (
 	"crypto/x509"
-	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"encoding/pem"
+	"runtime"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/pkg/errors"
-	"time"
 )
 
 const loggerModule = "fabsdk/client"
 
 var logger = logging.NewLogger(loggerModule)
 
+// SM2UIDResolver resolves the SM2 user ID an identity signs with by its MSP ID and the
+// common name of its certificate (see msp.IdentityConfig)
+type SM2UIDResolver interface {
+	SM2UID(mspID, id string) ([]byte, bool)
+}
+
+// CertificateCache is implemented by a fab.ChannelMembership that keeps the parsed
+// certificates of the serialized identities it has seen
+type CertificateCache interface {
+	// Certificate returns the MSP ID and the certificate of the serialized identity
+	Certificate(serializedID []byte) (mspID string, cert *Certificate, err error)
+}
+
 // Signature verifies response signature
 type Signature struct {
 	Membership fab.ChannelMembership
+	// SM2UIDs optionally resolves the SM2 user IDs of the endorsers. Endorsements of an
+	// SM2 identity with a user ID are verified over SM3(ZA || msg) with that user ID.
+	SM2UIDs SM2UIDResolver
+	// Workers bounds the number of endorsements VerifyBatch verifies at once;
+	// zero or less means runtime.NumCPU()
+	Workers int
 }
 
 // Verify checks transaction proposal response
@@ -51,7 +75,7 @@ func (v *Signature) Verify(response *fab.TransactionProposalResponse) error {
 	digest := append(res.GetPayload(), res.GetEndorsement().Endorser...)
 
 	// validate the signature
-	err = v.Membership.Verify(creatorID, digest, res.GetEndorsement().Signature)
+	err = v.verifySignature(creatorID, digest, res.GetEndorsement().Signature)
 	if err != nil {
 		return errors.WithStack(status.New(status.EndorserClientStatus, status.SignatureVerificationFailed.ToInt32(), "the creator's signature over the proposal is not valid", []interface{}{err.Error()}))
 	}
@@ -59,6 +83,95 @@ func (v *Signature) Verify(response *fab.TransactionProposalResponse) error {
 	return nil
 }
 
+// VerifyBatch checks the transaction proposal responses concurrently, with at most
+// Workers verifications in flight. The failures of all the endorsers are reported in
+// one aggregated error (see multi.Errors), in the order of the responses.
+func (v *Signature) VerifyBatch(responses []*fab.TransactionProposalResponse) error {
+	workers := v.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(responses) {
+		workers = len(responses)
+	}
+
+	errs := make([]error, len(responses))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := v.Verify(responses[i]); err != nil {
+					errs[i] = errors.WithMessage(err, "invalid endorsement from "+responses[i].Endorser)
+				}
+			}
+		}()
+	}
+	for i := range responses {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return multi.New(errs...)
+}
+
+func (v *Signature) verifySignature(creatorID, msg, signature []byte) error {
+	if v.SM2UIDs == nil {
+		return v.Membership.Verify(creatorID, msg, signature)
+	}
+
+	pub, uid, ok := v.sm2Signer(creatorID)
+	if !ok {
+		return v.Membership.Verify(creatorID, msg, signature)
+	}
+	r, s, err := sm2.SignDataToSignDigit(signature)
+	if err != nil {
+		return errors.Wrap(err, "failed to unmarshal SM2 signature")
+	}
+	if !sm2.Sm2Verify(pub, msg, uid, r, s) {
+		return errors.New("SM2 signature verification failed")
+	}
+	return nil
+}
+
+// sm2Signer returns the SM2 public key and the user ID of the serialized identity,
+// if it has an SM2 certificate and a user ID is configured for it
+func (v *Signature) sm2Signer(serializedID []byte) (*sm2.PublicKey, []byte, bool) {
+	mspID, cert, err := v.certificate(serializedID)
+	if err != nil || cert.Algorithm != SM2 {
+		return nil, nil, false
+	}
+	uid, ok := v.SM2UIDs.SM2UID(mspID, cert.Subject.CommonName)
+	if !ok {
+		return nil, nil, false
+	}
+	return cert.PublicKey.(*sm2.PublicKey), uid, true
+}
+
+// certificate returns the MSP ID and the certificate of the serialized identity,
+// from the membership if it caches them
+func (v *Signature) certificate(serializedID []byte) (string, *Certificate, error) {
+	if cache, ok := v.Membership.(CertificateCache); ok {
+		return cache.Certificate(serializedID)
+	}
+	sID := &mspprotos.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedID, sID); err != nil {
+		return "", nil, errors.Wrap(err, "could not deserialize a SerializedIdentity")
+	}
+	block, _ := pem.Decode(sID.IdBytes)
+	if block == nil {
+		return "", nil, errors.New("could not decode the PEM structure")
+	}
+	cert, err := ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", nil, err
+	}
+	return sID.Mspid, cert, nil
+}
+
 // Match matches transaction proposal responses (empty for signature verifier)
 func (v *Signature) Match(response []*fab.TransactionProposalResponse) error {
 	return nil
@@ -69,45 +182,22 @@ func ValidateCertificateDates(cert *sm2.Certificate) error {
 	if cert == nil {
 		return nil
 	}
-	if time.Now().UTC().Before(cert.NotBefore) {
-		return errors.New("Certificate provided is not valid until later date")
-	}
-
-	if time.Now().UTC().After(cert.NotAfter) {
-		return errors.New("Certificate provided has expired")
-	}
-	return nil
+	return validateDates(cert.NotBefore, cert.NotAfter)
 }
-//ValidateCertificateDates used to verify if certificate was expired or not valid until later date
+
+//ValidateTLSCertificateDates used to verify if certificate was expired or not valid until later date
 func ValidateTLSCertificateDates(cert *x509.Certificate) error {
 	if cert == nil {
 		return nil
 	}
-	if time.Now().UTC().Before(cert.NotBefore) {
-		return errors.New("Certificate provided is not valid until later date")
-	}
-	
-	if time.Now().UTC().After(cert.NotAfter) {
-		return errors.New("Certificate provided has expired")
-	}
-	return nil
+	return validateDates(cert.NotBefore, cert.NotAfter)
 }
 
-//VerifyPeerCertificate verifies raw certs and chain certs for expiry and not yet valid dates
+//VerifyPeerCertificate verifies raw certs and chain certs for expiry and not yet valid dates.
+//Raw certs may be ECDSA, RSA or SM2 certs.
 func VerifyPeerCertificate(rawCerts [][]byte, verifiedChains [][]*sm2.Certificate) error {
-	for _, chaincert := range rawCerts {
-		cert, err := utils.DERToSM2Certificate(chaincert)
-		if err != nil {
-			logger.Warn("Got error while verifying cert")
-		}
-		if cert != nil {
-			err = ValidateCertificateDates(cert)
-			if err != nil {
-				//cert is expired or not valid
-				logger.Warn(err.Error())
-				return err
-			}
-		}
+	if err := verifyRawCertificates(rawCerts); err != nil {
+		return err
 	}
 	for _, certs := range verifiedChains {
 		for _, cert := range certs {
@@ -121,21 +211,12 @@ func VerifyPeerCertificate(rawCerts [][]byte, verifiedChains [][]*sm2.Certificat
 	}
 	return nil
 }
-//VerifyPeerCertificate verifies raw certs and chain certs for expiry and not yet valid dates
+
+//VerifyTLSPeerCertificate verifies raw certs and chain certs for expiry and not yet valid dates.
+//Raw certs may be ECDSA, RSA or SM2 certs.
 func VerifyTLSPeerCertificate(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
-	for _, chaincert := range rawCerts {
-		cert, err := utils.DERToX509Certificate(chaincert)
-		if err != nil {
-			logger.Warn("Got error while verifying cert")
-		}
-		if cert != nil {
-			err = ValidateTLSCertificateDates(cert)
-			if err != nil {
-				//cert is expired or not valid
-				logger.Warn(err.Error())
-				return err
-			}
-		}
+	if err := verifyRawCertificates(rawCerts); err != nil {
+		return err
 	}
 	for _, certs := range verifiedChains {
 		for _, cert := range certs {
@@ -149,3 +230,20 @@ func VerifyTLSPeerCertificate(rawCerts [][]byte, verifiedChains [][]*x509.Certif
 	}
 	return nil
 }
+
+func verifyRawCertificates(rawCerts [][]byte) error {
+	for _, chaincert := range rawCerts {
+		cert, err := ParseCertificate(chaincert)
+		if err != nil {
+			logger.Warn("Got error while verifying cert")
+			continue
+		}
+		err = cert.ValidateDates()
+		if err != nil {
+			//cert is expired or not valid
+			logger.Warn(err.Error())
+			return err
+		}
+	}
+	return nil
+}
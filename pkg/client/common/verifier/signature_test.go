@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifier
+
+import (
+	"encoding/pem"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	mspprotos "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMembership validates every identity and rejects every signature
+type testMembership struct {
+	fab.ChannelMembership
+}
+
+func (m *testMembership) Validate(serializedID []byte) error {
+	return nil
+}
+
+func (m *testMembership) Verify(serializedID []byte, msg []byte, sig []byte) error {
+	return errors.New("membership verification failed")
+}
+
+type testSM2UIDs map[string][]byte
+
+func (u testSM2UIDs) SM2UID(mspID, id string) ([]byte, bool) {
+	uid, ok := u[mspID+"/"+id]
+	return uid, ok
+}
+
+func newEndorsedResponse(t *testing.T, uid []byte) *fab.TransactionProposalResponse {
+	endorser := newCert(t, "peer0", SM2, false, time.Now().Add(time.Hour), nil)
+	idBytes, err := proto.Marshal(&mspprotos.SerializedIdentity{
+		Mspid:   "Org1MSP",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: endorser.cert.Raw}),
+	})
+	require.NoError(t, err)
+
+	payload := []byte("payload")
+	r, s, err := sm2.Sm2Sign(endorser.key.(*sm2.PrivateKey), append(payload, idBytes...), uid)
+	require.NoError(t, err)
+	signature, err := sm2.SignDigitToSignData(r, s)
+	require.NoError(t, err)
+
+	return &fab.TransactionProposalResponse{ProposalResponse: &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200},
+		Payload:     payload,
+		Endorsement: &pb.Endorsement{Endorser: idBytes, Signature: signature},
+	}}
+}
+
+func TestVerifySM2UID(t *testing.T) {
+	uid := []byte("peer0@org1")
+	response := newEndorsedResponse(t, uid)
+
+	v := &Signature{Membership: &testMembership{}, SM2UIDs: testSM2UIDs{"Org1MSP/peer0": uid}}
+	assert.NoError(t, v.Verify(response))
+
+	// another user ID does not verify
+	v.SM2UIDs = testSM2UIDs{"Org1MSP/peer0": []byte("other")}
+	assert.Error(t, v.Verify(response))
+
+	// without a user ID the membership verifies the signature
+	v.SM2UIDs = testSM2UIDs{}
+	s, ok := status.FromError(v.Verify(response))
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"membership verification failed"}, s.Details)
+}
+
+func TestSM2SignerFallback(t *testing.T) {
+	v := &Signature{Membership: &testMembership{}, SM2UIDs: testSM2UIDs{"Org1MSP/peer0": []byte("uid")}}
+
+	_, _, ok := v.sm2Signer([]byte("not a serialized identity"))
+	assert.False(t, ok)
+
+	// user IDs only apply to SM2 identities
+	endorser := newCert(t, "peer0", ECDSA, false, time.Now().Add(time.Hour), nil)
+	idBytes, err := proto.Marshal(&mspprotos.SerializedIdentity{
+		Mspid:   "Org1MSP",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: endorser.cert.Raw}),
+	})
+	require.NoError(t, err)
+	_, _, ok = v.sm2Signer(idBytes)
+	assert.False(t, ok)
+}
+
+// batchMembership rejects the signatures of the endorsers in invalid and records the
+// highest number of concurrent verifications
+type batchMembership struct {
+	fab.ChannelMembership
+	invalid map[string]bool
+
+	mtx      sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (m *batchMembership) Validate(serializedID []byte) error {
+	return nil
+}
+
+func (m *batchMembership) Verify(serializedID []byte, msg []byte, sig []byte) error {
+	m.mtx.Lock()
+	m.inFlight++
+	if m.inFlight > m.max {
+		m.max = m.inFlight
+	}
+	m.mtx.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	m.mtx.Lock()
+	m.inFlight--
+	m.mtx.Unlock()
+
+	if m.invalid[string(serializedID)] {
+		return errors.New("bad signature")
+	}
+	return nil
+}
+
+func newBatchResponse(endorser string) *fab.TransactionProposalResponse {
+	return &fab.TransactionProposalResponse{
+		Endorser: endorser,
+		ProposalResponse: &pb.ProposalResponse{
+			Response:    &pb.Response{Status: 200},
+			Endorsement: &pb.Endorsement{Endorser: []byte(endorser), Signature: []byte("sig")},
+		},
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	var responses []*fab.TransactionProposalResponse
+	for _, endorser := range []string{"peer0", "peer1", "peer2", "peer3", "peer4", "peer5", "peer6", "peer7"} {
+		responses = append(responses, newBatchResponse(endorser))
+	}
+
+	membership := &batchMembership{invalid: map[string]bool{}}
+	v := &Signature{Membership: membership, Workers: 3}
+	assert.NoError(t, v.VerifyBatch(responses))
+	assert.Equal(t, 3, membership.max, "verifications should run concurrently, bounded by Workers")
+	assert.NoError(t, v.VerifyBatch(nil))
+
+	// every failed endorser is reported, in the order of the responses
+	membership.invalid = map[string]bool{"peer6": true, "peer2": true}
+	err := v.VerifyBatch(responses)
+	errs, ok := err.(multi.Errors)
+	require.True(t, ok, "expected multi.Errors, got %v", err)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "invalid endorsement from peer2")
+	assert.Contains(t, errs[1].Error(), "invalid endorsement from peer6")
+	s, ok := status.FromError(errs[0])
+	require.True(t, ok)
+	assert.Equal(t, status.SignatureVerificationFailed.ToInt32(), s.Code)
+
+	// a single failure is returned as is
+	membership.invalid = map[string]bool{"peer4": true}
+	err = v.VerifyBatch(responses)
+	_, ok = err.(multi.Errors)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), "invalid endorsement from peer4")
+}
+
+// certCache serves the certificates of its identities
+type certCache struct {
+	testMembership
+	certs map[string]*Certificate
+}
+
+func (c *certCache) Certificate(serializedID []byte) (string, *Certificate, error) {
+	cert, ok := c.certs[string(serializedID)]
+	if !ok {
+		return "", nil, errors.New("unknown identity")
+	}
+	return "Org1MSP", cert, nil
+}
+
+func TestVerifyWithCertificateCache(t *testing.T) {
+	uid := []byte("peer0@org1")
+	response := newEndorsedResponse(t, uid)
+
+	sID := &mspprotos.SerializedIdentity{}
+	require.NoError(t, proto.Unmarshal(response.ProposalResponse.Endorsement.Endorser, sID))
+	block, _ := pem.Decode(sID.IdBytes)
+	cert, err := ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	cache := &certCache{certs: map[string]*Certificate{string(response.ProposalResponse.Endorsement.Endorser): cert}}
+	v := &Signature{Membership: cache, SM2UIDs: testSM2UIDs{"Org1MSP/peer0": uid}}
+	assert.NoError(t, v.Verify(response))
+
+	// the identity is looked up in the cache only
+	cache.certs = map[string]*Certificate{}
+	assert.Error(t, v.Verify(response))
+}
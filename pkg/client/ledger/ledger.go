@@ -76,7 +76,13 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 		return nil, errors.WithMessage(err, "membership creation failed")
 	}
 
-	ledger, err := channel.NewLedger(channelContext.ChannelID())
+	chConfig, err := channelContext.ChannelService().ChannelConfig()
+	if err != nil {
+		return nil, errors.WithMessage(err, "channel config retrieval failed")
+	}
+
+	// queries are signed with the hash algorithm of the channel
+	ledger, err := channel.NewLedger(channelContext.ChannelID(), channel.WithHashingAlgorithm(chConfig.HashingAlgorithm()))
 	if err != nil {
 		return nil, err
 	}
@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 
 package msp
 
+import "time"
+
 // AttributeRequest is a request for an attribute.
 type AttributeRequest struct {
 	Name     string
@@ -73,6 +75,65 @@ type RevokedCert struct {
 	AKI string
 }
 
+// GetCertificatesRequest defines the filters of the certificates to retrieve from the CA.
+// If neither ID nor AKI and Serial are provided, all certificates in or under the
+// affiliation of the registrar are returned.
+type GetCertificatesRequest struct {
+	// ID is the enrollment ID of the identity whose certificates are retrieved
+	ID string
+	// AKI (Authority Key Identifier) of the certificate to retrieve
+	AKI string
+	// Serial number of the certificate to retrieve
+	Serial string
+	// Revoked restricts the certificates to the ones revoked within the time range
+	Revoked TimeRange
+	// Expired restricts the certificates to the ones expiring within the time range
+	Expired TimeRange
+	// NotExpired excludes expired certificates
+	NotExpired bool
+	// NotRevoked excludes revoked certificates
+	NotRevoked bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// TimeRange is a range of time. The start and end times are either RFC3339
+// timestamps or durations relative to the current time (e.g. -30d, -1h),
+// an empty start or end time leaves the range open.
+type TimeRange struct {
+	StartTime string
+	EndTime   string
+}
+
+// GetCertificatesResponse is the response from the server for a get certificates request
+type GetCertificatesResponse struct {
+	// CAName is the name of the CA
+	CAName string
+	// Certs are the PEM-encoded certificates
+	Certs [][]byte
+}
+
+// GenCRLRequest defines the attributes required to generate a CRL with the CA.
+// Zero times leave the corresponding bounds open.
+type GenCRLRequest struct {
+	// RevokedAfter includes the certificates revoked after this time
+	RevokedAfter time.Time
+	// RevokedBefore includes the certificates revoked before this time
+	RevokedBefore time.Time
+	// ExpireAfter includes the certificates expiring after this time
+	ExpireAfter time.Time
+	// ExpireBefore includes the certificates expiring before this time
+	ExpireBefore time.Time
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// GenCRLResponse represents response from the server for a generate CRL request
+type GenCRLResponse struct {
+	// CRL is PEM-encoded certificate revocation list (CRL) that contains the requested unexpired revoked certificates
+	CRL []byte
+}
+
 // IdentityRequest represents the request to add/update identity to the fabric-ca-server
 type IdentityRequest struct {
 
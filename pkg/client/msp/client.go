@@ -17,9 +17,11 @@ package msp
 
 import (
 	"fmt"
+	"time"
 
 	"strings"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
@@ -32,6 +34,7 @@ type Client struct {
 	orgName string
 	caName  string
 	ctx     context.Client
+	caOpts  []msp.CAClientOption
 }
 
 // ClientOption describes a functional parameter for the New constructor
@@ -45,6 +48,27 @@ func WithOrg(orgName string) ClientOption {
 	}
 }
 
+// WithCARetry sets the retry options of the requests which could not reach any of the URLs of the CA.
+// By default such requests are not retried.
+func WithCARetry(opts retry.Opts) ClientOption {
+	return func(c *Client) error {
+		c.caOpts = append(c.caOpts, msp.WithRetry(opts))
+		return nil
+	}
+}
+
+// WithCAGreylistExpiry sets the time for which a URL of the CA that could not be reached is skipped
+// in favour of the other URLs of the CA (10 seconds by default)
+func WithCAGreylistExpiry(expiry time.Duration) ClientOption {
+	return func(c *Client) error {
+		if expiry < 0 {
+			return errors.New("CA greylist expiry must not be negative")
+		}
+		c.caOpts = append(c.caOpts, msp.WithGreylistExpiry(expiry))
+		return nil
+	}
+}
+
 // opts allows the user to specify more advanced request options
 type requestOptions struct {
 	CA string
@@ -100,9 +124,9 @@ func New(clientProvider context.ClientProvider, opts ...ClientOption) (*Client,
 	return &msp, nil
 }
 
-func newCAClient(ctx context.Client, orgName string) (mspapi.CAClient, error) {
+func newCAClient(ctx context.Client, orgName string, opts ...msp.CAClientOption) (mspapi.CAClient, error) {
 
-	caClient, err := msp.NewCAClient(orgName, ctx)
+	caClient, err := msp.NewCAClient(orgName, ctx, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to create CA Client")
 	}
@@ -117,6 +141,7 @@ type enrollmentOptions struct {
 	label    string
 	typ      string
 	attrReqs []*AttributeRequest
+	keyAlgo  string
 }
 
 // EnrollmentOption describes a functional parameter for Enroll
@@ -162,6 +187,18 @@ func WithAttributeRequests(attrReqs []*AttributeRequest) EnrollmentOption {
 	}
 }
 
+// WithKeyAlgorithm enrollment option, the algorithm of the key of the
+// certificate: ecdsa (default) or sm2, which requires a GM crypto suite.
+// On re-enrollment, the default is the algorithm of the current key.
+// Keys of remote signers (cncc_gm) are sm2 keys, their certificate is
+// uploaded to the remote signer once issued
+func WithKeyAlgorithm(algorithm string) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		o.keyAlgo = algorithm
+		return nil
+	}
+}
+
 // CreateIdentity creates a new identity with the Fabric CA server. An enrollment secret is returned which can then be used,
 // along with the enrollment ID, to enroll a new identity.
 //  Parameters:
@@ -171,7 +208,7 @@ func WithAttributeRequests(attrReqs []*AttributeRequest) EnrollmentOption {
 //  Return identity info including the secret
 func (c *Client) CreateIdentity(request *IdentityRequest) (*IdentityResponse, error) {
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +244,7 @@ func (c *Client) CreateIdentity(request *IdentityRequest) (*IdentityResponse, er
 //  Return updated identity info
 func (c *Client) ModifyIdentity(request *IdentityRequest) (*IdentityResponse, error) {
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +280,7 @@ func (c *Client) ModifyIdentity(request *IdentityRequest) (*IdentityResponse, er
 //  Return removed identity info
 func (c *Client) RemoveIdentity(request *RemoveIdentityRequest) (*IdentityResponse, error) {
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -275,7 +312,7 @@ func (c *Client) GetAllIdentities(options ...RequestOption) ([]*IdentityResponse
 		return nil, err
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -304,7 +341,7 @@ func (c *Client) GetIdentity(ID string, options ...RequestOption) (*IdentityResp
 		return nil, err
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -367,17 +404,18 @@ func (c *Client) Enroll(enrollmentID string, opts ...EnrollmentOption) error {
 		}
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return err
 	}
 
 	req := &mspapi.EnrollmentRequest{
-		Name:    enrollmentID,
-		Secret:  eo.secret,
-		Profile: eo.profile,
-		Type:    eo.typ,
-		Label:   eo.label,
+		Name:         enrollmentID,
+		Secret:       eo.secret,
+		Profile:      eo.profile,
+		Type:         eo.typ,
+		Label:        eo.label,
+		KeyAlgorithm: eo.keyAlgo,
 	}
 
 	if len(eo.attrReqs) > 0 {
@@ -406,15 +444,16 @@ func (c *Client) Reenroll(enrollmentID string, opts ...EnrollmentOption) error {
 		}
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return err
 	}
 
 	req := &mspapi.ReenrollmentRequest{
-		Name:    enrollmentID,
-		Profile: eo.profile,
-		Label:   eo.label,
+		Name:         enrollmentID,
+		Profile:      eo.profile,
+		Label:        eo.label,
+		KeyAlgorithm: eo.keyAlgo,
 	}
 	if len(eo.attrReqs) > 0 {
 		attrs := make([]*mspapi.AttributeRequest, 0)
@@ -433,7 +472,7 @@ func (c *Client) Reenroll(enrollmentID string, opts ...EnrollmentOption) error {
 //  Returns:
 //  enrolment secret
 func (c *Client) Register(request *RegistrationRequest) (string, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return "", err
 	}
@@ -462,7 +501,7 @@ func (c *Client) Register(request *RegistrationRequest) (string, error) {
 //  Returns:
 //  revocation response
 func (c *Client) Revoke(request *RevocationRequest) (*RevocationResponse, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -487,9 +526,68 @@ func (c *Client) Revoke(request *RevocationRequest) (*RevocationResponse, error)
 	}, nil
 }
 
+// GetCertificates retrieves the certificates matching the filter from the CA
+//  Parameters:
+//  request holds the certificate filters, all certificates the registrar is authorized to see are returned if nil
+//
+//  Returns:
+//  the PEM-encoded certificates
+func (c *Client) GetCertificates(request *GetCertificatesRequest) (*GetCertificatesResponse, error) {
+	if request == nil {
+		request = &GetCertificatesRequest{}
+	}
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &mspapi.GetCertificatesRequest{
+		ID:         request.ID,
+		AKI:        request.AKI,
+		Serial:     request.Serial,
+		Revoked:    mspapi.TimeRange(request.Revoked),
+		Expired:    mspapi.TimeRange(request.Expired),
+		NotExpired: request.NotExpired,
+		NotRevoked: request.NotRevoked,
+		CAName:     request.CAName,
+	}
+	resp, err := ca.GetCertificates(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetCertificatesResponse{CAName: resp.CAName, Certs: resp.Certs}, nil
+}
+
+// GenCRL generates a certificate revocation list with the CA
+//  Parameters:
+//  request restricts the revoked certificates of the CRL, all unexpired revoked certificates are included if nil
+//
+//  Returns:
+//  the PEM-encoded CRL
+func (c *Client) GenCRL(request *GenCRLRequest) (*GenCRLResponse, error) {
+	if request == nil {
+		request = &GenCRLRequest{}
+	}
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := mspapi.GenCRLRequest(*request)
+	resp, err := ca.GenCRL(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GenCRLResponse{CRL: resp.CRL}, nil
+}
+
 // GetCAInfo returns generic CA information
 func (c *Client) GetCAInfo() (*GetCAInfoResponse, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -546,7 +644,7 @@ func (c *Client) GetAffiliation(affiliation string, options ...RequestOption) (*
 		return nil, err
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -570,7 +668,7 @@ func (c *Client) GetAllAffiliations(options ...RequestOption) (*AffiliationRespo
 		return nil, err
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -588,7 +686,7 @@ func (c *Client) GetAllAffiliations(options ...RequestOption) (*AffiliationRespo
 
 // AddAffiliation adds a new affiliation to the server
 func (c *Client) AddAffiliation(request *AffiliationRequest) (*AffiliationResponse, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -612,7 +710,7 @@ func (c *Client) AddAffiliation(request *AffiliationRequest) (*AffiliationRespon
 
 // ModifyAffiliation renames an existing affiliation on the server
 func (c *Client) ModifyAffiliation(request *ModifyAffiliationRequest) (*AffiliationResponse, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -639,7 +737,7 @@ func (c *Client) ModifyAffiliation(request *ModifyAffiliationRequest) (*Affiliat
 
 // RemoveAffiliation removes an existing affiliation from the server
 func (c *Client) RemoveAffiliation(request *AffiliationRequest) (*AffiliationResponse, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -15,7 +15,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
@@ -253,6 +255,22 @@ func WithClientOptionError() ClientOption {
 	}
 }
 
+// TestNewWithCAOptions tests the options of the CA client
+func TestNewWithCAOptions(t *testing.T) {
+	msp, err := New(mockClientProvider(), WithCARetry(retry.DefaultCAClientOpts), WithCAGreylistExpiry(time.Minute))
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+	if len(msp.caOpts) != 2 {
+		t.Fatalf("Expected 2 CA client options, got %d", len(msp.caOpts))
+	}
+
+	_, err = New(mockClientProvider(), WithCAGreylistExpiry(-time.Second))
+	if err == nil {
+		t.Fatal("Should have failed due to negative greylist expiry")
+	}
+}
+
 func TestRegister(t *testing.T) {
 	f := testFixture{}
 	sdk := f.setup()
@@ -295,6 +313,54 @@ func TestRevoke(t *testing.T) {
 
 }
 
+// TestGetCertificates tests retrieving certificates
+func TestGetCertificates(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	resp, err := msp.GetCertificates(&GetCertificatesRequest{ID: "123", NotRevoked: true})
+	if err != nil {
+		t.Fatalf("GetCertificates return error %s", err)
+	}
+	if len(resp.Certs) != 1 {
+		t.Fatalf("expecting %d, got %d certificates", 1, len(resp.Certs))
+	}
+
+	resp, err = msp.GetCertificates(&GetCertificatesRequest{ID: "abc"})
+	if err != nil {
+		t.Fatalf("GetCertificates return error %s", err)
+	}
+	if len(resp.Certs) != 0 {
+		t.Fatalf("expecting no certificates, got %d", len(resp.Certs))
+	}
+}
+
+// TestGenCRL tests generating a CRL
+func TestGenCRL(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	resp, err := msp.GenCRL(&GenCRLRequest{RevokedAfter: time.Now().Add(-24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("GenCRL return error %s", err)
+	}
+	if len(resp.CRL) == 0 {
+		t.Fatal("expecting a CRL")
+	}
+}
+
 // TestCreateIdentityFailure tests failures in CreateIdentity
 func TestCreateIdentityFailure(t *testing.T) {
 
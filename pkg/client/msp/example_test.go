@@ -7,6 +7,7 @@ package msp
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cloudflare/cfssl/log"
 	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
@@ -499,6 +500,42 @@ func ExampleClient_GetAllIdentities() {
 	// Output: 2 identities retrieved
 }
 
+func ExampleClient_NewRenewalManager() {
+
+	ctx := mockClientProvider()
+
+	// Create msp client
+	c, err := New(ctx)
+	if err != nil {
+		fmt.Println("failed to create msp client")
+		return
+	}
+
+	username := randomUsername()
+
+	err = c.Enroll(username, WithSecret("enrollmentSecret"))
+	if err != nil {
+		fmt.Printf("failed to enroll user: %s\n", err)
+		return
+	}
+
+	manager, err := c.NewRenewalManager(WithRenewalWindow(24*time.Hour), WithRenewalHandler(func(event *RenewalEvent) {
+		// contexts created with the previous identity of event.ID should be recreated here
+	}))
+	if err != nil {
+		fmt.Printf("failed to create renewal manager: %s\n", err)
+		return
+	}
+
+	manager.Track(username)
+	manager.Start()
+	defer manager.Stop()
+
+	fmt.Println("renewal manager started")
+
+	// Output: renewal manager started
+}
+
 func mockClientProvider() context.ClientProvider {
 	log.SetLogger(nil)
 	f := testFixture{}
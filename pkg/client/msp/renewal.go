@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"time"
+
+	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	"github.com/pkg/errors"
+)
+
+// RenewalEvent reports the renewal of the enrollment certificate of an identity
+type RenewalEvent struct {
+	ID    string
+	MSPID string
+	// NotAfter is the expiry of the new certificate, or of the current one if the renewal failed
+	NotAfter time.Time
+	// Identity is the re-enrolled signing identity, nil if the renewal failed.
+	// Contexts created with the previous identity have to be recreated.
+	Identity mspctx.SigningIdentity
+	// Err is the error of a failed renewal
+	Err error
+}
+
+type renewalOptions struct {
+	opts []msp.RenewalOption
+}
+
+// RenewalOption describes a functional parameter for NewRenewalManager
+type RenewalOption func(*renewalOptions) error
+
+// WithRenewalWindow sets the time before expiry from which enrollment certificates are renewed (7 days by default)
+func WithRenewalWindow(window time.Duration) RenewalOption {
+	return func(o *renewalOptions) error {
+		o.opts = append(o.opts, msp.WithRenewalWindow(window))
+		return nil
+	}
+}
+
+// WithRenewalCheckInterval sets the interval at which the expiry of enrollment certificates is checked (1 hour by default)
+func WithRenewalCheckInterval(interval time.Duration) RenewalOption {
+	return func(o *renewalOptions) error {
+		o.opts = append(o.opts, msp.WithRenewalCheckInterval(interval))
+		return nil
+	}
+}
+
+// WithKeyRotation sets whether a new key is generated on renewal (the default),
+// or the certificate is renewed for the current key
+func WithKeyRotation(rotate bool) RenewalOption {
+	return func(o *renewalOptions) error {
+		o.opts = append(o.opts, msp.WithKeyRotation(rotate))
+		return nil
+	}
+}
+
+// WithRenewalHandler registers a handler which is notified of every renewal,
+// e.g. to recreate the SDK contexts of the renewed identity
+func WithRenewalHandler(handler func(event *RenewalEvent)) RenewalOption {
+	return func(o *renewalOptions) error {
+		if handler == nil {
+			return errors.New("renewal handler is nil")
+		}
+		o.opts = append(o.opts, msp.WithRenewalHandler(func(event *msp.RenewalEvent) {
+			handler(getRenewalEvent(event))
+		}))
+		return nil
+	}
+}
+
+// RenewalManager re-enrolls tracked identities before their enrollment certificate expires.
+// Renewed certificates are persisted to the user store, so GetSigningIdentity returns
+// the renewed identity afterwards.
+type RenewalManager struct {
+	manager *msp.RenewalManager
+}
+
+// NewRenewalManager creates a renewal manager of the identities enrolled with this client.
+// Renewal is opt-in: identities have to be tracked and the manager started.
+//  Parameters:
+//  opts are optional renewal options
+//
+//  Returns:
+//  the renewal manager
+func (c *Client) NewRenewalManager(opts ...RenewalOption) (*RenewalManager, error) {
+	ro := renewalOptions{}
+	for _, param := range opts {
+		err := param(&ro)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to create renewal manager")
+		}
+	}
+
+	caClient, err := msp.NewCAClient(c.orgName, c.ctx, c.caOpts...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create CA Client")
+	}
+
+	manager, err := msp.NewRenewalManager(caClient, ro.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &RenewalManager{manager: manager}, nil
+}
+
+// Track adds identities to renew
+func (m *RenewalManager) Track(enrollmentIDs ...string) {
+	m.manager.Track(enrollmentIDs...)
+}
+
+// Untrack stops renewing an identity
+func (m *RenewalManager) Untrack(enrollmentID string) {
+	m.manager.Untrack(enrollmentID)
+}
+
+// Start checks the tracked identities now and then at every check interval, until Stop is called
+func (m *RenewalManager) Start() {
+	m.manager.Start()
+}
+
+// Stop stops the periodic checks
+func (m *RenewalManager) Stop() {
+	m.manager.Stop()
+}
+
+// Renew re-enrolls the tracked identities whose certificate expires within the renewal window
+//  Returns:
+//  the renewal events, handlers have been notified of them
+func (m *RenewalManager) Renew() []*RenewalEvent {
+	events := m.manager.Renew()
+	result := make([]*RenewalEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, getRenewalEvent(event))
+	}
+	return result
+}
+
+// NotAfter returns the expiry of the stored enrollment certificate of the identity
+func (m *RenewalManager) NotAfter(enrollmentID string) (time.Time, error) {
+	return m.manager.NotAfter(enrollmentID)
+}
+
+func getRenewalEvent(event *msp.RenewalEvent) *RenewalEvent {
+	return &RenewalEvent{
+		ID:       event.ID,
+		MSPID:    event.MSPID,
+		NotAfter: event.NotAfter,
+		Identity: event.Identity,
+		Err:      event.Err,
+	}
+}
@@ -43,7 +43,6 @@ import (
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
-	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/pkg/errors"
 )
@@ -754,10 +753,10 @@ func (rc *Client) getCCProposalTargets(channelID string, opts requestOptions) ([
 }
 
 // createTP
-func (rc *Client) createTP(req InstantiateCCRequest, channelID string, ccProposalType chaincodeProposalType) (*fab.TransactionProposal, fab.TransactionID, error) {
+func (rc *Client) createTP(req InstantiateCCRequest, channelID string, ccProposalType chaincodeProposalType, transactor fab.Transactor) (*fab.TransactionProposal, fab.TransactionID, error) {
 	deployProposal := chaincodeDeployRequest(req)
 
-	txID, err := txn.NewHeader(rc.ctx, channelID)
+	txID, err := transactor.CreateTransactionHeader()
 	if err != nil {
 		return nil, fab.EmptyTransactionID, errors.WithMessage(err, "create transaction ID failed")
 	}
@@ -777,10 +776,8 @@ func (rc *Client) verifyTPSignature(channelService fab.ChannelService, txProposa
 	}
 
 	sv := &verifier.Signature{Membership: membership}
-	for _, r := range txProposalResponse {
-		if err := sv.Verify(r); err != nil {
-			return errors.WithMessage(err, "Failed to verify signature")
-		}
+	if err := sv.VerifyBatch(txProposalResponse); err != nil {
+		return errors.WithMessage(err, "Failed to verify signature")
 	}
 	return nil
 }
@@ -807,7 +804,7 @@ func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chain
 	}
 
 	// create a transaction proposal for chaincode deployment
-	tp, txnID, err := rc.createTP(req, channelID, ccProposalType)
+	tp, txnID, err := rc.createTP(req, channelID, ccProposalType, transactor)
 	if err != nil {
 		return txnID, err
 	}
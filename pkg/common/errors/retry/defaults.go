@@ -65,6 +65,15 @@ var DefaultResMgmtOpts = Opts{
 	RetryableCodes: ResMgmtDefaultRetryableCodes,
 }
 
+// DefaultCAClientOpts default retry options for the Fabric CA client
+var DefaultCAClientOpts = Opts{
+	Attempts:       DefaultAttempts,
+	InitialBackoff: DefaultInitialBackoff,
+	MaxBackoff:     DefaultMaxBackoff,
+	BackoffFactor:  DefaultBackoffFactor,
+	RetryableCodes: CAClientRetryableCodes,
+}
+
 // DefaultRetryableCodes these are the error codes, grouped by source of error,
 // that are considered to be transient error conditions by default
 var DefaultRetryableCodes = map[status.Group][]status.Code{
@@ -161,6 +170,14 @@ var ChannelClientRetryableCodes = map[status.Group][]status.Code{
 	},
 }
 
+// CAClientRetryableCodes are the suggested codes that should be treated as
+// transient by the Fabric CA client of fabric-sdk-go/pkg/msp
+var CAClientRetryableCodes = map[status.Group][]status.Code{
+	status.ClientStatus: {
+		status.ConnectionFailed,
+	},
+}
+
 // ChannelConfigRetryableCodes error codes to be taken into account for query channel config retry
 var ChannelConfigRetryableCodes = map[status.Group][]status.Code{
 	status.EndorserClientStatus: {status.EndorsementMismatch},
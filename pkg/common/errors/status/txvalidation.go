@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package status
+
+import (
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// TxValidationClass classifies the transaction validation codes by the way a client may react to them
+type TxValidationClass int32
+
+const (
+	// TxValidationValid the transaction is valid
+	TxValidationValid TxValidationClass = iota
+
+	// TxValidationConflict the state read by the transaction was modified before its commit,
+	// the transaction may succeed if it is endorsed again
+	TxValidationConflict
+
+	// TxValidationEndorsement the endorsements of the transaction no longer satisfy the chaincode,
+	// the transaction may succeed if it is endorsed again by the endorsers currently required
+	TxValidationEndorsement
+
+	// TxValidationDuplicate the transaction ID was already committed, the transaction must not be resubmitted
+	TxValidationDuplicate
+
+	// TxValidationInvalid the transaction is malformed or otherwise rejected, it fails again if resubmitted
+	TxValidationInvalid
+)
+
+// TxValidationClassName maps the transaction validation classes to human-readable strings
+var TxValidationClassName = map[int32]string{
+	0: "VALID",
+	1: "CONFLICT",
+	2: "ENDORSEMENT",
+	3: "DUPLICATE",
+	4: "INVALID",
+}
+
+// String representation of the transaction validation class
+func (c TxValidationClass) String() string {
+	if s, ok := TxValidationClassName[int32(c)]; ok {
+		return s
+	}
+	return Unknown.String()
+}
+
+// ToTxValidationClass returns the class of the transaction validation code
+func ToTxValidationClass(code pb.TxValidationCode) TxValidationClass {
+	switch code {
+	case pb.TxValidationCode_VALID:
+		return TxValidationValid
+	case pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_PHANTOM_READ_CONFLICT:
+		return TxValidationConflict
+	case pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE, pb.TxValidationCode_CHAINCODE_VERSION_CONFLICT,
+		pb.TxValidationCode_EXPIRED_CHAINCODE:
+		return TxValidationEndorsement
+	case pb.TxValidationCode_DUPLICATE_TXID:
+		return TxValidationDuplicate
+	default:
+		return TxValidationInvalid
+	}
+}
+
+// TxValidationClassFromError returns the transaction validation class of err if it reports
+// an invalid transaction, otherwise it returns false
+func TxValidationClassFromError(err error) (TxValidationClass, bool) {
+	if err == nil {
+		return TxValidationValid, false
+	}
+	s, ok := FromError(err)
+	if !ok || s.Group != EventServerStatus {
+		return TxValidationValid, false
+	}
+	return ToTxValidationClass(ToTransactionValidationCode(s.Code)), true
+}
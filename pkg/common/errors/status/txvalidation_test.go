@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package status
+
+import (
+	"testing"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToTxValidationClass(t *testing.T) {
+	classes := map[pb.TxValidationCode]TxValidationClass{
+		pb.TxValidationCode_VALID:                      TxValidationValid,
+		pb.TxValidationCode_MVCC_READ_CONFLICT:         TxValidationConflict,
+		pb.TxValidationCode_PHANTOM_READ_CONFLICT:      TxValidationConflict,
+		pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE: TxValidationEndorsement,
+		pb.TxValidationCode_CHAINCODE_VERSION_CONFLICT: TxValidationEndorsement,
+		pb.TxValidationCode_DUPLICATE_TXID:             TxValidationDuplicate,
+		pb.TxValidationCode_BAD_RWSET:                  TxValidationInvalid,
+		pb.TxValidationCode_INVALID_OTHER_REASON:       TxValidationInvalid,
+	}
+	for code, class := range classes {
+		assert.Equal(t, class, ToTxValidationClass(code), "unexpected class for %s", code)
+	}
+
+	assert.Equal(t, "CONFLICT", TxValidationConflict.String())
+	assert.Equal(t, Unknown.String(), TxValidationClass(25999).String())
+}
+
+func TestTxValidationClassFromError(t *testing.T) {
+	_, ok := TxValidationClassFromError(nil)
+	assert.False(t, ok, "nil error should not be classified")
+
+	_, ok = TxValidationClassFromError(errors.New("test"))
+	assert.False(t, ok, "non status error should not be classified")
+
+	_, ok = TxValidationClassFromError(New(EndorserClientStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "test", nil))
+	assert.False(t, ok, "only event server status should be classified")
+
+	err := errors.Wrap(New(EventServerStatus, int32(pb.TxValidationCode_PHANTOM_READ_CONFLICT), "received invalid transaction", nil), "wrapped")
+	class, ok := TxValidationClassFromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, TxValidationConflict, class)
+}
@@ -63,6 +63,44 @@ type CryptoSuite interface {
 	Verify(k Key, signature, digest []byte, opts SignerOpts) (valid bool, err error)
 }
 
+// Encrypter is implemented by crypto suites able to encrypt (e.g. with the SM2 public
+// keys and SM4 keys of the GM suites)
+type Encrypter interface {
+
+	// Encrypt encrypts plaintext using key k.
+	// The opts argument should be appropriate for the algorithm used.
+	Encrypt(k Key, plaintext []byte, opts EncrypterOpts) (ciphertext []byte, err error)
+}
+
+// Decrypter is implemented by crypto suites able to decrypt
+type Decrypter interface {
+
+	// Decrypt decrypts ciphertext using key k.
+	// The opts argument should be appropriate for the algorithm used.
+	Decrypt(k Key, ciphertext []byte, opts DecrypterOpts) (plaintext []byte, err error)
+}
+
+// RemoteKeyManager is implemented by crypto suites whose private keys are held
+// by a remote signer (e.g. the NetSign servers of the cncc_gm suite)
+type RemoteKeyManager interface {
+
+	// BindCertificate binds the PEM or DER encoded certificate to the key pair
+	// of the private key k, the remote signer signs with it from then on.
+	BindCertificate(k Key, cert []byte) error
+
+	// DeleteKeyPair deletes the key pair of the private key k from the remote signer.
+	DeleteKeyPair(k Key) error
+}
+
+// KeyDeriver is implemented by crypto suites able to derive keys (e.g. the SM4 keys
+// derived with HMAC-SM3 or HKDF-SM3 by the GM suites)
+type KeyDeriver interface {
+
+	// KeyDeriv derives a key from k using opts.
+	// The opts argument should be appropriate for the primitive used.
+	KeyDeriv(k Key, opts KeyDerivOpts) (dk Key, err error)
+}
+
 // Key represents a cryptographic key
 type Key interface {
 
@@ -98,6 +136,12 @@ type SignerOpts interface {
 	crypto.SignerOpts
 }
 
+// EncrypterOpts contains options for encrypting with a CSP.
+type EncrypterOpts interface{}
+
+// DecrypterOpts contains options for decrypting with a CSP.
+type DecrypterOpts interface{}
+
 // KeyImportOpts contains options for importing the raw material of a key with a CSP.
 type KeyImportOpts interface {
 
@@ -109,6 +153,17 @@ type KeyImportOpts interface {
 	Ephemeral() bool
 }
 
+// KeyDerivOpts contains options for key-derivation with a CSP.
+type KeyDerivOpts interface {
+
+	// Algorithm returns the key derivation algorithm identifier (to be used).
+	Algorithm() string
+
+	// Ephemeral returns true if the key to derive has to be ephemeral,
+	// false otherwise.
+	Ephemeral() bool
+}
+
 // KeyGenOpts contains options for key-generation with a CSP.
 type KeyGenOpts interface {
 
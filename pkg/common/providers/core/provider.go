@@ -19,6 +19,12 @@ type CryptoSuiteConfig interface {
 	KeyStorePath() string
 }
 
+// KeyStorePasswordProvider is implemented by the crypto suite configs providing the passphrase
+// encrypting the private keys written to the keystore. Keys are stored in plaintext otherwise.
+type KeyStorePasswordProvider interface {
+	KeyStorePassword() ([]byte, error)
+}
+
 // Providers represents the SDK configured core providers context.
 type Providers interface {
 	CryptoSuite() CryptoSuite
@@ -10,3 +10,15 @@ package core
 type SigningManager interface {
 	Sign([]byte, Key) ([]byte, error)
 }
+
+// SigningManagerWithOpts is implemented by the signing managers that can sign
+// with signer options, e.g. with the SM2 user ID of the signing identity
+type SigningManagerWithOpts interface {
+	SignWithOpts(object []byte, key Key, opts SignerOpts) ([]byte, error)
+}
+
+// SigningManagerWithHashOpts is implemented by the signing managers that can hash the
+// object with given hash options, e.g. with the hashing algorithm of a channel
+type SigningManagerWithHashOpts interface {
+	SignWithHashOpts(object []byte, key Key, hashOpts HashOpts, opts SignerOpts) ([]byte, error)
+}
@@ -55,6 +55,7 @@ type ChannelCfg interface {
 	Orderers() []string
 	Versions() *Versions
 	HasCapability(group ConfigGroupKey, capability string) bool
+	HashingAlgorithm() string
 }
 
 // ChannelMembership helps identify a channel's members
@@ -10,6 +10,8 @@ import (
 	"crypto/x509"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 )
 
@@ -30,6 +32,11 @@ type ChannelEndpointConfig struct {
 	Peers map[string]PeerChannelConfig
 	//Policies list of policies for channel
 	Policies ChannelPolicies
+	//HashingAlgorithm overrides the hash algorithm from the channel config (e.g. SHA256 or GMSM3)
+	HashingAlgorithm string
+	//CryptoFamily selects the algorithm family (sw or gm) of a channel of a dual-stack crypto suite, which
+	//sets the default hash algorithm of its transactions (SHA256 or GMSM3) if HashingAlgorithm is not set
+	CryptoFamily string
 }
 
 //ChannelPolicies defines list of policies defined for a channel
@@ -128,6 +135,7 @@ type OrdererConfig struct {
 	URL         string
 	GRPCOptions map[string]interface{}
 	TLSCACert   *x509.Certificate
+	GMTLSCACert *sm2.Certificate
 }
 
 // PeerConfig defines a peer configuration
@@ -135,6 +143,7 @@ type PeerConfig struct {
 	URL         string
 	GRPCOptions map[string]interface{}
 	TLSCACert   *x509.Certificate
+	GMTLSCACert *sm2.Certificate
 }
 
 // CertKeyPair contains the private key and certificate
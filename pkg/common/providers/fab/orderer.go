@@ -20,6 +20,20 @@ type Orderer interface {
 	SendDeliver(ctx reqContext.Context, envelope *SignedEnvelope) (chan *common.Block, chan error)
 }
 
+// BroadcastStreamer is implemented by orderers which can broadcast several envelopes on one stream
+type BroadcastStreamer interface {
+	// NewBroadcastStream opens a broadcast stream, which is closed when Close is called or the context is done
+	NewBroadcastStream(ctx reqContext.Context) (BroadcastStream, error)
+}
+
+// BroadcastStream broadcasts envelopes to an orderer on a long-lived stream
+type BroadcastStream interface {
+	// Send broadcasts the envelope and waits for the status returned by the orderer, or until the context is done
+	Send(ctx reqContext.Context, envelope *SignedEnvelope) (*common.Status, error)
+	// Close closes the stream, the envelopes which have not been answered fail
+	Close()
+}
+
 // A SignedEnvelope can can be sent to an orderer for broadcasting
 type SignedEnvelope struct {
 	Payload   []byte
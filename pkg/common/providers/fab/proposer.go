@@ -19,8 +19,9 @@ type ProposalProcessor interface {
 
 // TxnHeaderOptions contains options for creating a Transaction Header
 type TxnHeaderOptions struct {
-	Nonce   []byte
-	Creator []byte
+	Nonce            []byte
+	Creator          []byte
+	HashingAlgorithm string
 }
 
 // TxnHeaderOpt is a Transaction Header option
@@ -40,6 +41,15 @@ func WithCreator(creator []byte) TxnHeaderOpt {
 	}
 }
 
+// WithHashingAlgorithm specifies the hash algorithm used to compute the transaction ID and to sign its
+// proposal and transaction (e.g. SHA256 or GMSM3). It takes precedence over the hashing algorithm of the
+// channel, from the SDK config or the channel config.
+func WithHashingAlgorithm(algorithm string) TxnHeaderOpt {
+	return func(options *TxnHeaderOptions) {
+		options.HashingAlgorithm = algorithm
+	}
+}
+
 // ProposalSender provides the ability for a transaction proposal to be created and sent.
 type ProposalSender interface {
 	CreateTransactionHeader(opts ...TxnHeaderOpt) (TransactionHeader, error)
@@ -75,6 +85,9 @@ type ChaincodeInvokeRequest struct {
 type TransactionProposal struct {
 	TxnID TransactionID
 	*pb.Proposal
+	// HashingAlgorithm is the hash algorithm of the channel the proposal and its transaction are signed with,
+	// the default hash of the signing manager if empty
+	HashingAlgorithm string
 }
 
 // ProcessProposalRequest requests simulation of a proposed transaction from transaction processors.
@@ -12,6 +12,8 @@ import (
 	"crypto/x509"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
@@ -106,6 +108,13 @@ type EndpointConfig interface {
 	CryptoConfigPath() string
 }
 
+// GMTLSEndpointConfig is implemented by endpoint configs that support
+// GM-TLS (SM2/SM4 dual certificate) connections
+type GMTLSEndpointConfig interface {
+	GMTLSCACerts() []*sm2.Certificate
+	GMTLSClientCerts() []gmtls.Certificate
+}
+
 // TimeoutType enumerates the different types of outgoing connections
 type TimeoutType int
 
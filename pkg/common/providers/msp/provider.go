@@ -62,9 +62,11 @@ type EnrollCredentials struct {
 	EnrollSecret string
 }
 
-// CAConfig defines a CA configuration
+// CAConfig defines a CA configuration.
+// URLs are the endpoints of the CA in failover order, starting with URL.
 type CAConfig struct {
 	URL              string
+	URLs             []string
 	GRPCOptions      map[string]interface{}
 	Registrar        EnrollCredentials
 	CAName           string
@@ -45,6 +45,22 @@ type Channel struct {
 	channelService fab.ChannelService
 	channelID      string
 	metrics        *metrics.ClientMetrics
+	cryptoSuite    core.CryptoSuite
+}
+
+// cryptoFamilies is implemented by crypto suites that support several
+// algorithm families (e.g. the dual-stack crypto suite)
+type cryptoFamilies interface {
+	WithDefaultFamily(family string) (core.CryptoSuite, error)
+}
+
+//CryptoSuite returns the crypto suite of the channel, which uses the crypto family
+//configured for the channel by default
+func (c *Channel) CryptoSuite() core.CryptoSuite {
+	if c.cryptoSuite != nil {
+		return c.cryptoSuite
+	}
+	return c.Client.CryptoSuite()
 }
 
 //Providers returns core providers
@@ -269,11 +285,17 @@ func NewChannel(clientProvider context.ClientProvider, channelID string) (*Chann
 		return nil, errors.WithMessage(err, "failed to get channel service to create channel client")
 	}
 
+	cryptoSuite, err := channelCryptoSuite(client, channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get crypto suite of the crypto family to create channel client")
+	}
+
 	channel := &Channel{
 		Client:         client,
 		channelService: channelService,
 		channelID:      channelID,
 		metrics:        client.GetMetrics(),
+		cryptoSuite:    cryptoSuite,
 	}
 	if pi, ok := channelService.(serviceInit); ok {
 		if err := pi.Initialize(channel); err != nil {
@@ -283,6 +305,22 @@ func NewChannel(clientProvider context.ClientProvider, channelID string) (*Chann
 	return channel, nil
 }
 
+// channelCryptoSuite returns a view of the crypto suite of the client using the crypto family
+// configured for the channel by default, nil if none is configured. The crypto family also selects
+// the default hash algorithm of the transactions of the channel (see txn.ChannelHashingAlgorithm).
+func channelCryptoSuite(client context.Client, channelID string) (core.CryptoSuite, error) {
+	chCfg := client.EndpointConfig().ChannelConfig(channelID)
+	if chCfg == nil || chCfg.CryptoFamily == "" {
+		return nil, nil
+	}
+
+	families, ok := client.CryptoSuite().(cryptoFamilies)
+	if !ok {
+		return nil, errors.Errorf("crypto family [%s] configured for channel [%s] but the crypto suite supports a single family", chCfg.CryptoFamily, channelID)
+	}
+	return families.WithDefaultFamily(chCfg.CryptoFamily)
+}
+
 type reqContextKey string
 
 //ReqContextTimeoutOverrides key for grpc context value of timeout overrides
@@ -11,6 +11,8 @@ import (
 
 	"crypto/x509"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	"github.com/pkg/errors"
@@ -31,19 +33,44 @@ func TLSConfig(cert *x509.Certificate, serverName string, config fab.EndpointCon
 	return &tls.Config{RootCAs: certPool, Certificates: config.TLSClientCerts(), ServerName: serverName}, nil
 }
 
+// GMTLSConfig returns the config for GM-TLS including the SM2 root CAs, the
+// signing and encryption certs for mutual GM-TLS, and server host override.
+func GMTLSConfig(cert *sm2.Certificate, serverName string, config fab.EndpointConfig) (*gmtls.Config, error) {
+
+	certPool := sm2.NewCertPool()
+	if cert != nil {
+		certPool.AddCert(cert)
+	}
+
+	var clientCerts []gmtls.Certificate
+	if gmConfig, ok := config.(fab.GMTLSEndpointConfig); ok {
+		for _, caCert := range gmConfig.GMTLSCACerts() {
+			certPool.AddCert(caCert)
+		}
+		clientCerts = gmConfig.GMTLSClientCerts()
+	}
+
+	if len(certPool.Subjects()) == 0 {
+		return nil, errors.New("no GM-TLS root certificates configured")
+	}
+
+	return &gmtls.Config{GMSupport: gmtls.NewGMSupport(), RootCAs: certPool, Certificates: clientCerts, ServerName: serverName}, nil
+}
+
 // TLSCertHash is a utility method to calculate the SHA256 hash of the configured certificate (for usage in channel headers)
 func TLSCertHash(config fab.EndpointConfig) ([]byte, error) {
 	certs := config.TLSClientCerts()
-	if len(certs) == 0 {
-		return computeHash([]byte(""))
-	}
-
-	cert := certs[0]
-	if len(cert.Certificate) == 0 {
+	if len(certs) == 0 || len(certs[0].Certificate) == 0 {
+		//fall back to the GM-TLS signing cert, if any
+		if gmConfig, ok := config.(fab.GMTLSEndpointConfig); ok {
+			if gmCerts := gmConfig.GMTLSClientCerts(); len(gmCerts) > 0 && len(gmCerts[0].Certificate) > 0 {
+				return computeHash(gmCerts[0].Certificate[0])
+			}
+		}
 		return computeHash([]byte(""))
 	}
 
-	return computeHash(cert.Certificate[0])
+	return computeHash(certs[0].Certificate[0])
 }
 
 //computeHash computes hash for given bytes using underlying cryptosuite default
@@ -130,6 +130,8 @@ func (priv *PrivateKey) Public() crypto.PublicKey {
 // *PSSOptions then the PSS algorithm will be used, otherwise PKCS#1 v1.5 will
 // be used. This method is intended to support keys where the private part is
 // kept in, for example, a hardware module.
+// With the SM2 signer options of cryptosuite.GetSM2SignerOpts, msg is the message
+// itself and is signed with the SM2 user ID of the options.
 func (priv *PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
 	if priv.cryptoSuite == nil {
 		return nil, errors.New("Crypto suite not set")
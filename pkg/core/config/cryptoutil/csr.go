@@ -0,0 +1,185 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptoutil
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"net"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/pkg/errors"
+)
+
+var (
+	oidSignatureSM2WithSM3     = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+	oidExtensionRequest        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14}
+	oidExtensionSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+)
+
+// SAN general name tags, RFC 5280 4.2.1.6
+const (
+	nameTypeEmail = 1
+	nameTypeDNS   = 2
+	nameTypeIP    = 7
+)
+
+// tbsCertificateRequest is the CertificationRequestInfo of PKCS #10 (RFC 2986),
+// the public key is the SubjectPublicKeyInfo returned by the crypto suite
+type tbsCertificateRequest struct {
+	Version       int
+	Subject       asn1.RawValue
+	PublicKey     asn1.RawValue
+	RawAttributes []asn1.RawValue `asn1:"tag:0"`
+}
+
+type certificateRequest struct {
+	TBSCSR             asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+// extensionRequest is the PKCS #9 extensionRequest attribute
+type extensionRequest struct {
+	Type   asn1.ObjectIdentifier
+	Values [][]pkix.Extension `asn1:"set"`
+}
+
+// IsSM2Key returns true if key is a key of the SM2 curve
+func IsSM2Key(key core.Key) bool {
+	pub, err := key.PublicKey()
+	if err != nil {
+		return false
+	}
+	raw, err := pub.Bytes()
+	if err != nil {
+		return false
+	}
+	sm2Pub, err := sm2.ParsePKIXPublicKey(raw)
+	if err != nil {
+		return false
+	}
+	pk, ok := sm2Pub.(*sm2.PublicKey)
+	return ok && pk.Curve == sm2.P256Sm2()
+}
+
+// CreateSM2CertificateRequest creates a PEM encoded PKCS #10 certificate
+// request for the SM2 key. The Subject, DNSNames, EmailAddresses, IPAddresses
+// and ExtraExtensions of the template are used.
+// The request is signed with SM2 over SM3(ZA || request) for the default user ID
+// by the crypto suite, the private key may be held by any suite (gm, cncc...)
+func CreateSM2CertificateRequest(template *sm2.CertificateRequest, key core.Key, cs core.CryptoSuite) ([]byte, error) {
+	if !IsSM2Key(key) {
+		return nil, errors.New("key is not an SM2 key")
+	}
+	pub, err := key.PublicKey()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get public key")
+	}
+	pubBytes, err := pub.Bytes()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to marshal public key")
+	}
+
+	subject, err := asn1.Marshal(template.Subject.ToRDNSequence())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal subject")
+	}
+	attributes, err := csrAttributes(template)
+	if err != nil {
+		return nil, err
+	}
+
+	tbs, err := asn1.Marshal(tbsCertificateRequest{
+		Version:       0, // PKCS #10, RFC 2986
+		Subject:       asn1.RawValue{FullBytes: subject},
+		PublicKey:     asn1.RawValue{FullBytes: pubBytes},
+		RawAttributes: attributes,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal certificate request info")
+	}
+
+	// signed over SM3(ZA || tbs) with the default user ID, as GM/T 0015 CAs verify it
+	signature, err := cs.Sign(key, tbs, cryptosuite.GetSM2SignerOpts(nil))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to sign certificate request")
+	}
+
+	der, err := asn1.Marshal(certificateRequest{
+		TBSCSR:             asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSignatureSM2WithSM3},
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal certificate request")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// csrAttributes returns the extensionRequest attribute of the extensions of
+// the template, if any
+func csrAttributes(template *sm2.CertificateRequest) ([]asn1.RawValue, error) {
+	var extensions []pkix.Extension
+	// a subject alternative name extension of the template takes precedence
+	if (len(template.DNSNames) > 0 || len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0) &&
+		!hasExtension(template.ExtraExtensions, oidExtensionSubjectAltName) {
+		san, err := marshalSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionSubjectAltName, Value: san})
+	}
+	extensions = append(extensions, template.ExtraExtensions...)
+	if len(extensions) == 0 {
+		return nil, nil
+	}
+
+	raw, err := asn1.Marshal(extensionRequest{
+		Type:   oidExtensionRequest,
+		Values: [][]pkix.Extension{extensions},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal extension request")
+	}
+	return []asn1.RawValue{{FullBytes: raw}}, nil
+}
+
+func hasExtension(extensions []pkix.Extension, oid asn1.ObjectIdentifier) bool {
+	for _, ext := range extensions {
+		if ext.Id.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
+
+func marshalSANs(dnsNames, emailAddresses []string, ipAddresses []net.IP) ([]byte, error) {
+	var rawValues []asn1.RawValue
+	for _, name := range dnsNames {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeDNS, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
+	}
+	for _, email := range emailAddresses {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeEmail, Class: asn1.ClassContextSpecific, Bytes: []byte(email)})
+	}
+	for _, rawIP := range ipAddresses {
+		// If possible, we always want to encode IPv4 addresses in 4 bytes.
+		ip := rawIP.To4()
+		if ip == nil {
+			ip = rawIP
+		}
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeIP, Class: asn1.ClassContextSpecific, Bytes: ip})
+	}
+	san, err := asn1.Marshal(rawValues)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal subject alternative names")
+	}
+	return san, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptoutil
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	factory "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/cryptosuitebridge"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/gm"
+)
+
+func TestCreateSM2CertificateRequest(t *testing.T) {
+	cs, err := gm.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to create gm crypto suite: %s", err)
+	}
+	key, err := cs.KeyGen(factory.GetGMSM2KeyGenOpts(true))
+	if err != nil {
+		t.Fatalf("Failed to generate SM2 key: %s", err)
+	}
+	if !IsSM2Key(key) {
+		t.Fatal("Expected an SM2 key")
+	}
+
+	template := &sm2.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "user1", Organization: []string{"org1"}},
+		DNSNames:    []string{"peer0.org1.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	csrPEM, err := CreateSM2CertificateRequest(template, key, cs)
+	if err != nil {
+		t.Fatalf("Failed to create certificate request: %s", err)
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		t.Fatal("Expected a PEM encoded certificate request")
+	}
+	csr, err := sm2.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate request: %s", err)
+	}
+	if csr.Subject.CommonName != "user1" || len(csr.Subject.Organization) != 1 || csr.Subject.Organization[0] != "org1" {
+		t.Fatalf("Unexpected subject: %v", csr.Subject)
+	}
+	if csr.SignatureAlgorithm != sm2.SM2WithSM3 {
+		t.Fatalf("Unexpected signature algorithm: %v", csr.SignatureAlgorithm)
+	}
+
+	pub, ok := csr.PublicKey.(*sm2.PublicKey)
+	if !ok {
+		t.Fatalf("Expected an SM2 public key, got %T", csr.PublicKey)
+	}
+	r, s, err := sm2.SignDataToSignDigit(csr.Signature)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal signature: %s", err)
+	}
+	// GM/T 0015: the request is signed over SM3(ZA || tbs) for the default user ID
+	if !sm2.Sm2Verify(pub, csr.RawTBSCertificateRequest, []byte("1234567812345678"), r, s) {
+		t.Fatal("Certificate request signature verification failed")
+	}
+
+	dnsNames, ips := requestedSANs(t, csr.RawTBSCertificateRequest)
+	if len(dnsNames) != 1 || dnsNames[0] != "peer0.org1.example.com" {
+		t.Fatalf("Unexpected DNS names: %v", dnsNames)
+	}
+	if len(ips) != 1 || !ips[0].Equal(net.ParseIP("127.0.0.1")) {
+		t.Fatalf("Unexpected IP addresses: %v", ips)
+	}
+}
+
+func TestCreateSM2CertificateRequestNotSM2(t *testing.T) {
+	cs := cryptosuite.GetDefault()
+	key, err := cs.KeyGen(factory.GetECDSAP256KeyGenOpts(true))
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %s", err)
+	}
+	if IsSM2Key(key) {
+		t.Fatal("ECDSA key should not be an SM2 key")
+	}
+
+	_, err = CreateSM2CertificateRequest(&sm2.CertificateRequest{Subject: pkix.Name{CommonName: "user1"}}, key, cs)
+	if err == nil {
+		t.Fatal("Should have failed for an ECDSA key")
+	}
+}
+
+// requestedSANs returns the subject alternative names of the extension request of the CSR
+func requestedSANs(t *testing.T, tbs []byte) ([]string, []net.IP) {
+	var info tbsCertificateRequest
+	if _, err := asn1.Unmarshal(tbs, &info); err != nil {
+		t.Fatalf("Failed to unmarshal certificate request info: %s", err)
+	}
+	if len(info.RawAttributes) != 1 {
+		t.Fatalf("Expected one attribute, got %d", len(info.RawAttributes))
+	}
+	var attr extensionRequest
+	if _, err := asn1.Unmarshal(info.RawAttributes[0].FullBytes, &attr); err != nil {
+		t.Fatalf("Failed to unmarshal extension request: %s", err)
+	}
+	if !attr.Type.Equal(oidExtensionRequest) || len(attr.Values) != 1 || len(attr.Values[0]) != 1 {
+		t.Fatalf("Unexpected extension request: %v", attr)
+	}
+	ext := attr.Values[0][0]
+	if !ext.Id.Equal(oidExtensionSubjectAltName) {
+		t.Fatalf("Unexpected extension: %v", ext.Id)
+	}
+
+	var names []asn1.RawValue
+	if _, err := asn1.Unmarshal(ext.Value, &names); err != nil {
+		t.Fatalf("Failed to unmarshal subject alternative names: %s", err)
+	}
+	var dnsNames []string
+	var ips []net.IP
+	for _, name := range names {
+		switch name.Tag {
+		case nameTypeDNS:
+			dnsNames = append(dnsNames, string(name.Bytes))
+		case nameTypeIP:
+			ips = append(ips, net.IP(name.Bytes))
+		}
+	}
+	return dnsNames, ips
+}
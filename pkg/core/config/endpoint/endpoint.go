@@ -14,6 +14,7 @@ import (
 
 	"regexp"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 	"github.com/pkg/errors"
 )
 
@@ -121,3 +122,21 @@ func (cfg *TLSConfig) TLSCert() (*x509.Certificate, bool, error) {
 	//no cert found and there is no error
 	return nil, false, nil
 }
+
+// GMTLSCert returns the tls certificate as a *sm2.Certificate by loading it either from the embedded Pem or Path
+func (cfg *TLSConfig) GMTLSCert() (*sm2.Certificate, bool, error) {
+
+	block, _ := pem.Decode(cfg.bytes)
+
+	if block != nil {
+		pub, err := sm2.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "certificate parsing failed")
+		}
+
+		return pub, true, nil
+	}
+
+	//no cert found and there is no error
+	return nil, false, nil
+}
@@ -7,6 +7,8 @@ package cncc
  * @Date: 2020/10/9 下午3:04
  */
 import (
+	"encoding/pem"
+
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/cncc"
 	bccspSw "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/factory/cncc"
@@ -20,17 +22,20 @@ var logger = logging.NewLogger("fabsdk/core")
 
 //GetSuiteByConfig returns cryptosuite adaptor for bccsp loaded according to given config
 func GetSuiteByConfig(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
-	// TODO: delete this check?
+	// the config must select this suite, whose options (e.g. the NetSign servers) other providers don't configure
 	if config.SecurityProvider() != "cncc_gm" {
 		return nil, errors.Errorf("Unsupported BCCSP Provider: %s", config.SecurityProvider())
 	}
 	
-	opts := getOptsByConfig(config)
+	opts, err := getOptsByConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	bccsp, err := getBCCSPFromOpts(opts)
 	if err != nil {
 		return nil, err
 	}
-	return wrapper.NewCryptoSuite(bccsp), nil
+	return NewCryptoSuite(bccsp), nil
 }
 
 //GetSuiteWithDefaultEphemeral returns cryptosuite adaptor for bccsp with default ephemeral options (intended to aid testing)
@@ -41,7 +46,65 @@ func GetSuiteWithDefaultEphemeral() (core.CryptoSuite, error) {
 	if err != nil {
 		return nil, err
 	}
-	return wrapper.NewCryptoSuite(bccsp), nil
+	return NewCryptoSuite(bccsp), nil
+}
+
+//NewCryptoSuite returns cryptosuite adaptor for the cncc_gm bccsp, it binds certificates to and
+//deletes the key pairs held by the NetSign servers (see core.RemoteKeyManager)
+func NewCryptoSuite(csp bccsp.BCCSP) core.CryptoSuite {
+	keyPairs, ok := csp.(keyPairManager)
+	if !ok {
+		return wrapper.NewCryptoSuite(csp)
+	}
+	return &CryptoSuite{
+		CryptoSuite: &wrapper.CryptoSuite{BCCSP: csp},
+		keyPairs:    keyPairs,
+	}
+}
+
+// closer is implemented by cncc.Impl, which holds the sessions and the health check of the NetSign servers
+type closer interface {
+	Close()
+}
+
+// Close closes the sessions of the NetSign servers and stops their health check,
+// it is called when the SDK is closed
+func (c *CryptoSuite) Close() {
+	if csp, ok := c.BCCSP.(closer); ok {
+		csp.Close()
+	}
+}
+
+// keyPairManager manages the key pairs of the NetSign servers, it is implemented by cncc.Impl
+type keyPairManager interface {
+	Uploadcert(ski []byte, certBytes []byte) error
+	DeleteKeyPair(ski []byte) error
+}
+
+// CryptoSuite is the cncc_gm cryptosuite, SM2 private keys are held by the NetSign servers
+// under the label of their SKI
+type CryptoSuite struct {
+	*wrapper.CryptoSuite
+	keyPairs keyPairManager
+}
+
+// BindCertificate uploads the certificate of the private key k to the NetSign servers
+func (c *CryptoSuite) BindCertificate(k core.Key, cert []byte) error {
+	if k == nil || !k.Private() {
+		return errors.New("a private key is required to bind a certificate")
+	}
+	if block, _ := pem.Decode(cert); block != nil {
+		cert = block.Bytes
+	}
+	return c.keyPairs.Uploadcert(k.SKI(), cert)
+}
+
+// DeleteKeyPair deletes the key pair of the private key k from the NetSign servers
+func (c *CryptoSuite) DeleteKeyPair(k core.Key) error {
+	if k == nil || !k.Private() {
+		return errors.New("a private key is required to delete a key pair")
+	}
+	return c.keyPairs.DeleteKeyPair(k.SKI())
 }
 
 func getBCCSPFromOpts(config *cncc.CNCC_GMOpts) (bccsp.BCCSP, error) {
@@ -65,18 +128,23 @@ func getBCCSPFromOpts(config *cncc.CNCC_GMOpts) (bccsp.BCCSP, error) {
 //}
 
 //GetOptsByConfig Returns Factory opts for given SDK config
-func getOptsByConfig(c core.CryptoSuiteConfig) *cncc.CNCC_GMOpts {
+func getOptsByConfig(c core.CryptoSuiteConfig) (*cncc.CNCC_GMOpts, error) {
+	pwd, err := wrapper.KeyStorePassword(c)
+	if err != nil {
+		return nil, err
+	}
 	opts := &cncc.CNCC_GMOpts{
 		HashFamily: c.SecurityAlgorithm(),
 		SecLevel:   c.SecurityLevel(),
 		FileKeystore: &cncc.FileKeystoreOpts{
 			KeyStorePath: c.KeyStorePath(),
+			Password:     pwd,
 		},
 		//Ephemeral: c.Ephemeral(),
 	}
 	logger.Debugf("Initialized CNCC_GM cryptosuite, %v", c.SecurityAlgorithm())
 	
-	return opts
+	return opts, nil
 }
 
 func getEphemeralOpts() *cncc.CNCC_GMOpts {
@@ -88,4 +156,4 @@ func getEphemeralOpts() *cncc.CNCC_GMOpts {
 	logger.Debug("Initialized ephemeral CNCC_GM cryptosuite with default opts")
 	
 	return opts
-}
\ No newline at end of file
+}
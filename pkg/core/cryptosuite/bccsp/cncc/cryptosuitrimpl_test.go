@@ -0,0 +1,410 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cncc
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/cncc"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/test/mockcore"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/cncc/mocknetsign"
+)
+
+// netSignServers starts count emulated NetSign servers and returns them along with the
+// ip and port settings listing them, one data center per server
+func netSignServers(t *testing.T, count int) ([]*mocknetsign.MockNetSignServer, []*httptest.Server, string, string) {
+	var servers []*mocknetsign.MockNetSignServer
+	var listeners []*httptest.Server
+	var ips, ports []string
+	for i := 0; i < count; i++ {
+		s := mocknetsign.NewMockNetSignServer()
+		srv := httptest.NewServer(s)
+		host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to split address: %s", err)
+		}
+		servers = append(servers, s)
+		listeners = append(listeners, srv)
+		ips = append(ips, host)
+		ports = append(ports, port)
+	}
+	return servers, listeners, strings.Join(ips, ";"), strings.Join(ports, ";")
+}
+
+func newTestSuite(t *testing.T, ip, port string, softVerify bool) (core.CryptoSuite, bccsp.BCCSP) {
+	passwords := strings.Repeat("password;", strings.Count(ip, ";")+1)
+	csp, err := getBCCSPFromOpts(&cncc.CNCC_GMOpts{
+		HashFamily:       "GMSM3",
+		SecLevel:         256,
+		Ephemeral:        true,
+		Ip:               ip,
+		Port:             port,
+		Password:         strings.TrimSuffix(passwords, ";"),
+		SoftVerify:       softVerify,
+		SessionCacheSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("failed to create cncc_gm suite: %s", err)
+	}
+	return NewCryptoSuite(csp), csp
+}
+
+// cryptoSigner signs with a key held by NetSign through the crypto suite
+type cryptoSigner struct {
+	suite core.CryptoSuite
+	key   core.Key
+	pub   *sm2.PublicKey
+}
+
+func (s *cryptoSigner) Public() crypto.PublicKey {
+	return s.pub
+}
+
+func (s *cryptoSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.suite.Sign(s.key, digest, opts)
+}
+
+func newCryptoSigner(t *testing.T, suite core.CryptoSuite, key core.Key) *cryptoSigner {
+	pubKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatalf("failed to get public key: %s", err)
+	}
+	raw, err := pubKey.Bytes()
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %s", err)
+	}
+	pub, err := sm2.ParseSm2PublicKey(raw)
+	if err != nil {
+		t.Fatalf("failed to parse public key: %s", err)
+	}
+	return &cryptoSigner{suite: suite, key: key, pub: pub}
+}
+
+// issueCertificate issues a certificate for the CSR from a new self-signed CA
+func issueCertificate(t *testing.T, csr *sm2.CertificateRequest) *sm2.Certificate {
+	caKey, err := sm2.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %s", err)
+	}
+	ca := &sm2.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              sm2.KeyUsageCertSign,
+	}
+	template := &sm2.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		SubjectKeyId: []byte("user"),
+	}
+	der, err := sm2.CreateCertificate(rand.Reader, template, ca, csr.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	cert, err := sm2.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	return cert
+}
+
+func TestCryptoSuiteEndToEnd(t *testing.T) {
+	servers, listeners, ip, port := netSignServers(t, 1)
+	defer listeners[0].Close()
+	netSign := servers[0]
+
+	suite, csp := newTestSuite(t, ip, port, true)
+	defer csp.(closer).Close()
+
+	// key generation
+	key, err := suite.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %s", err)
+	}
+	if !key.Private() {
+		t.Fatal("expected private key handle")
+	}
+	keyLabel := "SM2SignKey" + string(key.SKI())
+	pub, ok := netSign.PublicKey(keyLabel)
+	if !ok {
+		t.Fatalf("key pair [%s] should be held by NetSign", keyLabel)
+	}
+	signer := newCryptoSigner(t, suite, key)
+	if signer.pub.X.Cmp(pub.X) != 0 || signer.pub.Y.Cmp(pub.Y) != 0 {
+		t.Fatal("public key should be the one of the NetSign key pair")
+	}
+
+	// CSR signed through NetSign
+	csrBytes, err := sm2.CreateCertificateRequest(rand.Reader, &sm2.CertificateRequest{Subject: pkix.Name{CommonName: "user"}}, signer)
+	if err != nil {
+		t.Fatalf("failed to create CSR: %s", err)
+	}
+	csr, err := sm2.ParseCertificateRequest(csrBytes)
+	if err != nil {
+		t.Fatalf("failed to parse CSR: %s", err)
+	}
+	r, s, err := sm2.SignDataToSignDigit(csr.Signature)
+	if err != nil {
+		t.Fatalf("invalid CSR signature: %s", err)
+	}
+	if !sm2.Verify(pub, sm3.Sm3Sum(csr.RawTBSCertificateRequest), r, s) {
+		t.Fatal("CSR signature should verify")
+	}
+
+	// cert upload
+	cert := issueCertificate(t, csr)
+	keyPairs, ok := suite.(core.RemoteKeyManager)
+	if !ok {
+		t.Fatal("cncc_gm suite should manage the key pairs of NetSign")
+	}
+	if err = keyPairs.BindCertificate(key, cert.Raw); err != nil {
+		t.Fatalf("BindCertificate failed: %s", err)
+	}
+	if netSign.Calls(mocknetsign.UploadCert) != 1 {
+		t.Fatal("cert should be uploaded to NetSign")
+	}
+
+	// signing
+	digest, err := suite.Hash([]byte("Hello"), &bccsp.GMSM3Opts{})
+	if err != nil {
+		t.Fatalf("Hash failed: %s", err)
+	}
+	signature, err := suite.Sign(key, digest, nil)
+	if err != nil {
+		t.Fatalf("Sign failed: %s", err)
+	}
+
+	// soft verification with the public key of the certificate
+	certKey, err := suite.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyImport failed: %s", err)
+	}
+	valid, err := suite.Verify(certKey, signature, digest, nil)
+	if err != nil || !valid {
+		t.Fatalf("soft verification failed: %v, %v", valid, err)
+	}
+	valid, err = suite.Verify(certKey, signature, sm3.Sm3Sum([]byte("tampered")), nil)
+	if err != nil || valid {
+		t.Fatalf("soft verification should fail for another digest: %v, %v", valid, err)
+	}
+	if netSign.Calls(mocknetsign.Verify) != 0 {
+		t.Fatal("soft verification should not call NetSign")
+	}
+
+	// private key handles are verified by NetSign
+	valid, err = suite.Verify(key, signature, digest, nil)
+	if err != nil || !valid {
+		t.Fatalf("NetSign verification failed: %v, %v", valid, err)
+	}
+	if netSign.Calls(mocknetsign.Verify) != 1 {
+		t.Fatal("private key handles should be verified by NetSign")
+	}
+
+	// encryption with the public key of the certificate is done in software
+	ciphertext, err := suite.(core.Encrypter).Encrypt(certKey, digest, &bccsp.SM2EncrypterOpts{Format: bccsp.SM2ASN1})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %s", err)
+	}
+	if _, err := sm2.CipherUnmarshal(ciphertext); err != nil {
+		t.Fatalf("ciphertext should be ASN.1 encoded: %s", err)
+	}
+	if _, err := suite.(core.Decrypter).Decrypt(key, ciphertext, &bccsp.SM2DecrypterOpts{Format: bccsp.SM2ASN1}); err == nil {
+		t.Fatal("NetSign keys should not decrypt")
+	}
+
+	// key pair deletion
+	if err = keyPairs.BindCertificate(certKey, cert.Raw); err == nil {
+		t.Fatal("a certificate should only be bound to a private key")
+	}
+	if err = keyPairs.DeleteKeyPair(key); err != nil {
+		t.Fatalf("DeleteKeyPair failed: %s", err)
+	}
+	if _, ok := netSign.PublicKey(keyLabel); ok {
+		t.Fatalf("key pair [%s] should be deleted from NetSign", keyLabel)
+	}
+}
+
+func TestCryptoSuiteFailover(t *testing.T) {
+	servers, listeners, ip, port := netSignServers(t, 2)
+	defer listeners[1].Close()
+	// the primary data center is down
+	listeners[0].Close()
+
+	suite, csp := newTestSuite(t, ip, port, false)
+	defer csp.(closer).Close()
+
+	key, err := suite.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %s", err)
+	}
+	if _, ok := servers[1].PublicKey("SM2SignKey" + string(key.SKI())); !ok {
+		t.Fatal("key pair should be generated by the secondary data center")
+	}
+	if servers[0].Calls(mocknetsign.GenP10) != 0 {
+		t.Fatal("primary data center should not be called")
+	}
+}
+
+func TestCryptoSuiteSM4(t *testing.T) {
+	_, listeners, ip, port := netSignServers(t, 1)
+	defer listeners[0].Close()
+	suite, csp := newTestSuite(t, ip, port, false)
+	defer csp.(closer).Close()
+
+	key, err := suite.KeyGen(&bccsp.GMSM4KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %s", err)
+	}
+	imported, err := suite.KeyImport(bytes.Repeat([]byte{1}, 16), &bccsp.GMSM4ImportKeyOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyImport failed: %s", err)
+	}
+
+	msg := []byte("off-chain payload")
+	for _, k := range []core.Key{key, imported} {
+		for _, opts := range []interface{}{&bccsp.SM4CBCPKCS7ModeOpts{}, &bccsp.SM4CTRModeOpts{}, &bccsp.SM4GCMModeOpts{AdditionalData: []byte("tx1")}} {
+			ciphertext, err := suite.(core.Encrypter).Encrypt(k, msg, opts)
+			if err != nil {
+				t.Fatalf("Encrypt with %T failed: %s", opts, err)
+			}
+			plaintext, err := suite.(core.Decrypter).Decrypt(k, ciphertext, opts)
+			if err != nil || !bytes.Equal(plaintext, msg) {
+				t.Fatalf("Decrypt with %T failed: %s, %v", opts, plaintext, err)
+			}
+		}
+	}
+}
+
+func TestCryptoSuiteSM3KeyDeriv(t *testing.T) {
+	_, listeners, ip, port := netSignServers(t, 1)
+	defer listeners[0].Close()
+
+	keyStorePath, err := ioutil.TempDir("", "cncc")
+	if err != nil {
+		t.Fatalf("failed to create key store: %s", err)
+	}
+	defer os.RemoveAll(keyStorePath)
+
+	csp, err := getBCCSPFromOpts(&cncc.CNCC_GMOpts{HashFamily: "GMSM3", SecLevel: 256, Ip: ip, Port: port, Password: "password",
+		FileKeystore: &cncc.FileKeystoreOpts{KeyStorePath: keyStorePath}})
+	if err != nil {
+		t.Fatalf("failed to create cncc_gm suite: %s", err)
+	}
+	defer csp.(closer).Close()
+
+	master, err := csp.KeyImport(bytes.Repeat([]byte{1}, 16), &bccsp.GMSM4ImportKeyOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyImport failed: %s", err)
+	}
+	for _, opts := range []bccsp.KeyDerivOpts{&bccsp.HMACSM3DeriveKeyOpts{Arg: []byte("mychannel")}, &bccsp.HKDFSM3DeriveKeyOpts{Info: []byte("mychannel")}} {
+		dk, err := csp.KeyDeriv(master, opts)
+		if err != nil {
+			t.Fatalf("KeyDeriv with %T failed: %s", opts, err)
+		}
+
+		// derived sm4 keys are read back from the local keystore
+		stored, err := csp.GetKey(dk.SKI())
+		if err != nil || !bytes.Equal(stored.SKI(), dk.SKI()) {
+			t.Fatalf("derived key should be in the keystore: %v", err)
+		}
+
+		msg := []byte("message to authenticate")
+		mac, err := csp.Hash(msg, &bccsp.SM3HMACOpts{Key: stored})
+		if err != nil || len(mac) != 32 {
+			t.Fatalf("HMAC with derived key failed: %v", err)
+		}
+		h, err := csp.GetHash(&bccsp.SM3HMACOpts{Key: dk})
+		if err != nil {
+			t.Fatalf("GetHash failed: %s", err)
+		}
+		h.Write(msg)
+		if !bytes.Equal(h.Sum(nil), mac) {
+			t.Fatal("GetHash and Hash should give the same HMAC")
+		}
+	}
+
+	priv, err := csp.KeyGen(&bccsp.GMSM2KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %s", err)
+	}
+	if _, err := csp.KeyDeriv(priv, &bccsp.HKDFSM3DeriveKeyOpts{}); err == nil {
+		t.Fatal("deriving from a NetSign key should fail")
+	}
+	if _, err := csp.Hash([]byte("msg"), &bccsp.SM3HMACOpts{Key: priv}); err == nil {
+		t.Fatal("HMAC with a NetSign key should fail")
+	}
+}
+
+func TestCryptoSuiteNetSignUnavailable(t *testing.T) {
+	_, listeners, ip, port := netSignServers(t, 1)
+	listeners[0].Close()
+
+	_, err := getBCCSPFromOpts(&cncc.CNCC_GMOpts{HashFamily: "GMSM3", SecLevel: 256, Ephemeral: true, Ip: ip, Port: port, Password: "password"})
+	if err == nil {
+		t.Fatal("expected error without any NetSign server available")
+	}
+}
+
+func TestCryptoSuiteByConfig(t *testing.T) {
+	_, listeners, ip, port := netSignServers(t, 1)
+	defer listeners[0].Close()
+
+	for name, value := range map[string]string{"CORE_PEER_BCCSP_CNCC_GM_IP": ip, "CORE_PEER_BCCSP_CNCC_GM_PORT": port, "CORE_PEER_BCCSP_CNCC_GM_PASSWORD": "password"} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	keyStorePath, err := ioutil.TempDir("", "cncc")
+	if err != nil {
+		t.Fatalf("failed to create key store: %s", err)
+	}
+	defer os.RemoveAll(keyStorePath)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := mockcore.NewMockCryptoSuiteConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return("cncc_gm").AnyTimes()
+	mockConfig.EXPECT().SecurityAlgorithm().Return("GMSM3").AnyTimes()
+	mockConfig.EXPECT().SecurityLevel().Return(256)
+	mockConfig.EXPECT().KeyStorePath().Return(keyStorePath)
+
+	c, err := GetSuiteByConfig(mockConfig)
+	if err != nil {
+		t.Fatalf("Not supposed to get error, but got: %s", err)
+	}
+
+	digest, err := c.Hash([]byte("Hello"), &bccsp.GMSM3Opts{})
+	if err != nil {
+		t.Fatalf("Hash failed: %s", err)
+	}
+	if string(digest) != string(sm3.Sm3Sum([]byte("Hello"))) {
+		t.Fatal("expected SM3 digest")
+	}
+}
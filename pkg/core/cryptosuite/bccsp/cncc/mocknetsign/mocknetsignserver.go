@@ -0,0 +1,303 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocknetsign
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+// Operations of the NetSign HTTP API, served under /brilliance/netsign/
+const (
+	GenP10        = "genP10"
+	UploadCert    = "uploadCert"
+	Sign          = "sign"
+	Verify        = "verify"
+	Hash          = "hash"
+	DeleteKeyPair = "deleteKeyPair"
+	Status        = "status"
+)
+
+const pathPrefix = "/brilliance/netsign/"
+
+// response is the envelope of every NetSign response; the client only reads data
+type response struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+type keyPair struct {
+	priv *sm2.PrivateKey
+	cert *sm2.Certificate
+}
+
+// MockNetSignServer emulates a NetSign signature server, holding SM2 key pairs in memory.
+// It is an http.Handler, so it can be served with httptest.NewServer:
+//
+//	srv := httptest.NewServer(mocknetsign.NewMockNetSignServer())
+//	defer srv.Close()
+//
+// Signatures are computed over the received bytes as a digest, like the GM crypto suite does,
+// so that signatures made through NetSign can be verified in software.
+type MockNetSignServer struct {
+	mutex sync.RWMutex
+	keys  map[string]*keyPair
+	calls map[string]int
+}
+
+// NewMockNetSignServer returns an emulated NetSign server without any key pair
+func NewMockNetSignServer() *MockNetSignServer {
+	return &MockNetSignServer{
+		keys:  make(map[string]*keyPair),
+		calls: make(map[string]int),
+	}
+}
+
+// ServeHTTP handles the NetSign HTTP API
+func (s *MockNetSignServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	operation := strings.TrimPrefix(req.URL.Path, pathPrefix)
+	if operation == req.URL.Path {
+		http.NotFound(w, req)
+		return
+	}
+
+	s.mutex.Lock()
+	s.calls[operation]++
+	s.mutex.Unlock()
+
+	if operation == Status {
+		s.send(w, true, nil)
+		return
+	}
+
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	params := make(map[string]string)
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var data interface{}
+	var err error
+	switch operation {
+	case GenP10:
+		data, err = s.genP10(params["keyLabel"], params["certDn"], params["isCover"] == "true")
+	case UploadCert:
+		data, err = s.uploadCert(params["keyLabel"], params["cert"])
+	case Sign:
+		data, err = s.sign(params["keyLabel"], params["origBytes"])
+	case Verify:
+		data, err = s.verify(params["keyLabel"], params["origBytes"], params["signature"])
+	case Hash:
+		data, err = s.hash(params["digestAlg"], params["msgBytes"])
+	case DeleteKeyPair:
+		data, err = s.deleteKeyPair(params["keyLabel"])
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	s.send(w, data, err)
+}
+
+// Calls returns the number of requests received for the given operation
+func (s *MockNetSignServer) Calls(operation string) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.calls[operation]
+}
+
+// PublicKey returns the public key of the key pair with the given label
+func (s *MockNetSignServer) PublicKey(keyLabel string) (*sm2.PublicKey, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	key, ok := s.keys[keyLabel]
+	if !ok {
+		return nil, false
+	}
+	return &key.priv.PublicKey, true
+}
+
+// Certificate returns the certificate uploaded for the key pair with the given label, if any
+func (s *MockNetSignServer) Certificate(keyLabel string) (*sm2.Certificate, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	key, ok := s.keys[keyLabel]
+	if !ok || key.cert == nil {
+		return nil, false
+	}
+	return key.cert, true
+}
+
+func (s *MockNetSignServer) send(w http.ResponseWriter, data interface{}, err error) {
+	resp := &response{Code: "0", Message: "success", Data: data}
+	if err != nil {
+		logger.Debugf("NetSign emulator: %s", err)
+		resp = &response{Code: "1", Message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Errorf("failed to send NetSign response: %s", err)
+	}
+}
+
+func (s *MockNetSignServer) genP10(keyLabel, certDN string, isCover bool) (interface{}, error) {
+	if keyLabel == "" {
+		return nil, errors.New("key label is required")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.keys[keyLabel]; ok && !isCover {
+		return nil, errors.Errorf("key pair [%s] already exists", keyLabel)
+	}
+
+	priv, err := sm2.GenerateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SM2 key pair")
+	}
+	template := &sm2.CertificateRequest{
+		Subject:            pkix.Name{CommonName: strings.TrimPrefix(certDN, "CN=")},
+		SignatureAlgorithm: sm2.SM2WithSM3,
+	}
+	csr, err := sm2.CreateCertificateRequest(rand.Reader, template, priv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create certificate request")
+	}
+	s.keys[keyLabel] = &keyPair{priv: priv}
+
+	p10 := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+	return map[string]string{"p10": string(p10)}, nil
+}
+
+// uploadCert binds a certificate to a key pair. The certificate is optional since the
+// NetSign client only sends the key label; when sent, it must match the key pair.
+func (s *MockNetSignServer) uploadCert(keyLabel, encodedCert string) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, ok := s.keys[keyLabel]
+	if !ok {
+		return false, nil
+	}
+	if encodedCert != "" {
+		cert, err := parseCertificate(encodedCert)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := cert.PublicKey.(*sm2.PublicKey)
+		if !ok || pub.X.Cmp(key.priv.X) != 0 || pub.Y.Cmp(key.priv.Y) != 0 {
+			return false, nil
+		}
+		key.cert = cert
+	}
+	return true, nil
+}
+
+func (s *MockNetSignServer) sign(keyLabel, origBytes string) (interface{}, error) {
+	s.mutex.RLock()
+	key, ok := s.keys[keyLabel]
+	s.mutex.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("key pair [%s] not found", keyLabel)
+	}
+
+	digest, err := base64.StdEncoding.DecodeString(origBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid origBytes")
+	}
+	r, sig, err := sm2.Sign(key.priv, digest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign")
+	}
+	signature, err := sm2.SignDigitToSignData(r, sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal signature")
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+func (s *MockNetSignServer) verify(keyLabel, origBytes, encodedSignature string) (interface{}, error) {
+	s.mutex.RLock()
+	key, ok := s.keys[keyLabel]
+	s.mutex.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("key pair [%s] not found", keyLabel)
+	}
+
+	digest, err := base64.StdEncoding.DecodeString(origBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid origBytes")
+	}
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signature")
+	}
+	r, sig, err := sm2.SignDataToSignDigit(signature)
+	if err != nil {
+		return false, nil
+	}
+	return sm2.Verify(&key.priv.PublicKey, digest, r, sig), nil
+}
+
+func (s *MockNetSignServer) hash(digestAlg, msgBytes string) (interface{}, error) {
+	if !strings.EqualFold(digestAlg, "sm3") {
+		return nil, errors.Errorf("unsupported digest algorithm [%s]", digestAlg)
+	}
+	msg, err := base64.StdEncoding.DecodeString(msgBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid msgBytes")
+	}
+	return base64.StdEncoding.EncodeToString(sm3.Sm3Sum(msg)), nil
+}
+
+func (s *MockNetSignServer) deleteKeyPair(keyLabel string) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.keys[keyLabel]; !ok {
+		return false, nil
+	}
+	delete(s.keys, keyLabel)
+	return true, nil
+}
+
+// parseCertificate parses a PEM or base64 encoded DER certificate
+func parseCertificate(encoded string) (*sm2.Certificate, error) {
+	raw := []byte(encoded)
+	if block, _ := pem.Decode(raw); block != nil {
+		raw = block.Bytes
+	} else {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid certificate encoding")
+		}
+		raw = der
+	}
+	cert, err := sm2.ParseCertificate(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+	return cert, nil
+}
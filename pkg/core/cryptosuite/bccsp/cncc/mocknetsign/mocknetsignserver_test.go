@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocknetsign
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/TaurusWei/go-netsign/netsign"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+)
+
+// openNetSign starts an emulated NetSign server and opens a session on it with the NetSign client
+func openNetSign(t *testing.T) (*MockNetSignServer, *httptest.Server, *netsign.NetSign, int) {
+	s := NewMockNetSignServer()
+	srv := httptest.NewServer(s)
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to split address: %s", err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("invalid port: %s", err)
+	}
+
+	ns := &netsign.NetSign{}
+	socketFd, ret := ns.OpenNetSign(host, "password", p)
+	if ret != 0 {
+		t.Fatalf("failed to open NetSign session: %d", ret)
+	}
+	return s, srv, ns, socketFd
+}
+
+func TestMockNetSignServer(t *testing.T) {
+	s, srv, ns, socketFd := openNetSign(t)
+	defer srv.Close()
+
+	p10, ret := ns.GenP10(socketFd, "CN=CNCC", "test", "SM2")
+	if ret != 0 {
+		t.Fatalf("GenP10 failed: %d", ret)
+	}
+	if !strings.HasPrefix(string(p10), "-----BEGIN CERTIFICATE REQUEST-----") {
+		t.Fatalf("expected PEM certificate request, got %s", p10)
+	}
+	pub, ok := s.PublicKey("test")
+	if !ok {
+		t.Fatal("key pair should be held by the server")
+	}
+
+	if ret := ns.UploadCert(socketFd, "unknown", nil); ret == 0 {
+		t.Fatal("uploading a cert for an unknown key pair should fail")
+	}
+	if ret := ns.UploadCert(socketFd, "test", nil); ret != 0 {
+		t.Fatalf("UploadCert failed: %d", ret)
+	}
+
+	digest := sm3.Sm3Sum([]byte("hello world"))
+	signature, ret := ns.Sign(socketFd, 0, digest, "test", "sm3")
+	if ret != 0 {
+		t.Fatalf("Sign failed: %d", ret)
+	}
+	r, sig, err := sm2.SignDataToSignDigit(signature)
+	if err != nil {
+		t.Fatalf("invalid signature: %s", err)
+	}
+	if !sm2.Verify(pub, digest, r, sig) {
+		t.Fatal("signature should verify in software")
+	}
+
+	if ret := ns.Verify(socketFd, 1, digest, signature, "test", "sm3"); ret != 0 {
+		t.Fatalf("Verify failed: %d", ret)
+	}
+	if ret := ns.Verify(socketFd, 1, []byte("tampered"), signature, "test", "sm3"); ret == 0 {
+		t.Fatal("Verify should fail for another digest")
+	}
+
+	h, ret := ns.Hash(socketFd, "sm3", []byte("hello world"))
+	if ret != 0 || !bytes.Equal(h, digest) {
+		t.Fatalf("Hash failed: %d, %s", ret, base64.StdEncoding.EncodeToString(h))
+	}
+
+	if _, ret := ns.DeleteKeyPair(socketFd, "test"); ret != 0 {
+		t.Fatalf("DeleteKeyPair failed: %d", ret)
+	}
+	if _, ok := s.PublicKey("test"); ok {
+		t.Fatal("key pair should be deleted")
+	}
+	if _, ret := ns.DeleteKeyPair(socketFd, "test"); ret == 0 {
+		t.Fatal("deleting an unknown key pair should fail")
+	}
+
+	if s.Calls(Sign) != 1 || s.Calls(Verify) != 2 {
+		t.Fatalf("unexpected calls: sign %d, verify %d", s.Calls(Sign), s.Calls(Verify))
+	}
+}
+
+func TestMockNetSignServerStatus(t *testing.T) {
+	_, srv, _, _ := openNetSign(t)
+	defer srv.Close()
+
+	down := httptest.NewServer(NewMockNetSignServer())
+	down.Close()
+
+	status := netsign.CheckAllNetsignStatus([]string{srv.Listener.Addr().String(), down.Listener.Addr().String()}, 2)
+	if status[0] != 0 || status[1] == 0 {
+		t.Fatalf("unexpected status %v", status)
+	}
+}
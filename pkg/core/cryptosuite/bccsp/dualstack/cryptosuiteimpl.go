@@ -0,0 +1,358 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dualstack
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"hash"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/gm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+const (
+	// FamilyGM is the algorithm family of the Chinese national standards (SM2, SM3, SM4)
+	FamilyGM = "gm"
+	// FamilySW is the algorithm family of the international standards (ECDSA, RSA, SHA-2, SHA-3, AES)
+	FamilySW = "sw"
+)
+
+// CryptoSuite is a core.CryptoSuite made of one crypto suite per algorithm family.
+// Keys are routed to the suite of their family and hashes to the suite of the
+// requested algorithm; operations that don't identify a family use the default family.
+type CryptoSuite struct {
+	defaultFamily string
+	suites        map[string]core.CryptoSuite
+}
+
+//GetSuiteByConfig returns a dual-stack cryptosuite, made of the sw and gm cryptosuites, loaded according to given config.
+//The default family is gm if the configured hash algorithm is GMSM3, sw otherwise.
+func GetSuiteByConfig(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	// the member suites check their own provider, which memberConfig sets to their family
+	if config.SecurityProvider() != "dualstack" {
+		return nil, errors.Errorf("Unsupported BCCSP Provider: %s", config.SecurityProvider())
+	}
+
+	swSuite, err := sw.GetSuiteByConfig(&memberConfig{CryptoSuiteConfig: config, provider: FamilySW})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load sw cryptosuite")
+	}
+	gmSuite, err := gm.GetSuiteByConfig(&memberConfig{CryptoSuiteConfig: config, provider: FamilyGM})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load gm cryptosuite")
+	}
+
+	defaultFamily := FamilySW
+	if strings.ToUpper(config.SecurityAlgorithm()) == bccsp.GMSM3 {
+		defaultFamily = FamilyGM
+	}
+	logger.Debugf("Initialized dual-stack cryptosuite, default family: %s", defaultFamily)
+
+	return New(defaultFamily, map[string]core.CryptoSuite{FamilySW: swSuite, FamilyGM: gmSuite})
+}
+
+//New returns a dual-stack cryptosuite routing across the given cryptosuites, keyed by algorithm family
+func New(defaultFamily string, suites map[string]core.CryptoSuite) (*CryptoSuite, error) {
+	if _, ok := suites[defaultFamily]; !ok {
+		return nil, errors.Errorf("no cryptosuite given for default family [%s]", defaultFamily)
+	}
+	return &CryptoSuite{defaultFamily: defaultFamily, suites: suites}, nil
+}
+
+//WithDefaultFamily returns a view of the cryptosuite that uses the given family by default, e.g. the
+//crypto family configured for a channel (see the CryptoSuite of the channel context).
+func (c *CryptoSuite) WithDefaultFamily(family string) (core.CryptoSuite, error) {
+	return New(strings.ToLower(family), c.suites)
+}
+
+//DefaultFamily returns the family used when an operation doesn't identify one
+func (c *CryptoSuite) DefaultFamily() string {
+	return c.defaultFamily
+}
+
+//Suite returns the cryptosuite of the given family
+func (c *CryptoSuite) Suite(family string) (core.CryptoSuite, error) {
+	suite, ok := c.suites[family]
+	if !ok {
+		return nil, errors.Errorf("no cryptosuite configured for family [%s]", family)
+	}
+	return suite, nil
+}
+
+// KeyGen generates a key with the suite of the family of opts
+func (c *CryptoSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	family := c.familyByAlgorithm(opts.Algorithm())
+	suite, err := c.Suite(family)
+	if err != nil {
+		return nil, err
+	}
+	k, err := suite.KeyGen(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newKey(k, family), nil
+}
+
+// KeyImport imports a key with the suite of the family of the raw material (e.g. the
+// public key algorithm of a certificate) or, failing that, of opts
+func (c *CryptoSuite) KeyImport(raw interface{}, opts core.KeyImportOpts) (core.Key, error) {
+	family, raw, err := c.importFamily(raw, opts)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := c.Suite(family)
+	if err != nil {
+		return nil, err
+	}
+	k, err := suite.KeyImport(raw, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newKey(k, family), nil
+}
+
+// GetKey returns the key associated to ski, looking in the suite of the default family first
+func (c *CryptoSuite) GetKey(ski []byte) (core.Key, error) {
+	var errs []string
+	for _, family := range c.families() {
+		k, err := c.suites[family].GetKey(ski)
+		if err == nil && k != nil {
+			return newKey(k, family), nil
+		}
+		if err != nil {
+			errs = append(errs, family+": "+err.Error())
+		}
+	}
+	return nil, errors.Errorf("key not found for SKI [%x] in any cryptosuite: %s", ski, strings.Join(errs, "; "))
+}
+
+// Hash hashes msg with the suite of the family of opts, or of the key of HMAC opts
+func (c *CryptoSuite) Hash(msg []byte, opts core.HashOpts) ([]byte, error) {
+	suite, opts, err := c.suiteForHash(opts)
+	if err != nil {
+		return nil, err
+	}
+	return suite.Hash(msg, opts)
+}
+
+// GetHash returns a hash.Hash from the suite of the family of opts, or of the key of HMAC opts
+func (c *CryptoSuite) GetHash(opts core.HashOpts) (hash.Hash, error) {
+	suite, opts, err := c.suiteForHash(opts)
+	if err != nil {
+		return nil, err
+	}
+	return suite.GetHash(opts)
+}
+
+// KeyDeriv derives a key from k with the suite of the family of k
+func (c *CryptoSuite) KeyDeriv(k core.Key, opts core.KeyDerivOpts) (core.Key, error) {
+	family := c.keyFamily(k)
+	suite, inner, err := c.suiteForKey(k)
+	if err != nil {
+		return nil, err
+	}
+	deriver, ok := suite.(core.KeyDeriver)
+	if !ok {
+		return nil, errors.Errorf("cryptosuite of family [%s] does not support key derivation", family)
+	}
+	dk, err := deriver.KeyDeriv(inner, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newKey(dk, family), nil
+}
+
+// Sign signs digest with the suite of the family of k
+func (c *CryptoSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	suite, inner, err := c.suiteForKey(k)
+	if err != nil {
+		return nil, err
+	}
+	return suite.Sign(inner, digest, opts)
+}
+
+// Verify verifies signature with the suite of the family of k
+func (c *CryptoSuite) Verify(k core.Key, signature, digest []byte, opts core.SignerOpts) (bool, error) {
+	suite, inner, err := c.suiteForKey(k)
+	if err != nil {
+		return false, err
+	}
+	return suite.Verify(inner, signature, digest, opts)
+}
+
+// Encrypt encrypts plaintext with the suite of the family of k
+func (c *CryptoSuite) Encrypt(k core.Key, plaintext []byte, opts core.EncrypterOpts) ([]byte, error) {
+	suite, inner, err := c.suiteForKey(k)
+	if err != nil {
+		return nil, err
+	}
+	encrypter, ok := suite.(core.Encrypter)
+	if !ok {
+		return nil, errors.Errorf("cryptosuite of family [%s] does not support encryption", c.keyFamily(k))
+	}
+	return encrypter.Encrypt(inner, plaintext, opts)
+}
+
+// Decrypt decrypts ciphertext with the suite of the family of k
+func (c *CryptoSuite) Decrypt(k core.Key, ciphertext []byte, opts core.DecrypterOpts) ([]byte, error) {
+	suite, inner, err := c.suiteForKey(k)
+	if err != nil {
+		return nil, err
+	}
+	decrypter, ok := suite.(core.Decrypter)
+	if !ok {
+		return nil, errors.Errorf("cryptosuite of family [%s] does not support decryption", c.keyFamily(k))
+	}
+	return decrypter.Decrypt(inner, ciphertext, opts)
+}
+
+// families returns the configured families, default family first
+func (c *CryptoSuite) families() []string {
+	var others []string
+	for family := range c.suites {
+		if family != c.defaultFamily {
+			others = append(others, family)
+		}
+	}
+	sort.Strings(others)
+	return append([]string{c.defaultFamily}, others...)
+}
+
+// keyFamily returns the family of k, keys not obtained through this suite belong to the default family
+func (c *CryptoSuite) keyFamily(k core.Key) string {
+	if dk, ok := k.(*key); ok {
+		return dk.family
+	}
+	return c.defaultFamily
+}
+
+func (c *CryptoSuite) suiteForKey(k core.Key) (core.CryptoSuite, core.Key, error) {
+	if dk, ok := k.(*key); ok {
+		suite, err := c.Suite(dk.family)
+		return suite, dk.Key, err
+	}
+	// keys not obtained through this suite belong to the default family
+	suite, err := c.Suite(c.defaultFamily)
+	return suite, k, err
+}
+
+// suiteForHash returns the suite computing the hash of opts, an HMAC is computed by the
+// suite of its key
+func (c *CryptoSuite) suiteForHash(opts core.HashOpts) (core.CryptoSuite, core.HashOpts, error) {
+	if o, ok := opts.(*wrapper.SM3HMACOpts); ok {
+		suite, inner, err := c.suiteForKey(o.Key)
+		return suite, &wrapper.SM3HMACOpts{Key: inner}, err
+	}
+	suite, err := c.Suite(c.hashFamily(opts))
+	return suite, opts, err
+}
+
+func (c *CryptoSuite) familyByAlgorithm(algorithm string) string {
+	for _, prefix := range []string{bccsp.ECDSA, bccsp.RSA, bccsp.AES, bccsp.HMAC, bccsp.SHA} {
+		if strings.HasPrefix(algorithm, prefix) {
+			return FamilySW
+		}
+	}
+	if strings.HasPrefix(algorithm, "GMSM") {
+		return FamilyGM
+	}
+	return c.defaultFamily
+}
+
+func (c *CryptoSuite) hashFamily(opts core.HashOpts) string {
+	if opts == nil || opts.Algorithm() == bccsp.SHA {
+		return c.defaultFamily
+	}
+	return c.familyByAlgorithm(opts.Algorithm())
+}
+
+// importFamily returns the family of the key material. Certificates parsed by the sm2
+// package are converted to *x509.Certificate when they hold an international key.
+func (c *CryptoSuite) importFamily(raw interface{}, opts core.KeyImportOpts) (string, interface{}, error) {
+	switch r := raw.(type) {
+	case *sm2.Certificate:
+		if isSM2Key(r.PublicKey) {
+			return FamilyGM, raw, nil
+		}
+		cert, err := x509.ParseCertificate(r.Raw)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		return FamilySW, cert, nil
+	case *x509.Certificate:
+		return FamilySW, raw, nil
+	case *sm2.PublicKey, sm2.PublicKey, *sm2.PrivateKey, *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		if isSM2Key(raw) {
+			return FamilyGM, raw, nil
+		}
+		return FamilySW, raw, nil
+	}
+	return c.familyByAlgorithm(opts.Algorithm()), raw, nil
+}
+
+func isSM2Key(k interface{}) bool {
+	switch key := k.(type) {
+	case *sm2.PublicKey:
+		return key.Curve == sm2.P256Sm2()
+	case sm2.PublicKey:
+		return key.Curve == sm2.P256Sm2()
+	case *sm2.PrivateKey:
+		return key.Curve == sm2.P256Sm2()
+	case *ecdsa.PublicKey:
+		return key.Curve == sm2.P256Sm2()
+	case *ecdsa.PrivateKey:
+		return key.Curve == sm2.P256Sm2()
+	}
+	return false
+}
+
+// key remembers the family of the suite a key was obtained from
+type key struct {
+	core.Key
+	family string
+}
+
+func newKey(k core.Key, family string) core.Key {
+	if k == nil {
+		return nil
+	}
+	return &key{Key: k, family: family}
+}
+
+func (k *key) PublicKey() (core.Key, error) {
+	pk, err := k.Key.PublicKey()
+	return newKey(pk, k.family), err
+}
+
+// memberConfig presents the dual-stack config to a member cryptosuite as its own
+type memberConfig struct {
+	core.CryptoSuiteConfig
+	provider string
+}
+
+func (c *memberConfig) SecurityProvider() string {
+	return c.provider
+}
+
+func (c *memberConfig) SecurityAlgorithm() string {
+	if c.provider == FamilySW && strings.ToUpper(c.CryptoSuiteConfig.SecurityAlgorithm()) == bccsp.GMSM3 {
+		// the sw suite only knows the SHA families
+		return bccsp.SHA2
+	}
+	return c.CryptoSuiteConfig.SecurityAlgorithm()
+}
@@ -0,0 +1,182 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dualstack
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	bccspSw "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"golang.org/x/crypto/sha3"
+)
+
+// newTestSuite returns a dual-stack suite where both families are sw suites with
+// their own key stores; the gm stand-in hashes with SHA3 to tell the families apart
+func newTestSuite(t *testing.T, defaultFamily string) *CryptoSuite {
+	swSuite, err := sw.GetSuite(256, bccsp.SHA2, bccspSw.NewInMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("failed to create sw suite: %s", err)
+	}
+	gmSuite, err := sw.GetSuite(256, bccsp.SHA3, bccspSw.NewInMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("failed to create gm suite: %s", err)
+	}
+	c, err := New(defaultFamily, map[string]core.CryptoSuite{FamilySW: swSuite, FamilyGM: gmSuite})
+	if err != nil {
+		t.Fatalf("failed to create dual-stack suite: %s", err)
+	}
+	return c
+}
+
+func TestNewUnknownDefaultFamily(t *testing.T) {
+	_, err := New("unknown", map[string]core.CryptoSuite{})
+	if err == nil {
+		t.Fatal("expected error for unknown default family")
+	}
+
+	c := newTestSuite(t, FamilySW)
+	if _, err := c.WithDefaultFamily("unknown"); err == nil {
+		t.Fatal("expected error for unknown default family")
+	}
+}
+
+func TestHashRouting(t *testing.T) {
+	msg := []byte("Hello")
+	sha2Digest := sha256.Sum256(msg)
+	sha3Digest := sha3.Sum256(msg)
+
+	c := newTestSuite(t, FamilySW)
+
+	h, err := c.Hash(msg, &bccsp.SHAOpts{})
+	if err != nil {
+		t.Fatalf("hash failed: %s", err)
+	}
+	if !bytes.Equal(h, sha2Digest[:]) {
+		t.Fatal("SHA opts should use the default family")
+	}
+
+	gmDefault, err := c.WithDefaultFamily("GM")
+	if err != nil {
+		t.Fatalf("WithDefaultFamily failed: %s", err)
+	}
+	h, err = gmDefault.Hash(msg, &bccsp.SHAOpts{})
+	if err != nil {
+		t.Fatalf("hash failed: %s", err)
+	}
+	if !bytes.Equal(h, sha3Digest[:]) {
+		t.Fatal("SHA opts should use the default family of the channel view")
+	}
+
+	h, err = gmDefault.Hash(msg, &bccsp.SHA256Opts{})
+	if err != nil {
+		t.Fatalf("hash failed: %s", err)
+	}
+	if !bytes.Equal(h, sha2Digest[:]) {
+		t.Fatal("SHA256 opts should always use the sw family")
+	}
+
+	hf, err := gmDefault.GetHash(&bccsp.SHA256Opts{})
+	if err != nil {
+		t.Fatalf("GetHash failed: %s", err)
+	}
+	hf.Write(msg)
+	if !bytes.Equal(hf.Sum(nil), sha2Digest[:]) {
+		t.Fatal("SHA256 opts should always use the sw family")
+	}
+}
+
+func TestKeyGenRouting(t *testing.T) {
+	c := newTestSuite(t, FamilyGM)
+
+	k, err := c.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %s", err)
+	}
+	if k.(*key).family != FamilySW {
+		t.Fatalf("expected ECDSA key from the sw family, got %s", k.(*key).family)
+	}
+
+	swSuite, _ := c.Suite(FamilySW)
+	if _, err = swSuite.GetKey(k.SKI()); err != nil {
+		t.Fatalf("key should be stored by the sw suite: %s", err)
+	}
+	gmSuite, _ := c.Suite(FamilyGM)
+	if _, err = gmSuite.GetKey(k.SKI()); err == nil {
+		t.Fatal("key should not be stored by the gm suite")
+	}
+
+	// GetKey falls back from the default family to the others
+	found, err := c.GetKey(k.SKI())
+	if err != nil {
+		t.Fatalf("GetKey failed: %s", err)
+	}
+	if found.(*key).family != FamilySW {
+		t.Fatalf("expected key from the sw family, got %s", found.(*key).family)
+	}
+
+	digest, err := c.Hash([]byte("Hello"), &bccsp.SHA256Opts{})
+	if err != nil {
+		t.Fatalf("hash failed: %s", err)
+	}
+	signature, err := c.Sign(found, digest, nil)
+	if err != nil {
+		t.Fatalf("Sign failed: %s", err)
+	}
+	pk, err := found.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey failed: %s", err)
+	}
+	valid, err := c.Verify(pk, signature, digest, nil)
+	if err != nil || !valid {
+		t.Fatalf("Verify failed: %v, %s", valid, err)
+	}
+}
+
+func TestKeyImportCertificate(t *testing.T) {
+	c := newTestSuite(t, FamilyGM)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dualstack"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+
+	// certificates are parsed by the sm2 package throughout the SDK
+	cert, err := sm2.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+
+	k, err := c.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyImport failed: %s", err)
+	}
+	if k.(*key).family != FamilySW {
+		t.Fatalf("expected ECDSA certificate key from the sw family, got %s", k.(*key).family)
+	}
+}
@@ -21,12 +21,15 @@ var logger = logging.NewLogger("fabsdk/core")
 
 //GetSuiteByConfig returns cryptosuite adaptor for bccsp loaded according to given config
 func GetSuiteByConfig(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
-	// TODO: delete this check?
+	// the config must select this suite, the dual-stack suite presents its config with the gm provider
 	if config.SecurityProvider() != "gm" {
 		return nil, errors.Errorf("Unsupported BCCSP Provider: %s", config.SecurityProvider())
 	}
 	
-	opts := getOptsByConfig(config)
+	opts, err := getOptsByConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	bccsp, err := getBCCSPFromOpts(opts)
 	if err != nil {
 		return nil, err
@@ -66,18 +69,23 @@ func GetSuite(securityLevel int, hashFamily string, keyStore bccsp.KeyStore) (co
 }
 
 //GetOptsByConfig Returns Factory opts for given SDK config
-func getOptsByConfig(c core.CryptoSuiteConfig) *bccspSw.GmOpts {
+func getOptsByConfig(c core.CryptoSuiteConfig) (*bccspSw.GmOpts, error) {
+	pwd, err := wrapper.KeyStorePassword(c)
+	if err != nil {
+		return nil, err
+	}
 	opts := &bccspSw.GmOpts{
 		HashFamily: c.SecurityAlgorithm(),
 		SecLevel:   c.SecurityLevel(),
 		FileKeystore: &bccspSw.FileKeystoreOpts{
 			KeyStorePath: c.KeyStorePath(),
+			Password:     pwd,
 		},
 		//Ephemeral: c.Ephemeral(),
 	}
 	logger.Debugf("Initialized SW cryptosuite11111, %v", c.SecurityAlgorithm())
 	
-	return opts
+	return opts, nil
 }
 
 func getEphemeralOpts() *bccspSw.GmOpts {
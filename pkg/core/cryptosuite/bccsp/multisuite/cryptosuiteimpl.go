@@ -9,6 +9,7 @@ package multisuite
 import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/cncc"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/dualstack"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/gm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
@@ -26,6 +27,8 @@ func GetSuiteByConfig(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
 		return cncc.GetSuiteByConfig(config)
 	case "pkcs11":
 		return pkcs11.GetSuiteByConfig(config)
+	case "dualstack":
+		return dualstack.GetSuiteByConfig(config)
 	}
 
 	return nil, errors.Errorf("Unsupported security provider requested: %s", config.SecurityProvider())
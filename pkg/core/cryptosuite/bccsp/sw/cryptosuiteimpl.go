@@ -25,7 +25,10 @@ func GetSuiteByConfig(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
 		return nil, errors.Errorf("Unsupported BCCSP Provider: %s", config.SecurityProvider())
 	}
 
-	opts := getOptsByConfig(config)
+	opts, err := getOptsByConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	bccsp, err := getBCCSPFromOpts(opts)
 	if err != nil {
 		return nil, err
@@ -65,17 +68,22 @@ func GetSuite(securityLevel int, hashFamily string, keyStore bccsp.KeyStore) (co
 }
 
 //GetOptsByConfig Returns Factory opts for given SDK config
-func getOptsByConfig(c core.CryptoSuiteConfig) *bccspSw.SwOpts {
+func getOptsByConfig(c core.CryptoSuiteConfig) (*bccspSw.SwOpts, error) {
+	pwd, err := wrapper.KeyStorePassword(c)
+	if err != nil {
+		return nil, err
+	}
 	opts := &bccspSw.SwOpts{
 		HashFamily: c.SecurityAlgorithm(),
 		SecLevel:   c.SecurityLevel(),
 		FileKeystore: &bccspSw.FileKeystoreOpts{
 			KeyStorePath: c.KeyStorePath(),
+			Password:     pwd,
 		},
 	}
 	logger.Debug("Initialized SW cryptosuite")
 
-	return opts
+	return opts, nil
 }
 
 func getEphemeralOpts() *bccspSw.SwOpts {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/pkg/errors"
 )
 
 //NewCryptoSuite returns cryptosuite adaptor for given bccsp.BCCSP implementation
@@ -25,6 +26,19 @@ func GetKey(newkey bccsp.Key) core.Key {
 	return &key{newkey}
 }
 
+//KeyStorePassword returns the keystore passphrase of the config, if it provides one (see core.KeyStorePasswordProvider)
+func KeyStorePassword(c core.CryptoSuiteConfig) ([]byte, error) {
+	p, ok := c.(core.KeyStorePasswordProvider)
+	if !ok {
+		return nil, nil
+	}
+	pwd, err := p.KeyStorePassword()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get keystore password")
+	}
+	return pwd, nil
+}
+
 // CryptoSuite provides a wrapper of BCCSP
 type CryptoSuite struct {
 	BCCSP bccsp.BCCSP
@@ -50,12 +64,18 @@ func (c *CryptoSuite) GetKey(ski []byte) (k core.Key, err error) {
 
 // Hash is a wrapper of BCCSP.Hash
 func (c *CryptoSuite) Hash(msg []byte, opts core.HashOpts) (hash []byte, err error) {
-	return c.BCCSP.Hash(msg, opts)
+	return c.BCCSP.Hash(msg, bccspHashOpts(opts))
 }
 
 // GetHash is a wrapper of BCCSP.GetHash
 func (c *CryptoSuite) GetHash(opts core.HashOpts) (h hash.Hash, err error) {
-	return c.BCCSP.GetHash(opts)
+	return c.BCCSP.GetHash(bccspHashOpts(opts))
+}
+
+// KeyDeriv is a wrapper of BCCSP.KeyDeriv
+func (c *CryptoSuite) KeyDeriv(k core.Key, opts core.KeyDerivOpts) (dk core.Key, err error) {
+	key, err := c.BCCSP.KeyDeriv(k.(*key).key, opts)
+	return GetKey(key), err
 }
 
 // Sign is a wrapper of BCCSP.Sign
@@ -68,6 +88,37 @@ func (c *CryptoSuite) Verify(k core.Key, signature, digest []byte, opts core.Sig
 	return c.BCCSP.Verify(k.(*key).key, signature, digest, opts)
 }
 
+// Encrypt is a wrapper of BCCSP.Encrypt
+func (c *CryptoSuite) Encrypt(k core.Key, plaintext []byte, opts core.EncrypterOpts) (ciphertext []byte, err error) {
+	return c.BCCSP.Encrypt(k.(*key).key, plaintext, opts)
+}
+
+// Decrypt is a wrapper of BCCSP.Decrypt
+func (c *CryptoSuite) Decrypt(k core.Key, ciphertext []byte, opts core.DecrypterOpts) (plaintext []byte, err error) {
+	return c.BCCSP.Decrypt(k.(*key).key, ciphertext, opts)
+}
+
+// SM3HMACOpts contains options for computing HMAC-SM3 with Hash and GetHash
+// using an SM4 key of the crypto suite
+type SM3HMACOpts struct {
+	Key core.Key
+}
+
+// Algorithm returns the hash algorithm identifier (to be used).
+func (opts *SM3HMACOpts) Algorithm() string {
+	return bccsp.HMACSM3
+}
+
+// bccspHashOpts replaces the keys of the crypto suite held by opts with the BCCSP keys they wrap
+func bccspHashOpts(opts core.HashOpts) bccsp.HashOpts {
+	if o, ok := opts.(*SM3HMACOpts); ok {
+		if k, ok := o.Key.(*key); ok {
+			return &bccsp.SM3HMACOpts{Key: k.key}
+		}
+	}
+	return opts
+}
+
 type key struct {
 	key bccsp.Key
 }
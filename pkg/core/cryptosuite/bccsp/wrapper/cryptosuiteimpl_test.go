@@ -88,6 +88,34 @@ func TestCryptoSuiteByConfigFailures(t *testing.T) {
 
 }
 
+// passwordConfig provides the keystore passphrase returned by pwd
+type passwordConfig struct {
+	core.CryptoSuiteConfig
+	pwd func() ([]byte, error)
+}
+
+func (c *passwordConfig) KeyStorePassword() ([]byte, error) {
+	return c.pwd()
+}
+
+func TestKeyStorePassword(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockConfig := mockcore.NewMockCryptoSuiteConfig(mockCtrl)
+
+	pwd, err := KeyStorePassword(mockConfig)
+	assert.NoError(t, err)
+	assert.Nil(t, pwd, "configs without a passphrase should store keys in plaintext")
+
+	pwd, err = KeyStorePassword(&passwordConfig{CryptoSuiteConfig: mockConfig, pwd: func() ([]byte, error) { return []byte("passphrase"), nil }})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("passphrase"), pwd)
+
+	_, err = KeyStorePassword(&passwordConfig{CryptoSuiteConfig: mockConfig, pwd: func() ([]byte, error) { return nil, errors.New("secret manager unavailable") }})
+	assert.Error(t, err, "failures of the passphrase provider should be returned")
+}
+
 // TestCreateInvalidBCCSPSecurityLevel will test cryptsuite creation with invalid BCCSP options
 func TestCreateInvalidBCCSPSecurityLevel(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
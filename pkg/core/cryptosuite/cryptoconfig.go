@@ -14,6 +14,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/pathvar"
+	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 )
 
@@ -115,3 +116,23 @@ func (c *Config) KeyStorePath() string {
 	keystorePath := pathvar.Subst(c.backend.GetString("client.credentialStore.cryptoStore.path"))
 	return path.Join(keystorePath, "keystore")
 }
+
+// KeyStorePassword returns the passphrase encrypting the private keys written to the keystore.
+// It is read from client.credentialStore.cryptoStore.password, or else from the environment
+// variable named by client.credentialStore.cryptoStore.passwordEnv. Keys are stored in plaintext
+// if neither is set.
+func (c *Config) KeyStorePassword() ([]byte, error) {
+	if pwd := c.backend.GetString("client.credentialStore.cryptoStore.password"); pwd != "" {
+		return []byte(pwd), nil
+	}
+	env := c.backend.GetString("client.credentialStore.cryptoStore.passwordEnv")
+	if env == "" {
+		return nil, nil
+	}
+	// fail rather than silently storing keys in plaintext
+	pwd := os.Getenv(env)
+	if pwd == "" {
+		return nil, errors.Errorf("keystore password environment variable [%s] is not set", env)
+	}
+	return []byte(pwd), nil
+}
@@ -59,6 +59,33 @@ func TestCAConfigKeyStorePath(t *testing.T) {
 	}
 }
 
+func TestCAConfigKeyStorePassword(t *testing.T) {
+	backendMap := make(map[string]interface{})
+	cryptoConfig, ok := ConfigFromBackend(&mocks.MockConfigBackend{KeyValueMap: backendMap}).(core.KeyStorePasswordProvider)
+	assert.True(t, ok, "expected the config to provide a keystore password")
+
+	// keys are stored in plaintext by default
+	pwd, err := cryptoConfig.KeyStorePassword()
+	assert.NoError(t, err)
+	assert.Nil(t, pwd)
+
+	backendMap["client.credentialStore.cryptoStore.passwordEnv"] = "TEST_KEYSTORE_PASSWORD"
+	_, err = cryptoConfig.KeyStorePassword()
+	assert.Error(t, err, "expected error when the password environment variable is not set")
+
+	os.Setenv("TEST_KEYSTORE_PASSWORD", "from env")
+	defer os.Unsetenv("TEST_KEYSTORE_PASSWORD")
+	pwd, err = cryptoConfig.KeyStorePassword()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("from env"), pwd)
+
+	// the configured password takes precedence
+	backendMap["client.credentialStore.cryptoStore.password"] = "from config"
+	pwd, err = cryptoConfig.KeyStorePassword()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("from config"), pwd)
+}
+
 func TestCAConfigBCCSPSecurityEnabled(t *testing.T) {
 	backend, err := config.FromFile(configTestFilePath)()
 	if err != nil {
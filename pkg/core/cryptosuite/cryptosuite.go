@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package cryptosuite
 
 import (
+	"strings"
 	"sync/atomic"
 
 	"errors"
@@ -17,6 +18,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
 )
 
 var logger = logging.NewLogger("fabsdk/core")
@@ -77,11 +79,129 @@ func DefaultInitialized() bool {
 func GetSHA256Opts() core.HashOpts {
 	return &bccsp.SHA256Opts{}
 }
-//GetGMSM3Opts returns options relating to SHA-256.
+//GetGMSM3Opts returns options relating to SM3.
 func GetGMSM3Opts() core.HashOpts {
 	return &bccsp.GMSM3Opts{}
 }
 
+//GetHashOpts returns options for the hash algorithm with the given name, as found in
+//the HashingAlgorithm value of a channel config (e.g. SHA256 or GMSM3).
+func GetHashOpts(algorithm string) (core.HashOpts, error) {
+	switch strings.ToUpper(algorithm) {
+	case bccsp.SHA2:
+		return &bccsp.SHA256Opts{}, nil
+	case "SM3":
+		return &bccsp.GMSM3Opts{}, nil
+	}
+	return bccsp.GetHashOpt(strings.ToUpper(algorithm))
+}
+
+// SM2 ciphertext formats accepted by GetSM2EncrypterOpts and GetSM2DecrypterOpts
+const (
+	// SM2C1C3C2 is the layout of GM/T 0003-2012, the default
+	SM2C1C3C2 = "C1C3C2"
+	// SM2C1C2C3 is the layout of the draft standard still used by some devices
+	SM2C1C2C3 = "C1C2C3"
+	// SM2ASN1 is the DER encoding of GM/T 0009-2012
+	SM2ASN1 = "ASN1"
+)
+
+//GetSM2EncrypterOpts returns options for SM2 public key encryption to the given ciphertext format.
+func GetSM2EncrypterOpts(format string) (core.EncrypterOpts, error) {
+	f, err := sm2CiphertextFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return &bccsp.SM2EncrypterOpts{Format: f}, nil
+}
+
+//GetSM2DecrypterOpts returns options for SM2 private key decryption of the given ciphertext format.
+func GetSM2DecrypterOpts(format string) (core.DecrypterOpts, error) {
+	f, err := sm2CiphertextFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return &bccsp.SM2DecrypterOpts{Format: f}, nil
+}
+
+func sm2CiphertextFormat(format string) (bccsp.SM2CiphertextFormat, error) {
+	switch strings.ToUpper(format) {
+	case "", SM2C1C3C2:
+		return bccsp.SM2C1C3C2, nil
+	case SM2C1C2C3:
+		return bccsp.SM2C1C2C3, nil
+	case SM2ASN1:
+		return bccsp.SM2ASN1, nil
+	}
+	return 0, errors.New("unsupported SM2 ciphertext format: " + format)
+}
+
+//GetSM2SignerOpts returns options for signing and verifying with the SM2 user ID uid (the default user ID if empty).
+//With these options Sign and Verify take the message itself instead of its digest.
+func GetSM2SignerOpts(uid []byte) core.SignerOpts {
+	return &bccsp.SM2SignerOpts{UID: uid}
+}
+
+//GetSM4CBCPKCS7ModeOpts returns options for SM4 encryption in CBC mode with PKCS7 padding.
+//A random IV is used if iv is nil. The same options decrypt the ciphertext.
+func GetSM4CBCPKCS7ModeOpts(iv []byte) core.EncrypterOpts {
+	return &bccsp.SM4CBCPKCS7ModeOpts{IV: iv}
+}
+
+//GetSM4CTRModeOpts returns options for SM4 encryption in CTR mode.
+//A random IV is used if iv is nil. The same options decrypt the ciphertext.
+func GetSM4CTRModeOpts(iv []byte) core.EncrypterOpts {
+	return &bccsp.SM4CTRModeOpts{IV: iv}
+}
+
+//GetSM4GCMModeOpts returns options for authenticated SM4 encryption in GCM mode.
+//A random nonce is used if nonce is nil. The same additional data must be given to decrypt.
+func GetSM4GCMModeOpts(nonce, additionalData []byte) core.EncrypterOpts {
+	return &bccsp.SM4GCMModeOpts{Nonce: nonce, AdditionalData: additionalData}
+}
+
+//GetSM3HMACOpts returns options for computing HMAC-SM3 with Hash and GetHash using the SM4 key k of the crypto suite.
+func GetSM3HMACOpts(k core.Key) core.HashOpts {
+	return &wrapper.SM3HMACOpts{Key: k}
+}
+
+//GetHMACSM3DeriveKeyOpts returns options for deriving an SM4 key made of the first 16 bytes of HMAC-SM3(k, arg).
+func GetHMACSM3DeriveKeyOpts(arg []byte, ephemeral bool) core.KeyDerivOpts {
+	return &bccsp.HMACSM3DeriveKeyOpts{Arg: arg, Temporary: ephemeral}
+}
+
+//GetHKDFSM3DeriveKeyOpts returns options for deriving an SM4 key with HKDF-SM3, info binds the key to its context.
+func GetHKDFSM3DeriveKeyOpts(salt, info []byte, ephemeral bool) core.KeyDerivOpts {
+	return &bccsp.HKDFSM3DeriveKeyOpts{Salt: salt, Info: info, Temporary: ephemeral}
+}
+
+//KeyDeriv derives a key from k with the crypto suite, which must implement core.KeyDeriver.
+func KeyDeriv(suite core.CryptoSuite, k core.Key, opts core.KeyDerivOpts) (core.Key, error) {
+	deriver, ok := suite.(core.KeyDeriver)
+	if !ok {
+		return nil, errors.New("cryptosuite does not support key derivation")
+	}
+	return deriver.KeyDeriv(k, opts)
+}
+
+//Encrypt encrypts plaintext with key k of the crypto suite, which must implement core.Encrypter.
+func Encrypt(suite core.CryptoSuite, k core.Key, plaintext []byte, opts core.EncrypterOpts) ([]byte, error) {
+	encrypter, ok := suite.(core.Encrypter)
+	if !ok {
+		return nil, errors.New("cryptosuite does not support encryption")
+	}
+	return encrypter.Encrypt(k, plaintext, opts)
+}
+
+//Decrypt decrypts ciphertext with key k of the crypto suite, which must implement core.Decrypter.
+func Decrypt(suite core.CryptoSuite, k core.Key, ciphertext []byte, opts core.DecrypterOpts) ([]byte, error) {
+	decrypter, ok := suite.(core.Decrypter)
+	if !ok {
+		return nil, errors.New("cryptosuite does not support decryption")
+	}
+	return decrypter.Decrypt(k, ciphertext, opts)
+}
+
 //GetSHAOpts returns options for computing SHA.
 func GetSHAOpts() core.HashOpts {
 	return &bccsp.SHAOpts{}
@@ -96,3 +216,13 @@ func GetECDSAP256KeyGenOpts(ephemeral bool) core.KeyGenOpts {
 func GetECDSAPrivateKeyImportOpts(ephemeral bool) core.KeyImportOpts {
 	return &bccsp.ECDSAPrivateKeyImportOpts{Temporary: ephemeral}
 }
+
+//GetGMSM4KeyGenOpts returns options for SM4 key generation.
+func GetGMSM4KeyGenOpts(ephemeral bool) core.KeyGenOpts {
+	return &bccsp.GMSM4KeyGenOpts{Temporary: ephemeral}
+}
+
+//GetGMSM4ImportKeyOpts returns options for SM4 key import.
+func GetGMSM4ImportKeyOpts(ephemeral bool) core.KeyImportOpts {
+	return &bccsp.GMSM4ImportKeyOpts{Temporary: ephemeral}
+}
@@ -7,17 +7,24 @@ SPDX-License-Identifier: Apache-2.0
 package cryptosuite
 
 import (
+	"bytes"
 	"testing"
 
 	"sync/atomic"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/dualstack"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/gm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
 	shaHashOptsAlgorithm       = "SHA"
 	sha256HashOptsAlgorithm    = "SHA256"
+	gmsm3HashOptsAlgorithm     = "GMSM3"
 	ecdsap256KeyGenOpts        = "ECDSAP256"
 	setDefAlreadySetErrorMsg   = "default crypto suite is already set"
 	InvalidDefSuiteSetErrorMsg = "attempting to set invalid default suite"
@@ -84,6 +91,57 @@ func TestHashOpts(t *testing.T) {
 	assert.NotZero(t, hashOpts, "Not supposed to be empty sha256HashOpts")
 	assert.True(t, hashOpts.Algorithm() == sha256HashOptsAlgorithm, "Unexpected SHA hash opts, expected [%v], got [%v]", sha256HashOptsAlgorithm, hashOpts.Algorithm())
 
+	//Get CryptoSuite Opts by channel config algorithm name
+	for name, expected := range map[string]string{"SHA256": sha256HashOptsAlgorithm, "SHA2": sha256HashOptsAlgorithm, "GMSM3": gmsm3HashOptsAlgorithm, "sm3": gmsm3HashOptsAlgorithm} {
+		hashOpts, err := GetHashOpts(name)
+		assert.Nil(t, err, "Not supposed to get error for hash algorithm [%s]", name)
+		assert.True(t, hashOpts.Algorithm() == expected, "Unexpected hash opts for [%s], expected [%v], got [%v]", name, expected, hashOpts.Algorithm())
+	}
+	_, err := GetHashOpts("MD5")
+	assert.NotNil(t, err, "Supposed to get error for unknown hash algorithm")
+
+}
+
+func TestSM2EncrypterOpts(t *testing.T) {
+
+	for format, expected := range map[string]bccsp.SM2CiphertextFormat{"": bccsp.SM2C1C3C2, SM2C1C3C2: bccsp.SM2C1C3C2, "c1c2c3": bccsp.SM2C1C2C3, SM2ASN1: bccsp.SM2ASN1} {
+		encOpts, err := GetSM2EncrypterOpts(format)
+		assert.Nil(t, err, "Not supposed to get error for SM2 ciphertext format [%s]", format)
+		assert.Equal(t, &bccsp.SM2EncrypterOpts{Format: expected}, encOpts)
+
+		decOpts, err := GetSM2DecrypterOpts(format)
+		assert.Nil(t, err, "Not supposed to get error for SM2 ciphertext format [%s]", format)
+		assert.Equal(t, &bccsp.SM2DecrypterOpts{Format: expected}, decOpts)
+	}
+
+	_, err := GetSM2EncrypterOpts("PKCS1")
+	assert.NotNil(t, err, "Supposed to get error for unknown SM2 ciphertext format")
+	_, err = GetSM2DecrypterOpts("PKCS1")
+	assert.NotNil(t, err, "Supposed to get error for unknown SM2 ciphertext format")
+}
+
+func TestSM4ModeOpts(t *testing.T) {
+
+	iv := []byte("0123456789abcdef")
+	assert.Equal(t, &bccsp.SM4CBCPKCS7ModeOpts{IV: iv}, GetSM4CBCPKCS7ModeOpts(iv))
+	assert.Equal(t, &bccsp.SM4CTRModeOpts{}, GetSM4CTRModeOpts(nil))
+	assert.Equal(t, &bccsp.SM4GCMModeOpts{Nonce: iv[:12], AdditionalData: []byte("tx1")}, GetSM4GCMModeOpts(iv[:12], []byte("tx1")))
+
+	keygenOpts := GetGMSM4KeyGenOpts(true)
+	assert.True(t, keygenOpts.Ephemeral(), "Expected keygenOpts.Ephemeral() ==> true")
+	assert.Equal(t, bccsp.GMSM4, keygenOpts.Algorithm())
+	importOpts := GetGMSM4ImportKeyOpts(false)
+	assert.False(t, importOpts.Ephemeral(), "Expected importOpts.Ephemeral() ==> false")
+	assert.Equal(t, bccsp.GMSM4, importOpts.Algorithm())
+}
+
+func TestSM2SignerOpts(t *testing.T) {
+
+	opts := GetSM2SignerOpts([]byte("alice@example.com"))
+	assert.Equal(t, &bccsp.SM2SignerOpts{UID: []byte("alice@example.com")}, opts)
+	assert.Equal(t, []byte("alice@example.com"), opts.(*bccsp.SM2SignerOpts).UserID())
+	assert.Equal(t, []byte(bccsp.DefaultSM2UID), GetSM2SignerOpts(nil).(*bccsp.SM2SignerOpts).UserID())
+	assert.Zero(t, opts.HashFunc())
 }
 
 func TestKeyGenOpts(t *testing.T) {
@@ -99,3 +157,78 @@ func TestKeyGenOpts(t *testing.T) {
 	assert.True(t, keygenOpts.Algorithm() == ecdsap256KeyGenOpts, "Unexpected SHA hash opts, expected [%v], got [%v]", ecdsap256KeyGenOpts, keygenOpts.Algorithm())
 
 }
+
+func TestSM3KeyDerivAndHMAC(t *testing.T) {
+
+	gmSuite, err := gm.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+	swSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+	dualSuite, err := dualstack.New(dualstack.FamilySW, map[string]core.CryptoSuite{dualstack.FamilySW: swSuite, dualstack.FamilyGM: gmSuite})
+	require.NoError(t, err)
+
+	msg := []byte("message to authenticate")
+	for _, suite := range []core.CryptoSuite{gmSuite, dualSuite} {
+		master, err := suite.KeyImport(bytes.Repeat([]byte{1}, 16), GetGMSM4ImportKeyOpts(true))
+		require.NoError(t, err)
+
+		for _, opts := range []core.KeyDerivOpts{GetHMACSM3DeriveKeyOpts([]byte("mychannel"), true), GetHKDFSM3DeriveKeyOpts(nil, []byte("mychannel"), true)} {
+			dk, err := KeyDeriv(suite, master, opts)
+			require.NoError(t, err, "KeyDeriv with %T failed", opts)
+			assert.True(t, dk.Symmetric())
+
+			mac, err := suite.Hash(msg, GetSM3HMACOpts(dk))
+			require.NoError(t, err, "HMAC with derived key failed")
+			assert.Len(t, mac, 32)
+
+			h, err := suite.GetHash(GetSM3HMACOpts(dk))
+			require.NoError(t, err)
+			h.Write(msg)
+			assert.Equal(t, mac, h.Sum(nil), "GetHash and Hash should give the same HMAC")
+
+			masterMAC, err := suite.Hash(msg, GetSM3HMACOpts(master))
+			require.NoError(t, err)
+			assert.NotEqual(t, masterMAC, mac, "derived key should differ from the master key")
+		}
+	}
+
+	// the sw suite derives no SM4 keys and has no SM4 keys to compute HMAC-SM3 with
+	aesKey, err := swSuite.KeyGen(&bccsp.AES256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	_, err = KeyDeriv(swSuite, aesKey, GetHKDFSM3DeriveKeyOpts(nil, nil, true))
+	assert.Error(t, err)
+	_, err = swSuite.Hash(msg, GetSM3HMACOpts(aesKey))
+	assert.Error(t, err)
+
+	_, err = KeyDeriv(struct{ core.CryptoSuite }{gmSuite}, aesKey, GetHKDFSM3DeriveKeyOpts(nil, nil, true))
+	assert.Error(t, err, "suites without key derivation should fail")
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+
+	gmSuite, err := gm.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+	swSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+	dualSuite, err := dualstack.New(dualstack.FamilySW, map[string]core.CryptoSuite{dualstack.FamilySW: swSuite, dualstack.FamilyGM: gmSuite})
+	require.NoError(t, err)
+
+	msg := []byte("off-chain payload")
+	for _, suite := range []core.CryptoSuite{gmSuite, dualSuite} {
+		k, err := suite.KeyGen(GetGMSM4KeyGenOpts(true))
+		require.NoError(t, err)
+
+		ciphertext, err := Encrypt(suite, k, msg, GetSM4CBCPKCS7ModeOpts(nil))
+		require.NoError(t, err, "Encrypt failed")
+		plaintext, err := Decrypt(suite, k, ciphertext, GetSM4CBCPKCS7ModeOpts(nil))
+		require.NoError(t, err, "Decrypt failed")
+		assert.Equal(t, msg, plaintext)
+	}
+
+	k, err := gmSuite.KeyGen(GetGMSM4KeyGenOpts(true))
+	require.NoError(t, err)
+	_, err = Encrypt(struct{ core.CryptoSuite }{gmSuite}, k, msg, GetSM4CBCPKCS7ModeOpts(nil))
+	assert.Error(t, err, "suites without encryption should fail")
+	_, err = Decrypt(struct{ core.CryptoSuite }{gmSuite}, k, msg, GetSM4CBCPKCS7ModeOpts(nil))
+	assert.Error(t, err, "suites without decryption should fail")
+}
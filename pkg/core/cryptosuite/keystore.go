@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptosuite
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm4"
+	"github.com/pkg/errors"
+)
+
+// ReencryptKeyStore re-encrypts the private keys (*_sk) and the symmetric keys (*_key) of the
+// file keystore at keyStorePath with newPwd. oldPwd decrypts the existing keys and is nil for
+// a plaintext keystore; a nil newPwd decrypts the keystore. Public keys are left untouched,
+// as are files that do not hold a PEM key, e.g. the SKI references of the cncc_gm keystore.
+// All the keys are converted before any file is rewritten, so a wrong oldPwd leaves the
+// keystore unchanged.
+func ReencryptKeyStore(keyStorePath string, oldPwd, newPwd []byte) error {
+	files, err := ioutil.ReadDir(keyStorePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read keystore [%s]", keyStorePath)
+	}
+
+	converted := make(map[string][]byte)
+	for _, f := range files {
+		if f.IsDir() || !(strings.HasSuffix(f.Name(), "_sk") || strings.HasSuffix(f.Name(), "_key")) {
+			continue
+		}
+		path := filepath.Join(keyStorePath, f.Name())
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read key [%s]", path)
+		}
+		block, _ := pem.Decode(raw)
+		if block == nil {
+			logger.Debugf("Skipping [%s]: not a PEM key", path)
+			continue
+		}
+		pemBytes, err := reencryptKey(block.Type, raw, oldPwd, newPwd)
+		if err != nil {
+			return errors.WithMessage(err, "failed to re-encrypt key "+path)
+		}
+		converted[path] = pemBytes
+	}
+
+	for path, pemBytes := range converted {
+		// write to a temporary file first so that a key is never left half written
+		tmp := path + ".tmp"
+		if err := ioutil.WriteFile(tmp, pemBytes, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write key [%s]", tmp)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return errors.Wrapf(err, "failed to replace key [%s]", path)
+		}
+	}
+	logger.Infof("Re-encrypted %d keys in keystore [%s]", len(converted), keyStorePath)
+	return nil
+}
+
+func reencryptKey(pemType string, raw, oldPwd, newPwd []byte) ([]byte, error) {
+	switch pemType {
+	case "SM4 KEY", "SM4 ENCRYPTED KEY":
+		key, err := sm4.ReadKeyFromMem(raw, nilIfEmpty(oldPwd))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt sm4 key")
+		}
+		return sm4.WriteKeytoMem(key, nilIfEmpty(newPwd))
+	case "AES PRIVATE KEY":
+		key, err := utils.PEMtoAES(raw, oldPwd)
+		if err != nil {
+			return nil, err
+		}
+		return utils.AEStoEncryptedPEM(key, newPwd)
+	default:
+		key, err := utils.PEMtoPrivateKey(raw, oldPwd)
+		if err != nil {
+			return nil, err
+		}
+		return utils.PrivateKeyToPEM(key, newPwd)
+	}
+}
+
+// the tjfoc sm4 package encrypts with any non nil password
+func nilIfEmpty(pwd []byte) []byte {
+	if len(pwd) == 0 {
+		return nil
+	}
+	return pwd
+}
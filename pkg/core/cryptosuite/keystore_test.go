@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptosuite
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm4"
+)
+
+func TestReencryptKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sm2Key, err := sm2.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key: %s", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key: %s", err)
+	}
+	sm4Key := bytes.Repeat([]byte{1}, 16)
+
+	writeKey(t, filepath.Join(dir, "sm2_sk"), mustPEM(utils.PrivateKeyToPEM(sm2Key, nil)))
+	writeKey(t, filepath.Join(dir, "ecdsa_sk"), mustPEM(utils.PrivateKeyToPEM(ecKey, nil)))
+	writeKey(t, filepath.Join(dir, "sm4_key"), mustPEM(sm4.WriteKeytoMem(sm4Key, nil)))
+	// the cncc_gm keystore only references the keys kept in NetSign
+	ski := []byte("0123456789abcdef")
+	writeKey(t, filepath.Join(dir, "netsign_sk"), ski)
+
+	pwd := []byte("keystore passphrase")
+	if err := ReencryptKeyStore(dir, nil, pwd); err != nil {
+		t.Fatalf("Failed encrypting keystore: %s", err)
+	}
+	verifyKeyStore(t, dir, pwd, sm4Key)
+	if _, err := utils.PEMtoPrivateKey(readKey(t, filepath.Join(dir, "sm2_sk")), nil); err == nil {
+		t.Fatal("Reading an encrypted key without password should fail")
+	}
+	if !bytes.Equal(readKey(t, filepath.Join(dir, "netsign_sk")), ski) {
+		t.Fatal("Non PEM files should be left untouched")
+	}
+
+	// a wrong password must leave the keystore unchanged
+	if err := ReencryptKeyStore(dir, []byte("wrong"), nil); err == nil {
+		t.Fatal("Re-encrypting with a wrong password should fail")
+	}
+	verifyKeyStore(t, dir, pwd, sm4Key)
+
+	newPwd := []byte("new passphrase")
+	if err := ReencryptKeyStore(dir, pwd, newPwd); err != nil {
+		t.Fatalf("Failed changing keystore password: %s", err)
+	}
+	verifyKeyStore(t, dir, newPwd, sm4Key)
+
+	if err := ReencryptKeyStore(dir, newPwd, nil); err != nil {
+		t.Fatalf("Failed decrypting keystore: %s", err)
+	}
+	verifyKeyStore(t, dir, nil, sm4Key)
+
+	if err := ReencryptKeyStore(filepath.Join(dir, "missing"), nil, pwd); err == nil {
+		t.Fatal("Re-encrypting a missing keystore should fail")
+	}
+}
+
+func TestReencryptLegacyECDSAKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key: %s", err)
+	}
+	raw, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("Failed marshalling ECDSA key: %s", err)
+	}
+	pwd := []byte("keystore passphrase")
+	block, err := x509.EncryptPEMBlock(rand.Reader, "PRIVATE KEY", raw, pwd, x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatalf("Failed encrypting ECDSA key: %s", err)
+	}
+	path := filepath.Join(dir, "ecdsa_sk")
+	writeKey(t, path, pem.EncodeToMemory(block))
+
+	// keys encrypted before the PKCS#8 encryption are still read, and rewritten in PKCS#8
+	if err := ReencryptKeyStore(dir, pwd, pwd); err != nil {
+		t.Fatalf("Failed re-encrypting keystore: %s", err)
+	}
+	if !bytes.Contains(readKey(t, path), []byte("ENCRYPTED PRIVATE KEY")) {
+		t.Fatal("Expected a PKCS#8 encrypted ECDSA key")
+	}
+	key, err := utils.PEMtoPrivateKey(readKey(t, path), pwd)
+	if err != nil {
+		t.Fatalf("Failed reading ECDSA key: %s", err)
+	}
+	if k, ok := key.(*ecdsa.PrivateKey); !ok || k.D.Cmp(ecKey.D) != 0 {
+		t.Fatalf("Expected the original ECDSA key, got %T", key)
+	}
+	if _, err := utils.PEMtoPrivateKey(readKey(t, path), []byte("wrong")); err == nil {
+		t.Fatal("Reading an encrypted key with a wrong password should fail")
+	}
+}
+
+func verifyKeyStore(t *testing.T, dir string, pwd []byte, sm4Key []byte) {
+	key, err := utils.PEMtoPrivateKey(readKey(t, filepath.Join(dir, "sm2_sk")), pwd)
+	if err != nil {
+		t.Fatalf("Failed reading SM2 key: %s", err)
+	}
+	if _, ok := key.(*sm2.PrivateKey); !ok {
+		t.Fatalf("Expected an SM2 key, got %T", key)
+	}
+	key, err = utils.PEMtoPrivateKey(readKey(t, filepath.Join(dir, "ecdsa_sk")), pwd)
+	if err != nil {
+		t.Fatalf("Failed reading ECDSA key: %s", err)
+	}
+	if _, ok := key.(*ecdsa.PrivateKey); !ok {
+		t.Fatalf("Expected an ECDSA key, got %T", key)
+	}
+	if len(pwd) > 0 && !bytes.Contains(readKey(t, filepath.Join(dir, "ecdsa_sk")), []byte("ENCRYPTED PRIVATE KEY")) {
+		t.Fatal("Expected a PKCS#8 encrypted ECDSA key")
+	}
+	if len(pwd) == 0 {
+		pwd = nil
+	}
+	raw, err := sm4.ReadKeyFromMem(readKey(t, filepath.Join(dir, "sm4_key")), pwd)
+	if err != nil || !bytes.Equal(raw, sm4Key) {
+		t.Fatalf("Failed reading SM4 key: %v", err)
+	}
+}
+
+func mustPEM(raw []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func writeKey(t *testing.T, path string, raw []byte) {
+	if err := ioutil.WriteFile(path, raw, 0600); err != nil {
+		t.Fatalf("Failed writing key: %s", err)
+	}
+}
+
+func readKey(t *testing.T, path string) []byte {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed reading key: %s", err)
+	}
+	return raw
+}
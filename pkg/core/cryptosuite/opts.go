@@ -23,6 +23,7 @@ type CryptoConfigOptions struct {
 	securityProviderPin
 	securityProviderLabel
 	keyStorePath
+	keyStorePassword
 }
 
 type applier func()
@@ -74,6 +75,20 @@ type keyStorePath interface {
 	KeyStorePath() string
 }
 
+// keyStorePassword interface allows to uniquely override CryptoConfig interface's KeyStorePassword() function
+type keyStorePassword interface {
+	KeyStorePassword() ([]byte, error)
+}
+
+// KeyStorePasswordFunc is a callback providing the keystore passphrase, e.g. from a secret manager.
+// It overrides CryptoConfig interface's KeyStorePassword() function when passed to fabsdk's WithCryptoSuiteConfig(opts...)
+type KeyStorePasswordFunc func() ([]byte, error)
+
+// KeyStorePassword returns the passphrase provided by f
+func (f KeyStorePasswordFunc) KeyStorePassword() ([]byte, error) {
+	return f()
+}
+
 // BuildCryptoSuiteConfigFromOptions will return an CryptoConfig instance pre-built with Optional interfaces
 // provided in fabsdk's WithConfigCrypto(opts...) call
 func BuildCryptoSuiteConfigFromOptions(opts ...interface{}) (core.CryptoSuiteConfig, error) {
@@ -104,16 +119,28 @@ func UpdateMissingOptsWithDefaultConfig(c *CryptoConfigOptions, d core.CryptoSui
 	s.set(c.securityProviderPin, nil, func() { c.securityProviderPin = d })
 	s.set(c.securityProviderLabel, nil, func() { c.securityProviderLabel = d })
 	s.set(c.keyStorePath, nil, func() { c.keyStorePath = d })
+	if p, ok := d.(keyStorePassword); ok {
+		s.set(c.keyStorePassword, nil, func() { c.keyStorePassword = p })
+	}
 
 	return c
 }
 
 // IsCryptoConfigFullyOverridden will return true if all of the argument's sub interfaces is not nil
-// (ie CryptoSuiteConfig interface not fully overridden)
+// (ie CryptoSuiteConfig interface not fully overridden). The optional keystore password is not required.
 func IsCryptoConfigFullyOverridden(c *CryptoConfigOptions) bool {
 	return !anyNil(c.isSecurityEnabled, c.securityAlgorithm, c.securityLevel, c.securityProvider, c.softVerify, c.securityProviderLibPath, c.securityProviderPin, c.securityProviderLabel, c.keyStorePath)
 }
 
+// KeyStorePassword returns the keystore passphrase of the overriding option or of the default
+// config, nil if neither provides one (see core.KeyStorePasswordProvider)
+func (c *CryptoConfigOptions) KeyStorePassword() ([]byte, error) {
+	if c.keyStorePassword == nil {
+		return nil, nil
+	}
+	return c.keyStorePassword.KeyStorePassword()
+}
+
 // will override CryptoSuiteConfig interface with functions provided by o (option)
 func setCryptoConfigWithOptionInterface(c *CryptoConfigOptions, o interface{}) error {
 	s := &setter{}
@@ -127,6 +154,7 @@ func setCryptoConfigWithOptionInterface(c *CryptoConfigOptions, o interface{}) e
 	s.set(c.securityProviderPin, func() bool { _, ok := o.(securityProviderPin); return ok }, func() { c.securityProviderPin = o.(securityProviderPin) })
 	s.set(c.securityProviderLabel, func() bool { _, ok := o.(securityProviderLabel); return ok }, func() { c.securityProviderLabel = o.(securityProviderLabel) })
 	s.set(c.keyStorePath, func() bool { _, ok := o.(keyStorePath); return ok }, func() { c.keyStorePath = o.(keyStorePath) })
+	s.set(c.keyStorePassword, func() bool { _, ok := o.(keyStorePassword); return ok }, func() { c.keyStorePassword = o.(keyStorePassword) })
 
 	if !s.isSet {
 		return errors.Errorf("option %#v is not a sub interface of CryptoSuiteConfig, at least one of its functions must be implemented.", o)
@@ -13,16 +13,17 @@ import (
 )
 
 var (
-	m0 = &Config{}
-	m1 = &mockIsSecurityEnabled{}
-	m2 = &mockSecurityAlgorithm{}
-	m3 = &mockSecurityLevel{}
-	m4 = &mockSecurityProvider{}
-	m5 = &mockSoftVerify{}
-	m6 = &mockSecurityProviderLibPath{}
-	m7 = &mockSecurityProviderPin{}
-	m8 = &mockSecurityProviderLabel{}
-	m9 = &mockKeyStorePath{}
+	m0  = &Config{}
+	m1  = &mockIsSecurityEnabled{}
+	m2  = &mockSecurityAlgorithm{}
+	m3  = &mockSecurityLevel{}
+	m4  = &mockSecurityProvider{}
+	m5  = &mockSoftVerify{}
+	m6  = &mockSecurityProviderLibPath{}
+	m7  = &mockSecurityProviderPin{}
+	m8  = &mockSecurityProviderLabel{}
+	m9  = &mockKeyStorePath{}
+	m10 = KeyStorePasswordFunc(func() ([]byte, error) { return []byte("passphrase"), nil })
 )
 
 func TestCreateCustomFullCryptotConfig(t *testing.T) {
@@ -77,7 +78,7 @@ func TestCreateCustomCryptoConfig(t *testing.T) {
 
 func TestCreateCustomCryptoConfigRemainingFunctions(t *testing.T) {
 	// try to build with the remaining implementations not tested above
-	cryptoConfigOption, err := BuildCryptoSuiteConfigFromOptions(m5, m6, m7, m8, m9)
+	cryptoConfigOption, err := BuildCryptoSuiteConfigFromOptions(m5, m6, m7, m8, m9, m10)
 	if err != nil {
 		t.Fatalf("BuildCryptoSuiteConfigFromOptions returned unexpected error %s", err)
 	}
@@ -108,6 +109,11 @@ func TestCreateCustomCryptoConfigRemainingFunctions(t *testing.T) {
 	s = cco.KeyStorePath()
 	require.Equal(t, "test/keystore/path", s, "KeyStorePath did not return expected interface value")
 
+	// test m10 implementation
+	pwd, err := cco.KeyStorePassword()
+	require.NoError(t, err)
+	require.Equal(t, []byte("passphrase"), pwd, "KeyStorePassword did not return expected interface value")
+
 	// verify if an interface was not passed as an option but was not nil, it should be nil (ie these implementations should not be populated in cco: m1, m2, m3 and m4)
 	require.Nil(t, cco.isSecurityEnabled, "isSecurityEnabled created with nil interface but got non nil one: %s. Expected nil interface", cco.isSecurityEnabled)
 	require.Nil(t, cco.securityAlgorithm, "securityAlgorithm created with nil interface but got non nil one: %s. Expected nil interface", cco.securityAlgorithm)
@@ -141,6 +147,7 @@ func TestCreateCustomCryptoConfigWithSomeDefaultFunctions(t *testing.T) {
 	require.Nil(t, cco.securityProviderPin, "securityProviderPin created with nil interface but got non nil one: %s. Expected nil interface", cco.securityProviderPin)
 	require.Nil(t, cco.securityProviderLabel, "securityProviderLabel created with nil interface but got non nil one: %s. Expected nil interface", cco.securityProviderLabel)
 	require.Nil(t, cco.keyStorePath, "keyStorePath created with nil interface but got non nil one: %s. Expected nil interface", cco.keyStorePath)
+	require.Nil(t, cco.keyStorePassword, "keyStorePassword created with nil interface but got non nil one: %s. Expected nil interface", cco.keyStorePassword)
 
 	// do the same test using IsCryptoConfigFullyOverridden() call
 	require.False(t, IsCryptoConfigFullyOverridden(cco), "IsCryptoConfigFullyOverridden is supposed to return false with an Options instance not implementing all the interface functions")
@@ -177,6 +184,7 @@ func TestCreateCustomCryptoConfigWithSomeDefaultFunctions(t *testing.T) {
 	require.NotNil(t, cco.securityProviderPin, "securityProviderPin should be populated with default interface but got nil one: %s. Expected default interface", cco.securityProviderPin)
 	require.NotNil(t, cco.securityProviderLabel, "securityProviderLabel should be populated with default interface but got nil one: %s. Expected default interface", cco.securityProviderLabel)
 	require.NotNil(t, cco.keyStorePath, "keyStorePath should be populated with default interface but got nil one: %s. Expected default interface", cco.keyStorePath)
+	require.NotNil(t, cco.keyStorePassword, "keyStorePassword should be populated with default interface but got nil one: %s. Expected default interface", cco.keyStorePassword)
 
 	// do the same test using IsCryptoConfigFullyOverridden() call
 	require.True(t, IsCryptoConfigFullyOverridden(cco), "IsCryptoConfigFullyOverridden is supposed to return true since all the interface functions should be implemented")
@@ -23,3 +23,8 @@ func NewMockSigningManager() core.SigningManager {
 func (mgr *MockSigningManager) Sign(object []byte, key core.Key) ([]byte, error) {
 	return object, nil
 }
+
+// SignWithHashOpts will sign the given object using provided key, the hash options are ignored
+func (mgr *MockSigningManager) SignWithHashOpts(object []byte, key core.Key, hashOpts core.HashOpts, opts core.SignerOpts) ([]byte, error) {
+	return object, nil
+}
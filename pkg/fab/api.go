@@ -23,6 +23,14 @@ type ClientConfig struct {
 type ClientTLSConfig struct {
 	//Client TLS information
 	Client endpoint.TLSKeyPair
+	//GMClient GM-TLS information
+	GMClient GMTLSKeyPairs
+}
+
+// GMTLSKeyPairs contains the signing and encryption key pairs used by a GM-TLS client
+type GMTLSKeyPairs struct {
+	Sign endpoint.TLSKeyPair
+	Enc  endpoint.TLSKeyPair
 }
 
 // OrdererConfig defines an orderer configuration
@@ -57,6 +65,11 @@ type ChannelEndpointConfig struct {
 	Peers map[string]PeerChannelConfig
 	//Policies list of policies for channel
 	Policies ChannelPolicies
+	//HashingAlgorithm overrides the hash algorithm from the channel config (e.g. SHA256 or GMSM3)
+	HashingAlgorithm string
+	//CryptoFamily selects the algorithm family (sw or gm) of a channel of a dual-stack crypto suite, which
+	//sets the default hash algorithm of its transactions (SHA256 or GMSM3) if HashingAlgorithm is not set
+	CryptoFamily string
 }
 
 //ChannelPolicies defines list of policies defined for a channel
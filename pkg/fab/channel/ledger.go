@@ -32,7 +32,19 @@ const (
 
 // Ledger is a client that provides access to the underlying ledger of a channel.
 type Ledger struct {
-	chName string
+	chName      string
+	hashingAlgo string
+}
+
+// LedgerOption sets an option of a Ledger
+type LedgerOption func(*Ledger)
+
+// WithHashingAlgorithm sets the hash algorithm of the channel config. Queries are signed with it
+// unless the SDK config of the channel overrides it (see txn.ChannelHashingAlgorithm).
+func WithHashingAlgorithm(algorithm string) LedgerOption {
+	return func(l *Ledger) {
+		l.hashingAlgo = algorithm
+	}
 }
 
 // ResponseVerifier checks transaction proposal response(s)
@@ -42,10 +54,13 @@ type ResponseVerifier interface {
 }
 
 // NewLedger constructs a Ledger client for the current context and named channel.
-func NewLedger(chName string) (*Ledger, error) {
+func NewLedger(chName string, opts ...LedgerOption) (*Ledger, error) {
 	l := Ledger{
 		chName: chName,
 	}
+	for _, opt := range opts {
+		opt(&l)
+	}
 	return &l, nil
 }
 
@@ -55,7 +70,7 @@ func (c *Ledger) QueryInfo(reqCtx reqContext.Context, targets []fab.ProposalProc
 	logger.Debug("queryInfo - start")
 
 	cir := createChannelInfoInvokeRequest(c.chName)
-	tprs, errs := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, errs := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 
 	responses := []*fab.BlockchainInfoResponse{}
 	for _, tpr := range tprs {
@@ -88,7 +103,7 @@ func (c *Ledger) QueryBlockByHash(reqCtx reqContext.Context, blockHash []byte, t
 	}
 
 	cir := createBlockByHashInvokeRequest(c.chName, blockHash)
-	tprs, errs := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, errs := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 
 	responses, errors := getConfigBlocks(tprs)
 	errs = multi.Append(errs, errors)
@@ -106,7 +121,7 @@ func (c *Ledger) QueryBlockByTxID(reqCtx reqContext.Context, txID fab.Transactio
 	}
 
 	cir := createBlockByTxIDInvokeRequest(c.chName, txID)
-	tprs, errs := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, errs := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 
 	responses, errors := getConfigBlocks(tprs)
 	errs = multi.Append(errs, errors)
@@ -135,7 +150,7 @@ func getConfigBlocks(tprs []*fab.TransactionProposalResponse) ([]*common.Block,
 func (c *Ledger) QueryBlock(reqCtx reqContext.Context, blockNumber uint64, targets []fab.ProposalProcessor, verifier ResponseVerifier) ([]*common.Block, error) {
 
 	cir := createBlockByNumberInvokeRequest(c.chName, blockNumber)
-	tprs, errs := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, errs := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 
 	responses, errors := getConfigBlocks(tprs)
 	errs = multi.Append(errs, errors)
@@ -157,7 +172,7 @@ func createCommonBlock(tpr *fab.TransactionProposalResponse) (*common.Block, err
 func (c *Ledger) QueryTransaction(reqCtx reqContext.Context, transactionID fab.TransactionID, targets []fab.ProposalProcessor, verifier ResponseVerifier) ([]*pb.ProcessedTransaction, error) {
 
 	cir := createTransactionByIDInvokeRequest(c.chName, transactionID)
-	tprs, errs := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, errs := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 
 	responses := []*pb.ProcessedTransaction{}
 	for _, tpr := range tprs {
@@ -185,7 +200,7 @@ func createProcessedTransaction(tpr *fab.TransactionProposalResponse) (*pb.Proce
 // This query will be made to specified targets.
 func (c *Ledger) QueryInstantiatedChaincodes(reqCtx reqContext.Context, targets []fab.ProposalProcessor, verifier ResponseVerifier) ([]*pb.ChaincodeQueryResponse, error) {
 	cir := createChaincodeInvokeRequest()
-	tprs, errs := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, errs := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 
 	responses := []*pb.ChaincodeQueryResponse{}
 	for _, tpr := range tprs {
@@ -211,7 +226,7 @@ func createChaincodeQueryResponse(tpr *fab.TransactionProposalResponse) (*pb.Cha
 // QueryCollectionsConfig queries the collections config for a chaincode on this channel.
 func (c *Ledger) QueryCollectionsConfig(reqCtx reqContext.Context, chaincodeName string, targets []fab.ProposalProcessor, verifier ResponseVerifier) ([]*common.CollectionConfigPackage, error) {
 	cir := createCollectionsConfigInvokeRequest(chaincodeName)
-	tprs, errs := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, errs := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 
 	responses := []*common.CollectionConfigPackage{}
 	for _, tpr := range tprs {
@@ -235,14 +250,17 @@ func createCollectionsConfigQueryResponse(tpr *fab.TransactionProposalResponse)
 }
 
 // QueryConfigBlock returns the current configuration block for the specified channel. If the
-// peer doesn't belong to the channel, return error
+// peer doesn't belong to the channel, return error.
+// The config block bootstraps the channel config, so its query is usually made without knowing the
+// hash algorithm of the channel: the hashingAlgorithm (or cryptoFamily) of the channel in the SDK
+// config is required when the channel doesn't use the default algorithm.
 func (c *Ledger) QueryConfigBlock(reqCtx reqContext.Context, targets []fab.ProposalProcessor, verifier ResponseVerifier) (*common.Block, error) {
 	if len(targets) == 0 {
 		return nil, errors.New("target(s) required")
 	}
 
 	cir := createConfigBlockInvokeRequest(c.chName)
-	tprs, err := queryChaincode(reqCtx, c.chName, cir, targets, verifier)
+	tprs, err := queryChaincode(reqCtx, c.chName, c.hashingAlgo, cir, targets, verifier)
 	if err != nil && len(tprs) == 0 {
 		return nil, errors.WithMessage(err, "queryChaincode failed")
 	}
@@ -255,12 +273,14 @@ func (c *Ledger) QueryConfigBlock(reqCtx reqContext.Context, targets []fab.Propo
 	return createCommonBlock(tprs[0])
 }
 
-func queryChaincode(reqCtx reqContext.Context, channelID string, request fab.ChaincodeInvokeRequest, targets []fab.ProposalProcessor, verifier ResponseVerifier) ([]*fab.TransactionProposalResponse, error) {
+// queryChaincode sends the query proposal with the hash algorithm of the channel, hashingAlgo
+// being the algorithm of the channel config if known (the SDK config overriding it)
+func queryChaincode(reqCtx reqContext.Context, channelID string, hashingAlgo string, request fab.ChaincodeInvokeRequest, targets []fab.ProposalProcessor, verifier ResponseVerifier) ([]*fab.TransactionProposalResponse, error) {
 	ctx, ok := contextImpl.RequestClientContext(reqCtx)
 	if !ok {
 		return nil, errors.New("failed get client context from reqContext for signProposal")
 	}
-	txh, err := txn.NewHeader(ctx, channelID)
+	txh, err := txn.NewHeader(ctx, channelID, fab.WithHashingAlgorithm(txn.ChannelHashingAlgorithm(ctx, channelID, hashingAlgo)))
 	if err != nil {
 		return nil, errors.WithMessage(err, "creation of transaction ID failed")
 	}
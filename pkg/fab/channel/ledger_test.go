@@ -127,6 +127,30 @@ func TestQueryInfo(t *testing.T) {
 	}
 }
 
+func TestQueryWithHashingAlgorithm(t *testing.T) {
+	peer := mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, Status: 200}
+
+	reqCtx, cancel := context.NewRequest(setupContext(), context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	channel, err := NewLedger("testChannel", WithHashingAlgorithm("SHA256"))
+	if err != nil {
+		t.Fatalf("Failed to create ledger: %s", err)
+	}
+	if _, err = channel.QueryInfo(reqCtx, []fab.ProposalProcessor{&peer}, nil); err != nil {
+		t.Fatalf("Test QueryInfo with the hash algorithm of the channel failed: %s", err)
+	}
+
+	// the query is signed with the hash algorithm of the channel config
+	channel, err = NewLedger("testChannel", WithHashingAlgorithm("UNKNOWN"))
+	if err != nil {
+		t.Fatalf("Failed to create ledger: %s", err)
+	}
+	if _, err = channel.QueryInfo(reqCtx, []fab.ProposalProcessor{&peer}, nil); err == nil {
+		t.Fatal("Query should fail with an unknown hash algorithm")
+	}
+}
+
 func TestQueryConfig(t *testing.T) {
 	channel, _ := setupTestLedger()
 
@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package membership
+
+import (
+	"container/list"
+	"sync"
+)
+
+// identityCacheCapacity is the number of serialized identities a membership keeps parsed
+const identityCacheCapacity = 1024
+
+// identityCache caches the parsed serialized identities by their bytes, evicting the
+// least recently used identity once the cache is full
+type identityCache struct {
+	sync.Mutex
+
+	m        map[string]*list.Element
+	q        *list.List
+	capacity int
+}
+
+type identityCacheEntry struct {
+	serializedID string
+	identity     *cachedIdentity
+}
+
+func newIdentityCache(capacity int) *identityCache {
+	return &identityCache{
+		m:        make(map[string]*list.Element),
+		q:        list.New(),
+		capacity: capacity,
+	}
+}
+
+// Put adds the identity parsed from serializedID to the cache
+func (c *identityCache) Put(serializedID string, ci *cachedIdentity) {
+	c.Lock()
+	defer c.Unlock()
+
+	if elem, ok := c.m[serializedID]; ok {
+		elem.Value.(*identityCacheEntry).identity = ci
+		c.q.MoveToFront(elem)
+		return
+	}
+
+	if c.q.Len() < c.capacity {
+		c.m[serializedID] = c.q.PushFront(&identityCacheEntry{serializedID, ci})
+		return
+	}
+
+	elem := c.q.Back()
+	entry := elem.Value.(*identityCacheEntry)
+	delete(c.m, entry.serializedID)
+	entry.serializedID = serializedID
+	entry.identity = ci
+	c.q.MoveToFront(elem)
+	c.m[serializedID] = elem
+}
+
+// Get returns the identity parsed from serializedID, if it is cached
+func (c *identityCache) Get(serializedID string) (*cachedIdentity, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if elem, ok := c.m[serializedID]; ok {
+		c.q.MoveToFront(elem)
+		return elem.Value.(*identityCacheEntry).identity, true
+	}
+	return nil, false
+}
@@ -0,0 +1,42 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package membership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityCacheEviction(t *testing.T) {
+	cache := newIdentityCache(2)
+	id1, id2, id3 := &cachedIdentity{mspID: "1"}, &cachedIdentity{mspID: "2"}, &cachedIdentity{mspID: "3"}
+
+	cache.Put("id1", id1)
+	cache.Put("id2", id2)
+	ci, ok := cache.Get("id1")
+	assert.True(t, ok)
+	assert.True(t, ci == id1)
+
+	// the least recently used identity is evicted
+	cache.Put("id3", id3)
+	_, ok = cache.Get("id2")
+	assert.False(t, ok, "id2 should have been evicted")
+	ci, ok = cache.Get("id1")
+	assert.True(t, ok)
+	assert.True(t, ci == id1)
+	ci, ok = cache.Get("id3")
+	assert.True(t, ok)
+	assert.True(t, ci == id3)
+	assert.Equal(t, 2, cache.q.Len())
+
+	// an identity put again replaces the cached one
+	cache.Put("id3", id2)
+	ci, _ = cache.Get("id3")
+	assert.True(t, ci == id2)
+	assert.Equal(t, 2, len(cache.m))
+}
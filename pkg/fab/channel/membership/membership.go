@@ -14,7 +14,6 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
-	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -28,6 +27,22 @@ var logger = logging.NewLogger("fabsdk/fab")
 type identityImpl struct {
 	mspManager msp.MSPManager
 	msps       []string
+	caCerts    map[string]*mspCACerts
+	// identities caches the parsed serialized identities by their bytes
+	identities *identityCache
+}
+
+// cachedIdentity holds the certificate and the MSP identity parsed from a serialized identity
+type cachedIdentity struct {
+	mspID string
+	cert  *verifier.Certificate
+	id    msp.Identity
+}
+
+// mspCACerts holds the root and intermediate certs of an MSP, of any algorithm (ECDSA, RSA or SM2)
+type mspCACerts struct {
+	roots         []*verifier.Certificate
+	intermediates []*verifier.Certificate
 }
 
 // Context holds the providers
@@ -42,31 +57,103 @@ func New(ctx Context, cfg fab.ChannelCfg) (fab.ChannelMembership, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &identityImpl{mspManager: mspManager, msps: mspNames}, nil
+	caCerts, err := loadMSPCACerts(cfg.MSPs())
+	if err != nil {
+		return nil, err
+	}
+	return &identityImpl{
+		mspManager: mspManager,
+		msps:       mspNames,
+		caCerts:    caCerts,
+		identities: newIdentityCache(identityCacheCapacity),
+	}, nil
 }
 
 func (i *identityImpl) Validate(serializedID []byte) error {
-	err := areCertDatesValid(serializedID)
+	ci, err := i.identity(serializedID)
 	if err != nil {
-		logger.Errorf("Cert error %s", err)
+		// the dates of the certificate are checked before the deserialization error
+		if _, cert, parseErr := parseSerializedIdentity(serializedID); parseErr == nil {
+			if dateErr := validateDates(cert); dateErr != nil {
+				return dateErr
+			}
+		}
 		return err
 	}
 
-	id, err := i.mspManager.DeserializeIdentity(serializedID)
-	if err != nil {
-		logger.Errorf("failed to deserialize identity: %s", err)
+	if err = validateDates(ci.cert); err != nil {
+		return err
+	}
+
+	if err = ci.id.Validate(); err != nil {
+		return err
+	}
+
+	return i.validateChain(ci.mspID, ci.cert)
+}
+
+func validateDates(cert *verifier.Certificate) error {
+	if err := cert.ValidateDates(); err != nil {
+		logger.Warnf("Certificate error '%s' for cert '%v'", err, cert.SerialNumber)
 		return err
 	}
-	return id.Validate()
+	return nil
+}
+
+// validateChain checks the signatures of the chain from cert to a root of the MSP,
+// whatever the algorithms (ECDSA, RSA or SM2) of the certificates of the chain
+func (i *identityImpl) validateChain(mspID string, cert *verifier.Certificate) error {
+	caCerts, ok := i.caCerts[mspID]
+	if !ok {
+		return errors.Errorf("MSP %s is unknown", mspID)
+	}
+	if _, err := cert.Verify(caCerts.roots, caCerts.intermediates); err != nil {
+		logger.Warnf("Certificate chain error '%s' for cert '%v'", err, cert.SerialNumber)
+		return errors.WithMessage(err, "the supplied identity is not valid")
+	}
+	return nil
 }
 
 func (i *identityImpl) Verify(serializedID []byte, msg []byte, sig []byte) error {
-	id, err := i.mspManager.DeserializeIdentity(serializedID)
+	ci, err := i.identity(serializedID)
 	if err != nil {
 		return err
 	}
 
-	return id.Verify(msg, sig)
+	return ci.id.Verify(msg, sig)
+}
+
+// Certificate returns the MSP ID and the certificate of the serialized identity (see verifier.CertificateCache)
+func (i *identityImpl) Certificate(serializedID []byte) (string, *verifier.Certificate, error) {
+	ci, err := i.identity(serializedID)
+	if err != nil {
+		return "", nil, err
+	}
+	return ci.mspID, ci.cert, nil
+}
+
+// identity parses and deserializes the serialized identity, once per identity as long as
+// it stays in the cache. Identities that cannot be deserialized are not cached.
+func (i *identityImpl) identity(serializedID []byte) (*cachedIdentity, error) {
+	if ci, ok := i.identities.Get(string(serializedID)); ok {
+		return ci, nil
+	}
+
+	sID, cert, err := parseSerializedIdentity(serializedID)
+	if err != nil {
+		logger.Errorf("Cert error %s", err)
+		return nil, err
+	}
+
+	id, err := i.mspManager.DeserializeIdentity(serializedID)
+	if err != nil {
+		logger.Errorf("failed to deserialize identity: %s", err)
+		return nil, err
+	}
+
+	ci := &cachedIdentity{mspID: sID.Mspid, cert: cert, id: id}
+	i.identities.Put(string(serializedID), ci)
+	return ci, nil
 }
 
 func (i *identityImpl) ContainsMSP(msp string) bool {
@@ -78,28 +165,23 @@ func (i *identityImpl) ContainsMSP(msp string) bool {
 	return false
 }
 
-func areCertDatesValid(serializedID []byte) error {
+func parseSerializedIdentity(serializedID []byte) (*mb.SerializedIdentity, *verifier.Certificate, error) {
 
 	sID := &mb.SerializedIdentity{}
 	err := proto.Unmarshal(serializedID, sID)
 	if err != nil {
-		return errors.Wrap(err, "could not deserialize a SerializedIdentity")
+		return nil, nil, errors.Wrap(err, "could not deserialize a SerializedIdentity")
 	}
 
 	bl, _ := pem.Decode(sID.IdBytes)
 	if bl == nil {
-		return errors.New("could not decode the PEM structure")
+		return nil, nil, errors.New("could not decode the PEM structure")
 	}
-	cert, err := sm2.ParseCertificate(bl.Bytes)
+	cert, err := verifier.ParseCertificate(bl.Bytes)
 	if err != nil {
-		return err
-	}
-	err = verifier.ValidateCertificateDates(cert)
-	if err != nil {
-		logger.Warnf("Certificate error '%s' for cert '%v'", err, cert.SerialNumber)
-		return err
+		return nil, nil, err
 	}
-	return nil
+	return sID, cert, nil
 }
 
 func createMSPManager(ctx Context, cfg fab.ChannelCfg) (msp.MSPManager, []string, error) {
@@ -188,6 +270,34 @@ func loadMSPs(mspConfigs []*mb.MSPConfig, cs core.CryptoSuite) ([]msp.MSP, error
 	return msps, nil
 }
 
+func loadMSPCACerts(mspConfigs []*mb.MSPConfig) (map[string]*mspCACerts, error) {
+	caCerts := make(map[string]*mspCACerts)
+	for _, config := range mspConfigs {
+		fabricConfig, err := getFabricConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		certs := &mspCACerts{}
+		for _, pemCerts := range fabricConfig.RootCerts {
+			roots, err := verifier.ParsePEMCertificates(pemCerts)
+			if err != nil {
+				return nil, errors.WithMessage(err, "failed to parse root certs of MSP "+fabricConfig.Name)
+			}
+			certs.roots = append(certs.roots, roots...)
+		}
+		for _, pemCerts := range fabricConfig.IntermediateCerts {
+			intermediates, err := verifier.ParsePEMCertificates(pemCerts)
+			if err != nil {
+				return nil, errors.WithMessage(err, "failed to parse intermediate certs of MSP "+fabricConfig.Name)
+			}
+			certs.intermediates = append(certs.intermediates, intermediates...)
+		}
+		caCerts[fabricConfig.Name] = certs
+	}
+	return caCerts, nil
+}
+
 func getFabricConfig(config *mb.MSPConfig) (*mb.FabricMSPConfig, error) {
 
 	fabricConfig := &mb.FabricMSPConfig{}
@@ -229,17 +339,22 @@ func addCertsToConfig(config fab.EndpointConfig, pemCertsList [][]byte) {
 				continue
 			}
 
-			cert, err := x509.ParseCertificate(block.Bytes)
+			cert, err := verifier.ParseCertificate(block.Bytes)
 			if err != nil {
 				continue
 			}
-			err = verifier.ValidateTLSCertificateDates(cert)
+			err = cert.ValidateDates()
 			if err != nil {
 				logger.Warn("%v", err)
 				continue
 			}
 
-			certs = append(certs, cert)
+			// SM2 certs are only used by GM-TLS, which has its own CA certs
+			if cert.X509() == nil {
+				logger.Debugf("skipping %s TLS CA cert [%s]", cert.Algorithm, cert.Subject.CommonName)
+				continue
+			}
+			certs = append(certs, cert.X509())
 		}
 	}
 
@@ -8,6 +8,7 @@ package membership
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -27,6 +28,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 	"github.com/pkg/errors"
 
 	"github.com/golang/protobuf/proto"
@@ -34,6 +36,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const configPath = "../../../../test/fixtures/fabric/v1/crypto-config"
@@ -207,6 +210,125 @@ func TestNewMembership(t *testing.T) {
 	assert.NotNil(t, m.Verify(badEndorser, []byte("test"), []byte("test1")))
 }
 
+func TestIdentityCache(t *testing.T) {
+	goodMSPID := "GoodMSP"
+
+	ctx := mocks.NewMockProviderContext()
+	cfg := mocks.NewMockChannelCfg("")
+	fabCertPool, err := tls.NewCertPool(false)
+	assert.Nil(t, err)
+	endpointConfig := &mocks.MockConfig{CustomTLSCACertPool: fabCertPool}
+
+	cfg.MockMSPs = []*mb.MSPConfig{buildMSPConfig(goodMSPID, []byte(validRootCA))}
+	m, err := New(Context{Providers: ctx, EndpointConfig: endpointConfig}, cfg)
+	assert.Nil(t, err)
+
+	goodEndorser, err := proto.Marshal(&mb.SerializedIdentity{Mspid: goodMSPID, IdBytes: []byte(certPem)})
+	assert.Nil(t, err)
+	badEndorser, err := proto.Marshal(&mb.SerializedIdentity{Mspid: "BadMSP", IdBytes: []byte(certPem)})
+	assert.Nil(t, err)
+
+	impl := m.(*identityImpl)
+	assert.Nil(t, m.Validate(goodEndorser))
+	cached, ok := impl.identities.Get(string(goodEndorser))
+	assert.True(t, ok, "identity should be cached after validation")
+
+	// later calls use the cached identity
+	assert.Nil(t, m.Validate(goodEndorser))
+	assert.Nil(t, m.Verify(goodEndorser, []byte("test"), []byte("test1")))
+	mspID, cert, err := impl.Certificate(goodEndorser)
+	assert.Nil(t, err)
+	assert.Equal(t, goodMSPID, mspID)
+	assert.True(t, cert == cached.cert)
+
+	// identities that fail to deserialize are not cached
+	assert.NotNil(t, m.Validate(badEndorser))
+	_, ok = impl.identities.Get(string(badEndorser))
+	assert.False(t, ok)
+	_, _, err = impl.Certificate(badEndorser)
+	assert.NotNil(t, err)
+}
+
+// plainSM2Signer signs the plain digest handed over by sm2.CreateCertificate, without the Z value
+// of GM/T 0009, like the sm2 package used to
+type plainSM2Signer struct {
+	*sm2.PrivateKey
+}
+
+type testCert struct {
+	cert *sm2.Certificate
+	key  crypto.Signer
+	pem  []byte
+}
+
+// newTestCert issues a cert signed by parent with signer, or self-signed if parent is nil
+func newTestCert(t *testing.T, cn string, key crypto.Signer, isCA bool, parent *testCert, signer crypto.Signer) *testCert {
+	template := &sm2.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		SubjectKeyId:          []byte(cn),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage = sm2.KeyUsageCertSign | sm2.KeyUsageCRLSign
+	}
+	issuer := template
+	if parent != nil {
+		issuer = parent.cert
+	}
+	der, err := sm2.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+	require.NoError(t, err)
+	cert, err := sm2.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func TestValidateMixedChain(t *testing.T) {
+	mspID := "MixedMSP"
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	root := newTestCert(t, "ecdsa-root", ecdsaKey, true, nil, ecdsaKey)
+
+	sm2Key, err := sm2.GenerateKey()
+	require.NoError(t, err)
+	intermediate := newTestCert(t, "sm2-intermediate", sm2Key, true, root, root.key)
+
+	leafKey, err := sm2.GenerateKey()
+	require.NoError(t, err)
+	sm2Leaf := newTestCert(t, "sm2-leaf", leafKey, false, intermediate, intermediate.key)
+	// same key and subject, but signed over the plain SM3 digest instead of SM3(ZA || tbsCertificate)
+	plainLeaf := newTestCert(t, "sm2-leaf", leafKey, false, intermediate, plainSM2Signer{sm2Key})
+
+	config := &mb.FabricMSPConfig{
+		Name:              mspID,
+		RootCerts:         [][]byte{root.pem},
+		IntermediateCerts: [][]byte{intermediate.pem},
+	}
+	cfg := mocks.NewMockChannelCfg("")
+	cfg.MockMSPs = []*mb.MSPConfig{{Type: 0, Config: marshalOrPanic(config)}}
+	fabCertPool, err := tls.NewCertPool(false)
+	require.NoError(t, err)
+	m, err := New(Context{Providers: mocks.NewMockProviderContext(), EndpointConfig: &mocks.MockConfig{CustomTLSCACertPool: fabCertPool}}, cfg)
+	require.NoError(t, err)
+
+	assert.NoError(t, m.Validate(serializeIdentity(t, mspID, sm2Leaf.pem)))
+
+	// SM2 signatures must hash the Z value of the signer (GM/T 0009)
+	err = m.Validate(serializeIdentity(t, mspID, plainLeaf.pem))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signed by unknown authority")
+}
+
+func serializeIdentity(t *testing.T, mspID string, pemCert []byte) []byte {
+	serializedID, err := proto.Marshal(&mb.SerializedIdentity{Mspid: mspID, IdBytes: pemCert})
+	require.NoError(t, err)
+	return serializedID
+}
+
 func buildMSPConfig(name string, root []byte) *mb.MSPConfig {
 	return &mb.MSPConfig{
 		Type:   0,
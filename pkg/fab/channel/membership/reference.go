@@ -9,6 +9,7 @@ package membership
 import (
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/lazyref"
 	"github.com/pkg/errors"
@@ -57,6 +58,23 @@ func (ref *Ref) Verify(serializedID []byte, msg []byte, sig []byte) error {
 	return membership.Verify(serializedID, msg, sig)
 }
 
+// Certificate returns the MSP ID and the certificate of the serialized identity, from the
+// cache of the underlying reference if it keeps one
+func (ref *Ref) Certificate(serializedID []byte) (string, *verifier.Certificate, error) {
+	membership, err := ref.get()
+	if err != nil {
+		return "", nil, err
+	}
+	if cache, ok := membership.(verifier.CertificateCache); ok {
+		return cache.Certificate(serializedID)
+	}
+	sID, cert, err := parseSerializedIdentity(serializedID)
+	if err != nil {
+		return "", nil, err
+	}
+	return sID.Mspid, cert, nil
+}
+
 // ContainsMSP checks if given MSP is available in the underlying reference
 func (ref *Ref) ContainsMSP(msp string) bool {
 	membership, err := ref.get()
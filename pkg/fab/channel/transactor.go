@@ -21,9 +21,10 @@ import (
 
 // Transactor enables sending transactions and transaction proposals on the channel.
 type Transactor struct {
-	reqCtx    reqContext.Context
-	ChannelID string
-	orderers  []fab.Orderer
+	reqCtx      reqContext.Context
+	ChannelID   string
+	orderers    []fab.Orderer
+	hashingAlgo string
 }
 
 // NewTransactor returns a Transactor for the current context and channel config.
@@ -44,9 +45,10 @@ func NewTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg) (*Transactor,
 	//}
 
 	t := Transactor{
-		reqCtx:    reqCtx,
-		ChannelID: cfg.ID(),
-		orderers:  orderers,
+		reqCtx:      reqCtx,
+		ChannelID:   cfg.ID(),
+		orderers:    orderers,
+		hashingAlgo: cfg.HashingAlgorithm(),
 	}
 	return &t, nil
 }
@@ -147,6 +149,10 @@ func (t *Transactor) CreateTransactionHeader(opts ...fab.TxnHeaderOpt) (fab.Tran
 		return nil, errors.New("failed get client context from reqContext for txn Header")
 	}
 
+	// the hashing algorithm of the channel (the SDK config overriding the channel config)
+	// may be overridden by the given options
+	opts = append([]fab.TxnHeaderOpt{fab.WithHashingAlgorithm(txn.ChannelHashingAlgorithm(ctx, t.ChannelID, t.hashingAlgo))}, opts...)
+
 	txh, err := txn.NewHeader(ctx, t.ChannelID, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "new transaction ID failed")
@@ -186,3 +192,28 @@ func (t *Transactor) SendTransaction(tx *fab.Transaction) (*fab.TransactionRespo
 
 	return txn.Send(reqCtx, tx, t.orderers)
 }
+
+// NewBroadcaster returns a broadcaster sending transactions to the orderers of the channel on
+// reused broadcast streams. The broadcaster must be closed once the transactions have been sent.
+func (t *Transactor) NewBroadcaster() (*txn.Broadcaster, error) {
+	ctx, ok := contextImpl.RequestClientContext(t.reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for NewBroadcaster")
+	}
+
+	return txn.NewBroadcaster(ctx, t.orderers), nil
+}
+
+// SendEnvelope sends an envelope signed by the creator of the transaction to the orderers of the channel.
+// The envelope may have been signed outside of the SDK.
+func (t *Transactor) SendEnvelope(envelope *fab.SignedEnvelope) (*fab.TransactionResponse, error) {
+	ctx, ok := contextImpl.RequestClientContext(t.reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for SendEnvelope")
+	}
+
+	reqCtx, cancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeoutType(fab.OrdererResponse), contextImpl.WithParent(t.reqCtx))
+	defer cancel()
+
+	return txn.BroadcastEnvelope(reqCtx, envelope, t.orderers)
+}
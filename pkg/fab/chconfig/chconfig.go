@@ -67,6 +67,7 @@ type ChannelCfg struct {
 	orderers     []string
 	versions     *fab.Versions
 	capabilities map[fab.ConfigGroupKey]map[string]bool
+	hashingAlgo  string
 }
 
 // NewChannelCfg creates channel cfg
@@ -105,6 +106,11 @@ func (cfg *ChannelCfg) Versions() *fab.Versions {
 	return cfg.versions
 }
 
+// HashingAlgorithm returns the name of the hash algorithm used by the channel (e.g. SHA256)
+func (cfg *ChannelCfg) HashingAlgorithm() string {
+	return cfg.hashingAlgo
+}
+
 // HasCapability indicates whether or not the given group has the given capability
 func (cfg *ChannelCfg) HasCapability(group fab.ConfigGroupKey, capability string) bool {
 	groupCapabilities, ok := cfg.capabilities[group]
@@ -154,6 +160,7 @@ func (c *ChannelConfig) queryPeers(reqCtx reqContext.Context) (*ChannelCfg, erro
 		return nil, errors.New("failed get client context from reqContext for signPayload")
 	}
 
+	// the channel config being queried, the hash algorithm of the channel comes from the SDK config only
 	l, err := channel.NewLedger(c.channelID)
 	if err != nil {
 		return nil, errors.WithMessage(err, "ledger client creation failed")
@@ -511,6 +518,16 @@ func loadOrdererAddressesKey(configValue *common.ConfigValue, configItems *Chann
 
 }
 
+func loadHashingAlgorithm(configValue *common.ConfigValue, configItems *ChannelCfg) error {
+	hashingAlgorithm := &common.HashingAlgorithm{}
+	err := proto.Unmarshal(configValue.Value, hashingAlgorithm)
+	if err != nil {
+		return errors.Wrap(err, "unmarshal hashing algorithm from config failed")
+	}
+	configItems.hashingAlgo = hashingAlgorithm.Name
+	return nil
+}
+
 func loadCapabilities(configValue *common.ConfigValue, configItems *ChannelCfg, groupName string) error {
 	capabilities := &common.Capabilities{}
 	err := proto.Unmarshal(configValue.Value, capabilities)
@@ -575,13 +592,10 @@ func loadConfigValue(configItems *ChannelCfg, key string, versionsValue *common.
 	//	}
 	//	// TODO: Do something with this value
 
-	//case channelConfig.HashingAlgorithmKey:
-	//	hashingAlgorithm := &common.HashingAlgorithm{}
-	//	err := proto.Unmarshal(configValue.Value, hashingAlgorithm)
-	//	if err != nil {
-	//		return errors.Wrap(err, "unmarshal hashing algorithm from config failed")
-	//	}
-	//	// TODO: Do something with this value
+	case channelConfig.HashingAlgorithmKey:
+		if err := loadHashingAlgorithm(configValue, configItems); err != nil {
+			return err
+		}
 
 	//case channelConfig.ConsortiumKey:
 	//	consortium := &common.Consortium{}
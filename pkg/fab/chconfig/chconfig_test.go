@@ -272,6 +272,23 @@ func TestCapabilities(t *testing.T) {
 	assert.Falsef(t, chConfig.HasCapability(fab.ApplicationGroupKey, capability2), "not expecting application capability [%s]", capability2)
 }
 
+func TestHashingAlgorithm(t *testing.T) {
+	builder := &mocks.MockConfigBlockBuilder{
+		MockConfigGroupBuilder: mocks.MockConfigGroupBuilder{
+			ModPolicy:      "Admins",
+			MSPNames:       []string{"Org1MSP"},
+			OrdererAddress: "localhost:9999",
+			RootCA:         validRootCA,
+		},
+		Index:           0,
+		LastConfigIndex: 0,
+	}
+
+	chConfig, err := extractConfig("mychannel", builder.Build())
+	require.NoError(t, err)
+	assert.Equal(t, "SHA2", chConfig.HashingAlgorithm(), "expecting hashing algorithm from channel config")
+}
+
 func testResolveOptsDefaultValues(t *testing.T, channelID string) {
 	user := mspmocks.NewMockSigningIdentity("test", "test")
 	ctx := mocks.NewMockContext(user)
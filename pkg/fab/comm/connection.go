@@ -12,6 +12,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls/gmcredentials"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
@@ -127,7 +129,19 @@ func newDialOpts(config fab.EndpointConfig, url string, params *params) ([]grpc.
 
 	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.FailFast(params.failFast)))
 
-	if endpoint.AttemptSecured(url, params.insecure) {
+	if endpoint.AttemptSecured(url, params.insecure) && params.gmTLS {
+		gmTLSConfig, err := comm.GMTLSConfig(params.gmCertificate, params.hostOverride, config)
+		if err != nil {
+			return nil, err
+		}
+		//verify if certificate was expired or not yet valid
+		gmTLSConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*sm2.Certificate) error {
+			return verifier.VerifyPeerCertificate(rawCerts, verifiedChains)
+		}
+
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(gmcredentials.NewTLS(gmTLSConfig)))
+		logger.Debugf("Creating a GM-TLS secure connection to [%s] with TLS HostOverride [%s]", url, params.hostOverride)
+	} else if endpoint.AttemptSecured(url, params.insecure) {
 		tlsConfig, err := comm.TLSConfig(params.certificate, params.hostOverride, config)
 		if err != nil {
 			return nil, err
@@ -7,9 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package comm
 
 import (
+	reqContext "context"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls/gmcredentials"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+
 	eventmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/mocks"
 	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
@@ -44,6 +53,118 @@ func TestConnection(t *testing.T) {
 	conn.Close()
 }
 
+func TestGMTLSConnection(t *testing.T) {
+	caCert, signCert, encCert, err := newGMTLSServerCerts("peer0.example.com")
+	if err != nil {
+		t.Fatalf("error generating GM-TLS certificates: %s", err)
+	}
+
+	srv := &fabmocks.MockEndorserServer{
+		Creds: gmcredentials.NewTLS(&gmtls.Config{
+			GMSupport:    gmtls.NewGMSupport(),
+			Certificates: []gmtls.Certificate{signCert, encCert},
+		}),
+	}
+	addr := srv.Start("127.0.0.1:0")
+	defer srv.Stop()
+
+	context := newMockContext()
+	conn, err := NewConnection(context, "grpcs://"+addr, WithGMCertificate(caCert), WithHostOverride("peer0.example.com"))
+	if err != nil {
+		t.Fatalf("error creating new GM-TLS connection: %s", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := pb.NewEndorserClient(conn.ClientConn()).ProcessProposal(ctx, &pb.SignedProposal{})
+	if err != nil {
+		t.Fatalf("error sending proposal over GM-TLS: %s", err)
+	}
+	if resp.Response.Status != 200 {
+		t.Fatalf("expected status 200 but got %d", resp.Response.Status)
+	}
+
+	_, err = NewConnection(context, "grpcs://"+addr, WithGMCertificate(nil))
+	if err == nil {
+		t.Fatal("expected error creating GM-TLS connection without root certificates")
+	}
+}
+
+func TestIsGMTLSEnabled(t *testing.T) {
+	if IsGMTLSEnabled(nil) {
+		t.Fatal("expected GM-TLS to be disabled without grpc options")
+	}
+	if !IsGMTLSEnabled(map[string]interface{}{"gm-tls": "true"}) {
+		t.Fatal("expected GM-TLS to be enabled by the endpoint options")
+	}
+	if !IsGMTLSEnabled(map[string]interface{}{}, map[string]interface{}{"gm-tls": true}) {
+		t.Fatal("expected GM-TLS to be enabled by the default options")
+	}
+	if IsGMTLSEnabled(map[string]interface{}{"gm-tls": false}, map[string]interface{}{"gm-tls": true}) {
+		t.Fatal("expected the endpoint options to override the default options")
+	}
+}
+
+// newGMTLSServerCerts generates an SM2 CA along with a signing and an encryption
+// certificate for the given host
+func newGMTLSServerCerts(host string) (*sm2.Certificate, gmtls.Certificate, gmtls.Certificate, error) {
+	caKey, err := sm2.GenerateKey()
+	if err != nil {
+		return nil, gmtls.Certificate{}, gmtls.Certificate{}, err
+	}
+	caTemplate := &sm2.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              sm2.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SignatureAlgorithm:    sm2.SM2WithSM3,
+	}
+	caDER, err := sm2.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, gmtls.Certificate{}, gmtls.Certificate{}, err
+	}
+	caCert, err := sm2.ParseCertificate(caDER)
+	if err != nil {
+		return nil, gmtls.Certificate{}, gmtls.Certificate{}, err
+	}
+
+	newCert := func(serial int64, usage sm2.KeyUsage) (gmtls.Certificate, error) {
+		key, err := sm2.GenerateKey()
+		if err != nil {
+			return gmtls.Certificate{}, err
+		}
+		template := &sm2.Certificate{
+			SerialNumber:       big.NewInt(serial),
+			Subject:            pkix.Name{CommonName: host},
+			DNSNames:           []string{host},
+			NotBefore:          time.Now().Add(-time.Hour),
+			NotAfter:           time.Now().Add(time.Hour),
+			KeyUsage:           usage,
+			ExtKeyUsage:        []sm2.ExtKeyUsage{sm2.ExtKeyUsageServerAuth},
+			SignatureAlgorithm: sm2.SM2WithSM3,
+		}
+		der, err := sm2.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			return gmtls.Certificate{}, err
+		}
+		return gmtls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+	}
+
+	signCert, err := newCert(2, sm2.KeyUsageDigitalSignature)
+	if err != nil {
+		return nil, gmtls.Certificate{}, gmtls.Certificate{}, err
+	}
+	encCert, err := newCert(3, sm2.KeyUsageKeyEncipherment|sm2.KeyUsageDataEncipherment)
+	if err != nil {
+		return nil, gmtls.Certificate{}, gmtls.Certificate{}, err
+	}
+	return caCert, signCert, encCert, nil
+}
+
 // Use the mock deliver server for testing
 var testServer *eventmocks.MockDeliverServer
 var endorserAddr []string
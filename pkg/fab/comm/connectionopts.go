@@ -10,6 +10,7 @@ import (
 	"crypto/x509"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/spf13/cast"
@@ -19,6 +20,8 @@ import (
 type params struct {
 	hostOverride    string
 	certificate     *x509.Certificate
+	gmTLS           bool
+	gmCertificate   *sm2.Certificate
 	keepAliveParams keepalive.ClientParameters
 	failFast        bool
 	insecure        bool
@@ -50,6 +53,17 @@ func WithCertificate(value *x509.Certificate) options.Opt {
 	}
 }
 
+// WithGMCertificate enables GM-TLS for the connection and sets the SM2 certificate
+// used to verify the server. If the certificate is nil then only the GM-TLS
+// CA certs from the endpoint config are used.
+func WithGMCertificate(value *sm2.Certificate) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(gmCertificateSetter); ok {
+			setter.SetGMCertificate(value)
+		}
+	}
+}
+
 // WithKeepAliveParams sets the GRPC keep-alive parameters
 func WithKeepAliveParams(value keepalive.ClientParameters) options.Opt {
 	return func(p options.Params) {
@@ -101,6 +115,16 @@ func (p *params) SetCertificate(value *x509.Certificate) {
 	p.certificate = value
 }
 
+func (p *params) SetGMCertificate(value *sm2.Certificate) {
+	if value != nil {
+		logger.Debugf("setting GM certificate [subject: %s, serial: %s]", value.Subject, value.SerialNumber)
+	} else {
+		logger.Debug("setting nil GM certificate")
+	}
+	p.gmTLS = true
+	p.gmCertificate = value
+}
+
 func (p *params) SetKeepAliveParams(value keepalive.ClientParameters) {
 	logger.Debugf("KeepAliveParams: %#v", value)
 	p.keepAliveParams = value
@@ -129,6 +153,10 @@ type certificateSetter interface {
 	SetCertificate(value *x509.Certificate)
 }
 
+type gmCertificateSetter interface {
+	SetGMCertificate(value *sm2.Certificate)
+}
+
 type keepAliveParamsSetter interface {
 	SetKeepAliveParams(value keepalive.ClientParameters)
 }
@@ -154,6 +182,9 @@ func OptsFromPeerConfig(peerCfg *fab.PeerConfig) []options.Opt {
 		WithKeepAliveParams(getKeepAliveOptions(peerCfg)),
 		WithCertificate(peerCfg.TLSCACert),
 	}
+	if IsGMTLSEnabled(peerCfg.GRPCOptions) {
+		opts = append(opts, WithGMCertificate(peerCfg.GMTLSCACert))
+	}
 	if isInsecureAllowed(peerCfg) {
 		opts = append(opts, WithInsecure())
 	}
@@ -196,3 +227,14 @@ func isInsecureAllowed(peerCfg *fab.PeerConfig) bool {
 	}
 	return false
 }
+
+// IsGMTLSEnabled returns the "gm-tls" grpc option of an endpoint, falling back to the
+// given default options (e.g. the default entity's) if not set
+func IsGMTLSEnabled(grpcOpts map[string]interface{}, defaultGRPCOpts ...map[string]interface{}) bool {
+	for _, opts := range append([]map[string]interface{}{grpcOpts}, defaultGRPCOpts...) {
+		if gmTLS, ok := opts["gm-tls"]; ok {
+			return cast.ToBool(gmTLS)
+		}
+	}
+	return false
+}
@@ -4,7 +4,7 @@ Copyright SecureKey Technologies Inc. All Rights Reserved.
 SPDX-License-Identifier: Apache-2.0
 */
 
-package comm
+package comm_test
 
 import (
 	"testing"
@@ -12,6 +12,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,15 +31,15 @@ func TestNetworkPeerConfigFromURL(t *testing.T) {
 		t.Fatalf("Unexpected error reading config: %s", err)
 	}
 
-	_, err = NetworkPeerConfig(sampleConfig, "invalid")
+	_, err = comm.NetworkPeerConfig(sampleConfig, "invalid")
 	assert.NotNil(t, err, "invalid url should return err")
 
-	np, err := NetworkPeerConfig(sampleConfig, "peer0.org2.example.com:8051")
+	np, err := comm.NetworkPeerConfig(sampleConfig, "peer0.org2.example.com:8051")
 	assert.Nil(t, err, "valid url should not return err")
 	assert.Equal(t, "peer0.org2.example.com:8051", np.URL, "wrong URL")
 	assert.Equal(t, "Org2MSP", np.MSPID, "wrong MSP")
 
-	np, err = NetworkPeerConfig(sampleConfig, "peer0.org1.example.com:7051")
+	np, err = comm.NetworkPeerConfig(sampleConfig, "peer0.org1.example.com:7051")
 	assert.Nil(t, err, "valid url should not return err")
 	assert.Equal(t, "peer0.org1.example.com:7051", np.URL, "wrong URL")
 	assert.Equal(t, "Org1MSP", np.MSPID, "wrong MSP")
@@ -70,7 +71,7 @@ func TestSearchPeerConfigFromURL(t *testing.T) {
 	//Positive scenario,
 	// peerconfig should be found using matched URL
 	testURL := "localhost:7051"
-	peerConfig, err := SearchPeerConfigFromURL(sampleConfig, testURL)
+	peerConfig, err := comm.SearchPeerConfigFromURL(sampleConfig, testURL)
 	assert.Nil(t, err, "supposed to get no error")
 	assert.NotNil(t, peerConfig, "supposed to get valid peerConfig by url :%s", testURL)
 	assert.Equal(t, testURL, peerConfig.URL)
@@ -78,7 +79,7 @@ func TestSearchPeerConfigFromURL(t *testing.T) {
 
 	// peerconfig should be found using actual URL
 	testURL2 := "peer0.org1.example.com:7051"
-	peerConfig, err = SearchPeerConfigFromURL(sampleConfig, testURL2)
+	peerConfig, err = comm.SearchPeerConfigFromURL(sampleConfig, testURL2)
 
 	assert.Nil(t, err, "supposed to get no error")
 	assert.NotNil(t, peerConfig, "supposed to get valid peerConfig by url :%s", testURL2)
@@ -97,11 +98,11 @@ func TestMSPID(t *testing.T) {
 		t.Fatalf("Unexpected error reading config: %s", err)
 	}
 
-	mspID, ok := MSPID(sampleConfig, "invalid")
+	mspID, ok := comm.MSPID(sampleConfig, "invalid")
 	assert.False(t, ok, "supposed to fail for invalid org name")
 	assert.Empty(t, mspID, "supposed to get valid MSP ID")
 
-	mspID, ok = MSPID(sampleConfig, "org1")
+	mspID, ok = comm.MSPID(sampleConfig, "org1")
 	assert.True(t, ok, "supposed to pass with valid org name")
 	assert.NotEmpty(t, mspID, "supposed to get valid MSP ID")
 	assert.Equal(t, "Org1MSP", mspID, "supposed to get valid MSP ID")
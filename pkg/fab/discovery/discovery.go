@@ -18,6 +18,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	corecomm "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/signingmgr"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 )
@@ -106,7 +107,7 @@ func (c *Client) send(reqCtx context.Context, req *discclient.Request, target fa
 			return conn.ClientConn(), nil
 		},
 		func(msg []byte) ([]byte, error) {
-			return c.ctx.SigningManager().Sign(msg, c.ctx.PrivateKey())
+			return signingmgr.SignWithIdentity(c.ctx, msg)
 		},
 		signerCacheSize,
 	)
@@ -15,6 +15,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
@@ -25,6 +27,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/pathvar"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
@@ -133,6 +136,8 @@ type EndpointConfig struct {
 	channelPeersByChannel    map[string][]fab.ChannelPeer
 	channelOrderersByChannel map[string][]fab.OrdererConfig
 	tlsClientCerts           []tls.Certificate
+	gmTLSCACerts             []*sm2.Certificate
+	gmTLSClientCerts         []gmtls.Certificate
 	peerMatchers             []matcherEntry
 	ordererMatchers          []matcherEntry
 	channelMatchers          []matcherEntry
@@ -276,6 +281,16 @@ func (c *EndpointConfig) TLSClientCerts() []tls.Certificate {
 	return c.tlsClientCerts
 }
 
+// GMTLSCACerts returns the GM-TLS CA certs of all peers and orderers having the "gm-tls" grpc option set
+func (c *EndpointConfig) GMTLSCACerts() []*sm2.Certificate {
+	return c.gmTLSCACerts
+}
+
+// GMTLSClientCerts returns the client's signing and encryption certs for mutual GM-TLS
+func (c *EndpointConfig) GMTLSClientCerts() []gmtls.Certificate {
+	return c.gmTLSClientCerts
+}
+
 func (c *EndpointConfig) loadPrivateKeyFromConfig(clientConfig *ClientConfig, clientCerts tls.Certificate, cb []byte) ([]tls.Certificate, error) {
 
 	kb := clientConfig.TLSCerts.Client.Key.Bytes()
@@ -505,7 +520,7 @@ func (c *EndpointConfig) loadEndpointConfigEntities(configEntity *endpointConfig
 func (c *EndpointConfig) loadDefaultChannel() {
 	defChCfg, ok := c.networkConfig.Channels[defaultEntity]
 	if ok {
-		c.defaultChannel = &fab.ChannelEndpointConfig{Peers: defChCfg.Peers, Orderers: defChCfg.Orderers, Policies: defChCfg.Policies}
+		c.defaultChannel = &fab.ChannelEndpointConfig{Peers: defChCfg.Peers, Orderers: defChCfg.Orderers, Policies: defChCfg.Policies, HashingAlgorithm: defChCfg.HashingAlgorithm, CryptoFamily: defChCfg.CryptoFamily}
 		delete(c.networkConfig.Channels, defaultEntity)
 	} else {
 		logger.Debugf("No default config. Returning hard-coded defaults.")
@@ -631,11 +646,25 @@ func (c *EndpointConfig) loadChannelEndpointConfig(chNwCfg ChannelEndpointConfig
 		}
 	}
 
+	hashingAlgorithm := chNwCfg.HashingAlgorithm
+	if hashingAlgorithm == "" {
+		//fill hashing algorithm in with default channel hashing algorithm
+		hashingAlgorithm = defChNwCfg.HashingAlgorithm
+	}
+
+	cryptoFamily := chNwCfg.CryptoFamily
+	if cryptoFamily == "" {
+		//fill crypto family in with default channel crypto family
+		cryptoFamily = defChNwCfg.CryptoFamily
+	}
+
 	// Policies use default channel policies if info is missing
 	return fab.ChannelEndpointConfig{
-		Peers:    chPeers,
-		Orderers: chOrderers,
-		Policies: c.addMissingChannelPoliciesItems(chNwCfg),
+		Peers:            chPeers,
+		Orderers:         chOrderers,
+		Policies:         c.addMissingChannelPoliciesItems(chNwCfg),
+		HashingAlgorithm: hashingAlgorithm,
+		CryptoFamily:     cryptoFamily,
 	}
 }
 
@@ -818,7 +847,7 @@ func (c *EndpointConfig) loadAllPeerConfigs(networkConfig *fab.NetworkConfig, en
 			//filter default and ignored peers
 			continue
 		}
-		tlsCert, _, err := peerConfig.TLSCACerts.TLSCert()
+		tlsCert, gmTLSCert, err := loadTLSCACert(&peerConfig.TLSCACerts, comm.IsGMTLSEnabled(peerConfig.GRPCOptions, c.defaultPeerConfig.GRPCOptions))
 		if err != nil {
 			return errors.WithMessage(err, "failed to load peer network config")
 		}
@@ -826,6 +855,7 @@ func (c *EndpointConfig) loadAllPeerConfigs(networkConfig *fab.NetworkConfig, en
 			URL:         peerConfig.URL,
 			GRPCOptions: peerConfig.GRPCOptions,
 			TLSCACert:   tlsCert,
+			GMTLSCACert: gmTLSCert,
 		})
 	}
 	return nil
@@ -838,7 +868,7 @@ func (c *EndpointConfig) loadAllOrdererConfigs(networkConfig *fab.NetworkConfig,
 			//filter default and ignored orderers
 			continue
 		}
-		tlsCert, _, err := ordererConfig.TLSCACerts.TLSCert()
+		tlsCert, gmTLSCert, err := loadTLSCACert(&ordererConfig.TLSCACerts, comm.IsGMTLSEnabled(ordererConfig.GRPCOptions, c.defaultOrdererConfig.GRPCOptions))
 		if err != nil {
 			return errors.WithMessage(err, "failed to load orderer network config")
 		}
@@ -846,6 +876,7 @@ func (c *EndpointConfig) loadAllOrdererConfigs(networkConfig *fab.NetworkConfig,
 			URL:         ordererConfig.URL,
 			GRPCOptions: ordererConfig.GRPCOptions,
 			TLSCACert:   tlsCert,
+			GMTLSCACert: gmTLSCert,
 		})
 	}
 	return nil
@@ -863,8 +894,9 @@ func (c *EndpointConfig) addMissingPeerConfigItems(name string, config fab.PeerC
 	}
 
 	//tls ca certs
-	if config.TLSCACert == nil {
+	if config.TLSCACert == nil && config.GMTLSCACert == nil {
 		config.TLSCACert = c.defaultPeerConfig.TLSCACert
+		config.GMTLSCACert = c.defaultPeerConfig.GMTLSCACert
 	}
 
 	//if no grpc opts found
@@ -895,8 +927,9 @@ func (c *EndpointConfig) addMissingOrdererConfigItems(name string, config fab.Or
 	}
 
 	//tls ca certs
-	if config.TLSCACert == nil {
+	if config.TLSCACert == nil && config.GMTLSCACert == nil {
 		config.TLSCACert = c.defaultOrdererConfig.TLSCACert
+		config.GMTLSCACert = c.defaultOrdererConfig.GMTLSCACert
 	}
 
 	//if no grpc opts found
@@ -962,7 +995,7 @@ func (c *EndpointConfig) loadDefaultOrderer(configEntity *endpointConfigEntity)
 	}
 
 	var err error
-	c.defaultOrdererConfig.TLSCACert, _, err = defaultEntityOrderer.TLSCACerts.TLSCert()
+	c.defaultOrdererConfig.TLSCACert, c.defaultOrdererConfig.GMTLSCACert, err = loadTLSCACert(&defaultEntityOrderer.TLSCACerts, comm.IsGMTLSEnabled(c.defaultOrdererConfig.GRPCOptions))
 	if err != nil {
 		return errors.WithMessage(err, "failed to load default orderer network config")
 	}
@@ -1095,7 +1128,7 @@ func (c *EndpointConfig) loadDefaultPeer(configEntity *endpointConfigEntity) err
 	}
 
 	var err error
-	c.defaultPeerConfig.TLSCACert, _, err = defaultEntityPeer.TLSCACerts.TLSCert()
+	c.defaultPeerConfig.TLSCACert, c.defaultPeerConfig.GMTLSCACert, err = loadTLSCACert(&defaultEntityPeer.TLSCACerts, comm.IsGMTLSEnabled(c.defaultPeerConfig.GRPCOptions))
 	if err != nil {
 		return errors.WithMessage(err, "failed to load default peer network config")
 	}
@@ -1129,6 +1162,12 @@ func (c *EndpointConfig) loadAllTLSConfig(configEntity *endpointConfigEntity) er
 		return errors.WithMessage(err, "failed to load TLS client certs ")
 	}
 
+	//preload GM-TLS client certs
+	err = c.loadGMTLSClientCerts(configEntity)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load GM-TLS client certs ")
+	}
+
 	return nil
 }
 
@@ -1151,6 +1190,22 @@ func (c *EndpointConfig) loadClientTLSConfig(configEntity *endpointConfigEntity)
 		return errors.WithMessage(err, "failed to load client cert")
 	}
 
+	//pre load GM-TLS client key pairs
+	for _, keyPair := range []*endpoint.TLSKeyPair{&configEntity.Client.TLSCerts.GMClient.Sign, &configEntity.Client.TLSCerts.GMClient.Enc} {
+		keyPair.Key.Path = pathvar.Subst(keyPair.Key.Path)
+		keyPair.Cert.Path = pathvar.Subst(keyPair.Cert.Path)
+
+		err = keyPair.Key.LoadBytes()
+		if err != nil {
+			return errors.WithMessage(err, "failed to load GM-TLS client key")
+		}
+
+		err = keyPair.Cert.LoadBytes()
+		if err != nil {
+			return errors.WithMessage(err, "failed to load GM-TLS client cert")
+		}
+	}
+
 	return nil
 }
 
@@ -1264,7 +1319,7 @@ func (c *EndpointConfig) loadOrdererConfigs() error {
 			continue
 		}
 
-		if matchedOrderer.TLSCACert == nil && !c.backend.GetBool("client.tlsCerts.systemCertPool") {
+		if matchedOrderer.TLSCACert == nil && matchedOrderer.GMTLSCACert == nil && !c.backend.GetBool("client.tlsCerts.systemCertPool") {
 			//check for TLS config only if secured connection is enabled
 			allowInSecure := matchedOrderer.GRPCOptions["allow-insecure"] == true
 			if endpoint.AttemptSecured(matchedOrderer.URL, allowInSecure) {
@@ -1353,6 +1408,7 @@ func (c *EndpointConfig) loadTLSCertPool() error {
 
 	//add certs to cert pool
 	c.tlsCertPool.Add(certs...)
+	c.gmTLSCACerts = c.loadGMTLSCerts()
 	//update cetr pool
 	if _, err := c.tlsCertPool.Get(); err != nil {
 		return errors.WithMessage(err, "cert pool load failed")
@@ -1397,6 +1453,28 @@ func (c *EndpointConfig) loadTLSClientCerts(configEntity *endpointConfigEntity)
 	return nil
 }
 
+// loadGMTLSClientCerts loads the client's signing and encryption certs for mutual GM-TLS
+func (c *EndpointConfig) loadGMTLSClientCerts(configEntity *endpointConfigEntity) error {
+	gmClient := configEntity.Client.TLSCerts.GMClient
+	if len(gmClient.Sign.Cert.Bytes()) == 0 {
+		c.gmTLSClientCerts = nil
+		return nil
+	}
+
+	signCert, err := gmtls.X509KeyPair(gmClient.Sign.Cert.Bytes(), gmClient.Sign.Key.Bytes())
+	if err != nil {
+		return errors.Errorf("Error loading signing cert/key pair as GM-TLS client credentials: %s", err)
+	}
+
+	encCert, err := gmtls.X509KeyPair(gmClient.Enc.Cert.Bytes(), gmClient.Enc.Key.Bytes())
+	if err != nil {
+		return errors.Errorf("Error loading encryption cert/key pair as GM-TLS client credentials: %s", err)
+	}
+
+	c.gmTLSClientCerts = []gmtls.Certificate{signCert, encCert}
+	return nil
+}
+
 func (c *EndpointConfig) isPeerToBeIgnored(peerName string) bool {
 	for _, matcher := range c.peerMatchers {
 		if matcher.regex.MatchString(peerName) {
@@ -1439,6 +1517,7 @@ func (c *EndpointConfig) tryMatchingPeerConfig(peerSearchKey string, searchByURL
 					URL:         staticPeerConfig.URL,
 					GRPCOptions: staticPeerConfig.GRPCOptions,
 					TLSCACert:   staticPeerConfig.TLSCACert,
+					GMTLSCACert: staticPeerConfig.GMTLSCACert,
 				}, true
 			}
 		}
@@ -1449,6 +1528,7 @@ func (c *EndpointConfig) tryMatchingPeerConfig(peerSearchKey string, searchByURL
 			URL:         peerSearchKey,
 			GRPCOptions: c.defaultPeerConfig.GRPCOptions,
 			TLSCACert:   c.defaultPeerConfig.TLSCACert,
+			GMTLSCACert: c.defaultPeerConfig.GMTLSCACert,
 		}, true
 	}
 
@@ -1506,6 +1586,7 @@ func (c *EndpointConfig) getMappedPeer(host string) *fab.PeerConfig {
 	mappedConfig := fab.PeerConfig{
 		URL:         peerConfig.URL,
 		TLSCACert:   peerConfig.TLSCACert,
+		GMTLSCACert: peerConfig.GMTLSCACert,
 		GRPCOptions: make(map[string]interface{}),
 	}
 
@@ -1540,6 +1621,7 @@ func (c *EndpointConfig) tryMatchingOrdererConfig(ordererSearchKey string, searc
 					URL:         ordererCfg.URL,
 					GRPCOptions: ordererCfg.GRPCOptions,
 					TLSCACert:   ordererCfg.TLSCACert,
+					GMTLSCACert: ordererCfg.GMTLSCACert,
 				}, true
 			}
 		}
@@ -1551,6 +1633,7 @@ func (c *EndpointConfig) tryMatchingOrdererConfig(ordererSearchKey string, searc
 			URL:         ordererSearchKey,
 			GRPCOptions: c.defaultOrdererConfig.GRPCOptions,
 			TLSCACert:   c.defaultOrdererConfig.TLSCACert,
+			GMTLSCACert: c.defaultOrdererConfig.GMTLSCACert,
 		}, true
 	}
 
@@ -1601,6 +1684,7 @@ func (c *EndpointConfig) getMappedOrderer(host string) *fab.OrdererConfig {
 	mappedConfig := fab.OrdererConfig{
 		URL:         ordererConfig.URL,
 		TLSCACert:   ordererConfig.TLSCACert,
+		GMTLSCACert: ordererConfig.GMTLSCACert,
 		GRPCOptions: make(map[string]interface{}),
 	}
 
@@ -1679,7 +1763,7 @@ func (c *EndpointConfig) verifyPeerConfig(p *fab.PeerConfig, peerName string, tl
 	if p == nil || p.URL == "" {
 		return errors.Errorf("URL does not exist or empty for peer %s", peerName)
 	}
-	if tlsEnabled && p.TLSCACert == nil && !c.backend.GetBool("client.tlsCerts.systemCertPool") {
+	if tlsEnabled && p.TLSCACert == nil && p.GMTLSCACert == nil && !c.backend.GetBool("client.tlsCerts.systemCertPool") {
 		return errors.Errorf("tls.certificate does not exist or empty for peer %s", peerName)
 	}
 	return nil
@@ -1702,6 +1786,32 @@ func (c *EndpointConfig) loadTLSCerts() ([]*x509.Certificate, error) {
 	return certs, errs.ToError()
 }
 
+func (c *EndpointConfig) loadGMTLSCerts() []*sm2.Certificate {
+	var certs []*sm2.Certificate
+
+	for _, peer := range c.networkPeers {
+		if peer.GMTLSCACert != nil {
+			certs = append(certs, peer.GMTLSCACert)
+		}
+	}
+	for _, orderer := range c.ordererConfigs {
+		if orderer.GMTLSCACert != nil {
+			certs = append(certs, orderer.GMTLSCACert)
+		}
+	}
+	return certs
+}
+
+// loadTLSCACert parses the TLS CA cert of an endpoint, as an SM2 certificate for GM-TLS endpoints
+func loadTLSCACert(tlsCACerts *endpoint.TLSConfig, gmTLS bool) (*x509.Certificate, *sm2.Certificate, error) {
+	if gmTLS {
+		gmTLSCert, _, err := tlsCACerts.GMTLSCert()
+		return nil, gmTLSCert, err
+	}
+	tlsCert, _, err := tlsCACerts.TLSCert()
+	return tlsCert, nil, err
+}
+
 //ResetNetworkConfig clears network config cache
 func (c *EndpointConfig) ResetNetworkConfig() error {
 	c.networkConfig = nil
@@ -11,6 +11,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 
 	"github.com/golang/protobuf/proto"
 	po "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
@@ -53,37 +54,50 @@ type MockBroadcastServer struct {
 	// mutexes to ensure parallel channel deliveries are sent in sequence
 	delMtx         sync.Mutex
 	filteredDelMtx sync.Mutex
+	// number of broadcast streams opened by clients
+	broadcastStreams int32
 }
 
-// Broadcast mock broadcast
+// Broadcast mock broadcast, the envelopes of the stream are answered until the client closes it
 func (m *MockBroadcastServer) Broadcast(server po.AtomicBroadcast_BroadcastServer) error {
-	res, err := server.Recv()
-	if err == io.EOF {
-		return nil
-	}
+	atomic.AddInt32(&m.broadcastStreams, 1)
 
-	if err != nil {
-		return err
-	}
+	for {
+		res, err := server.Recv()
+		if err == io.EOF {
+			return nil
+		}
 
-	if m.BroadcastError != nil {
-		return m.BroadcastError
-	}
+		if err != nil {
+			return err
+		}
 
-	if m.BroadcastInternalServerError {
-		return server.Send(broadcastResponseError)
-	}
+		if m.BroadcastError != nil {
+			return m.BroadcastError
+		}
 
-	if m.BroadcastCustomResponse != nil {
-		return server.Send(m.BroadcastCustomResponse)
-	}
+		if m.BroadcastInternalServerError {
+			return server.Send(broadcastResponseError)
+		}
 
-	err = server.Send(broadcastResponseSuccess)
-	if err != nil {
-		return err
+		if m.BroadcastCustomResponse != nil {
+			return server.Send(m.BroadcastCustomResponse)
+		}
+
+		err = server.Send(broadcastResponseSuccess)
+		if err != nil {
+			return err
+		}
+
+		if err := m.mockBlockDelivery(res.Payload); err != nil {
+			return err
+		}
 	}
+}
 
-	return m.mockBlockDelivery(res.Payload)
+// BroadcastStreams returns the number of broadcast streams opened by clients
+func (m *MockBroadcastServer) BroadcastStreams() int {
+	return int(atomic.LoadInt32(&m.broadcastStreams))
 }
 
 func (m *MockBroadcastServer) mockBlockDelivery(payload []byte) error {
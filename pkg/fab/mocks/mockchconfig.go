@@ -24,6 +24,7 @@ type MockChannelCfg struct {
 	MockVersions     *fab.Versions
 	MockMembership   fab.ChannelMembership
 	MockCapabilities map[fab.ConfigGroupKey]map[string]bool
+	MockHashingAlgo  string
 }
 
 // NewMockChannelCfg ...
@@ -78,6 +79,11 @@ func (cfg *MockChannelCfg) HasCapability(group fab.ConfigGroupKey, capability st
 	return capabilities[capability]
 }
 
+// HashingAlgorithm returns the channel's hashing algorithm
+func (cfg *MockChannelCfg) HashingAlgorithm() string {
+	return cfg.MockHashingAlgo
+}
+
 // MockChannelConfig mockcore query channel configuration
 type MockChannelConfig struct {
 	channelID string
@@ -219,6 +219,11 @@ func (c *MockConfig) KeyStorePath() string {
 	return "/tmp/fabsdkgo_test"
 }
 
+// KeyStorePassword ...
+func (c *MockConfig) KeyStorePassword() ([]byte, error) {
+	return nil, nil
+}
+
 // CredentialStorePath ...
 func (c *MockConfig) CredentialStorePath() string {
 	return "/tmp/userstore"
@@ -53,3 +53,13 @@ func (m *MockCryptoSuite) Sign(k core.Key, digest []byte,
 func (m *MockCryptoSuite) Verify(k core.Key, signature, digest []byte, opts core.SignerOpts) (valid bool, err error) {
 	return true, nil
 }
+
+//Encrypt mock encrypt implementation
+func (m *MockCryptoSuite) Encrypt(k core.Key, plaintext []byte, opts core.EncrypterOpts) (ciphertext []byte, err error) {
+	return plaintext, nil
+}
+
+//Decrypt mock decrypt implementation
+func (m *MockCryptoSuite) Decrypt(k core.Key, ciphertext []byte, opts core.DecrypterOpts) (plaintext []byte, err error) {
+	return ciphertext, nil
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package mocks
 
 import (
+	"sync"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -19,12 +20,17 @@ type MockEventService struct {
 	TxStatusRegCh    chan *dispatcher.TxStatusReg
 	TxValidationCode pb.TxValidationCode
 	Timeout          bool
+
+	mutex              sync.RWMutex
+	filteredBlockRegs  map[*dispatcher.FilteredBlockReg]struct{}
+	filteredBlockCount int
 }
 
 // NewMockEventService returns a new mock event service
 func NewMockEventService() *MockEventService {
 	return &MockEventService{
-		TxStatusRegCh: make(chan *dispatcher.TxStatusReg, 1),
+		TxStatusRegCh:     make(chan *dispatcher.TxStatusReg, 1),
+		filteredBlockRegs: make(map[*dispatcher.FilteredBlockReg]struct{}),
 	}
 }
 
@@ -39,13 +45,39 @@ func (m *MockEventService) RegisterBlockEvent(filter ...fab.BlockFilter) (fab.Re
 
 // RegisterFilteredBlockEvent registers for filtered block events.
 func (m *MockEventService) RegisterFilteredBlockEvent() (fab.Registration, <-chan *fab.FilteredBlockEvent, error) {
-	eventCh := make(chan *fab.FilteredBlockEvent)
+	eventCh := make(chan *fab.FilteredBlockEvent, 10)
 	reg := &dispatcher.FilteredBlockReg{
 		Eventch: eventCh,
 	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.filteredBlockRegs == nil {
+		m.filteredBlockRegs = make(map[*dispatcher.FilteredBlockReg]struct{})
+	}
+	m.filteredBlockRegs[reg] = struct{}{}
+	m.filteredBlockCount++
+
 	return reg, eventCh, nil
 }
 
+// PublishFilteredBlock sends the filtered block to the filtered block registrations
+func (m *MockEventService) PublishFilteredBlock(fblock *pb.FilteredBlock) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for reg := range m.filteredBlockRegs {
+		reg.Eventch <- &fab.FilteredBlockEvent{FilteredBlock: fblock}
+	}
+}
+
+// FilteredBlockRegistrations returns the number of filtered block registrations
+// which have not been unregistered, and the total number of registrations
+func (m *MockEventService) FilteredBlockRegistrations() (active int, total int) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return len(m.filteredBlockRegs), m.filteredBlockCount
+}
+
 // RegisterChaincodeEvent registers for chaincode events.
 func (m *MockEventService) RegisterChaincodeEvent(ccID, eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error) {
 	eventCh := make(chan *fab.CCEvent)
@@ -82,7 +114,29 @@ func (m *MockEventService) RegisterTxStatusEvent(txID string) (fab.Registration,
 	return reg, eventCh, nil
 }
 
-// Unregister removes the given registration.
+// Unregister removes the given registration. The event channel of
+// filtered block registrations is closed.
 func (m *MockEventService) Unregister(reg fab.Registration) {
-	// Nothing to do
+	fbReg, ok := reg.(*dispatcher.FilteredBlockReg)
+	if !ok {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.filteredBlockRegs[fbReg]; ok {
+		delete(m.filteredBlockRegs, fbReg)
+		close(fbReg.Eventch)
+	}
+}
+
+// CloseFilteredBlockRegistrations closes the event channels of the filtered block
+// registrations, as the event service does when it is closed
+func (m *MockEventService) CloseFilteredBlockRegistrations() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for reg := range m.filteredBlockRegs {
+		delete(m.filteredBlockRegs, reg)
+		close(reg.Eventch)
+	}
 }
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	reqContext "context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	grpcstatus "google.golang.org/grpc/status"
+
+	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// broadcastClientStream sends envelopes on one broadcast stream. The orderer answers
+// the envelopes of a stream in order, so the responses are matched with the pending envelopes.
+type broadcastClientStream struct {
+	orderer   *Orderer
+	ctx       reqContext.Context
+	cancel    reqContext.CancelFunc
+	conn      *grpc.ClientConn
+	client    ab.AtomicBroadcast_BroadcastClient
+	mutex     sync.Mutex
+	pending   []chan broadcastResult
+	err       error
+	closeOnce sync.Once
+}
+
+type broadcastResult struct {
+	status *common.Status
+	err    error
+}
+
+// NewBroadcastStream opens a broadcast stream to the orderer. Envelopes may be sent
+// concurrently on the stream, until Close is called or the context is done.
+func (o *Orderer) NewBroadcastStream(ctx reqContext.Context) (fab.BroadcastStream, error) {
+	conn, err := o.conn(ctx)
+	if err != nil {
+		rpcStatus, ok := grpcstatus.FromError(err)
+		if ok {
+			return nil, errors.WithMessage(status.NewFromGRPCStatus(rpcStatus), "connection failed")
+		}
+
+		return nil, status.New(status.OrdererClientStatus, status.ConnectionFailed.ToInt32(), err.Error(), nil)
+	}
+
+	streamCtx, cancel := reqContext.WithCancel(ctx)
+	broadcastClient, err := ab.NewAtomicBroadcastClient(conn).Broadcast(streamCtx)
+	if err != nil {
+		cancel()
+		o.releaseConn(ctx, conn)
+		rpcStatus, ok := grpcstatus.FromError(err)
+		if ok {
+			err = status.NewFromGRPCStatus(rpcStatus)
+		}
+		return nil, errors.Wrap(err, "NewAtomicBroadcastClient failed")
+	}
+
+	s := &broadcastClientStream{
+		orderer: o,
+		ctx:     ctx,
+		cancel:  cancel,
+		conn:    conn,
+		client:  broadcastClient,
+	}
+	go s.receive()
+
+	return s, nil
+}
+
+// Send broadcasts the envelope and waits for its status
+func (s *broadcastClientStream) Send(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	result := make(chan broadcastResult, 1)
+
+	s.mutex.Lock()
+	if s.err != nil {
+		s.mutex.Unlock()
+		return nil, s.err
+	}
+	// the response may be received as soon as the envelope is sent
+	s.pending = append(s.pending, result)
+	err := s.client.Send(&common.Envelope{
+		Payload:   envelope.Payload,
+		Signature: envelope.Signature,
+	})
+	s.mutex.Unlock()
+
+	if err != nil {
+		s.fail(errors.Wrap(err, "failed to send envelope to orderer"))
+	}
+
+	select {
+	case r := <-result:
+		return r.status, r.err
+	case <-ctx.Done():
+		return nil, status.New(status.ClientStatus, status.Timeout.ToInt32(), "timed out waiting for broadcast response", nil)
+	}
+}
+
+// Close closes the stream
+func (s *broadcastClientStream) Close() {
+	s.mutex.Lock()
+	if s.err == nil {
+		if err := s.client.CloseSend(); err != nil {
+			logger.Debugf("unable to close broadcast client [%s]", err)
+		}
+	}
+	s.mutex.Unlock()
+
+	s.fail(errors.New("broadcast stream closed"))
+}
+
+func (s *broadcastClientStream) receive() {
+	for {
+		broadcastResponse, err := s.client.Recv()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("broadcast stream closed by orderer")
+			} else {
+				rpcStatus, ok := grpcstatus.FromError(err)
+				if ok {
+					err = status.NewFromGRPCStatus(rpcStatus)
+				}
+				err = errors.Wrap(err, "broadcast recv failed")
+			}
+			s.fail(err)
+			return
+		}
+
+		s.mutex.Lock()
+		if len(s.pending) == 0 {
+			s.mutex.Unlock()
+			logger.Warnf("unexpected broadcast response from orderer [%s]", s.orderer.url)
+			continue
+		}
+		result := s.pending[0]
+		s.pending = s.pending[1:]
+		s.mutex.Unlock()
+
+		if broadcastResponse.Status == common.Status_SUCCESS {
+			result <- broadcastResult{status: &broadcastResponse.Status}
+		} else {
+			result <- broadcastResult{err: status.New(status.OrdererServerStatus, int32(broadcastResponse.Status), broadcastResponse.Info, nil)}
+		}
+	}
+}
+
+// fail fails the pending envelopes and the ones sent later, then releases the connection
+func (s *broadcastClientStream) fail(err error) {
+	s.mutex.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	pending := s.pending
+	s.pending = nil
+	s.mutex.Unlock()
+
+	for _, result := range pending {
+		result <- broadcastResult{err: err}
+	}
+
+	s.closeOnce.Do(func() {
+		s.cancel()
+		s.orderer.releaseConn(s.ctx, s.conn)
+	})
+}
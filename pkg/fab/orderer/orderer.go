@@ -21,6 +21,8 @@ import (
 	grpcstatus "google.golang.org/grpc/status"
 
 	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls/gmcredentials"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
@@ -28,6 +30,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	fabcomm "github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 )
 
@@ -45,6 +48,8 @@ type Orderer struct {
 	url            string
 	serverName     string
 	tlsCACert      *x509.Certificate
+	gmTLSCACert    *sm2.Certificate
+	gmTLS          bool
 	grpcDialOption []grpc.DialOption
 	kap            keepalive.ClientParameters
 	dialTimeout    time.Duration
@@ -75,7 +80,18 @@ func New(config fab.EndpointConfig, opts ...Option) (*Orderer, error) {
 		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(orderer.kap))
 	}
 	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.FailFast(orderer.failFast)))
-	if endpoint.AttemptSecured(orderer.url, orderer.allowInsecure) {
+	if endpoint.AttemptSecured(orderer.url, orderer.allowInsecure) && orderer.gmTLS {
+		//gm-tls config
+		gmTLSConfig, err := comm.GMTLSConfig(orderer.gmTLSCACert, orderer.serverName, config)
+		if err != nil {
+			return nil, err
+		}
+		gmTLSConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*sm2.Certificate) error {
+			return verifier.VerifyPeerCertificate(rawCerts, verifiedChains)
+		}
+
+		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(gmcredentials.NewTLS(gmTLSConfig)))
+	} else if endpoint.AttemptSecured(orderer.url, orderer.allowInsecure) {
 		//tls config
 		tlsConfig, err := comm.TLSConfig(orderer.tlsCACert, orderer.serverName, config)
 		if err != nil {
@@ -118,6 +134,17 @@ func WithTLSCert(tlsCACert *x509.Certificate) Option {
 	}
 }
 
+// WithGMTLSCert is a functional option for the orderer.New constructor that enables GM-TLS
+// and configures the orderer's SM2 TLS certificate
+func WithGMTLSCert(gmTLSCACert *sm2.Certificate) Option {
+	return func(o *Orderer) error {
+		o.gmTLSCACert = gmTLSCACert
+		o.gmTLS = true
+
+		return nil
+	}
+}
+
 // WithServerName is a functional option for the orderer.New constructor that configures the orderer's server name
 func WithServerName(serverName string) Option {
 	return func(o *Orderer) error {
@@ -142,10 +169,17 @@ func FromOrdererConfig(ordererCfg *fab.OrdererConfig) Option {
 	return func(o *Orderer) error {
 		o.url = ordererCfg.URL
 		o.tlsCACert = ordererCfg.TLSCACert
+		o.gmTLSCACert = ordererCfg.GMTLSCACert
+		o.gmTLS = fabcomm.IsGMTLSEnabled(ordererCfg.GRPCOptions)
 
 		if ordererCfg.GRPCOptions["allow-insecure"] == false {
 			//verify if certificate was expired or not yet valid
-			err := verifier.ValidateTLSCertificateDates(o.tlsCACert)
+			var err error
+			if o.gmTLS {
+				err = verifier.ValidateCertificateDates(o.gmTLSCACert)
+			} else {
+				err = verifier.ValidateTLSCertificateDates(o.tlsCACert)
+			}
 			if err != nil {
 				//log this error
 				logger.Warn(err)
@@ -11,6 +11,7 @@ import (
 	"crypto/x509"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -332,6 +333,68 @@ func TestSendBroadcastServerBadResponse(t *testing.T) {
 	assert.Equal(t, status.OrdererServerStatus, statusError.Group)
 }
 
+func TestBroadcastStream(t *testing.T) {
+
+	broadcastServer := mocks.MockBroadcastServer{}
+	addr := broadcastServer.Start(testOrdererURL)
+	defer broadcastServer.Stop()
+	orderer, _ := New(mocks.NewMockEndpointConfig(), WithURL("grpc://"+addr), WithInsecure())
+
+	stream, err := orderer.NewBroadcastStream(reqContext.Background())
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := stream.Send(reqContext.Background(), &fab.SignedEnvelope{})
+			assert.Nil(t, err)
+			if assert.NotNil(t, s) {
+				assert.Equal(t, common.Status_SUCCESS, *s)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, broadcastServer.BroadcastStreams(), "envelopes should be sent on one stream")
+
+	stream.Close()
+	_, err = stream.Send(reqContext.Background(), &fab.SignedEnvelope{})
+	assert.NotNil(t, err, "send on closed stream should fail")
+}
+
+func TestBroadcastStreamServerBadResponse(t *testing.T) {
+
+	broadcastServer := mocks.MockBroadcastServer{
+		BroadcastInternalServerError: true,
+	}
+	addr := broadcastServer.Start(testOrdererURL)
+	defer broadcastServer.Stop()
+	orderer, _ := New(mocks.NewMockEndpointConfig(), WithURL("grpc://"+addr), WithInsecure())
+
+	stream, err := orderer.NewBroadcastStream(reqContext.Background())
+	assert.Nil(t, err)
+	defer stream.Close()
+
+	_, err = stream.Send(reqContext.Background(), &fab.SignedEnvelope{})
+	statusError, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error")
+	assert.EqualValues(t, common.Status_INTERNAL_SERVER_ERROR, status.ToOrdererStatusCode(statusError.Code))
+	assert.Equal(t, status.OrdererServerStatus, statusError.Group)
+}
+
+func TestBroadcastStreamBadDial(t *testing.T) {
+
+	ordererConfig := getGRPCOpts(testOrdererURL+"Test", true, false, true)
+	orderer, _ := New(mocks.NewMockEndpointConfig(), FromOrdererConfig(ordererConfig))
+	orderer.dialTimeout = 15
+
+	_, err := orderer.NewBroadcastStream(reqContext.Background())
+	statusError, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error")
+	assert.Equal(t, status.OrdererClientStatus, statusError.Group)
+}
+
 func TestSendBroadcastError(t *testing.T) {
 
 	broadcastServer := mocks.MockBroadcastServer{
@@ -19,6 +19,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 )
 
 var logger = logging.NewLogger("fabsdk/fab")
@@ -29,6 +30,7 @@ type Peer struct {
 	config      fab.EndpointConfig
 	tlsCertificate *x509.Certificate
 	certificate *sm2.Certificate
+	gmTLS       bool
 	serverName  string
 	processor   fab.ProposalProcessor
 	mspID       string
@@ -63,6 +65,7 @@ func New(config fab.EndpointConfig, opts ...Option) (*Peer, error) {
 			target:             peer.url,
 			tlsCertificate:      peer.tlsCertificate,
 			certificate:        peer.certificate,
+			gmTLS:              peer.gmTLS,
 			serverHostOverride: peer.serverName,
 			config:             peer.config,
 			kap:                peer.kap,
@@ -99,6 +102,17 @@ func WithTLSCert(certificate *x509.Certificate) Option {
 	}
 }
 
+// WithGMTLSCert is a functional option for the peer.New constructor that enables GM-TLS
+// and configures the peer's SM2 TLS certificate
+func WithGMTLSCert(certificate *sm2.Certificate) Option {
+	return func(p *Peer) error {
+		p.certificate = certificate
+		p.gmTLS = true
+
+		return nil
+	}
+}
+
 // WithServerName is a functional option for the peer.New constructor that configures the peer's server name
 func WithServerName(serverName string) Option {
 	return func(p *Peer) error {
@@ -137,9 +151,15 @@ func FromPeerConfig(peerCfg *fab.NetworkPeer) Option {
 
 		var err error
 		p.tlsCertificate = peerCfg.TLSCACert
+		p.certificate = peerCfg.GMTLSCACert
+		p.gmTLS = comm.IsGMTLSEnabled(peerCfg.GRPCOptions)
 		if peerCfg.GRPCOptions["allow-insecure"] == false {
 			//verify if certificate was expired or not yet valid
-			err = verifier.ValidateTLSCertificateDates(p.tlsCertificate)
+			if p.gmTLS {
+				err = verifier.ValidateCertificateDates(p.certificate)
+			} else {
+				err = verifier.ValidateTLSCertificateDates(p.tlsCertificate)
+			}
 			if err != nil {
 				logger.Warn(err)
 			}
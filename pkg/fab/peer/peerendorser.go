@@ -21,6 +21,7 @@ import (
 	grpcstatus "google.golang.org/grpc/status"
 	
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmtls/gmcredentials"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -51,6 +52,7 @@ type peerEndorserRequest struct {
 	target             string
 	tlsCertificate     *x509.Certificate
 	certificate        *sm2.Certificate
+	gmTLS              bool
 	serverHostOverride string
 	config             fab.EndpointConfig
 	kap                keepalive.ClientParameters
@@ -71,7 +73,17 @@ func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
 	}
 	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.FailFast(endorseReq.failFast)))
 
-	if endpoint.AttemptSecured(endorseReq.target, endorseReq.allowInsecure) {
+	if endpoint.AttemptSecured(endorseReq.target, endorseReq.allowInsecure) && endorseReq.gmTLS {
+		gmTLSConfig, err := comm.GMTLSConfig(endorseReq.certificate, endorseReq.serverHostOverride, endorseReq.config)
+		if err != nil {
+			return nil, err
+		}
+		//verify if certificate was expired or not yet valid
+		gmTLSConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*sm2.Certificate) error {
+			return verifier.VerifyPeerCertificate(rawCerts, verifiedChains)
+		}
+		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(gmcredentials.NewTLS(gmTLSConfig)))
+	} else if endpoint.AttemptSecured(endorseReq.target, endorseReq.allowInsecure) {
 		tlsConfig, err := comm.TLSConfig(endorseReq.tlsCertificate, endorseReq.serverHostOverride, endorseReq.config)
 		if err != nil {
 			return nil, err
@@ -21,7 +21,10 @@ import (
 	"github.com/pkg/errors"
 )
 
-// block retrieves the block at the given position
+// block retrieves the block at the given position. The channel config is not known here (e.g. the
+// genesis block of a channel bootstraps it), so the request is signed with the hash algorithm set for
+// the channel in the SDK config (hashingAlgorithm or cryptoFamily), which is required for channels
+// not using the default algorithm.
 func retrieveBlock(reqCtx reqContext.Context, orderers []fab.Orderer, channel string, pos *ab.SeekPosition, opts options) (*common.Block, error) {
 	ctx, ok := contextImpl.RequestClientContext(reqCtx)
 	if !ok {
@@ -13,6 +13,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/crypto"
 	fcutils "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/signingmgr"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 )
 
@@ -23,8 +24,7 @@ func CreateConfigSignature(ctx context.Client, config []byte) (*common.ConfigSig
 		return nil, e
 	}
 
-	signingMgr := ctx.SigningManager()
-	signature, err := signingMgr.Sign(cfd.SigningBytes, ctx.PrivateKey())
+	signature, err := signingmgr.SignWithIdentity(ctx, cfd.SigningBytes)
 	if err != nil {
 		return nil, errors.WithMessage(err, "signing of channel config failed")
 	}
@@ -7,12 +7,21 @@ SPDX-License-Identifier: Apache-2.0
 package signingmgr
 
 import (
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	"github.com/pkg/errors"
 )
 
+// commonNames caches the common names of the enrollment certificates of the signing
+// identities, by certificate PEM
+var commonNames sync.Map
+
 // SigningManager is used for signing objects with private key
 type SigningManager struct {
 	cryptoProvider core.CryptoSuite
@@ -20,7 +29,8 @@ type SigningManager struct {
 	signerOpts     core.SignerOpts
 }
 
-// New Constructor for a signing manager.
+// New Constructor for a signing manager. Objects are hashed with the hash family of the
+// crypto suite unless other hash options are given (see SignWithHashOpts).
 // @param {BCCSP} cryptoProvider - crypto provider
 // @param {Config} config - configuration provider
 // @returns {SigningManager} new signing manager
@@ -30,6 +40,20 @@ func New(cryptoProvider core.CryptoSuite) (*SigningManager, error) {
 
 // Sign will sign the given object using provided key
 func (mgr *SigningManager) Sign(object []byte, key core.Key) ([]byte, error) {
+	return mgr.SignWithOpts(object, key, mgr.signerOpts)
+}
+
+// SignWithOpts will sign the given object using provided key and signer options.
+// With SM2 signer options (see cryptosuite.GetSM2SignerOpts) the object itself is passed
+// to the crypto suite, which hashes it together with the user ID; otherwise its digest is signed.
+func (mgr *SigningManager) SignWithOpts(object []byte, key core.Key, opts core.SignerOpts) ([]byte, error) {
+	return mgr.SignWithHashOpts(object, key, nil, opts)
+}
+
+// SignWithHashOpts will sign the digest of the given object computed with hashOpts, e.g.
+// the hash options of a channel, using provided key and signer options. The hash options of
+// the signing manager are used if hashOpts is nil.
+func (mgr *SigningManager) SignWithHashOpts(object []byte, key core.Key, hashOpts core.HashOpts, opts core.SignerOpts) ([]byte, error) {
 
 	if len(object) == 0 {
 		return nil, errors.New("object (to sign) required")
@@ -39,13 +63,94 @@ func (mgr *SigningManager) Sign(object []byte, key core.Key) ([]byte, error) {
 		return nil, errors.New("key (for signing) required")
 	}
 
-	digest, err := mgr.cryptoProvider.Hash(object, mgr.hashOpts)
+	if _, ok := opts.(*bccsp.SM2SignerOpts); ok {
+		return mgr.cryptoProvider.Sign(key, object, opts)
+	}
+
+	if hashOpts == nil {
+		hashOpts = mgr.hashOpts
+	}
+	digest, err := mgr.cryptoProvider.Hash(object, hashOpts)
 	if err != nil {
 		return nil, err
 	}
-	signature, err := mgr.cryptoProvider.Sign(key, digest, mgr.signerOpts)
+	signature, err := mgr.cryptoProvider.Sign(key, digest, opts)
 	if err != nil {
 		return nil, err
 	}
 	return signature, nil
 }
+
+// SignWithIdentity signs object with the private key of the signing identity of ctx.
+// If an SM2 user ID is configured for the common name of the enrollment certificate of the
+// identity (see verifier.SM2UIDResolver, implemented by the IdentityConfig of pkg/msp), the object
+// is signed with it, which requires a core.SigningManagerWithOpts. Endorsement verifiers resolve
+// the user ID by the same name.
+func SignWithIdentity(ctx context.Client, object []byte) ([]byte, error) {
+	return SignWithIdentityAndHashOpts(ctx, object, nil)
+}
+
+// SignWithIdentityAndHashOpts signs object as SignWithIdentity, the digest of the object being
+// computed with hashOpts, e.g. the hash options of the channel of the object. The hash options
+// of the signing manager are used if hashOpts is nil, otherwise a core.SigningManagerWithHashOpts
+// is required. An object signed with an SM2 user ID is hashed by the crypto suite with SM3.
+func SignWithIdentityAndHashOpts(ctx context.Client, object []byte, hashOpts core.HashOpts) ([]byte, error) {
+	mgr := ctx.SigningManager()
+	if mgr == nil {
+		return nil, errors.New("signing manager is nil")
+	}
+
+	uid, ok, err := identitySM2UID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		optsMgr, ok := mgr.(core.SigningManagerWithOpts)
+		if !ok {
+			return nil, errors.New("signing manager does not support signing with an SM2 user ID")
+		}
+		return optsMgr.SignWithOpts(object, ctx.PrivateKey(), cryptosuite.GetSM2SignerOpts(uid))
+	}
+
+	if hashOpts == nil {
+		return mgr.Sign(object, ctx.PrivateKey())
+	}
+	hashOptsMgr, ok := mgr.(core.SigningManagerWithHashOpts)
+	if !ok {
+		return nil, errors.New("signing manager does not support signing with hash options")
+	}
+	return hashOptsMgr.SignWithHashOpts(object, ctx.PrivateKey(), hashOpts, nil)
+}
+
+// identitySM2UID returns the SM2 user ID configured for the common name of the enrollment
+// certificate of the signing identity, the only name verifiers know the identity by
+func identitySM2UID(ctx context.Client) ([]byte, bool, error) {
+	resolver, ok := ctx.IdentityConfig().(verifier.SM2UIDResolver)
+	id := ctx.Identifier()
+	if !ok || id == nil {
+		return nil, false, nil
+	}
+	cn, err := enrollmentCommonName(ctx.EnrollmentCertificate())
+	if err != nil {
+		return nil, false, err
+	}
+	uid, ok := resolver.SM2UID(id.MSPID, cn)
+	return uid, ok, nil
+}
+
+// enrollmentCommonName returns the common name of the enrollment certificate cert, the
+// certificate being parsed once
+func enrollmentCommonName(cert []byte) (string, error) {
+	if cn, ok := commonNames.Load(string(cert)); ok {
+		return cn.(string), nil
+	}
+	certs, err := verifier.ParsePEMCertificates(cert)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to parse the enrollment certificate")
+	}
+	if len(certs) == 0 {
+		return "", errors.New("no enrollment certificate found")
+	}
+	commonNames.Store(string(cert), certs[0].Subject.CommonName)
+	return certs[0].Subject.CommonName, nil
+}
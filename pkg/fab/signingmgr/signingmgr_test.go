@@ -8,11 +8,27 @@ package signingmgr
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	bccspwrapper "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/mocks"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	mspprotos "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
 )
 
 func TestSigningManager(t *testing.T) {
@@ -48,3 +64,211 @@ func TestSigningManager(t *testing.T) {
 	}
 
 }
+
+// recordingCryptoSuite records what it is asked to sign
+type recordingCryptoSuite struct {
+	fcmocks.MockCryptoSuite
+	hashed   bool
+	hashOpts core.HashOpts
+	signed   []byte
+	opts     core.SignerOpts
+}
+
+func (cs *recordingCryptoSuite) Hash(msg []byte, opts core.HashOpts) ([]byte, error) {
+	cs.hashed = true
+	cs.hashOpts = opts
+	return []byte("digest"), nil
+}
+
+func (cs *recordingCryptoSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	cs.signed = digest
+	cs.opts = opts
+	return []byte("testSignature"), nil
+}
+
+// newSM2Identity returns an SM2 key and a PEM certificate with the common name cn
+func newSM2Identity(t *testing.T, cn string) (*sm2.PrivateKey, []byte) {
+	key, err := sm2.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	template := &sm2.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := sm2.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// sm2UIDConfig configures an SM2 user ID for one identity
+type sm2UIDConfig struct {
+	msp.IdentityConfig
+	mspID, id string
+	uid       []byte
+}
+
+func (c *sm2UIDConfig) SM2UID(mspID, id string) ([]byte, bool) {
+	if mspID == c.mspID && id == c.id {
+		return c.uid, true
+	}
+	return nil, false
+}
+
+func TestSignWithIdentity(t *testing.T) {
+	cs := &recordingCryptoSuite{}
+	signingMgr, err := New(cs)
+	if err != nil {
+		t.Fatalf("Failed to create signing manager: %s", err)
+	}
+	identityConfig := &sm2UIDConfig{IdentityConfig: fcmocks.NewMockIdentityConfig(), mspID: "Org1MSP", id: "User1@org1.example.com", uid: []byte("user1@org1")}
+
+	newContext := func(id string) *fcmocks.MockContext {
+		_, cert := newSM2Identity(t, id+"@org1.example.com")
+		si := mockmsp.NewMockSigningIdentity(id, "Org1MSP")
+		si.SetEnrollmentCertificate(cert)
+		si.SetPrivateKey(bccspwrapper.GetKey(&mockmsp.MockKey{}))
+		return &fcmocks.MockContext{
+			MockProviderContext: fcmocks.NewMockProviderContextCustom(nil, nil, identityConfig, cs, signingMgr, nil, nil),
+			SigningIdentity:     si,
+		}
+	}
+
+	// the identity with a user ID signs the object itself with the SM2 signer options
+	if _, err := SignWithIdentity(newContext("User1"), []byte("Hello")); err != nil {
+		t.Fatalf("Failed to sign object: %s", err)
+	}
+	if cs.hashed || !bytes.Equal(cs.signed, []byte("Hello")) {
+		t.Fatal("Object should be signed without being hashed first")
+	}
+	if opts, ok := cs.opts.(*bccsp.SM2SignerOpts); !ok || !bytes.Equal(opts.UID, []byte("user1@org1")) {
+		t.Fatalf("Expected SM2 signer options with the user ID, got %#v", cs.opts)
+	}
+
+	// other identities sign the digest
+	*cs = recordingCryptoSuite{}
+	if _, err := SignWithIdentity(newContext("User2"), []byte("Hello")); err != nil {
+		t.Fatalf("Failed to sign object: %s", err)
+	}
+	if !cs.hashed || !bytes.Equal(cs.signed, []byte("digest")) || cs.opts != nil {
+		t.Fatal("Digest should be signed without signer options")
+	}
+	if cs.hashOpts.Algorithm() != bccsp.SHA {
+		t.Fatalf("Expected the hash family of the crypto suite, got %s", cs.hashOpts.Algorithm())
+	}
+
+	// the digest is computed with the hash options of the channel
+	*cs = recordingCryptoSuite{}
+	if _, err := SignWithIdentityAndHashOpts(newContext("User2"), []byte("Hello"), &bccsp.GMSM3Opts{}); err != nil {
+		t.Fatalf("Failed to sign object: %s", err)
+	}
+	if !cs.hashed || cs.hashOpts.Algorithm() != bccsp.GMSM3 {
+		t.Fatalf("Expected the digest to be computed with the given hash options, got %#v", cs.hashOpts)
+	}
+
+	// the enrollment certificate of an identity must be parsed to resolve its user ID
+	ctx := newContext("User3")
+	si := mockmsp.NewMockSigningIdentity("User3", "Org1MSP")
+	si.SetEnrollmentCertificate([]byte("invalid certificate"))
+	ctx.SigningIdentity = si
+	if _, err := SignWithIdentity(ctx, []byte("Hello")); err == nil {
+		t.Fatal("Signing should fail with an invalid enrollment certificate")
+	}
+
+	// a signing manager without options cannot sign with a user ID
+	ctx = newContext("User1")
+	ctx.MockProviderContext = fcmocks.NewMockProviderContextCustom(nil, nil, identityConfig, cs, mocks.NewMockSigningManager(), nil, nil)
+	if _, err := SignWithIdentity(ctx, []byte("Hello")); err == nil {
+		t.Fatal("Signing with a user ID should fail without core.SigningManagerWithOpts")
+	}
+
+	// a signing manager without hash options cannot sign with the hash options of a channel
+	ctx = newContext("User2")
+	ctx.MockProviderContext = fcmocks.NewMockProviderContextCustom(nil, nil, identityConfig, cs, &signingManager{}, nil, nil)
+	if _, err := SignWithIdentityAndHashOpts(ctx, []byte("Hello"), &bccsp.GMSM3Opts{}); err == nil {
+		t.Fatal("Signing with hash options should fail without core.SigningManagerWithHashOpts")
+	}
+}
+
+// sm2CryptoSuite signs with an SM2 key and the user ID of the signer options
+type sm2CryptoSuite struct {
+	fcmocks.MockCryptoSuite
+	key *sm2.PrivateKey
+}
+
+func (cs *sm2CryptoSuite) Sign(k core.Key, msg []byte, opts core.SignerOpts) ([]byte, error) {
+	var uid []byte
+	if sm2Opts, ok := opts.(*bccsp.SM2SignerOpts); ok {
+		uid = sm2Opts.UID
+	}
+	r, s, err := sm2.Sm2Sign(cs.key, msg, uid)
+	if err != nil {
+		return nil, err
+	}
+	return sm2.SignDigitToSignData(r, s)
+}
+
+// rejectingMembership validates every identity and rejects every signature the verifier
+// passes on to it
+type rejectingMembership struct {
+	fab.ChannelMembership
+}
+
+func (m *rejectingMembership) Validate(serializedID []byte) error {
+	return nil
+}
+
+func (m *rejectingMembership) Verify(serializedID []byte, msg []byte, sig []byte) error {
+	return errors.New("membership verification failed")
+}
+
+func TestSignWithIdentityVerifies(t *testing.T) {
+	// the name of the identity differs from the common name of its certificate
+	key, cert := newSM2Identity(t, "User1@org1.example.com")
+	cs := &sm2CryptoSuite{key: key}
+	signingMgr, err := New(cs)
+	if err != nil {
+		t.Fatalf("Failed to create signing manager: %s", err)
+	}
+	identityConfig := &sm2UIDConfig{IdentityConfig: fcmocks.NewMockIdentityConfig(), mspID: "Org1MSP", id: "User1@org1.example.com", uid: []byte("user1@org1")}
+
+	si := mockmsp.NewMockSigningIdentity("User1", "Org1MSP")
+	si.SetEnrollmentCertificate(cert)
+	si.SetPrivateKey(bccspwrapper.GetKey(&mockmsp.MockKey{}))
+	ctx := &fcmocks.MockContext{
+		MockProviderContext: fcmocks.NewMockProviderContextCustom(nil, nil, identityConfig, cs, signingMgr, nil, nil),
+		SigningIdentity:     si,
+	}
+
+	endorser, err := proto.Marshal(&mspprotos.SerializedIdentity{Mspid: "Org1MSP", IdBytes: cert})
+	if err != nil {
+		t.Fatalf("Failed to serialize identity: %s", err)
+	}
+	payload := []byte("payload")
+	signature, err := SignWithIdentity(ctx, append(payload, endorser...))
+	if err != nil {
+		t.Fatalf("Failed to sign object: %s", err)
+	}
+
+	// the verifier resolves the same user ID from the certificate of the endorser
+	v := &verifier.Signature{Membership: &rejectingMembership{}, SM2UIDs: identityConfig}
+	err = v.Verify(&fab.TransactionProposalResponse{ProposalResponse: &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200},
+		Payload:     payload,
+		Endorsement: &pb.Endorsement{Endorser: endorser, Signature: signature},
+	}})
+	if err != nil {
+		t.Fatalf("Signature with the SM2 user ID of the identity should verify: %s", err)
+	}
+}
+
+// signingManager only implements core.SigningManager
+type signingManager struct{}
+
+func (mgr *signingManager) Sign(object []byte, key core.Key) ([]byte, error) {
+	return object, nil
+}
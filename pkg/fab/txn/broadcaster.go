@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	reqContext "context"
+	"math/rand"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+)
+
+// Broadcaster sends transactions to the orderers, reusing one broadcast stream per orderer
+// for the orderers supporting it. It may be used concurrently and must be closed once
+// the transactions have been sent.
+type Broadcaster struct {
+	ctx      contextApi.Client
+	orderers []fab.Orderer
+	// streamCtx bounds the lifetime of the streams, which outlive the requests
+	streamCtx reqContext.Context
+	cancel    reqContext.CancelFunc
+	mutex     sync.Mutex
+	streams   map[string]fab.BroadcastStream
+	closed    bool
+}
+
+// NewBroadcaster returns a broadcaster sending the transactions signed by the client to the orderers
+func NewBroadcaster(ctx contextApi.Client, orderers []fab.Orderer) *Broadcaster {
+	streamCtx, cancel := reqContext.WithCancel(reqContext.Background())
+	return &Broadcaster{
+		ctx:       ctx,
+		orderers:  orderers,
+		streamCtx: streamCtx,
+		cancel:    cancel,
+		streams:   make(map[string]fab.BroadcastStream),
+	}
+}
+
+// CreateTransaction create a transaction with proposal response.
+func (b *Broadcaster) CreateTransaction(request fab.TransactionRequest) (*fab.Transaction, error) {
+	return New(request)
+}
+
+// SendTransaction sends the transaction to some orderer, picking random endpoints until all are exhausted.
+// Each send is bounded by the orderer response timeout.
+func (b *Broadcaster) SendTransaction(tx *fab.Transaction) (*fab.TransactionResponse, error) {
+	if len(b.orderers) == 0 {
+		return nil, errors.New("orderers is nil")
+	}
+
+	payload, err := CreateTransactionPayload(tx)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := signPayloadWithAlgorithm(b.ctx, payload, tx.Proposal.HashingAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancel := context.NewRequest(b.ctx, context.WithTimeoutType(fab.OrdererResponse))
+	defer cancel()
+
+	var errResp error
+	for _, i := range rand.Perm(len(b.orderers)) {
+		resp, err := b.send(reqCtx, envelope, b.orderers[i])
+		if err != nil {
+			errResp = err
+		} else {
+			return resp, nil
+		}
+	}
+	return nil, errResp
+}
+
+// Close closes the broadcast streams
+func (b *Broadcaster) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	for url, stream := range b.streams {
+		stream.Close()
+		delete(b.streams, url)
+	}
+	b.cancel()
+}
+
+func (b *Broadcaster) send(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderer fab.Orderer) (*fab.TransactionResponse, error) {
+	streamer, ok := orderer.(fab.BroadcastStreamer)
+	if !ok {
+		return sendBroadcast(reqCtx, envelope, orderer)
+	}
+
+	stream, err := b.stream(streamer, orderer.URL())
+	if err != nil {
+		return nil, errors.Wrapf(err, "calling orderer '%s' failed", orderer.URL())
+	}
+
+	logger.Debugf("Broadcasting envelope on stream to orderer :%s\n", orderer.URL())
+	if _, err := stream.Send(reqCtx, envelope); err != nil {
+		logger.Debugf("Receive Error Response from orderer :%s\n", err)
+		// a status returned by the orderer leaves the stream usable
+		if s, ok := status.FromError(err); !ok || s.Group != status.OrdererServerStatus {
+			b.release(orderer.URL(), stream)
+		}
+		return nil, errors.Wrapf(err, "calling orderer '%s' failed", orderer.URL())
+	}
+
+	logger.Debugf("Receive Success Response from orderer\n")
+	return &fab.TransactionResponse{Orderer: orderer.URL()}, nil
+}
+
+// stream returns the stream opened to the orderer, the stream is opened on first use
+func (b *Broadcaster) stream(streamer fab.BroadcastStreamer, url string) (fab.BroadcastStream, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, errors.New("broadcaster is closed")
+	}
+
+	if stream, ok := b.streams[url]; ok {
+		return stream, nil
+	}
+
+	// the dial is bounded by the orderer, the stream outlives the request
+	stream, err := streamer.NewBroadcastStream(b.streamCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	b.streams[url] = stream
+	return stream, nil
+}
+
+// release closes the stream and forgets it, the next send opens a new stream
+func (b *Broadcaster) release(url string, stream fab.BroadcastStream) {
+	b.mutex.Lock()
+	if b.streams[url] == stream {
+		delete(b.streams, url)
+	}
+	b.mutex.Unlock()
+
+	stream.Close()
+}
@@ -0,0 +1,167 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	reqContext "context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// streamingOrderer is a mock orderer broadcasting on streams
+type streamingOrderer struct {
+	*mocks.MockOrderer
+	mutex   sync.Mutex
+	streams []*mockBroadcastStream
+	sendErr error
+}
+
+func (o *streamingOrderer) NewBroadcastStream(ctx reqContext.Context) (fab.BroadcastStream, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	stream := &mockBroadcastStream{orderer: o}
+	o.streams = append(o.streams, stream)
+	return stream, nil
+}
+
+func (o *streamingOrderer) openedStreams() []*mockBroadcastStream {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.streams
+}
+
+type mockBroadcastStream struct {
+	orderer *streamingOrderer
+	mutex   sync.Mutex
+	sent    int
+	closed  bool
+}
+
+func (s *mockBroadcastStream) Send(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil, errors.New("broadcast stream closed")
+	}
+	if err := s.orderer.sendErr; err != nil {
+		return nil, err
+	}
+	s.sent++
+	st := common.Status_SUCCESS
+	return &st, nil
+}
+
+func (s *mockBroadcastStream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+}
+
+func (s *mockBroadcastStream) state() (int, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sent, s.closed
+}
+
+func validTransaction() *fab.Transaction {
+	return &fab.Transaction{
+		Proposal: &fab.TransactionProposal{
+			Proposal: &pb.Proposal{Header: []byte(""), Payload: []byte(""), Extension: []byte("")},
+		},
+		Transaction: &pb.Transaction{},
+	}
+}
+
+func TestBroadcasterReusesStream(t *testing.T) {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "1234"))
+	orderer := &streamingOrderer{MockOrderer: mocks.NewMockOrderer("orderer", nil)}
+
+	broadcaster := NewBroadcaster(ctx, []fab.Orderer{orderer})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := broadcaster.SendTransaction(validTransaction())
+			assert.NoError(t, err)
+			if assert.NotNil(t, response) {
+				assert.Equal(t, "orderer", response.Orderer)
+			}
+		}()
+	}
+	wg.Wait()
+
+	streams := orderer.openedStreams()
+	require.Len(t, streams, 1, "transactions should be sent on one stream")
+	sent, closed := streams[0].state()
+	assert.Equal(t, 10, sent)
+	assert.False(t, closed)
+
+	broadcaster.Close()
+	_, closed = streams[0].state()
+	assert.True(t, closed, "stream should be closed with the broadcaster")
+
+	_, err := broadcaster.SendTransaction(validTransaction())
+	assert.Error(t, err, "send on closed broadcaster should fail")
+}
+
+func TestBroadcasterStreamErrors(t *testing.T) {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "1234"))
+	orderer := &streamingOrderer{MockOrderer: mocks.NewMockOrderer("orderer", nil)}
+
+	broadcaster := NewBroadcaster(ctx, []fab.Orderer{orderer})
+	defer broadcaster.Close()
+
+	// a status returned by the orderer keeps the stream
+	orderer.sendErr = status.New(status.OrdererServerStatus, int32(common.Status_SERVICE_UNAVAILABLE), "unavailable", nil)
+	_, err := broadcaster.SendTransaction(validTransaction())
+	assert.Error(t, err)
+	assert.Len(t, orderer.openedStreams(), 1)
+
+	// a broken stream is replaced
+	orderer.sendErr = errors.New("stream failed")
+	_, err = broadcaster.SendTransaction(validTransaction())
+	assert.Error(t, err)
+	_, closed := orderer.openedStreams()[0].state()
+	assert.True(t, closed, "failed stream should be closed")
+
+	orderer.sendErr = nil
+	_, err = broadcaster.SendTransaction(validTransaction())
+	assert.NoError(t, err)
+	assert.Len(t, orderer.openedStreams(), 2, "a new stream should be opened")
+}
+
+func TestBroadcasterWithoutStreams(t *testing.T) {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "1234"))
+	lsnr := make(chan *fab.SignedEnvelope, 1)
+	orderer := mocks.NewMockOrderer("orderer", lsnr)
+
+	broadcaster := NewBroadcaster(ctx, []fab.Orderer{orderer})
+	defer broadcaster.Close()
+
+	_, err := broadcaster.SendTransaction(nil)
+	assert.EqualError(t, err, "transaction is nil")
+
+	response, err := broadcaster.SendTransaction(validTransaction())
+	require.NoError(t, err)
+	assert.Equal(t, "orderer", response.Orderer)
+	assert.NotNil(t, <-lsnr, "envelope should be broadcasted")
+}
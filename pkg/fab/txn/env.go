@@ -9,18 +9,23 @@ package txn
 import (
 	"encoding/hex"
 	"hash"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/pkg/errors"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/crypto"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/signingmgr"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
 )
 
 // TransactionHeader contains metadata for a transaction created by the SDK.
@@ -29,6 +34,8 @@ type TransactionHeader struct {
 	creator   []byte
 	nonce     []byte
 	channelID string
+	// hashingAlgorithm is the algorithm the ID was computed with, empty for the default
+	hashingAlgorithm string
 }
 
 // TransactionID returns the transaction's computed identifier.
@@ -51,6 +58,12 @@ func (th *TransactionHeader) ChannelID() string {
 	return th.channelID
 }
 
+// HashingAlgorithm returns the hash algorithm of the transaction's channel, empty if the default
+// algorithm is used. The transaction is signed with the same algorithm.
+func (th *TransactionHeader) HashingAlgorithm() string {
+	return th.hashingAlgorithm
+}
+
 // NewHeader computes a TransactionID from the current user context and holds
 // metadata to create transaction proposals.
 func NewHeader(ctx contextApi.Client, channelID string, opts ...fab.TxnHeaderOpt) (*TransactionHeader, error) {
@@ -78,7 +91,14 @@ func NewHeader(ctx contextApi.Client, channelID string, opts ...fab.TxnHeaderOpt
 		}
 	}
 
-	ho := cryptosuite.GetGMSM3Opts() // TODO: make configurable
+	algorithm := options.HashingAlgorithm
+	if algorithm == "" {
+		algorithm = ChannelHashingAlgorithm(ctx, channelID, "")
+	}
+	ho, err := HashOpts(ctx, channelID, algorithm)
+	if err != nil {
+		return nil, errors.WithMessage(err, "hash options creation failed")
+	}
 	h, err := ctx.CryptoSuite().GetHash(ho)
 	if err != nil {
 		return nil, errors.WithMessage(err, "hash function creation failed")
@@ -90,15 +110,63 @@ func NewHeader(ctx contextApi.Client, channelID string, opts ...fab.TxnHeaderOpt
 	}
 
 	txnID := TransactionHeader{
-		id:        fab.TransactionID(id),
-		creator:   creator,
-		nonce:     nonce,
-		channelID: channelID,
+		id:               fab.TransactionID(id),
+		creator:          creator,
+		nonce:            nonce,
+		channelID:        channelID,
+		hashingAlgorithm: algorithm,
 	}
 
 	return &txnID, nil
 }
 
+// familyHashingAlgorithms are the hash algorithms of the crypto families a channel may be configured with
+var familyHashingAlgorithms = map[string]string{
+	"gm": bccsp.GMSM3,
+	"sw": bccsp.SHA256,
+}
+
+// ChannelHashingAlgorithm returns the hash algorithm configured for the channel in the SDK config,
+// otherwise the given algorithm of the channel config, otherwise the hash algorithm of the crypto
+// family configured for the channel in the SDK config (the result may be empty).
+func ChannelHashingAlgorithm(ctx contextApi.Client, channelID string, channelCfgAlgorithm string) string {
+	chCfg := ctx.EndpointConfig().ChannelConfig(channelID)
+	if chCfg != nil && chCfg.HashingAlgorithm != "" {
+		return chCfg.HashingAlgorithm
+	}
+	if channelCfgAlgorithm != "" || chCfg == nil {
+		return channelCfgAlgorithm
+	}
+	return familyHashingAlgorithms[strings.ToLower(chCfg.CryptoFamily)]
+}
+
+// HashOpts returns the hash options used to compute the transaction IDs of the given channel.
+// The given algorithm, e.g. an explicit fab.WithHashingAlgorithm, takes precedence over the
+// algorithm configured for the channel in the SDK config. If neither is set then SM3 is used,
+// as it was before transaction IDs followed the channel hashing algorithm.
+func HashOpts(ctx contextApi.Client, channelID string, algorithm string) (core.HashOpts, error) {
+	if algorithm == "" {
+		algorithm = ChannelHashingAlgorithm(ctx, channelID, "")
+	}
+	if algorithm == "" {
+		return cryptosuite.GetGMSM3Opts(), nil
+	}
+	return cryptosuite.GetHashOpts(algorithm)
+}
+
+// signingHashOpts returns the hash options used to sign on the given channel with the given
+// algorithm of its transaction. Nil is returned, i.e. the hash options of the signing manager,
+// when no algorithm is set for the channel.
+func signingHashOpts(ctx contextApi.Client, channelID string, algorithm string) (core.HashOpts, error) {
+	if algorithm == "" {
+		algorithm = ChannelHashingAlgorithm(ctx, channelID, "")
+	}
+	if algorithm == "" {
+		return nil, nil
+	}
+	return cryptosuite.GetHashOpts(algorithm)
+}
+
 func computeTxnID(nonce, creator []byte, h hash.Hash) (string, error) {
 	b := append(nonce, creator...)
 
@@ -112,15 +180,24 @@ func computeTxnID(nonce, creator []byte, h hash.Hash) (string, error) {
 	return id, nil
 }
 
-// signPayload signs payload
+// signPayload signs payload with the hashing algorithm of its channel
 func signPayload(ctx contextApi.Client, payload *common.Payload) (*fab.SignedEnvelope, error) {
+	return signPayloadWithAlgorithm(ctx, payload, "")
+}
+
+// signPayloadWithAlgorithm signs payload with the given hashing algorithm of its transaction
+func signPayloadWithAlgorithm(ctx contextApi.Client, payload *common.Payload, algorithm string) (*fab.SignedEnvelope, error) {
 	payloadBytes, err := proto.Marshal(payload)
 	if err != nil {
 		return nil, errors.WithMessage(err, "marshaling of payload failed")
 	}
 
-	signingMgr := ctx.SigningManager()
-	signature, err := signingMgr.Sign(payloadBytes, ctx.PrivateKey())
+	hashOpts, err := signingHashOpts(ctx, payloadChannelID(payload), algorithm)
+	if err != nil {
+		return nil, errors.WithMessage(err, "hash options creation failed")
+	}
+
+	signature, err := signingmgr.SignWithIdentityAndHashOpts(ctx, payloadBytes, hashOpts)
 	if err != nil {
 		return nil, errors.WithMessage(err, "signing of payload failed")
 	}
@@ -222,3 +299,15 @@ func CreateSignatureHeader(txh *TransactionHeader) (*common.SignatureHeader, err
 
 	return &signatureHeader, nil
 }
+
+// payloadChannelID returns the channel ID of the payload header, empty if it has none
+func payloadChannelID(payload *common.Payload) string {
+	if payload.GetHeader() == nil {
+		return ""
+	}
+	chdr, err := protos_utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return ""
+	}
+	return chdr.ChannelId
+}
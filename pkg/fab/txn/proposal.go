@@ -17,6 +17,7 @@ import (
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/signingmgr"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
@@ -50,26 +51,45 @@ func CreateChaincodeInvokeProposal(txh fab.TransactionHeader, request fab.Chainc
 	}
 
 	tp := fab.TransactionProposal{
-		TxnID:    txh.TransactionID(),
-		Proposal: proposal,
+		TxnID:            txh.TransactionID(),
+		Proposal:         proposal,
+		HashingAlgorithm: hashingAlgorithm(txh),
 	}
 
 	return &tp, nil
 }
 
+// hashingAlgorithmProvider is implemented by the transaction headers knowing the hash algorithm of their channel
+type hashingAlgorithmProvider interface {
+	HashingAlgorithm() string
+}
+
+func hashingAlgorithm(txh fab.TransactionHeader) string {
+	if p, ok := txh.(hashingAlgorithmProvider); ok {
+		return p.HashingAlgorithm()
+	}
+	return ""
+}
+
 // signProposal creates a SignedProposal based on the current context.
-func signProposal(ctx contextApi.Client, proposal *pb.Proposal) (*pb.SignedProposal, error) {
-	proposalBytes, err := proto.Marshal(proposal)
+func signProposal(ctx contextApi.Client, proposal *fab.TransactionProposal) (*pb.SignedProposal, error) {
+	proposalBytes, err := proto.Marshal(proposal.Proposal)
 	if err != nil {
 		return nil, errors.Wrap(err, "mashal proposal failed")
 	}
 
-	signingMgr := ctx.SigningManager()
-	if signingMgr == nil {
-		return nil, errors.New("signing manager is nil")
+	var channelID string
+	if hdr, err := protos_utils.GetHeader(proposal.Header); err == nil {
+		if chdr, err := protos_utils.UnmarshalChannelHeader(hdr.ChannelHeader); err == nil {
+			channelID = chdr.ChannelId
+		}
+	}
+	hashOpts, err := signingHashOpts(ctx, channelID, proposal.HashingAlgorithm)
+	if err != nil {
+		return nil, errors.WithMessage(err, "hash options creation failed")
 	}
 
-	signature, err := signingMgr.Sign(proposalBytes, ctx.PrivateKey())
+	signature, err := signingmgr.SignWithIdentityAndHashOpts(ctx, proposalBytes, hashOpts)
 	if err != nil {
 		return nil, errors.WithMessage(err, "sign failed")
 	}
@@ -94,17 +114,38 @@ func SendProposal(reqCtx reqContext.Context, proposal *fab.TransactionProposal,
 		}
 	}
 
-	targets = getTargetsWithoutDuplicates(targets)
-
 	ctx, ok := context.RequestClientContext(reqCtx)
 	if !ok {
 		return nil, errors.New("failed get client context from reqContext for signProposal")
 	}
-	signedProposal, err := signProposal(ctx, proposal.Proposal)
+	signedProposal, err := signProposal(ctx, proposal)
 	if err != nil {
 		return nil, errors.WithMessage(err, "sign proposal failed")
 	}
 
+	return SendSignedProposal(reqCtx, signedProposal, targets)
+}
+
+// SendSignedProposal sends a proposal signed by its creator to ProposalProcessor. The proposal
+// may have been signed outside of the SDK, the signature of the proposal bytes is not verified.
+func SendSignedProposal(reqCtx reqContext.Context, signedProposal *pb.SignedProposal, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, error) {
+
+	if signedProposal == nil {
+		return nil, errors.New("signed proposal is required")
+	}
+
+	if len(targets) < 1 {
+		return nil, errors.New("targets is required")
+	}
+
+	for _, p := range targets {
+		if p == nil {
+			return nil, errors.New("target is nil")
+		}
+	}
+
+	targets = getTargetsWithoutDuplicates(targets)
+
 	request := fab.ProcessProposalRequest{SignedProposal: signedProposal}
 
 	var responseMtx sync.Mutex
@@ -74,7 +74,7 @@ func TestNewTransactionProposal(t *testing.T) {
 		t.Fatalf("Create Transaction Proposal Failed: %s", err)
 	}
 
-	signedProposal, err := signProposal(ctx, tp.Proposal)
+	signedProposal, err := signProposal(ctx, tp)
 	if err != nil {
 		t.Fatalf("signProposal failed: %s", err)
 	}
@@ -216,7 +216,7 @@ func TestSendTransactionProposalToProcessors(t *testing.T) {
 	defer mockCtrl.Finish()
 	proc := mock_context.NewMockProposalProcessor(mockCtrl)
 
-	stp, err := signProposal(ctx, &pb.Proposal{})
+	stp, err := signProposal(ctx, &fab.TransactionProposal{Proposal: &pb.Proposal{}})
 	if err != nil {
 		t.Fatalf("signProposal returned error: %s", err)
 	}
@@ -255,6 +255,34 @@ func TestSendTransactionProposalToProcessors(t *testing.T) {
 	}
 }
 
+func TestSendSignedProposal(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := mocks.NewMockContext(user)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	proc := mock_context.NewMockProposalProcessor(mockCtrl)
+
+	// the proposal signed outside of the SDK is sent as is
+	signedProposal := &pb.SignedProposal{ProposalBytes: []byte("proposal"), Signature: []byte("external signature")}
+	tpr := fab.TransactionProposalResponse{Endorser: "example.com", Status: 200}
+	proc.EXPECT().ProcessTransactionProposal(gomock.Any(), fab.ProcessProposalRequest{SignedProposal: signedProposal}).Return(&tpr, nil)
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	_, err := SendSignedProposal(reqCtx, nil, []fab.ProposalProcessor{proc})
+	assert.EqualError(t, err, "signed proposal is required")
+
+	_, err = SendSignedProposal(reqCtx, signedProposal, nil)
+	assert.EqualError(t, err, "targets is required")
+
+	result, err := SendSignedProposal(reqCtx, signedProposal, []fab.ProposalProcessor{proc})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, &tpr, result[0])
+}
+
 func TestProposalResponseError(t *testing.T) {
 	testError := fmt.Errorf("Test Error")
 
@@ -266,7 +294,7 @@ func TestProposalResponseError(t *testing.T) {
 	proc := mock_context.NewMockProposalProcessor(mockCtrl)
 	proc2 := mock_context.NewMockProposalProcessor(mockCtrl)
 
-	stp, err := signProposal(ctx, &pb.Proposal{})
+	stp, err := signProposal(ctx, &fab.TransactionProposal{Proposal: &pb.Proposal{}})
 	if err != nil {
 		t.Fatalf("signProposal returned error: %s", err)
 	}
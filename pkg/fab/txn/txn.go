@@ -111,6 +111,26 @@ func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers is nil")
 	}
+
+	payload, err := CreateTransactionPayload(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, ok := context.RequestClientContext(reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for signPayload")
+	}
+	envelope, err := signPayloadWithAlgorithm(ctx, payload, tx.Proposal.HashingAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return BroadcastEnvelope(reqCtx, envelope, orderers)
+}
+
+// CreateTransactionPayload creates the payload broadcasted for the transaction
+func CreateTransactionPayload(tx *fab.Transaction) (*common.Payload, error) {
 	if tx == nil {
 		return nil, errors.New("transaction is nil")
 	}
@@ -130,14 +150,7 @@ func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer
 	}
 
 	// create the payload
-	payload := common.Payload{Header: hdr, Data: txBytes}
-
-	transactionResponse, err := BroadcastPayload(reqCtx, &payload, orderers)
-	if err != nil {
-		return nil, err
-	}
-
-	return transactionResponse, nil
+	return &common.Payload{Header: hdr, Data: txBytes}, nil
 }
 
 // BroadcastPayload will send the given payload to some orderer, picking random endpoints
@@ -157,12 +170,12 @@ func BroadcastPayload(reqCtx reqContext.Context, payload *common.Payload, ordere
 		return nil, err
 	}
 
-	return broadcastEnvelope(reqCtx, envelope, orderers)
+	return BroadcastEnvelope(reqCtx, envelope, orderers)
 }
 
-// broadcastEnvelope will send the given envelope to some orderer, picking random endpoints
+// BroadcastEnvelope will send the given envelope to some orderer, picking random endpoints
 // until all are exhausted
-func broadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
+func BroadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
 	// Check if orderers are defined
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers not set")
@@ -151,7 +151,7 @@ func TestBroadcastEnvelope(t *testing.T) {
 	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
 	defer cancel()
 
-	res, err := broadcastEnvelope(reqCtx, sigEnvelope, orderers)
+	res, err := BroadcastEnvelope(reqCtx, sigEnvelope, orderers)
 
 	if err != nil {
 		t.Fatalf("Test Broadcast Envelope Failed, cause %s %+v", err, res)
@@ -181,7 +181,7 @@ func TestBroadcastEnvelope(t *testing.T) {
 	}
 	// It should always succeed even though one of them has failed
 	for i := 0; i < broadcastCount; i++ {
-		if res, err1 := broadcastEnvelope(reqCtx, sigEnvelope, orderers); err1 != nil {
+		if res, err1 := BroadcastEnvelope(reqCtx, sigEnvelope, orderers); err1 != nil {
 			t.Fatalf("Test Broadcast Envelope Failed, cause %s %+v", err1, res)
 		}
 	}
@@ -196,13 +196,13 @@ func checkBroadcastCount(broadcastCount int, orderer1 *mocks.MockOrderer, ordere
 		orderer2.EnqueueSendBroadcastError(errors.New("Service Unavailable"))
 	}
 	for i := 0; i < broadcastCount; i++ {
-		_, err1 := broadcastEnvelope(reqCtx, sigEnvelope, orderers)
+		_, err1 := BroadcastEnvelope(reqCtx, sigEnvelope, orderers)
 		if !strings.Contains(err1.Error(), "Service Unavailable") {
 			t.Fatal("Test Broadcast failed but didn't return the correct reason(should contain 'Service Unavailable')")
 		}
 	}
 	emptyOrderers := []fab.Orderer{}
-	_, err := broadcastEnvelope(reqCtx, sigEnvelope, emptyOrderers)
+	_, err := BroadcastEnvelope(reqCtx, sigEnvelope, emptyOrderers)
 	if err == nil || err.Error() != "orderers not set" {
 		t.Fatal("orderers not set validation on broadcast envelope is not working as expected")
 	}
@@ -299,6 +299,59 @@ func TestBuildChannelHeader(t *testing.T) {
 
 }
 
+func TestHashOpts(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := mocks.NewMockContext(user)
+
+	ho, err := HashOpts(ctx, "test", "")
+	assert.Nil(t, err, "HashOpts failed")
+	assert.Equal(t, "GMSM3", ho.Algorithm(), "expecting SM3 by default")
+
+	ho, err = HashOpts(ctx, "test", "GMSM3")
+	assert.Nil(t, err, "HashOpts failed")
+	assert.Equal(t, "GMSM3", ho.Algorithm(), "expecting channel hashing algorithm")
+
+	_, err = HashOpts(ctx, "test", "MD5")
+	assert.NotNil(t, err, "expecting error for unknown hashing algorithm")
+
+	ctx.EndpointConfig().(*mocks.MockConfig).SetCustomChannelConfig("test", &fab.ChannelEndpointConfig{HashingAlgorithm: "SHA256"})
+	ho, err = HashOpts(ctx, "test", "")
+	assert.Nil(t, err, "HashOpts failed")
+	assert.Equal(t, "SHA256", ho.Algorithm(), "expecting hashing algorithm from SDK config")
+
+	ho, err = HashOpts(ctx, "test", "GMSM3")
+	assert.Nil(t, err, "HashOpts failed")
+	assert.Equal(t, "GMSM3", ho.Algorithm(), "expecting explicit hashing algorithm to take precedence over SDK config")
+
+	assert.Equal(t, "SHA256", ChannelHashingAlgorithm(ctx, "test", "GMSM3"), "expecting SDK config to override channel config")
+	assert.Equal(t, "GMSM3", ChannelHashingAlgorithm(ctx, "other", "GMSM3"), "expecting channel config")
+
+	// the crypto family of the channel sets the default hashing algorithm
+	ctx.EndpointConfig().(*mocks.MockConfig).SetCustomChannelConfig("sw", &fab.ChannelEndpointConfig{CryptoFamily: "SW"})
+	assert.Equal(t, "SHA256", ChannelHashingAlgorithm(ctx, "sw", ""), "expecting hashing algorithm of crypto family")
+	assert.Equal(t, "GMSM3", ChannelHashingAlgorithm(ctx, "sw", "GMSM3"), "expecting channel config to override crypto family")
+	ho, err = HashOpts(ctx, "sw", "")
+	assert.Nil(t, err, "HashOpts failed")
+	assert.Equal(t, "SHA256", ho.Algorithm(), "expecting hashing algorithm of crypto family")
+	ho, err = signingHashOpts(ctx, "sw", "")
+	assert.Nil(t, err, "signingHashOpts failed")
+	assert.Equal(t, "SHA256", ho.Algorithm(), "expecting signatures with hashing algorithm of crypto family")
+
+	txh, err := NewHeader(ctx, "test")
+	assert.Nil(t, err, "NewHeader failed")
+	assert.Equal(t, "SHA256", txh.HashingAlgorithm())
+	txh, err = NewHeader(ctx, "test", fab.WithHashingAlgorithm("GMSM3"))
+	assert.Nil(t, err, "NewHeader failed")
+	assert.Equal(t, "GMSM3", txh.HashingAlgorithm())
+
+	proposal, err := CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{ChaincodeID: "cc", Fcn: "invoke"})
+	assert.Nil(t, err, "CreateChaincodeInvokeProposal failed")
+	assert.Equal(t, "GMSM3", proposal.HashingAlgorithm, "expecting proposal to be signed with the algorithm of its transaction ID")
+
+	_, err = NewHeader(ctx, "other", fab.WithHashingAlgorithm("MD5"))
+	assert.NotNil(t, err, "expecting error creating header with unknown hashing algorithm")
+}
+
 func TestSignPayload(t *testing.T) {
 	user := mspmocks.NewMockSigningIdentity("test", "1234")
 	ctx := mocks.NewMockContext(user)
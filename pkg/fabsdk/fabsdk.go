@@ -338,6 +338,10 @@ func (sdk *FabricSDK) Close() {
 		pvdr.Close()
 	}
 	sdk.provider.InfraProvider().Close()
+	// crypto suites holding connections, e.g. to remote signers, release them
+	if cs, ok := sdk.cryptoSuite.(closeable); ok {
+		cs.Close()
+	}
 }
 
 // CloseContext frees up caches being maintained by the SDK for the given context
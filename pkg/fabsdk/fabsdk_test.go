@@ -21,12 +21,14 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/fabricselection"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	context2 "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	configImpl "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	discmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defcore"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defsvc"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/chpvdr"
 	mockapisdk "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/test/mocksdkapi"
@@ -81,6 +83,41 @@ func TestDoubleClose(t *testing.T) {
 	sdk.Close()
 }
 
+// closeableCryptoSuite counts the calls to Close
+type closeableCryptoSuite struct {
+	core.CryptoSuite
+	closed int
+}
+
+func (cs *closeableCryptoSuite) Close() {
+	cs.closed++
+}
+
+// closeableCorePkg creates a closeable crypto suite
+type closeableCorePkg struct {
+	*defcore.ProviderFactory
+	cryptoSuite *closeableCryptoSuite
+}
+
+func (f *closeableCorePkg) CreateCryptoSuiteProvider(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	cs, err := f.ProviderFactory.CreateCryptoSuiteProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	f.cryptoSuite = &closeableCryptoSuite{CryptoSuite: cs}
+	return f.cryptoSuite, nil
+}
+
+func TestCloseCryptoSuite(t *testing.T) {
+	factory := &closeableCorePkg{ProviderFactory: defcore.NewProviderFactory()}
+	sdk, err := New(configImpl.FromFile(sdkConfigFile), WithCorePkg(factory))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	sdk.Close()
+	assert.Equal(t, 1, factory.cryptoSuite.closed, "the crypto suite should be closed with the SDK")
+}
+
 func TestWithCorePkg(t *testing.T) {
 	// Test New SDK with valid config file
 	c := configImpl.FromFile(sdkConfigFile)
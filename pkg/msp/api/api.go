@@ -8,6 +8,7 @@ package api
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -22,6 +23,8 @@ type CAClient interface {
 	Register(request *RegistrationRequest) (string, error)
 	Revoke(request *RevocationRequest) (*RevocationResponse, error)
 	GetCAInfo() (*GetCAInfoResponse, error)
+	GetCertificates(request *GetCertificatesRequest) (*GetCertificatesResponse, error)
+	GenCRL(request *GenCRLRequest) (*GenCRLResponse, error)
 	CreateIdentity(request *IdentityRequest) (*IdentityResponse, error)
 	GetIdentity(id, caname string) (*IdentityResponse, error)
 	ModifyIdentity(request *IdentityRequest) (*IdentityResponse, error)
@@ -77,6 +80,9 @@ type EnrollmentRequest struct {
 	// The type of the enrollment request: x509 or idemix
 	// The default is a request for an X509 enrollment certificate
 	Type string
+	// KeyAlgorithm is the algorithm of the key generated for the certificate: ecdsa or sm2
+	// The default is ecdsa, sm2 requires a crypto suite with SM2 keys (gm, cncc)
+	KeyAlgorithm string
 }
 
 // ReenrollmentRequest is a request to reenroll an identity.
@@ -91,6 +97,12 @@ type ReenrollmentRequest struct {
 	// AttrReqs are requests for attributes to add to the certificate.
 	// Each attribute is added only if the requestor owns the attribute.
 	AttrReqs []*AttributeRequest
+	// KeyAlgorithm is the algorithm of the new key: ecdsa or sm2
+	// The default is the algorithm of the current key of the identity
+	KeyAlgorithm string
+	// ReuseKey requests a certificate for the current key of the identity
+	// instead of a new key
+	ReuseKey bool
 }
 
 // Attribute defines additional attributes that may be passed along during registration
@@ -133,6 +145,65 @@ type RevokedCert struct {
 	AKI string
 }
 
+// GetCertificatesRequest defines the filters of the certificates to retrieve from the CA.
+// If neither ID nor AKI and Serial are provided, all certificates in or under the
+// affiliation of the registrar are returned.
+type GetCertificatesRequest struct {
+	// ID is the enrollment ID of the identity whose certificates are retrieved
+	ID string
+	// AKI (Authority Key Identifier) of the certificate to retrieve
+	AKI string
+	// Serial number of the certificate to retrieve
+	Serial string
+	// Revoked restricts the certificates to the ones revoked within the time range
+	Revoked TimeRange
+	// Expired restricts the certificates to the ones expiring within the time range
+	Expired TimeRange
+	// NotExpired excludes expired certificates
+	NotExpired bool
+	// NotRevoked excludes revoked certificates
+	NotRevoked bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// TimeRange is a range of time. The start and end times are either RFC3339
+// timestamps or durations relative to the current time (e.g. -30d, -1h),
+// an empty start or end time leaves the range open.
+type TimeRange struct {
+	StartTime string
+	EndTime   string
+}
+
+// GetCertificatesResponse is the response from the server for a get certificates request
+type GetCertificatesResponse struct {
+	// CAName is the name of the CA
+	CAName string
+	// Certs are the PEM-encoded certificates
+	Certs [][]byte
+}
+
+// GenCRLRequest defines the attributes required to generate a CRL with the CA.
+// Zero times leave the corresponding bounds open.
+type GenCRLRequest struct {
+	// RevokedAfter includes the certificates revoked after this time
+	RevokedAfter time.Time
+	// RevokedBefore includes the certificates revoked before this time
+	RevokedBefore time.Time
+	// ExpireAfter includes the certificates expiring after this time
+	ExpireAfter time.Time
+	// ExpireBefore includes the certificates expiring before this time
+	ExpireBefore time.Time
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// GenCRLResponse represents response from the server for a generate CRL request
+type GenCRLResponse struct {
+	// CRL is PEM-encoded certificate revocation list (CRL) that contains the requested unexpired revoked certificates
+	CRL []byte
+}
+
 // IdentityRequest represents the request to add/update identity to the fabric-ca-server
 type IdentityRequest struct {
 
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
@@ -32,8 +33,14 @@ type CAClientImpl struct {
 	registrar       msp.EnrollCredentials
 }
 
-// NewCAClient creates a new CA CAClient instance
-func NewCAClient(orgName string, ctx contextApi.Client) (*CAClientImpl, error) {
+// NewCAClient creates a new CA CAClient instance.
+// Requests fail over between the URLs configured for the CA in order.
+func NewCAClient(orgName string, ctx contextApi.Client, opts ...CAClientOption) (*CAClientImpl, error) {
+
+	caOpts, err := newCAClientOptions(opts...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid CA client options")
+	}
 
 	if orgName == "" {
 		orgName = ctx.IdentityConfig().Client().Organization
@@ -55,13 +62,12 @@ func NewCAClient(orgName string, ctx contextApi.Client) (*CAClientImpl, error) {
 
 	var adapter *fabricCAAdapter
 	var registrar msp.EnrollCredentials
-	var err error
 
 	// Currently, an organization can be associated with only one CA
 	caName := orgConfig.CertificateAuthorities[0]
 	caConfig, ok := ctx.IdentityConfig().CAConfig(orgName)
 	if ok {
-		adapter, err = newFabricCAAdapter(orgName, ctx.CryptoSuite(), ctx.IdentityConfig())
+		adapter, err = newFabricCAAdapter(orgName, ctx.CryptoSuite(), ctx.IdentityConfig(), caOpts)
 		if err == nil {
 			registrar = caConfig.Registrar
 		} else {
@@ -108,7 +114,11 @@ func (c *CAClientImpl) Enroll(request *api.EnrollmentRequest) error {
 		return errors.New("enrollmentSecret is required")
 	}
 	// TODO add attributes
-	cert, err := c.adapter.Enroll(request)
+	key, cert, err := c.adapter.Enroll(request)
+	if err != nil {
+		return errors.Wrap(err, "enroll failed")
+	}
+	err = c.bindCertificate(key, cert)
 	if err != nil {
 		return errors.Wrap(err, "enroll failed")
 	}
@@ -209,8 +219,21 @@ func (c *CAClientImpl) RemoveIdentity(request *api.RemoveIdentityRequest) (*api.
 		return nil, err
 	}
 
-	return c.adapter.RemoveIdentity(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+	response, err := c.adapter.RemoveIdentity(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+	if err != nil {
+		return nil, err
+	}
 
+	// the key pair of an identity enrolled with this client is of no more use
+	if _, ok := c.cryptoSuite.(core.RemoteKeyManager); ok {
+		user, err := c.identityManager.GetSigningIdentity(request.ID)
+		if err == nil {
+			c.deleteKeyPair(request.ID, user.PrivateKey())
+		} else if err != msp.ErrUserNotFound {
+			logger.Warnf("failed to retrieve removed identity [%s]: %s", request.ID, err)
+		}
+	}
+	return response, nil
 }
 
 // GetIdentity retrieves identity information.
@@ -272,7 +295,11 @@ func (c *CAClientImpl) Reenroll(request *api.ReenrollmentRequest) error {
 		return errors.Wrapf(err, "failed to retrieve user: %s", request.Name)
 	}
 
-	cert, err := c.adapter.Reenroll(user.PrivateKey(), user.EnrollmentCertificate(), request)
+	key, cert, err := c.adapter.Reenroll(user.PrivateKey(), user.EnrollmentCertificate(), request)
+	if err != nil {
+		return errors.Wrap(err, "reenroll failed")
+	}
+	err = c.bindCertificate(key, cert)
 	if err != nil {
 		return errors.Wrap(err, "reenroll failed")
 	}
@@ -286,6 +313,10 @@ func (c *CAClientImpl) Reenroll(request *api.ReenrollmentRequest) error {
 		return errors.Wrap(err, "reenroll failed")
 	}
 
+	// the new key is held under a new key label, the previous one is retired
+	if _, ok := c.cryptoSuite.(core.RemoteKeyManager); ok && !bytes.Equal(key.SKI(), user.PrivateKey().SKI()) {
+		c.deleteKeyPair(request.Name, user.PrivateKey())
+	}
 	return nil
 }
 
@@ -347,6 +378,52 @@ func (c *CAClientImpl) Revoke(request *api.RevocationRequest) (*api.RevocationRe
 	return resp, nil
 }
 
+// GetCertificates returns the certificates matching the request that the registrar is authorized to see
+//
+//  Returns:
+//  Response containing the PEM-encoded certificates
+func (c *CAClientImpl) GetCertificates(request *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return nil, api.ErrCARegistrarNotFound
+	}
+	if request == nil {
+		return nil, errors.New("get certificates request is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetCertificates(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// GenCRL generates a CRL of the revoked certificates matching the request
+//
+//  Returns:
+//  Response containing the PEM-encoded CRL
+func (c *CAClientImpl) GenCRL(request *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return nil, api.ErrCARegistrarNotFound
+	}
+	if request == nil {
+		return nil, errors.New("generate CRL request is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GenCRL(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
 // GetCAInfo returns generic CA information
 func (c *CAClientImpl) GetCAInfo() (*api.GetCAInfoResponse, error) {
 	if c.adapter == nil {
@@ -458,6 +535,28 @@ func (c *CAClientImpl) RemoveAffiliation(request *api.AffiliationRequest) (*api.
 	return c.adapter.RemoveAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
 }
 
+// bindCertificate binds the enrollment certificate to the key pair of the
+// enrolled key when it is held by a remote signer
+func (c *CAClientImpl) bindCertificate(key core.Key, cert []byte) error {
+	keyPairs, ok := c.cryptoSuite.(core.RemoteKeyManager)
+	if !ok {
+		return nil
+	}
+	return keyPairs.BindCertificate(key, cert)
+}
+
+// deleteKeyPair deletes the key pair of the key from the remote signer. A failure
+// is only logged since the enrollment or removal it follows has succeeded
+func (c *CAClientImpl) deleteKeyPair(id string, key core.Key) {
+	keyPairs, ok := c.cryptoSuite.(core.RemoteKeyManager)
+	if !ok {
+		return
+	}
+	if err := keyPairs.DeleteKeyPair(key); err != nil {
+		logger.Warnf("failed to delete key pair of [%s]: %s", id, err)
+	}
+}
+
 func (c *CAClientImpl) getRegistrar(enrollID string, enrollSecret string) (msp.SigningIdentity, error) {
 
 	if enrollID == "" {
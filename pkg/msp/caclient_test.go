@@ -9,8 +9,10 @@ package msp
 import (
 	"testing"
 
+	"encoding/pem"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -680,6 +682,55 @@ func TestGetCAInfo(t *testing.T) {
 	}
 }
 
+func TestGetCertificates(t *testing.T) {
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	_, err := f.caClient.GetCertificates(nil)
+	if err == nil {
+		t.Fatal("Expected error with nil request")
+	}
+
+	resp, err := f.caClient.GetCertificates(&api.GetCertificatesRequest{ID: "123", NotRevoked: true, Expired: api.TimeRange{StartTime: "-30d"}})
+	if err != nil {
+		t.Fatalf("Get certificates return error %s", err)
+	}
+	if len(resp.Certs) != 1 {
+		t.Fatalf("expecting %d, got %d certificates", 1, len(resp.Certs))
+	}
+	if block, _ := pem.Decode(resp.Certs[0]); block == nil || block.Type != "CERTIFICATE" {
+		t.Fatalf("expecting a PEM-encoded certificate, got %s", resp.Certs[0])
+	}
+
+	resp, err = f.caClient.GetCertificates(&api.GetCertificatesRequest{ID: "abc"})
+	if err != nil {
+		t.Fatalf("Get certificates return error %s", err)
+	}
+	if len(resp.Certs) != 0 {
+		t.Fatalf("expecting no certificates, got %d", len(resp.Certs))
+	}
+}
+
+func TestGenCRL(t *testing.T) {
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	_, err := f.caClient.GenCRL(nil)
+	if err == nil {
+		t.Fatal("Expected error with nil request")
+	}
+
+	resp, err := f.caClient.GenCRL(&api.GenCRLRequest{RevokedAfter: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Generate CRL return error %s", err)
+	}
+	if string(resp.CRL) != "MockCRL" {
+		t.Fatalf("unexpected CRL %s", resp.CRL)
+	}
+}
+
 func getCustomBackend(configPath string) ([]core.ConfigBackend, error) {
 
 	configBackends, err := config.FromFile(configPath)()
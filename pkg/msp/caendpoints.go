@@ -0,0 +1,198 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	calib "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/pkg/errors"
+)
+
+// DefaultCAGreylistExpiry is the time for which a CA endpoint that could not be reached
+// is skipped in favour of the other endpoints of the CA
+const DefaultCAGreylistExpiry = 10 * time.Second
+
+// CAClientOption describes a functional parameter for NewCAClient
+type CAClientOption func(*caClientOptions) error
+
+type caClientOptions struct {
+	retryOpts      retry.Opts
+	greylistExpiry time.Duration
+}
+
+// WithRetry sets the retry options of the requests which could not reach any endpoint of the CA.
+// The retryable codes default to retry.CAClientRetryableCodes, by default requests are not retried.
+func WithRetry(opts retry.Opts) CAClientOption {
+	return func(o *caClientOptions) error {
+		if len(opts.RetryableCodes) == 0 {
+			opts.RetryableCodes = retry.CAClientRetryableCodes
+		}
+		o.retryOpts = opts
+		return nil
+	}
+}
+
+// WithGreylistExpiry sets the time for which a CA endpoint that could not be reached is skipped
+func WithGreylistExpiry(expiry time.Duration) CAClientOption {
+	return func(o *caClientOptions) error {
+		if expiry < 0 {
+			return errors.New("greylist expiry must not be negative")
+		}
+		o.greylistExpiry = expiry
+		return nil
+	}
+}
+
+func newCAClientOptions(opts ...CAClientOption) (*caClientOptions, error) {
+	o := &caClientOptions{greylistExpiry: DefaultCAGreylistExpiry}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// caGreylist holds the CA endpoints that could not be reached. It is shared
+// by the CA clients, as clients are usually created for every request.
+var caGreylist = &endpointGreylist{}
+
+// endpointGreylist records the time at which endpoints could not be reached
+type endpointGreylist struct {
+	endpoints sync.Map
+}
+
+// accept returns whether the endpoint has not been greylisted within the expiry
+func (g *endpointGreylist) accept(endpoint string, expiry time.Duration) bool {
+	value, ok := g.endpoints.Load(endpoint)
+	if !ok {
+		return true
+	}
+	if timeAdded, ok := value.(time.Time); ok && timeAdded.Add(expiry).After(time.Now()) {
+		return false
+	}
+	return true
+}
+
+func (g *endpointGreylist) greylist(endpoint string) {
+	logger.Infof("Greylisting CA endpoint %s", endpoint)
+	g.endpoints.Store(endpoint, time.Now())
+}
+
+func (g *endpointGreylist) remove(endpoint string) {
+	g.endpoints.Delete(endpoint)
+}
+
+// failoverTransport sends the requests to the endpoints of a CA in order, skipping
+// the greylisted ones, until an endpoint can be reached. The endpoints must serve
+// the CA under the same path, as the authorization token covers the request URI.
+type failoverTransport struct {
+	transport http.RoundTripper
+	endpoints []*url.URL
+	greylist  *endpointGreylist
+	expiry    time.Duration
+	retryOpts retry.Opts
+}
+
+func newFailoverTransport(transport http.RoundTripper, urls []string, opts *caClientOptions) (*failoverTransport, error) {
+	t := &failoverTransport{
+		transport: transport,
+		greylist:  caGreylist,
+		expiry:    opts.greylistExpiry,
+		retryOpts: opts.retryOpts,
+	}
+	for _, u := range urls {
+		caURL, err := calib.NormalizeURL(endpoint.ToAddress(u))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid CA URL [%s]", u)
+		}
+		t.endpoints = append(t.endpoints, caURL)
+	}
+	return t, nil
+}
+
+// RoundTrip sends the request to the first endpoint which can be reached. If none
+// can be, the request is retried according to the retry options.
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	handler := retry.New(t.retryOpts)
+	sent := false
+	for {
+		resp, err := t.roundTrip(req, &sent)
+		if err == nil || !handler.Required(err) {
+			return resp, err
+		}
+		logger.Infof("Retrying request to CA: %s", err)
+	}
+}
+
+func (t *failoverTransport) roundTrip(req *http.Request, sent *bool) (*http.Response, error) {
+	var errs []interface{}
+	for _, caURL := range t.orderedEndpoints() {
+		r, err := t.endpointRequest(req, caURL, *sent)
+		if err != nil {
+			return nil, err
+		}
+		*sent = true
+
+		address := caURL.String()
+		resp, err := t.transport.RoundTrip(r)
+		if err == nil {
+			t.greylist.remove(address)
+			return resp, nil
+		}
+		logger.Warnf("Failed to reach CA endpoint %s: %s", address, err)
+		t.greylist.greylist(address)
+		errs = append(errs, err)
+	}
+	return nil, status.New(status.ClientStatus, status.ConnectionFailed.ToInt32(),
+		fmt.Sprintf("failed to reach any CA endpoint %s", t.endpoints), errs)
+}
+
+// orderedEndpoints returns the endpoints which are not greylisted followed by
+// the greylisted ones, they are tried as a last resort
+func (t *failoverTransport) orderedEndpoints() []*url.URL {
+	var accepted, greylisted []*url.URL
+	for _, caURL := range t.endpoints {
+		if t.greylist.accept(caURL.String(), t.expiry) {
+			accepted = append(accepted, caURL)
+		} else {
+			greylisted = append(greylisted, caURL)
+		}
+	}
+	return append(accepted, greylisted...)
+}
+
+// endpointRequest returns a copy of the request for the endpoint. The body of a
+// request which has already been sent is recreated.
+func (t *failoverTransport) endpointRequest(req *http.Request, caURL *url.URL, sent bool) (*http.Request, error) {
+	r := req.WithContext(req.Context())
+	u := *req.URL
+	u.Scheme = caURL.Scheme
+	u.Host = caURL.Host
+	r.URL = &u
+	r.Host = ""
+
+	if sent && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errors.New("request body cannot be resent to another CA endpoint")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to recreate request body")
+		}
+		r.Body = body
+	}
+	return r, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyListener drops the first connections, or all of them if failures is negative
+type flakyListener struct {
+	net.Listener
+	failures int32
+	accepted int32
+}
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt32(&l.accepted, 1)
+		remaining := atomic.LoadInt32(&l.failures)
+		if remaining == 0 {
+			return conn, nil
+		}
+		if remaining > 0 {
+			atomic.AddInt32(&l.failures, -1)
+		}
+		conn.Close()
+	}
+}
+
+func (l *flakyListener) acceptedCount() int {
+	return int(atomic.LoadInt32(&l.accepted))
+}
+
+// newFlakyCAServer starts a CA server dropping the given number of connections
+func newFlakyCAServer(handler http.Handler, failures int) (*httptest.Server, *flakyListener) {
+	server := httptest.NewUnstartedServer(handler)
+	listener := &flakyListener{Listener: server.Listener, failures: int32(failures)}
+	server.Listener = listener
+	server.Start()
+	return server, listener
+}
+
+func TestCAFailover(t *testing.T) {
+	down, downListener := newFlakyCAServer(newSM2TestCA(t), -1)
+	defer down.Close()
+	ca1 := newSM2TestCA(t)
+	server1 := httptest.NewServer(ca1)
+	defer server1.Close()
+	ca2 := newSM2TestCA(t)
+	server2 := httptest.NewServer(ca2)
+	defer server2.Close()
+
+	urls := []string{down.URL, server1.URL, server2.URL}
+	caClient, _ := newSM2FailoverTestCAClient(t, urls, newGMCryptoSuite(t))
+
+	enrollUsername := createRandomName()
+	err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, downListener.acceptedCount())
+	assert.Equal(t, 1, ca1.issuedCount(), "the request should fail over to the next endpoint")
+	assert.Equal(t, 0, ca2.issuedCount())
+	assert.False(t, caGreylist.accept(down.URL, DefaultCAGreylistExpiry), "the endpoint which could not be reached should be greylisted")
+
+	// the greylisted endpoint is skipped
+	err = caClient.Reenroll(&api.ReenrollmentRequest{Name: enrollUsername})
+	require.NoError(t, err)
+	assert.Equal(t, 1, downListener.acceptedCount())
+	assert.Equal(t, 2, ca1.issuedCount())
+
+	// the endpoint is tried again once the greylisting expired
+	caClient, _ = newSM2FailoverTestCAClient(t, urls, newGMCryptoSuite(t), WithGreylistExpiry(0))
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, downListener.acceptedCount())
+	assert.Equal(t, 3, ca1.issuedCount())
+}
+
+func TestCAFailoverGreylisted(t *testing.T) {
+	ca := newSM2TestCA(t)
+	server := httptest.NewServer(ca)
+	defer server.Close()
+	down, _ := newFlakyCAServer(newSM2TestCA(t), -1)
+	defer down.Close()
+
+	// greylisted endpoints are tried as a last resort
+	caGreylist.greylist(server.URL)
+	caClient, _ := newSM2FailoverTestCAClient(t, []string{server.URL, down.URL}, newGMCryptoSuite(t))
+	err := caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, ca.issuedCount())
+	assert.True(t, caGreylist.accept(server.URL, DefaultCAGreylistExpiry), "the endpoint should be removed from the greylist once reached")
+	assert.False(t, caGreylist.accept(down.URL, DefaultCAGreylistExpiry))
+}
+
+func TestCAFailoverRetry(t *testing.T) {
+	down1, downListener1 := newFlakyCAServer(newSM2TestCA(t), -1)
+	defer down1.Close()
+	down2, downListener2 := newFlakyCAServer(newSM2TestCA(t), -1)
+	defer down2.Close()
+
+	retryOpts := retry.Opts{Attempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffFactor: 1}
+
+	// no retries by default
+	caClient, _ := newSM2FailoverTestCAClient(t, []string{down1.URL, down2.URL}, newGMCryptoSuite(t))
+	err := caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to reach any CA endpoint")
+	assert.Equal(t, 1, downListener1.acceptedCount())
+	assert.Equal(t, 1, downListener2.acceptedCount())
+
+	caClient, _ = newSM2FailoverTestCAClient(t, []string{down1.URL, down2.URL}, newGMCryptoSuite(t), WithRetry(retryOpts))
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.Error(t, err)
+	assert.Equal(t, 4, downListener1.acceptedCount())
+	assert.Equal(t, 4, downListener2.acceptedCount())
+
+	// the request succeeds once the CA is back
+	ca := newSM2TestCA(t)
+	flaky, flakyListener := newFlakyCAServer(ca, 1)
+	defer flaky.Close()
+	caClient, _ = newSM2FailoverTestCAClient(t, []string{flaky.URL}, newGMCryptoSuite(t), WithRetry(retryOpts))
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, flakyListener.acceptedCount())
+	assert.Equal(t, 1, ca.issuedCount())
+}
+
+func TestCAClientOptions(t *testing.T) {
+	opts, err := newCAClientOptions()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCAGreylistExpiry, opts.greylistExpiry)
+	assert.Equal(t, 0, opts.retryOpts.Attempts)
+
+	opts, err = newCAClientOptions(WithRetry(retry.Opts{Attempts: 3}), WithGreylistExpiry(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 3, opts.retryOpts.Attempts)
+	assert.Equal(t, retry.CAClientRetryableCodes, opts.retryOpts.RetryableCodes)
+	assert.Equal(t, time.Minute, opts.greylistExpiry)
+
+	_, err = newCAClientOptions(WithGreylistExpiry(-time.Second))
+	assert.Error(t, err)
+}
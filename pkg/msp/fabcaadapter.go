@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 
 	"encoding/json"
+	"net/http"
 
 	caapi "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
 	calib "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib"
@@ -17,10 +18,14 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/client/credential/x509"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
 )
 
+// sm2KeyAlgorithm is the key algorithm of SM2 enrollment certificates
+const sm2KeyAlgorithm = "sm2"
+
 // fabricCAAdapter translates between SDK lingo and native Fabric CA API
 type fabricCAAdapter struct {
 	config      msp.IdentityConfig
@@ -28,9 +33,9 @@ type fabricCAAdapter struct {
 	caClient    *calib.Client
 }
 
-func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig) (*fabricCAAdapter, error) {
+func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig, opts *caClientOptions) (*fabricCAAdapter, error) {
 
-	caClient, err := createFabricCAClient(orgName, cryptoSuite, config)
+	caClient, err := createFabricCAClient(orgName, cryptoSuite, config, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -44,10 +49,17 @@ func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config msp
 }
 
 // Enroll handles enrollment.
-func (c *fabricCAAdapter) Enroll(request *api.EnrollmentRequest) ([]byte, error) {
+// Returns the enrolled private key and enrollment certificate
+func (c *fabricCAAdapter) Enroll(request *api.EnrollmentRequest) (core.Key, []byte, error) {
 
 	logger.Debugf("Enrolling user [%s]", request.Name)
 
+	keyAlgorithm := request.KeyAlgorithm
+	if _, ok := c.cryptoSuite.(core.RemoteKeyManager); ok && keyAlgorithm == "" {
+		// remote signers only hold SM2 key pairs
+		keyAlgorithm = sm2KeyAlgorithm
+	}
+
 	// TODO add attributes
 	careq := &caapi.EnrollmentRequest{
 		CAName:  c.caClient.Config.CAName,
@@ -56,6 +68,7 @@ func (c *fabricCAAdapter) Enroll(request *api.EnrollmentRequest) ([]byte, error)
 		Profile: request.Profile,
 		Type:    request.Type,
 		Label:   request.Label,
+		CSR:     csrInfo(keyAlgorithm),
 	}
 
 	if len(request.AttrReqs) > 0 {
@@ -68,20 +81,34 @@ func (c *fabricCAAdapter) Enroll(request *api.EnrollmentRequest) ([]byte, error)
 
 	caresp, err := c.caClient.Enroll(careq)
 	if err != nil {
-		return nil, errors.WithMessage(err, "enroll failed")
+		return nil, nil, errors.WithMessage(err, "enroll failed")
 	}
-	return caresp.Identity.GetECert().Cert(), nil
+	ecert := caresp.Identity.GetECert()
+	return ecert.Key(), ecert.Cert(), nil
 }
 
 // Reenroll handles re-enrollment
-func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte, request *api.ReenrollmentRequest) ([]byte, error) {
+// Returns the new private key and enrollment certificate
+func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte, request *api.ReenrollmentRequest) (core.Key, []byte, error) {
 
 	logger.Debugf("Re Enrolling user with provided key/cert pair for CA [%s]", c.caClient.Config.CAName)
 
+	keyAlgorithm := request.KeyAlgorithm
+	if keyAlgorithm == "" && cryptoutil.IsSM2Key(key) {
+		keyAlgorithm = sm2KeyAlgorithm
+	}
+
 	careq := &caapi.ReenrollmentRequest{
 		CAName:  c.caClient.Config.CAName,
 		Profile: request.Profile,
 		Label:   request.Label,
+		CSR:     csrInfo(keyAlgorithm),
+	}
+	if request.ReuseKey {
+		if careq.CSR == nil {
+			careq.CSR = &caapi.CSRInfo{KeyRequest: caapi.NewBasicKeyRequest()}
+		}
+		careq.CSR.KeyRequest.ReuseKey = true
 	}
 	if len(request.AttrReqs) > 0 {
 		attrs := make([]*caapi.AttributeRequest, len(request.AttrReqs))
@@ -93,15 +120,27 @@ func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte, request *api.Reenr
 
 	caidentity, err := c.newIdentity(key, cert)
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to create CA signing identity")
+		return nil, nil, errors.WithMessage(err, "failed to create CA signing identity")
 	}
 
 	caresp, err := caidentity.Reenroll(careq)
 	if err != nil {
-		return nil, errors.WithMessage(err, "reenroll failed")
+		return nil, nil, errors.WithMessage(err, "reenroll failed")
 	}
 
-	return caresp.Identity.GetECert().Cert(), nil
+	ecert := caresp.Identity.GetECert()
+	return ecert.Key(), ecert.Cert(), nil
+}
+
+// csrInfo returns the CSR info of the key algorithm, nil for the default
+// ECDSA key of the Fabric CA client
+func csrInfo(keyAlgorithm string) *caapi.CSRInfo {
+	if keyAlgorithm == "" {
+		return nil
+	}
+	return &caapi.CSRInfo{
+		KeyRequest: &caapi.BasicKeyRequest{Algo: keyAlgorithm, Size: 256},
+	}
 }
 
 // Register handles user registration
@@ -199,6 +238,81 @@ func getCAInfoResponse(response *calib.GetCAInfoResponse) *api.GetCAInfoResponse
 	}
 }
 
+// GetCertificates returns the certificates matching the request that the caller is authorized to see
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) GetCertificates(key core.Key, cert []byte, request *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+
+	logger.Debugf("Retrieving certificates [%s]", request.ID)
+
+	registrar, err := c.newIdentity(key, cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CA signing identity")
+	}
+
+	req := caapi.GetCertificatesRequest{
+		ID:         request.ID,
+		AKI:        request.AKI,
+		Serial:     request.Serial,
+		Revoked:    caapi.TimeRange{StartTime: request.Revoked.StartTime, EndTime: request.Revoked.EndTime},
+		Expired:    caapi.TimeRange{StartTime: request.Expired.StartTime, EndTime: request.Expired.EndTime},
+		NotExpired: request.NotExpired,
+		NotRevoked: request.NotRevoked,
+		CAName:     request.CAName,
+	}
+
+	var certs [][]byte
+	err = registrar.GetCertificates(&req, func(decoder *json.Decoder) error {
+		var cert certPEM
+		decodeErr := decoder.Decode(&cert)
+		if decodeErr != nil {
+			return decodeErr
+		}
+
+		certs = append(certs, []byte(cert.PEM))
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get certificates")
+	}
+
+	return &api.GetCertificatesResponse{CAName: c.caClient.Config.CAName, Certs: certs}, nil
+}
+
+// certPEM is a certificate of the get certificates response stream
+type certPEM struct {
+	PEM string
+}
+
+// GenCRL generates a CRL of the revoked certificates matching the request
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) GenCRL(key core.Key, cert []byte, request *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+
+	logger.Debugf("Generating CRL [%s]", request.CAName)
+
+	registrar, err := c.newIdentity(key, cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CA signing identity")
+	}
+
+	req := caapi.GenCRLRequest{
+		CAName:        request.CAName,
+		RevokedAfter:  request.RevokedAfter,
+		RevokedBefore: request.RevokedBefore,
+		ExpireAfter:   request.ExpireAfter,
+		ExpireBefore:  request.ExpireBefore,
+	}
+
+	resp, err := registrar.GenCRL(&req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate CRL")
+	}
+
+	return &api.GenCRLResponse{CRL: resp.CRL}, nil
+}
+
 // CreateIdentity creates new identity
 // key: registrar private key
 // cert: registrar enrollment certificate
@@ -577,7 +691,7 @@ func getIdentityResponses(ca string, responses []caapi.IdentityInfo) []*api.Iden
 	return ret
 }
 
-func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig) (*calib.Client, error) {
+func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig, opts *caClientOptions) (*calib.Client, error) {
 
 	// Create new Fabric-ca client without configs
 	c := &calib.Client{
@@ -624,5 +738,22 @@ func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config msp.I
 		return nil, errors.Wrap(err, "CA Client init failed")
 	}
 
+	//fail over between the URLs of the CA
+	urls := conf.URLs
+	if len(urls) == 0 {
+		urls = []string{conf.URL}
+	}
+	failover, err := newFailoverTransport(nil, urls, opts)
+	if err != nil {
+		return nil, err
+	}
+	err = c.WrapTransport(func(transport http.RoundTripper) http.RoundTripper {
+		failover.transport = transport
+		return failover
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "CA Client init failed")
+	}
+
 	return c, nil
 }
@@ -51,6 +51,7 @@ type IdentityConfig struct {
 	caKeyStorePath      string
 	credentialStorePath string
 	caMatchers          []matcherEntry
+	sm2UIDsByMSP        map[string]map[string][]byte
 }
 
 //entityMatchers for identity configuration
@@ -71,6 +72,14 @@ type identityConfigEntity struct {
 	CertificateAuthorities map[string]CAConfig
 }
 
+//identityOrgConfig contains the identity settings of an organization in identity config
+type identityOrgConfig struct {
+	MSPID string
+	// SM2UIDs maps the certificate common names of the identities of the organization
+	// to the SM2 user IDs they sign with
+	SM2UIDs map[string]string
+}
+
 // ClientConfig defines client configuration in identity config
 type ClientConfig struct {
 	Organization    string
@@ -86,9 +95,11 @@ type ClientTLSConfig struct {
 	Client endpoint.TLSKeyPair
 }
 
-// CAConfig defines a CA configuration in identity config
+// CAConfig defines a CA configuration in identity config.
+// URLs are additional endpoints of the CA, tried in order when URL is unavailable.
 type CAConfig struct {
 	URL         string
+	URLs        []string
 	GRPCOptions map[string]interface{}
 	TLSCACerts  endpoint.MutualTLSConfig
 	Registrar   msp.EnrollCredentials
@@ -172,6 +183,14 @@ func (c *IdentityConfig) CredentialStorePath() string {
 	return c.credentialStorePath
 }
 
+// SM2UID returns the SM2 user ID the identity of the MSP mspID whose certificate has the
+// common name id signs with, as configured by organizations.<org>.sm2UIDs.<id>.
+// Common names are case insensitive.
+func (c *IdentityConfig) SM2UID(mspID, id string) ([]byte, bool) {
+	uid, ok := c.sm2UIDsByMSP[mspID][strings.ToLower(id)]
+	return uid, ok
+}
+
 //loadIdentityConfigEntities loads config entities and dictionaries for searches
 func (c *IdentityConfig) loadIdentityConfigEntities() error {
 	configEntity := identityConfigEntity{}
@@ -215,12 +234,42 @@ func (c *IdentityConfig) loadIdentityConfigEntities() error {
 		return errors.WithMessage(err, "failed to load all CA configs ")
 	}
 
+	err = c.loadSM2UIDs()
+	if err != nil {
+		return errors.WithMessage(err, "failed to load SM2 user IDs")
+	}
+
 	c.caKeyStorePath = pathvar.Subst(c.backend.GetString("client.credentialStore.cryptoStore.path"))
 	c.credentialStorePath = pathvar.Subst(c.backend.GetString("client.credentialStore.path"))
 
 	return nil
 }
 
+//loadSM2UIDs loads the SM2 user IDs of the identities of the organizations, by MSP ID
+func (c *IdentityConfig) loadSM2UIDs() error {
+	orgs := make(map[string]identityOrgConfig)
+	err := c.backend.UnmarshalKey("organizations", &orgs)
+	if err != nil {
+		return errors.WithMessage(err, "failed to parse 'organizations' config item to identityOrgConfig type")
+	}
+
+	c.sm2UIDsByMSP = make(map[string]map[string][]byte)
+	for orgName, orgConfig := range orgs {
+		if len(orgConfig.SM2UIDs) == 0 {
+			continue
+		}
+		if orgConfig.MSPID == "" {
+			return errors.Errorf("SM2 user IDs are configured for organization [%s] without MSP ID", orgName)
+		}
+		uids := make(map[string][]byte)
+		for id, uid := range orgConfig.SM2UIDs {
+			uids[strings.ToLower(id)] = []byte(uid)
+		}
+		c.sm2UIDsByMSP[orgConfig.MSPID] = uids
+	}
+	return nil
+}
+
 //loadClientTLSConfig pre-loads all TLSConfig bytes in client config
 func (c *IdentityConfig) loadClientTLSConfig(configEntity *identityConfigEntity) error {
 	//Clients Config
@@ -324,6 +373,7 @@ func (c *IdentityConfig) getMSPCAConfig(caName string, caConfig *CAConfig) (*msp
 
 	return &msp.CAConfig{
 		URL:              URL,
+		URLs:             caURLs(URL, caConfig.URLs),
 		GRPCOptions:      caConfig.GRPCOptions,
 		Registrar:        caConfig.Registrar,
 		CAName:           caConfig.CAName,
@@ -333,6 +383,24 @@ func (c *IdentityConfig) getMSPCAConfig(caName string, caConfig *CAConfig) (*msp
 	}, nil
 }
 
+// caURLs returns the URLs of a CA in failover order without duplicates
+func caURLs(URL string, URLs []string) []string {
+	caURLs := []string{URL}
+	for _, u := range URLs {
+		duplicate := false
+		for _, existing := range caURLs {
+			if u == existing {
+				duplicate = true
+				break
+			}
+		}
+		if u != "" && !duplicate {
+			caURLs = append(caURLs, u)
+		}
+	}
+	return caURLs
+}
+
 func (c *IdentityConfig) getServerCerts(caConfig *CAConfig) ([][]byte, error) {
 
 	var serverCerts [][]byte
@@ -432,6 +500,8 @@ func (c *IdentityConfig) findMatchingCAConfig(configEntity *identityConfigEntity
 		if strings.Contains(caConfig.URL, "$") {
 			caConfig.URL = matcher.regex.ReplaceAllString(caName, caConfig.URL)
 		}
+		//the failover URLs are those of the mapped host, not of the substituted URL
+		caConfig.URLs = nil
 	}
 
 	if caConfig.GRPCOptions == nil {
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"os"
+	"regexp"
 	"strings"
 
 	"encoding/pem"
@@ -19,6 +20,7 @@ import (
 	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab"
 	"github.com/pkg/errors"
@@ -505,3 +507,71 @@ func TestEntityMatchers(t *testing.T) {
 	assert.Equal(t, 3, len(configImpl.caMatchers), "preloading matchers isn't working as expected")
 
 }
+
+func TestSM2UIDs(t *testing.T) {
+	backend := &mocks.MockConfigBackend{KeyValueMap: map[string]interface{}{
+		"organizations": map[string]interface{}{
+			"org1": map[string]interface{}{
+				"mspid":   "Org1MSP",
+				"sm2UIDs": map[string]interface{}{"user1@org1.example.com": "user1@org1", "admin@org1.example.com": "admin@org1"},
+			},
+			"org2": map[string]interface{}{
+				"mspid": "Org2MSP",
+			},
+		},
+	}}
+	identityConfig := &IdentityConfig{backend: lookup.New(backend)}
+	assert.NoError(t, identityConfig.loadSM2UIDs())
+
+	uid, ok := identityConfig.SM2UID("Org1MSP", "User1@org1.example.com")
+	assert.True(t, ok, "SM2UID should be configured for User1@org1.example.com")
+	assert.Equal(t, []byte("user1@org1"), uid)
+
+	_, ok = identityConfig.SM2UID("Org1MSP", "user1")
+	assert.False(t, ok, "SM2UID should not be configured for user1")
+	_, ok = identityConfig.SM2UID("Org2MSP", "user1@org1.example.com")
+	assert.False(t, ok, "SM2UID should not be configured for Org2MSP")
+
+	// user IDs are looked up by MSP ID
+	backend.KeyValueMap["organizations"] = map[string]interface{}{
+		"org1": map[string]interface{}{
+			"sm2UIDs": map[string]interface{}{"user1": "user1@org1"},
+		},
+	}
+	assert.Error(t, identityConfig.loadSM2UIDs(), "loading SM2 user IDs without MSP ID should fail")
+}
+
+func TestCAURLs(t *testing.T) {
+	assert.Equal(t, []string{"https://ca1:7054"}, caURLs("https://ca1:7054", nil))
+
+	// the primary URL comes first, duplicates and empty URLs are dropped
+	urls := caURLs("https://ca1:7054", []string{"https://ca2:7054", "", "https://ca1:7054", "https://ca3:7054", "https://ca2:7054"})
+	assert.Equal(t, []string{"https://ca1:7054", "https://ca2:7054", "https://ca3:7054"}, urls)
+}
+
+func TestCAURLsWithEntityMatcher(t *testing.T) {
+	configEntity := &identityConfigEntity{
+		CertificateAuthorities: map[string]CAConfig{
+			"ca.org1.example.com": {URL: "https://ca.org1.example.com:7054", URLs: []string{"https://ca2.org1.example.com:7054"}},
+		},
+	}
+	matcher := func(urlSubstitutionExp string) matcherEntry {
+		return matcherEntry{
+			regex:       regexp.MustCompile(`(\w+).org1.example.com`),
+			matchConfig: MatchConfig{MappedHost: "ca.org1.example.com", URLSubstitutionExp: urlSubstitutionExp},
+		}
+	}
+	identityConfig := &IdentityConfig{}
+
+	// the failover URLs of the mapped host are kept with its URL
+	caConfig, ok := identityConfig.findMatchingCAConfig(configEntity, "ca.org1.example.com", matcher(""))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"https://ca2.org1.example.com:7054"}, caConfig.URLs)
+
+	// they don't apply to a substituted URL
+	caConfig, ok = identityConfig.findMatchingCAConfig(configEntity, "ca.org1.example.com", matcher("https://$1.local:7054"))
+	assert.True(t, ok)
+	assert.Equal(t, "https://ca.local:7054", caConfig.URL)
+	assert.Empty(t, caConfig.URLs)
+	assert.NotEmpty(t, configEntity.CertificateAuthorities["ca.org1.example.com"].URLs, "the mapped host config should be left unchanged")
+}
@@ -211,6 +211,11 @@ func (p *identityManagerProvider) IdentityManager(orgName string) (msp.IdentityM
 }
 
 func updateCAServerURL(caServerURL string, existingBackends []core.ConfigBackend) []core.ConfigBackend {
+	return updateCAServerURLs([]string{caServerURL}, existingBackends)
+}
+
+// updateCAServerURLs sets the failover URLs of the CAs
+func updateCAServerURLs(caServerURLs []string, existingBackends []core.ConfigBackend) []core.ConfigBackend {
 
 	//get existing certificateAuthorities
 	networkConfig := identityConfigEntity{}
@@ -218,10 +223,12 @@ func updateCAServerURL(caServerURL string, existingBackends []core.ConfigBackend
 
 	//update URLs
 	ca1Config := networkConfig.CertificateAuthorities["ca.org1.example.com"]
-	ca1Config.URL = caServerURL
+	ca1Config.URL = caServerURLs[0]
+	ca1Config.URLs = caServerURLs[1:]
 
 	ca2Config := networkConfig.CertificateAuthorities["ca.org2.example.com"]
-	ca2Config.URL = caServerURL
+	ca2Config.URL = caServerURLs[0]
+	ca2Config.URLs = caServerURLs[1:]
 
 	networkConfig.CertificateAuthorities["ca.org1.example.com"] = ca1Config
 	networkConfig.CertificateAuthorities[".ca.org2.example.com"] = ca2Config
@@ -7,11 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"sync"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 )
 
 // MemoryUserStore is in-memory implementation of UserStore
 type MemoryUserStore struct {
+	mutex sync.RWMutex
 	store map[string][]byte
 }
 
@@ -23,12 +26,16 @@ func NewMemoryUserStore() *MemoryUserStore {
 
 // Store stores a user into store
 func (s *MemoryUserStore) Store(user *msp.UserData) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.store[user.ID+"@"+user.MSPID] = user.EnrollmentCertificate
 	return nil
 }
 
 // Load loads a user from store
 func (s *MemoryUserStore) Load(id msp.IdentityIdentifier) (*msp.UserData, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	cert, ok := s.store[id.ID+"@"+id.MSPID]
 	if !ok {
 		return nil, msp.ErrUserNotFound
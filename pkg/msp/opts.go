@@ -21,6 +21,7 @@ type IdentityConfigOptions struct {
 	caClientCert
 	caKeyStorePath
 	credentialStorePath
+	sm2UID
 }
 
 type applier func()
@@ -62,6 +63,21 @@ type credentialStorePath interface {
 	CredentialStorePath() string
 }
 
+// sm2UID interface allows to uniquely override the optional SM2UID() function of IdentityConfig
+// (see verifier.SM2UIDResolver)
+type sm2UID interface {
+	SM2UID(mspID, id string) ([]byte, bool)
+}
+
+// SM2UID returns the SM2 user ID of the sm2UID option, none if no option nor the default
+// IdentityConfig resolves SM2 user IDs
+func (c *IdentityConfigOptions) SM2UID(mspID, id string) ([]byte, bool) {
+	if c.sm2UID == nil {
+		return nil, false
+	}
+	return c.sm2UID.SM2UID(mspID, id)
+}
+
 // BuildIdentityConfigFromOptions will return an IdentityConfig instance pre-built with Optional interfaces
 // provided in fabsdk's WithConfigIdentity(opts...) call
 func BuildIdentityConfigFromOptions(opts ...interface{}) (msp.IdentityConfig, error) {
@@ -90,12 +106,15 @@ func UpdateMissingOptsWithDefaultConfig(c *IdentityConfigOptions, d msp.Identity
 	s.set(c.caClientCert, nil, func() { c.caClientCert = d })
 	s.set(c.caKeyStorePath, nil, func() { c.caKeyStorePath = d })
 	s.set(c.credentialStorePath, nil, func() { c.credentialStorePath = d })
+	if u, ok := d.(sm2UID); ok {
+		s.set(c.sm2UID, nil, func() { c.sm2UID = u })
+	}
 
 	return c
 }
 
 // IsIdentityConfigFullyOverridden will return true if all of the argument's sub interfaces is not nil
-// (ie IdentityConfig interface not fully overridden)
+// (ie IdentityConfig interface not fully overridden), the optional sm2UID aside
 func IsIdentityConfigFullyOverridden(c *IdentityConfigOptions) bool {
 	return !anyNil(c.client, c.caConfig, c.caServerCerts, c.caClientKey, c.caClientCert, c.caKeyStorePath, c.credentialStorePath)
 }
@@ -111,6 +130,7 @@ func setIdentityConfigWithOptionInterface(c *IdentityConfigOptions, o interface{
 	s.set(c.caClientCert, func() bool { _, ok := o.(caClientCert); return ok }, func() { c.caClientCert = o.(caClientCert) })
 	s.set(c.caKeyStorePath, func() bool { _, ok := o.(caKeyStorePath); return ok }, func() { c.caKeyStorePath = o.(caKeyStorePath) })
 	s.set(c.credentialStorePath, func() bool { _, ok := o.(credentialStorePath); return ok }, func() { c.credentialStorePath = o.(credentialStorePath) })
+	s.set(c.sm2UID, func() bool { _, ok := o.(sm2UID); return ok }, func() { c.sm2UID = o.(sm2UID) })
 
 	if !s.isSet {
 		return errors.Errorf("option %#v is not a sub interface of IdentityConfig, at least one of its functions must be implemented.", o)
@@ -23,6 +23,7 @@ var (
 	m5 = &mockCaClientCert{}
 	m6 = &mockCaKeyStorePath{}
 	m7 = &mockCredentialStorePath{}
+	m8 = &mockSM2UID{}
 )
 
 func TestCreateCustomFullIdentitytConfig(t *testing.T) {
@@ -71,15 +72,16 @@ func TestCreateCustomIdentityConfig(t *testing.T) {
 	require.True(t, ok, "CAClientKey failed")
 	require.Equal(t, []byte("testCAclientkey"), c, "CAClientKey did not return the right cert")
 
-	// verify if an interface was not passed as an option but was not nil, it should be nil (ie these implementations should not be populated in ico: m5, m6 and m7)
+	// verify if an interface was not passed as an option but was not nil, it should be nil (ie these implementations should not be populated in ico: m5, m6, m7 and m8)
 	require.Nil(t, ico.caClientCert, "caClientCert created with nil interface but got non nil one: %s. Expected nil interface", ico.caClientCert)
 	require.Nil(t, ico.caKeyStorePath, "caKeyStorePath created with nil interface but got non nil one: %s. Expected nil interface", ico.caKeyStorePath)
 	require.Nil(t, ico.credentialStorePath, "credentialStorePath created with nil interface but got non nil one: %s. Expected nil interface", ico.credentialStorePath)
+	require.Nil(t, ico.sm2UID, "sm2UID created with nil interface but got non nil one: %s. Expected nil interface", ico.sm2UID)
 }
 
 func TestCreateCustomIdentityConfigRemainingFunctions(t *testing.T) {
 	// try to build with the remaining implementations not tested above
-	identityConfigOption, err := BuildIdentityConfigFromOptions(m5, m6, m7)
+	identityConfigOption, err := BuildIdentityConfigFromOptions(m5, m6, m7, m8)
 	if err != nil {
 		t.Fatalf("BuildIdentityConfigFromOptions returned unexpected error %s", err)
 	}
@@ -103,6 +105,11 @@ func TestCreateCustomIdentityConfigRemainingFunctions(t *testing.T) {
 	s = ico.CredentialStorePath()
 	require.Equal(t, "test/cred/store/path", s, "CredentialStorePath did not return expected interface value")
 
+	// test m8 implementation
+	uid, ok := ico.SM2UID("Org1MSP", "user1")
+	require.True(t, ok, "SM2UID failed")
+	require.Equal(t, []byte("testSM2UID"), uid, "SM2UID did not return expected interface value")
+
 	// verify if an interface was not passed as an option but was not nil, it should be nil (ie these implementations should not be populated in ico: m1, m2, m3 and m4)
 	require.Nil(t, ico.client, "client created with nil interface but got non nil one: %s. Expected nil interface", ico.client)
 	require.Nil(t, ico.caConfig, "caConfig created with nil interface but got non nil one: %s. Expected nil interface", ico.caConfig)
@@ -130,10 +137,11 @@ func TestCreateCustomIdentityConfigWithSomeDefaultFunctions(t *testing.T) {
 	require.NotNil(t, ico, "build ConfigIdentityOption returned is nil")
 
 	// now check if implementations that were not injected when building the config (ref first line in this function) are nil at this point
-	// ie, verify these implementations should be nil: m5, m6 and m7
+	// ie, verify these implementations should be nil: m5, m6, m7 and m8
 	require.Nil(t, ico.caClientCert, "caClientCert should be nil but got a non-nil one: %s. Expected nil interface", ico.caClientCert)
 	require.Nil(t, ico.caKeyStorePath, "caKeyStorePath should be nil but got non-nil one: %s. Expected nil interface", ico.caKeyStorePath)
 	require.Nil(t, ico.credentialStorePath, "credentialStorePath should be nil but got non-nil one: %s. Expected nil interface", ico.credentialStorePath)
+	require.Nil(t, ico.sm2UID, "sm2UID should be nil but got non-nil one: %s. Expected nil interface", ico.sm2UID)
 
 	// do the same test using IsIdentityConfigFullyOverridden() call
 	require.False(t, IsIdentityConfigFullyOverridden(ico), "IsIdentityConfigFullyOverridden is supposed to return false with an Options instance not implementing all the interface functions")
@@ -168,10 +176,11 @@ func TestCreateCustomIdentityConfigWithSomeDefaultFunctions(t *testing.T) {
 	}
 
 	// now check if implementations that were not injected when building the config (ref first line in this function) are defaulted with m0 this time
-	// ie, verify these implementations should now be populated in ico: m5, m6, m7
+	// ie, verify these implementations should now be populated in ico: m5, m6, m7 and m8
 	require.NotNil(t, ico.caClientCert, "caClientCert should be populated with default interface but got nil one: %s. Expected default interface", ico.caClientCert)
 	require.NotNil(t, ico.caKeyStorePath, "caKeyStorePath should be populated with default interface but got nil one: %s. Expected default interface", ico.caKeyStorePath)
 	require.NotNil(t, ico.credentialStorePath, "credentialStorePath should be populated with default interface but got nil one: %s. Expected default interface", ico.credentialStorePath)
+	require.NotNil(t, ico.sm2UID, "sm2UID should be populated with default interface but got nil one: %s. Expected default interface", ico.sm2UID)
 
 	// do the same test using IsIdentityConfigFullyOverridden() call
 	require.True(t, IsIdentityConfigFullyOverridden(ico), "IsIdentityConfigFullyOverridden is supposed to return true since all the interface functions should be implemented")
@@ -232,3 +241,9 @@ type mockCredentialStorePath struct{}
 func (m *mockCredentialStorePath) CredentialStorePath() string {
 	return "test/cred/store/path"
 }
+
+type mockSM2UID struct{}
+
+func (m *mockSM2UID) SM2UID(mspID, id string) ([]byte, bool) {
+	return []byte("testSM2UID"), true
+}
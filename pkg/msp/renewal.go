@@ -0,0 +1,258 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"encoding/pem"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRenewalWindow is the time before expiry from which enrollment certificates are renewed
+	DefaultRenewalWindow = 7 * 24 * time.Hour
+	// DefaultRenewalCheckInterval is the interval at which the expiry of enrollment certificates is checked
+	DefaultRenewalCheckInterval = time.Hour
+)
+
+// RenewalEvent reports the renewal of the enrollment certificate of an identity
+type RenewalEvent struct {
+	ID    string
+	MSPID string
+	// NotAfter is the expiry of the new certificate, or of the current one if the renewal failed
+	NotAfter time.Time
+	// Identity is the re-enrolled signing identity, nil if the renewal failed.
+	// Contexts created with the previous identity have to be recreated.
+	Identity msp.SigningIdentity
+	// Err is the error of a failed renewal
+	Err error
+}
+
+// RenewalHandler is notified of renewal events
+type RenewalHandler func(event *RenewalEvent)
+
+// RenewalOption describes a functional parameter for NewRenewalManager
+type RenewalOption func(*RenewalManager) error
+
+// WithRenewalWindow sets the time before expiry from which certificates are renewed
+func WithRenewalWindow(window time.Duration) RenewalOption {
+	return func(m *RenewalManager) error {
+		if window <= 0 {
+			return errors.New("renewal window must be positive")
+		}
+		m.window = window
+		return nil
+	}
+}
+
+// WithRenewalCheckInterval sets the interval at which the expiry of certificates is checked
+func WithRenewalCheckInterval(interval time.Duration) RenewalOption {
+	return func(m *RenewalManager) error {
+		if interval <= 0 {
+			return errors.New("renewal check interval must be positive")
+		}
+		m.interval = interval
+		return nil
+	}
+}
+
+// WithKeyRotation sets whether a new key is generated on renewal (the default),
+// or the certificate is renewed for the current key
+func WithKeyRotation(rotate bool) RenewalOption {
+	return func(m *RenewalManager) error {
+		m.reuseKey = !rotate
+		return nil
+	}
+}
+
+// WithRenewalHandler registers a handler of renewal events
+func WithRenewalHandler(handler RenewalHandler) RenewalOption {
+	return func(m *RenewalManager) error {
+		if handler == nil {
+			return errors.New("renewal handler is nil")
+		}
+		m.handlers = append(m.handlers, handler)
+		return nil
+	}
+}
+
+// RenewalManager re-enrolls tracked identities before their enrollment certificate
+// expires. The expiry is read from the certificates of the user store and the
+// renewed certificates are persisted to it, so identities retrieved from the
+// identity manager afterwards are the renewed ones.
+type RenewalManager struct {
+	caClient *CAClientImpl
+	window   time.Duration
+	interval time.Duration
+	reuseKey bool
+	handlers []RenewalHandler
+
+	mutex sync.Mutex
+	ids   map[string]struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewRenewalManager creates a renewal manager of the identities enrolled with the CA client
+func NewRenewalManager(caClient *CAClientImpl, opts ...RenewalOption) (*RenewalManager, error) {
+	if caClient == nil {
+		return nil, errors.New("CA client is required")
+	}
+	m := &RenewalManager{
+		caClient: caClient,
+		window:   DefaultRenewalWindow,
+		interval: DefaultRenewalCheckInterval,
+		ids:      make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, errors.WithMessage(err, "failed to create renewal manager")
+		}
+	}
+	return m, nil
+}
+
+// Track adds identities to renew
+func (m *RenewalManager) Track(ids ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, id := range ids {
+		m.ids[id] = struct{}{}
+	}
+}
+
+// Untrack stops renewing an identity
+func (m *RenewalManager) Untrack(id string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.ids, id)
+}
+
+// Start checks the tracked identities now and then at every check interval, until Stop is called
+func (m *RenewalManager) Start() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.run(m.stop, m.done)
+}
+
+// Stop stops the periodic checks, it waits for a check in progress to complete
+func (m *RenewalManager) Stop() {
+	m.mutex.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mutex.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (m *RenewalManager) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.Renew()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Renew re-enrolls the tracked identities whose certificate expires within the
+// renewal window. Handlers are notified of each renewal, the events are returned.
+func (m *RenewalManager) Renew() []*RenewalEvent {
+	var events []*RenewalEvent
+	deadline := time.Now().Add(m.window)
+	for _, id := range m.trackedIDs() {
+		notAfter, err := m.NotAfter(id)
+		if err == nil && notAfter.After(deadline) {
+			continue
+		}
+
+		event := &RenewalEvent{ID: id, MSPID: m.caClient.orgMSPID, NotAfter: notAfter, Err: err}
+		if err == nil {
+			logger.Infof("renewing enrollment certificate of [%s], expiring at %s", id, notAfter)
+			m.renew(event)
+		}
+		if event.Err != nil {
+			logger.Warnf("failed to renew enrollment certificate of [%s]: %s", id, event.Err)
+		}
+		for _, handler := range m.handlers {
+			handler(event)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func (m *RenewalManager) renew(event *RenewalEvent) {
+	err := m.caClient.Reenroll(&api.ReenrollmentRequest{Name: event.ID, ReuseKey: m.reuseKey})
+	if err != nil {
+		event.Err = err
+		return
+	}
+	identity, err := m.caClient.identityManager.GetSigningIdentity(event.ID)
+	if err != nil {
+		event.Err = errors.WithMessage(err, "failed to retrieve renewed identity")
+		return
+	}
+	notAfter, err := certNotAfter(identity.EnrollmentCertificate())
+	if err != nil {
+		event.Err = err
+		return
+	}
+	event.Identity = identity
+	event.NotAfter = notAfter
+}
+
+// NotAfter returns the expiry of the enrollment certificate of the identity in the user store
+func (m *RenewalManager) NotAfter(id string) (time.Time, error) {
+	userData, err := m.caClient.userStore.Load(msp.IdentityIdentifier{MSPID: m.caClient.orgMSPID, ID: id})
+	if err != nil {
+		return time.Time{}, errors.WithMessage(err, "failed to load enrollment certificate")
+	}
+	return certNotAfter(userData.EnrollmentCertificate)
+}
+
+func (m *RenewalManager) trackedIDs() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	ids := make([]string, 0, len(m.ids))
+	for id := range m.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func certNotAfter(certPEM []byte) (time.Time, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return time.Time{}, errors.New("invalid enrollment certificate")
+	}
+	cert, err := sm2.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse enrollment certificate")
+	}
+	return cert.NotAfter, nil
+}
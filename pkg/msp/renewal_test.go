@@ -0,0 +1,142 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewalManager(t *testing.T) {
+	ca := newSM2TestCA(t)
+	server := httptest.NewServer(ca)
+	defer server.Close()
+
+	caClient, identityManager := newSM2TestCAClient(t, server.URL, newGMCryptoSuite(t))
+	enrollUsername := createRandomName()
+	err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.NoError(t, err)
+	enrolled, err := identityManager.GetSigningIdentity(enrollUsername)
+	require.NoError(t, err)
+
+	var handled []*RenewalEvent
+	handler := func(event *RenewalEvent) { handled = append(handled, event) }
+
+	// the certificates of the test CA expire in an hour
+	manager, err := NewRenewalManager(caClient, WithRenewalWindow(30*time.Minute), WithRenewalHandler(handler))
+	require.NoError(t, err)
+	manager.Track(enrollUsername)
+	notAfter, err := manager.NotAfter(enrollUsername)
+	require.NoError(t, err)
+	assert.Equal(t, ca.issued[0].NotAfter, notAfter)
+	assert.Empty(t, manager.Renew(), "certificate should not be renewed before the renewal window")
+	assert.Empty(t, handled)
+
+	manager, err = NewRenewalManager(caClient, WithRenewalWindow(2*time.Hour), WithRenewalHandler(handler))
+	require.NoError(t, err)
+	manager.Track(enrollUsername)
+	events := manager.Renew()
+	require.Len(t, events, 1)
+	assert.Equal(t, events, handled)
+	event := events[0]
+	require.NoError(t, event.Err)
+	require.Len(t, ca.issued, 2)
+	assert.Equal(t, enrollUsername, event.ID)
+	assert.Equal(t, caClient.orgMSPID, event.MSPID)
+	assert.Equal(t, ca.issued[1].NotAfter, event.NotAfter)
+	assert.NotEqual(t, enrolled.PrivateKey().SKI(), event.Identity.PrivateKey().SKI(), "key should be rotated by default")
+
+	// the renewed identity is persisted
+	renewed, err := identityManager.GetSigningIdentity(enrollUsername)
+	require.NoError(t, err)
+	assert.Equal(t, event.Identity.EnrollmentCertificate(), renewed.EnrollmentCertificate())
+
+	// renewal for the current key
+	manager, err = NewRenewalManager(caClient, WithRenewalWindow(2*time.Hour), WithKeyRotation(false))
+	require.NoError(t, err)
+	manager.Track(enrollUsername)
+	events = manager.Renew()
+	require.Len(t, events, 1)
+	require.NoError(t, events[0].Err)
+	require.Len(t, ca.issued, 3)
+	assert.Equal(t, renewed.PrivateKey().SKI(), events[0].Identity.PrivateKey().SKI())
+	assert.NotEqual(t, renewed.EnrollmentCertificate(), events[0].Identity.EnrollmentCertificate())
+
+	// identities which are not enrolled can't be renewed
+	manager.Untrack(enrollUsername)
+	manager.Track("unknown")
+	events = manager.Renew()
+	require.Len(t, events, 1)
+	assert.Error(t, events[0].Err)
+	assert.Nil(t, events[0].Identity)
+	assert.Len(t, ca.issued, 3)
+}
+
+func TestRenewalManagerStart(t *testing.T) {
+	ca := newSM2TestCA(t)
+	server := httptest.NewServer(ca)
+	defer server.Close()
+
+	caClient, _ := newSM2TestCAClient(t, server.URL, newGMCryptoSuite(t))
+	enrollUsername := createRandomName()
+	err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.NoError(t, err)
+
+	renewed := make(chan *RenewalEvent, 10)
+	manager, err := NewRenewalManager(caClient, WithRenewalWindow(2*time.Hour), WithRenewalCheckInterval(10*time.Millisecond),
+		WithRenewalHandler(func(event *RenewalEvent) { renewed <- event }))
+	require.NoError(t, err)
+	manager.Track(enrollUsername)
+
+	manager.Start()
+	manager.Start()
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-renewed:
+			require.NoError(t, event.Err)
+			assert.Equal(t, enrollUsername, event.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for renewal")
+		}
+	}
+	manager.Stop()
+	manager.Stop()
+
+	count := ca.issuedCount()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, count, ca.issuedCount(), "no renewal should happen once stopped")
+}
+
+func TestNewRenewalManagerOptions(t *testing.T) {
+	_, err := NewRenewalManager(nil)
+	assert.Error(t, err)
+
+	caClient := &CAClientImpl{}
+	_, err = NewRenewalManager(caClient, WithRenewalWindow(0))
+	assert.Error(t, err)
+	_, err = NewRenewalManager(caClient, WithRenewalCheckInterval(-time.Second))
+	assert.Error(t, err)
+	_, err = NewRenewalManager(caClient, WithRenewalHandler(nil))
+	assert.Error(t, err)
+
+	manager, err := NewRenewalManager(caClient)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRenewalWindow, manager.window)
+	assert.Equal(t, DefaultRenewalCheckInterval, manager.interval)
+	assert.False(t, manager.reuseKey)
+}
+
+func (ca *sm2TestCA) issuedCount() int {
+	ca.mutex.Lock()
+	defer ca.mutex.Unlock()
+	return len(ca.issued)
+}
@@ -0,0 +1,423 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cfsslapi "github.com/cloudflare/cfssl/api"
+	caapi "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/common"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	gmbccsp "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/gm"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm3"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var oidExtensionSubjectKeyID = asn1.ObjectIdentifier{2, 5, 29, 14}
+
+// sm2TestCA is a Fabric CA which issues SM2 certificates. It verifies the
+// signature of the CSRs and the token of re-enrollment and removal requests
+type sm2TestCA struct {
+	t       *testing.T
+	key     *sm2.PrivateKey
+	cert    *sm2.Certificate
+	certPEM []byte
+
+	mutex   sync.Mutex
+	serial  int64
+	issued  []*sm2.Certificate
+	removed []string
+}
+
+func newSM2TestCA(t *testing.T) *sm2TestCA {
+	key, err := sm2.GenerateKey()
+	require.NoError(t, err)
+	template := &sm2.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              sm2.KeyUsageCertSign,
+	}
+	der, err := sm2.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := sm2.ParseCertificate(der)
+	require.NoError(t, err)
+	return &sm2TestCA{
+		t:       t,
+		key:     key,
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		serial:  1,
+	}
+}
+
+func (ca *sm2TestCA) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "/cainfo":
+		ca.sendResponse(w, &common.CAInfoResponseNet{CAName: "ca.org1.example.com", CAChain: util.B64Encode(ca.certPEM), Version: "1.4.0"})
+	case "/enroll":
+		if user, secret, ok := req.BasicAuth(); !ok || user == "" || secret == "" {
+			ca.sendError(w, "invalid enrollment secret")
+			return
+		}
+		ca.issue(w, req)
+	case "/reenroll":
+		ca.issue(w, req)
+	default:
+		if strings.HasPrefix(req.URL.Path, "/identities/") && req.Method == http.MethodDelete {
+			ca.removeIdentity(w, req)
+			return
+		}
+		ca.sendError(w, "unexpected request "+req.URL.Path)
+	}
+}
+
+// issue issues a certificate for the CSR of the request
+func (ca *sm2TestCA) issue(w http.ResponseWriter, req *http.Request) {
+	raw, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		ca.sendError(w, err.Error())
+		return
+	}
+	if req.URL.Path == "/reenroll" {
+		if err = ca.verifyToken(req, raw); err != nil {
+			ca.sendError(w, err.Error())
+			return
+		}
+	}
+	var enrollReq caapi.EnrollmentRequestNet
+	if err = json.Unmarshal(raw, &enrollReq); err != nil {
+		ca.sendError(w, err.Error())
+		return
+	}
+
+	block, _ := pem.Decode([]byte(enrollReq.Request))
+	if block == nil {
+		ca.sendError(w, "invalid CSR")
+		return
+	}
+	csr, err := sm2.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		ca.sendError(w, err.Error())
+		return
+	}
+	pub, ok := csr.PublicKey.(*sm2.PublicKey)
+	if !ok {
+		ca.sendError(w, "CSR public key is not an SM2 key")
+		return
+	}
+	if !verifySM2Request(pub, csr.RawTBSCertificateRequest, csr.Signature) {
+		ca.sendError(w, "invalid CSR signature")
+		return
+	}
+
+	ca.mutex.Lock()
+	ca.serial++
+	template := &sm2.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     sm2.KeyUsageDigitalSignature,
+	}
+	ca.mutex.Unlock()
+	// the requested SKI is kept, as remote signers find key pairs by SKI
+	if template.SubjectKeyId, err = requestedSKI(csr.RawTBSCertificateRequest); err != nil {
+		ca.sendError(w, err.Error())
+		return
+	}
+	der, err := sm2.CreateCertificate(rand.Reader, template, ca.cert, pub, ca.key)
+	if err != nil {
+		ca.sendError(w, err.Error())
+		return
+	}
+	cert, err := sm2.ParseCertificate(der)
+	if err != nil {
+		ca.sendError(w, err.Error())
+		return
+	}
+	ca.mutex.Lock()
+	ca.issued = append(ca.issued, cert)
+	ca.mutex.Unlock()
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	ca.sendResponse(w, &common.EnrollmentResponseNet{
+		Cert:       util.B64Encode(certPEM),
+		ServerInfo: common.CAInfoResponseNet{CAName: "ca.org1.example.com", CAChain: util.B64Encode(ca.certPEM)},
+	})
+}
+
+func (ca *sm2TestCA) removeIdentity(w http.ResponseWriter, req *http.Request) {
+	if err := ca.verifyToken(req, nil); err != nil {
+		ca.sendError(w, err.Error())
+		return
+	}
+	id := strings.TrimPrefix(req.URL.Path, "/identities/")
+	ca.mutex.Lock()
+	ca.removed = append(ca.removed, id)
+	ca.mutex.Unlock()
+	ca.sendResponse(w, &caapi.IdentityResponse{ID: id, CAName: "ca.org1.example.com"})
+}
+
+// verifyToken verifies the token of the authorization header, it is the
+// enrollment certificate and the SM2 signature of the request
+func (ca *sm2TestCA) verifyToken(req *http.Request, body []byte) error {
+	parts := strings.Split(req.Header.Get("authorization"), ".")
+	if len(parts) != 2 {
+		return errors.New("invalid token")
+	}
+	certPEM, err := util.B64Decode(parts[0])
+	if err != nil {
+		return err
+	}
+	sig, err := util.B64Decode(parts[1])
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return errors.New("invalid token certificate")
+	}
+	cert, err := sm2.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	if err = cert.CheckSignatureFrom(ca.cert); err != nil {
+		return err
+	}
+
+	payload := req.Method + "." + util.B64Encode([]byte(req.URL.RequestURI())) + "." + util.B64Encode(body) + "." + parts[0]
+	if !verifySM2(cert.PublicKey.(*sm2.PublicKey), []byte(payload), sig) {
+		return errors.New("invalid token signature")
+	}
+	return nil
+}
+
+func (ca *sm2TestCA) sendResponse(w http.ResponseWriter, resp interface{}) {
+	if err := cfsslapi.SendResponse(w, resp); err != nil {
+		ca.t.Errorf("failed to send response: %s", err)
+	}
+}
+
+func (ca *sm2TestCA) sendError(w http.ResponseWriter, msg string) {
+	ca.t.Errorf("SM2 test CA: %s", msg)
+	w.WriteHeader(http.StatusBadRequest)
+	ca.sendResponse(w, &cfsslapi.Response{Success: false, Errors: []cfsslapi.ResponseMessage{{Code: 400, Message: msg}}})
+}
+
+// requestedSKI returns the subject key identifier of the extension request of
+// the CSR, if any. The sm2 package doesn't parse the extensions of CSRs
+func requestedSKI(tbs []byte) ([]byte, error) {
+	var info struct {
+		Version       int
+		Subject       asn1.RawValue
+		PublicKey     asn1.RawValue
+		RawAttributes []asn1.RawValue `asn1:"tag:0"`
+	}
+	if _, err := asn1.Unmarshal(tbs, &info); err != nil {
+		return nil, err
+	}
+	for _, rawAttr := range info.RawAttributes {
+		var attr struct {
+			Type   asn1.ObjectIdentifier
+			Values [][]pkix.Extension `asn1:"set"`
+		}
+		if _, err := asn1.Unmarshal(rawAttr.FullBytes, &attr); err != nil {
+			return nil, err
+		}
+		for _, values := range attr.Values {
+			for _, ext := range values {
+				if ext.Id.Equal(oidExtensionSubjectKeyID) {
+					var ski []byte
+					_, err := asn1.Unmarshal(ext.Value, &ski)
+					return ski, err
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// verifySM2Request verifies the SM2 signature of a certificate request, computed over
+// SM3(ZA || tbs) for the default user ID as GM/T 0015 CAs expect
+func verifySM2Request(pub *sm2.PublicKey, tbs, sig []byte) bool {
+	r, s, err := sm2.SignDataToSignDigit(sig)
+	if err != nil {
+		return false
+	}
+	return sm2.Sm2Verify(pub, tbs, []byte("1234567812345678"), r, s)
+}
+
+// verifySM2 verifies the plain SM2 signature of the SM3 digest of msg
+func verifySM2(pub *sm2.PublicKey, msg, sig []byte) bool {
+	r, s, err := sm2.SignDataToSignDigit(sig)
+	if err != nil {
+		return false
+	}
+	return sm2.Verify(pub, sm3.Sm3Sum(msg), r, s)
+}
+
+// newSM2TestCAClient returns a CA client of org1 enrolling with the test CA
+func newSM2TestCAClient(t *testing.T, caURL string, cryptoSuite core.CryptoSuite) (*CAClientImpl, *IdentityManager) {
+	return newSM2FailoverTestCAClient(t, []string{caURL}, cryptoSuite)
+}
+
+func newSM2FailoverTestCAClient(t *testing.T, caURLs []string, cryptoSuite core.CryptoSuite, opts ...CAClientOption) (*CAClientImpl, *IdentityManager) {
+	backend, err := getCustomBackend(configPath)
+	require.NoError(t, err)
+	backend = updateCAServerURLs(caURLs, backend)
+
+	endpointConfig, err := fabImpl.ConfigFromBackend(backend...)
+	require.NoError(t, err)
+	identityConfig, err := ConfigFromBackend(backend...)
+	require.NoError(t, err)
+
+	userStore := NewMemoryUserStore()
+	identityManager, err := NewIdentityManager(org1, userStore, cryptoSuite, endpointConfig)
+	require.NoError(t, err)
+	ctxProvider := context.NewProvider(
+		context.WithIdentityManagerProvider(&identityManagerProvider{identityManager: map[string]msp.IdentityManager{"org1": identityManager}}),
+		context.WithUserStore(userStore), context.WithCryptoSuite(cryptoSuite),
+		context.WithCryptoSuiteConfig(cryptosuite.ConfigFromBackend(backend...)), context.WithEndpointConfig(endpointConfig),
+		context.WithIdentityConfig(identityConfig))
+	caClient, err := NewCAClient(org1, &context.Client{Providers: ctxProvider}, opts...)
+	require.NoError(t, err)
+	return caClient, identityManager
+}
+
+func newGMCryptoSuite(t *testing.T) core.CryptoSuite {
+	csp, err := gmbccsp.NewDefaultSecurityLevelWithKeystore(gmbccsp.NewInMemoryKeyStore())
+	require.NoError(t, err)
+	return wrapper.NewCryptoSuite(csp)
+}
+
+func TestEnrollAndReenrollSM2(t *testing.T) {
+	ca := newSM2TestCA(t)
+	server := httptest.NewServer(ca)
+	defer server.Close()
+
+	cryptoSuite := newGMCryptoSuite(t)
+	caClient, identityManager := newSM2TestCAClient(t, server.URL, cryptoSuite)
+
+	enrollUsername := createRandomName()
+	err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", KeyAlgorithm: "sm2"})
+	require.NoError(t, err)
+	require.Len(t, ca.issued, 1)
+	assert.Equal(t, enrollUsername, ca.issued[0].Subject.CommonName)
+
+	enrolled, err := identityManager.GetSigningIdentity(enrollUsername)
+	require.NoError(t, err)
+	assert.True(t, enrolled.PrivateKey().Private())
+	assert.True(t, cryptoutil.IsSM2Key(enrolled.PrivateKey()))
+
+	// the algorithm of the current key is used by default
+	err = caClient.Reenroll(&api.ReenrollmentRequest{Name: enrollUsername})
+	require.NoError(t, err)
+	require.Len(t, ca.issued, 2)
+
+	reenrolled, err := identityManager.GetSigningIdentity(enrollUsername)
+	require.NoError(t, err)
+	assert.True(t, cryptoutil.IsSM2Key(reenrolled.PrivateKey()))
+	assert.NotEqual(t, enrolled.PrivateKey().SKI(), reenrolled.PrivateKey().SKI(), "re-enrollment should generate a new key")
+	assert.NotEqual(t, enrolled.EnrollmentCertificate(), reenrolled.EnrollmentCertificate())
+
+	// the enrolled key signs transactions as the other SM2 keys of the suite
+	digest, err := cryptoSuite.Hash([]byte("transaction"), cryptosuite.GetGMSM3Opts())
+	require.NoError(t, err)
+	sig, err := cryptoSuite.Sign(reenrolled.PrivateKey(), digest, nil)
+	require.NoError(t, err)
+	assert.True(t, verifySM2(ca.issued[1].PublicKey.(*sm2.PublicKey), []byte("transaction"), sig))
+}
+
+// remoteKeySuite emulates a crypto suite whose keys are held by a remote signer
+type remoteKeySuite struct {
+	core.CryptoSuite
+
+	mutex   sync.Mutex
+	bound   map[string][]byte
+	deleted [][]byte
+}
+
+func (s *remoteKeySuite) BindCertificate(k core.Key, cert []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.bound[string(k.SKI())] = cert
+	return nil
+}
+
+func (s *remoteKeySuite) DeleteKeyPair(k core.Key) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.deleted = append(s.deleted, k.SKI())
+	return nil
+}
+
+func TestEnrollRemoteSignerKeys(t *testing.T) {
+	ca := newSM2TestCA(t)
+	server := httptest.NewServer(ca)
+	defer server.Close()
+
+	cryptoSuite := &remoteKeySuite{CryptoSuite: newGMCryptoSuite(t), bound: make(map[string][]byte)}
+	caClient, identityManager := newSM2TestCAClient(t, server.URL, cryptoSuite)
+
+	// remote signers hold SM2 keys, which are requested by default
+	enrollUsername := createRandomName()
+	err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"})
+	require.NoError(t, err)
+	enrolled, err := identityManager.GetSigningIdentity(enrollUsername)
+	require.NoError(t, err)
+	require.True(t, cryptoutil.IsSM2Key(enrolled.PrivateKey()))
+	assert.Equal(t, enrolled.PrivateKey().SKI(), ca.issued[0].SubjectKeyId, "the certificate should have the SKI of the remote key")
+	assert.Equal(t, enrolled.EnrollmentCertificate(), cryptoSuite.bound[string(enrolled.PrivateKey().SKI())])
+
+	// re-enrollment binds the certificate to a new key pair and deletes the previous one
+	err = caClient.Reenroll(&api.ReenrollmentRequest{Name: enrollUsername})
+	require.NoError(t, err)
+	reenrolled, err := identityManager.GetSigningIdentity(enrollUsername)
+	require.NoError(t, err)
+	assert.NotEqual(t, enrolled.PrivateKey().SKI(), reenrolled.PrivateKey().SKI())
+	assert.Equal(t, reenrolled.EnrollmentCertificate(), cryptoSuite.bound[string(reenrolled.PrivateKey().SKI())])
+	assert.Equal(t, [][]byte{enrolled.PrivateKey().SKI()}, cryptoSuite.deleted)
+
+	// removal deletes the key pair of the identity
+	_, err = caClient.RemoveIdentity(&api.RemoveIdentityRequest{ID: enrollUsername})
+	require.NoError(t, err)
+	assert.Equal(t, []string{enrollUsername}, ca.removed)
+	assert.Equal(t, [][]byte{enrolled.PrivateKey().SKI(), reenrolled.PrivateKey().SKI()}, cryptoSuite.deleted)
+
+	// identities unknown to the client have no key pair to delete
+	_, err = caClient.RemoveIdentity(&api.RemoveIdentityRequest{ID: "unknown"})
+	require.NoError(t, err)
+	assert.Len(t, cryptoSuite.deleted, 2)
+}
@@ -61,6 +61,21 @@ type serverInfoResponseNet struct {
 	CAChain string
 }
 
+// The response to the GET /certificates request
+type certificatesResponseNet struct {
+	Certs []certPEM `json:"certs"`
+}
+
+type certPEM struct {
+	PEM string
+}
+
+// The response to the POST /gencrl request
+type genCRLResponseNet struct {
+	// Base64 encoding of PEM-encoded CRL
+	CRL string
+}
+
 // MockFabricCAServer is a mock for FabricCAServer
 type MockFabricCAServer struct {
 	address     string
@@ -90,6 +105,8 @@ func (s *MockFabricCAServer) Start(lis net.Listener, cryptoSuite core.CryptoSuit
 	http.HandleFunc("/affiliations", s.affiliations)
 	http.HandleFunc("/affiliations/123", s.affiliation)
 	http.HandleFunc("/cainfo", s.cainfo)
+	http.HandleFunc("/certificates", s.certificates)
+	http.HandleFunc("/gencrl", s.gencrl)
 
 	server := &http.Server{
 		Addr:      addr,
@@ -265,3 +282,30 @@ func (s *MockFabricCAServer) cainfo(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 }
+
+// Handler for retrieving certificates, only the certificates of identity 123 are known
+func (s *MockFabricCAServer) certificates(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		resp := &certificatesResponseNet{Certs: []certPEM{}}
+		if id := req.URL.Query().Get("id"); id == "" || id == "123" {
+			resp.Certs = append(resp.Certs, certPEM{PEM: ecert})
+		}
+		if err := cfsslapi.SendResponse(w, resp); err != nil {
+			logger.Error(err)
+		}
+	default:
+		// Give an error message
+		logger.Error("Request method not supported ")
+	}
+}
+
+func (s *MockFabricCAServer) gencrl(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		resp := &genCRLResponseNet{CRL: util.B64Encode([]byte("MockCRL"))}
+		if err := cfsslapi.SendResponse(w, resp); err != nil {
+			logger.Error(err)
+		}
+	}
+}
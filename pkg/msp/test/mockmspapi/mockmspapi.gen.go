@@ -78,6 +78,21 @@ func (mr *MockCAClientMockRecorder) Enroll(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockCAClient)(nil).Enroll), arg0)
 }
 
+// GenCRL mocks base method
+func (m *MockCAClient) GenCRL(arg0 *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenCRL", arg0)
+	ret0, _ := ret[0].(*api.GenCRLResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenCRL indicates an expected call of GenCRL
+func (mr *MockCAClientMockRecorder) GenCRL(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenCRL", reflect.TypeOf((*MockCAClient)(nil).GenCRL), arg0)
+}
+
 // GetAffiliation mocks base method
 func (m *MockCAClient) GetAffiliation(arg0, arg1 string) (*api.AffiliationResponse, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +153,21 @@ func (mr *MockCAClientMockRecorder) GetCAInfo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCAInfo", reflect.TypeOf((*MockCAClient)(nil).GetCAInfo))
 }
 
+// GetCertificates mocks base method
+func (m *MockCAClient) GetCertificates(arg0 *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCertificates", arg0)
+	ret0, _ := ret[0].(*api.GetCertificatesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCertificates indicates an expected call of GetCertificates
+func (mr *MockCAClientMockRecorder) GetCertificates(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCertificates", reflect.TypeOf((*MockCAClient)(nil).GetCertificates), arg0)
+}
+
 // GetIdentity mocks base method
 func (m *MockCAClient) GetIdentity(arg0, arg1 string) (*api.IdentityResponse, error) {
 	m.ctrl.T.Helper()
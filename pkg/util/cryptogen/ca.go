@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptogen
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// certValidity is the validity period of the generated certificates
+const certValidity = 10 * 365 * 24 * time.Hour
+
+// ca is a self-signed certificate authority of an organization
+type ca struct {
+	name      string
+	algorithm string
+	cert      *x509.Certificate
+	certPEM   []byte
+	key       *privateKey
+}
+
+// newCA generates the key and the self-signed certificate of a CA in baseDir
+func newCA(baseDir string, org string, spec NodeSpec, algorithm string) (*ca, error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create CA directory [%s]", baseDir)
+	}
+
+	key, err := generatePrivateKey(baseDir, algorithm)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to generate CA key")
+	}
+
+	template, err := x509Template()
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment |
+		x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.Subject = subjectTemplate(spec)
+	template.Subject.Organization = []string{org}
+	template.Subject.CommonName = spec.CommonName
+	template.SubjectKeyId = key.key.SKI()
+	template.PublicKey = key.public
+
+	certPEM, err := key.createCertificate(template, template, key.public)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create CA certificate")
+	}
+	if err := writePEM(certFile(baseDir, spec.CommonName), certPEM); err != nil {
+		return nil, err
+	}
+
+	return &ca{
+		name:      spec.CommonName,
+		algorithm: algorithm,
+		cert:      template,
+		certPEM:   certPEM,
+		key:       key,
+	}, nil
+}
+
+// signCertificate issues a certificate for pub, named name, and writes it to baseDir
+func (c *ca) signCertificate(baseDir, name string, ous, sans []string, pub interface{},
+	ku x509.KeyUsage, eku []x509.ExtKeyUsage) ([]byte, error) {
+
+	template, err := x509Template()
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = ku
+	template.ExtKeyUsage = eku
+	template.Subject = pkix.Name{
+		Country:            c.cert.Subject.Country,
+		Province:           c.cert.Subject.Province,
+		Locality:           c.cert.Subject.Locality,
+		StreetAddress:      c.cert.Subject.StreetAddress,
+		PostalCode:         c.cert.Subject.PostalCode,
+		CommonName:         name,
+		OrganizationalUnit: ous,
+	}
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, san)
+		}
+	}
+
+	certPEM, err := c.key.createCertificate(template, c.cert, pub)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to sign certificate for ["+name+"]")
+	}
+	if err := writePEM(certFile(baseDir, name), certPEM); err != nil {
+		return nil, err
+	}
+	return certPEM, nil
+}
+
+// x509Template returns a certificate template with a random serial number
+func x509Template() (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serial number")
+	}
+
+	// backdate the certificate to tolerate clock skew
+	notBefore := time.Now().Round(time.Minute).Add(-5 * time.Minute).UTC()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(certValidity).UTC(),
+	}, nil
+}
+
+// subjectTemplate returns the subject of the CA spec, with the defaults of cryptogen
+func subjectTemplate(spec NodeSpec) pkix.Name {
+	name := pkix.Name{
+		Country:  []string{"US"},
+		Province: []string{"California"},
+		Locality: []string{"San Francisco"},
+	}
+	if spec.Country != "" {
+		name.Country = []string{spec.Country}
+	}
+	if spec.Province != "" {
+		name.Province = []string{spec.Province}
+	}
+	if spec.Locality != "" {
+		name.Locality = []string{spec.Locality}
+	}
+	if spec.OrganizationalUnit != "" {
+		name.OrganizationalUnit = []string{spec.OrganizationalUnit}
+	}
+	if spec.StreetAddress != "" {
+		name.StreetAddress = []string{spec.StreetAddress}
+	}
+	if spec.PostalCode != "" {
+		name.PostalCode = []string{spec.PostalCode}
+	}
+	return name
+}
+
+func certFile(baseDir, name string) string {
+	return filepath.Join(baseDir, name+"-cert.pem")
+}
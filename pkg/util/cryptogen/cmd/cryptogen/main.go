@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command cryptogen generates the crypto material of a test network with
+// ECDSA or SM2 keys. It accepts the arguments of the cryptogen tool:
+//
+//	cryptogen generate --config=crypto-config.yaml --output=crypto-config [--algorithm=sm2]
+//	cryptogen showtemplate
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/cryptogen"
+)
+
+const defaultConfig = `
+# KeyAlgorithm is ecdsa (default) or sm2, it may be overridden per organization
+KeyAlgorithm: ecdsa
+
+OrdererOrgs:
+  - Name: Orderer
+    Domain: example.com
+    Specs:
+      - Hostname: orderer
+
+PeerOrgs:
+  - Name: Org1
+    Domain: org1.example.com
+    EnableNodeOUs: false
+    # KeyAlgorithm: sm2
+    Template:
+      Count: 1
+      # Start: 5
+      # Hostname: {{.Prefix}}{{.Index}} # default
+      # SANS:
+      #   - "{{.Hostname}}.alt.{{.Domain}}"
+    Users:
+      Count: 1
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "generate":
+		flags := flag.NewFlagSet("generate", flag.ExitOnError)
+		configFile := flags.String("config", "", "The configuration template to use")
+		output := flags.String("output", "crypto-config", "The output directory in which to place artifacts")
+		algorithm := flags.String("algorithm", "", "The default key algorithm (ecdsa or sm2) of organizations without KeyAlgorithm")
+		flags.Parse(os.Args[2:]) // nolint: errcheck
+
+		exitWhenError(generate(*configFile, *output, *algorithm))
+	case "showtemplate":
+		fmt.Print(defaultConfig)
+	default:
+		usage()
+	}
+}
+
+func generate(configFile, output, algorithm string) error {
+	var config *cryptogen.Config
+	var err error
+	if configFile == "" {
+		config, err = cryptogen.ParseConfig([]byte(defaultConfig))
+	} else {
+		config, err = cryptogen.LoadConfig(configFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	if algorithm != "" {
+		config.KeyAlgorithm = algorithm
+	}
+	return cryptogen.Generate(config, output)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cryptogen generate [--config=<file>] [--output=<dir>] [--algorithm=ecdsa|sm2]")
+	fmt.Fprintln(os.Stderr, "       cryptogen showtemplate")
+	os.Exit(2)
+}
+
+func exitWhenError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptogen
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// ECDSA generates ECDSA P-256 keys and ECDSA-SHA256 certificates
+	ECDSA = "ecdsa"
+	// SM2 generates SM2 keys and SM2-SM3 certificates
+	SM2 = "sm2"
+)
+
+const (
+	defaultHostnameTemplate   = "{{.Prefix}}{{.Index}}"
+	defaultCommonNameTemplate = "{{.Hostname}}.{{.Domain}}"
+)
+
+// Config is the crypto material spec, in the format of the cryptogen tool,
+// with the key algorithm as an addition
+type Config struct {
+	// KeyAlgorithm is the default key algorithm of all organizations (ecdsa or sm2)
+	KeyAlgorithm string    `yaml:"KeyAlgorithm,omitempty"`
+	OrdererOrgs  []OrgSpec `yaml:"OrdererOrgs"`
+	PeerOrgs     []OrgSpec `yaml:"PeerOrgs"`
+}
+
+// OrgSpec describes an organization, its CA and its nodes and users
+type OrgSpec struct {
+	Name          string `yaml:"Name"`
+	Domain        string `yaml:"Domain"`
+	EnableNodeOUs bool   `yaml:"EnableNodeOUs"`
+	// KeyAlgorithm overrides the default key algorithm for this organization
+	KeyAlgorithm string       `yaml:"KeyAlgorithm,omitempty"`
+	CA           NodeSpec     `yaml:"CA"`
+	Template     NodeTemplate `yaml:"Template"`
+	Specs        []NodeSpec   `yaml:"Specs"`
+	Users        UsersSpec    `yaml:"Users"`
+}
+
+// NodeTemplate generates Count nodes named by the Hostname template
+type NodeTemplate struct {
+	Count    int      `yaml:"Count"`
+	Start    int      `yaml:"Start"`
+	Hostname string   `yaml:"Hostname"`
+	SANS     []string `yaml:"SANS"`
+}
+
+// NodeSpec describes a node (or the CA) of an organization. CommonName and
+// SANS are templates with the fields of the node as data
+type NodeSpec struct {
+	Hostname           string   `yaml:"Hostname"`
+	CommonName         string   `yaml:"CommonName"`
+	Country            string   `yaml:"Country"`
+	Province           string   `yaml:"Province"`
+	Locality           string   `yaml:"Locality"`
+	OrganizationalUnit string   `yaml:"OrganizationalUnit"`
+	StreetAddress      string   `yaml:"StreetAddress"`
+	PostalCode         string   `yaml:"PostalCode"`
+	SANS               []string `yaml:"SANS"`
+}
+
+// UsersSpec is the number of users, besides Admin, of an organization
+type UsersSpec struct {
+	Count int `yaml:"Count"`
+}
+
+// nodeTemplateData is the data of the Hostname, CommonName and SANS templates
+type nodeTemplateData struct {
+	Prefix     string
+	Index      int
+	Domain     string
+	Hostname   string
+	CommonName string
+}
+
+// LoadConfig reads the crypto material spec from a YAML file
+func LoadConfig(path string) (*Config, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read crypto config [%s]", path)
+	}
+	return ParseConfig(raw)
+}
+
+// ParseConfig parses the YAML crypto material spec
+func ParseConfig(raw []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(raw, config); err != nil {
+		return nil, errors.Wrap(err, "failed to parse crypto config")
+	}
+	return config, nil
+}
+
+// keyAlgorithm returns the key algorithm of the organization
+func (o *OrgSpec) keyAlgorithm(defaultAlgorithm string) (string, error) {
+	algorithm := o.KeyAlgorithm
+	if algorithm == "" {
+		algorithm = defaultAlgorithm
+	}
+	switch strings.ToLower(algorithm) {
+	case "", ECDSA:
+		return ECDSA, nil
+	case SM2:
+		return SM2, nil
+	default:
+		return "", errors.Errorf("unsupported key algorithm [%s] for organization [%s]", algorithm, o.Name)
+	}
+}
+
+// renderOrgSpec expands the node template of the organization into its
+// specs and renders the common names and SANS of all nodes and of the CA
+func renderOrgSpec(orgSpec *OrgSpec, prefix string) error {
+	for i := 0; i < orgSpec.Template.Count; i++ {
+		data := nodeTemplateData{
+			Prefix: prefix,
+			Index:  i + orgSpec.Template.Start,
+			Domain: orgSpec.Domain,
+		}
+
+		hostname, err := parseTemplateWithDefault(orgSpec.Template.Hostname, defaultHostnameTemplate, data)
+		if err != nil {
+			return err
+		}
+
+		orgSpec.Specs = append(orgSpec.Specs, NodeSpec{Hostname: hostname, SANS: orgSpec.Template.SANS})
+	}
+
+	for i := range orgSpec.Specs {
+		if err := renderNodeSpec(orgSpec.Domain, &orgSpec.Specs[i]); err != nil {
+			return err
+		}
+	}
+
+	if orgSpec.CA.Hostname == "" {
+		orgSpec.CA.Hostname = "ca"
+	}
+	return renderNodeSpec(orgSpec.Domain, &orgSpec.CA)
+}
+
+// renderNodeSpec renders the common name and the SANS of the node. The
+// common name and the hostname are always part of the SANS
+func renderNodeSpec(domain string, spec *NodeSpec) error {
+	data := nodeTemplateData{
+		Domain:   domain,
+		Hostname: spec.Hostname,
+	}
+
+	cn, err := parseTemplateWithDefault(spec.CommonName, defaultCommonNameTemplate, data)
+	if err != nil {
+		return err
+	}
+	spec.CommonName = cn
+	data.CommonName = cn
+
+	sans := []string{cn, spec.Hostname}
+	for _, san := range spec.SANS {
+		s, err := parseTemplate(san, data)
+		if err != nil {
+			return err
+		}
+		if !containsString(sans, s) {
+			sans = append(sans, s)
+		}
+	}
+	spec.SANS = sans
+
+	return nil
+}
+
+func parseTemplate(input string, data interface{}) (string, error) {
+	t, err := template.New("parse").Parse(input)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse template [%s]", input)
+	}
+
+	var output bytes.Buffer
+	if err := t.Execute(&output, data); err != nil {
+		return "", errors.Wrapf(err, "failed to execute template [%s]", input)
+	}
+	return output.String(), nil
+}
+
+func parseTemplateWithDefault(input, defaultInput string, data interface{}) (string, error) {
+	if input == "" {
+		input = defaultInput
+	}
+	return parseTemplate(input, data)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// userName returns the name of a user of the organization, such as User1@org1.example.com
+func userName(name string, domain string) string {
+	return fmt.Sprintf("%s@%s", name, domain)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cryptogen generates the crypto material of a test network, in the
+// crypto-config layout of the cryptogen tool, with ECDSA or SM2 keys.
+package cryptogen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const adminBaseName = "Admin"
+
+// Generate generates the crypto material of all the organizations of config
+// under baseDir (peerOrganizations/ and ordererOrganizations/). The existing
+// material of these organizations is replaced
+func Generate(config *Config, baseDir string) error {
+	for _, orgSpec := range config.PeerOrgs {
+		if err := generateOrg(filepath.Join(baseDir, "peerOrganizations"), orgSpec, config.KeyAlgorithm, peer); err != nil {
+			return errors.WithMessage(err, "failed to generate peer organization ["+orgSpec.Name+"]")
+		}
+	}
+
+	for _, orgSpec := range config.OrdererOrgs {
+		if err := generateOrg(filepath.Join(baseDir, "ordererOrganizations"), orgSpec, config.KeyAlgorithm, orderer); err != nil {
+			return errors.WithMessage(err, "failed to generate orderer organization ["+orgSpec.Name+"]")
+		}
+	}
+	return nil
+}
+
+// generateOrg generates the CAs, the MSP, the nodes and the users of a peer
+// or an orderer organization. Orderer organizations only have an admin user
+func generateOrg(baseDir string, orgSpec OrgSpec, defaultAlgorithm string, nodeType int) error {
+	if orgSpec.Domain == "" {
+		return errors.New("domain is required")
+	}
+	algorithm, err := orgSpec.keyAlgorithm(defaultAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	nodesFolder, prefix := "peers", "peer"
+	if nodeType == orderer {
+		nodesFolder, prefix = "orderers", "orderer"
+		orgSpec.Users.Count = 0
+	}
+	if err := renderOrgSpec(&orgSpec, prefix); err != nil {
+		return err
+	}
+
+	orgName := orgSpec.Domain
+	orgDir := filepath.Join(baseDir, orgName)
+	if err := os.RemoveAll(orgDir); err != nil {
+		return errors.Wrapf(err, "failed to remove [%s]", orgDir)
+	}
+	nodesDir := filepath.Join(orgDir, nodesFolder)
+	usersDir := filepath.Join(orgDir, "users")
+	mspDir := filepath.Join(orgDir, "msp")
+
+	signCA, err := newCA(filepath.Join(orgDir, "ca"), orgName, orgSpec.CA, algorithm)
+	if err != nil {
+		return errors.WithMessage(err, "failed to generate signing CA")
+	}
+	tlsCASpec := orgSpec.CA
+	tlsCASpec.CommonName = "tlsca." + orgName
+	tlsCA, err := newCA(filepath.Join(orgDir, "tlsca"), orgName, tlsCASpec, algorithm)
+	if err != nil {
+		return errors.WithMessage(err, "failed to generate TLS CA")
+	}
+
+	if err := generateVerifyingMSP(mspDir, signCA, tlsCA, orgSpec.EnableNodeOUs); err != nil {
+		return errors.WithMessage(err, "failed to generate organization MSP")
+	}
+
+	if err := generateNodes(nodesDir, orgSpec.Specs, signCA, tlsCA, nodeType, orgSpec.EnableNodeOUs); err != nil {
+		return err
+	}
+
+	adminUserName := userName(adminBaseName, orgName)
+	users := []NodeSpec{{CommonName: adminUserName}}
+	for j := 1; j <= orgSpec.Users.Count; j++ {
+		users = append(users, NodeSpec{CommonName: userName("User"+strconv.Itoa(j), orgName)})
+	}
+	if err := generateNodes(usersDir, users, signCA, tlsCA, client, orgSpec.EnableNodeOUs); err != nil {
+		return err
+	}
+
+	// the admin of the organization administers its MSP, its nodes and its users
+	adminCertsDirs := []string{filepath.Join(mspDir, "admincerts")}
+	for _, spec := range orgSpec.Specs {
+		adminCertsDirs = append(adminCertsDirs, filepath.Join(nodesDir, spec.CommonName, "msp", "admincerts"))
+	}
+	for _, spec := range users {
+		adminCertsDirs = append(adminCertsDirs, filepath.Join(usersDir, spec.CommonName, "msp", "admincerts"))
+	}
+	for _, dir := range adminCertsDirs {
+		if err := copyAdminCert(usersDir, dir, adminUserName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generateNodes(baseDir string, nodes []NodeSpec, signCA, tlsCA *ca, nodeType int, enableNodeOUs bool) error {
+	for _, node := range nodes {
+		nodeDir := filepath.Join(baseDir, node.CommonName)
+		if err := generateLocalMSP(nodeDir, node.CommonName, node.SANS, signCA, tlsCA, nodeType, enableNodeOUs); err != nil {
+			return errors.WithMessage(err, "failed to generate the crypto material of ["+node.CommonName+"]")
+		}
+	}
+	return nil
+}
+
+// copyAdminCert replaces the content of adminCertsDir with the certificate of the admin user
+func copyAdminCert(usersDir, adminCertsDir, adminUserName string) error {
+	if err := os.RemoveAll(adminCertsDir); err != nil {
+		return errors.Wrapf(err, "failed to clean [%s]", adminCertsDir)
+	}
+	cert, err := ioutil.ReadFile(certFile(filepath.Join(usersDir, adminUserName, "msp", "signcerts"), adminUserName))
+	if err != nil {
+		return errors.Wrap(err, "failed to read admin certificate")
+	}
+	return writePEM(certFile(adminCertsDir, adminUserName), cert)
+}
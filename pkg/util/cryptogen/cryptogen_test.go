@@ -0,0 +1,263 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptogen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/gm"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm/tls"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/dualstack"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspimpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const testConfig = `
+OrdererOrgs:
+  - Name: OrdererOrg
+    Domain: example.com
+    Specs:
+      - Hostname: orderer
+
+PeerOrgs:
+  - Name: Org1
+    Domain: org1.example.com
+    EnableNodeOUs: true
+    CA:
+      Country: CN
+    Template:
+      Count: 2
+      SANS:
+        - "127.0.0.1"
+        - "{{.Hostname}}.alt.{{.Domain}}"
+    Users:
+      Count: 1
+`
+
+func TestGenerate(t *testing.T) {
+	for _, algorithm := range []string{ECDSA, SM2} {
+		t.Run(algorithm, func(t *testing.T) {
+			config, err := ParseConfig([]byte(testConfig))
+			require.NoError(t, err)
+			config.KeyAlgorithm = algorithm
+
+			dir, err := ioutil.TempDir("", "cryptogen")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			require.NoError(t, Generate(config, dir))
+
+			org := filepath.Join(dir, "peerOrganizations", "org1.example.com")
+			caCert := readCert(t, filepath.Join(org, "ca", "ca.org1.example.com-cert.pem"))
+			assert.Equal(t, []string{"CN"}, caCert.Subject.Country)
+			tlsCACert := readCert(t, filepath.Join(org, "tlsca", "tlsca.org1.example.com-cert.pem"))
+			assert.FileExists(t, filepath.Join(org, "msp", "cacerts", "ca.org1.example.com-cert.pem"))
+			assert.FileExists(t, filepath.Join(org, "msp", "tlscacerts", "tlsca.org1.example.com-cert.pem"))
+			assert.FileExists(t, filepath.Join(org, "msp", "admincerts", "Admin@org1.example.com-cert.pem"))
+
+			for _, peer := range []string{"peer0.org1.example.com", "peer1.org1.example.com"} {
+				peerDir := filepath.Join(org, "peers", peer)
+				cert := checkIdentity(t, algorithm, filepath.Join(peerDir, "msp"), peer, caCert)
+				assert.Equal(t, []string{"peer"}, cert.Subject.OrganizationalUnit)
+
+				tlsCert := readCert(t, filepath.Join(peerDir, "tls", "server.crt"))
+				_, err := tlsCert.Verify([]*verifier.Certificate{tlsCACert}, nil)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{peer, peer[:5], peer[:5] + ".alt.org1.example.com"}, tlsCert.SM2().DNSNames)
+				assert.Len(t, tlsCert.SM2().IPAddresses, 1)
+				assert.FileExists(t, filepath.Join(peerDir, "tls", "server.key"))
+				assert.FileExists(t, filepath.Join(peerDir, "tls", "ca.crt"))
+			}
+
+			for _, user := range []string{"Admin@org1.example.com", "User1@org1.example.com"} {
+				userDir := filepath.Join(org, "users", user)
+				cert := checkIdentity(t, algorithm, filepath.Join(userDir, "msp"), user, caCert)
+				assert.Equal(t, []string{"client"}, cert.Subject.OrganizationalUnit)
+				assert.FileExists(t, filepath.Join(userDir, "tls", "client.crt"))
+				assert.FileExists(t, filepath.Join(userDir, "tls", "client.key"))
+			}
+
+			raw, err := ioutil.ReadFile(filepath.Join(org, "msp", "config.yaml"))
+			require.NoError(t, err)
+			mspConfig := &msp.Configuration{}
+			require.NoError(t, yaml.Unmarshal(raw, mspConfig))
+			require.NotNil(t, mspConfig.NodeOUs)
+			assert.True(t, mspConfig.NodeOUs.Enable)
+			assert.Equal(t, "cacerts/ca.org1.example.com-cert.pem", mspConfig.NodeOUs.PeerOUIdentifier.Certificate)
+
+			checkSDKLoading(t, algorithm, org, "Org1MSP")
+
+			ordererOrg := filepath.Join(dir, "ordererOrganizations", "example.com")
+			ordererCA := readCert(t, filepath.Join(ordererOrg, "ca", "ca.example.com-cert.pem"))
+			checkIdentity(t, algorithm, filepath.Join(ordererOrg, "orderers", "orderer.example.com", "msp"), "orderer.example.com", ordererCA)
+			checkIdentity(t, algorithm, filepath.Join(ordererOrg, "users", "Admin@example.com", "msp"), "Admin@example.com", ordererCA)
+			_, err = os.Stat(filepath.Join(ordererOrg, "users", "User1@example.com"))
+			assert.True(t, os.IsNotExist(err), "orderer organizations only have an admin user")
+		})
+	}
+}
+
+func TestGenerateInvalidAlgorithm(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig))
+	require.NoError(t, err)
+	config.PeerOrgs[0].KeyAlgorithm = "rsa"
+
+	dir, err := ioutil.TempDir("", "cryptogen")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = Generate(config, dir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported key algorithm [rsa]")
+}
+
+// checkIdentity checks that the signing certificate of the MSP chains to the
+// CA and that its private key is found in the key store by the SKI of the
+// certificate, as the SDK loads it
+func checkIdentity(t *testing.T, algorithm, mspDir, name string, caCert *verifier.Certificate) *verifier.Certificate {
+	certPath := filepath.Join(mspDir, "signcerts", name+"-cert.pem")
+	certPEM, err := ioutil.ReadFile(certPath)
+	require.NoError(t, err)
+	cert := readCert(t, certPath)
+	assert.Equal(t, name, cert.Subject.CommonName)
+	_, err = cert.Verify([]*verifier.Certificate{caCert}, nil)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(mspDir, "cacerts", caCert.Subject.CommonName+"-cert.pem"))
+	assert.Len(t, readDir(t, filepath.Join(mspDir, "admincerts")), 1)
+
+	suite := newCryptoSuite(t, algorithm, filepath.Join(mspDir, "keystore"))
+	key, err := cryptoutil.GetPrivateKeyFromCert(certPEM, suite)
+	require.NoError(t, err)
+	assert.True(t, key.Private())
+	assert.Len(t, readDir(t, filepath.Join(mspDir, "keystore")), 1)
+	return cert
+}
+
+// checkSDKLoading loads the user and the MSP of the organization generated under org as the SDK
+// does: the signing identity through the identity manager, and the MSP through the channel membership
+func checkSDKLoading(t *testing.T, algorithm, org, mspID string) {
+	domain := filepath.Base(org)
+	userMSPDir := filepath.Join(org, "users", "User1@"+domain, "msp")
+	suite := newCryptoSuite(t, algorithm, filepath.Join(userMSPDir, "keystore"))
+
+	endpointConfig := &testEndpointConfig{
+		EndpointConfig: mocks.NewMockEndpointConfig(),
+		orgs: map[string]fab.OrganizationConfig{
+			"org1": {MSPID: mspID, CryptoPath: filepath.Join(org, "users", "{username}@"+domain, "msp")},
+		},
+	}
+	mgr, err := mspimpl.NewIdentityManager("Org1", mspimpl.NewMemoryUserStore(), suite, endpointConfig)
+	require.NoError(t, err)
+	signingIdentity, err := mgr.GetSigningIdentity("User1")
+	require.NoError(t, err)
+	assert.Equal(t, mspID, signingIdentity.Identifier().MSPID)
+	assert.True(t, signingIdentity.PrivateKey().Private())
+	serializedID, err := signingIdentity.Serialize()
+	require.NoError(t, err)
+
+	mspDir := filepath.Join(org, "msp")
+	fabricConfig := &mb.FabricMSPConfig{
+		Name:         mspID,
+		RootCerts:    readPEMs(t, filepath.Join(mspDir, "cacerts")),
+		Admins:       readPEMs(t, filepath.Join(mspDir, "admincerts")),
+		TlsRootCerts: readPEMs(t, filepath.Join(mspDir, "tlscacerts")),
+	}
+	mspConfig, err := proto.Marshal(fabricConfig)
+	require.NoError(t, err)
+	channelConfig := mocks.NewMockChannelCfg("mychannel")
+	channelConfig.MockMSPs = []*mb.MSPConfig{{Config: mspConfig}}
+	certPool, err := tls.NewCertPool(false)
+	require.NoError(t, err)
+	membershipContext := membership.Context{
+		Providers:      &testProviders{MockProviderContext: mocks.NewMockProviderContext(), suite: suite},
+		EndpointConfig: &mocks.MockConfig{CustomTLSCACertPool: certPool},
+	}
+	channelMembership, err := membership.New(membershipContext, channelConfig)
+	require.NoError(t, err)
+	assert.True(t, channelMembership.ContainsMSP(mspID))
+	assert.NoError(t, channelMembership.Validate(serializedID))
+}
+
+// testEndpointConfig serves the organizations of the generated tree
+type testEndpointConfig struct {
+	fab.EndpointConfig
+	orgs map[string]fab.OrganizationConfig
+}
+
+func (c *testEndpointConfig) NetworkConfig() *fab.NetworkConfig {
+	return &fab.NetworkConfig{Organizations: c.orgs}
+}
+
+// testProviders provides the crypto suite loading the generated keys
+type testProviders struct {
+	*mocks.MockProviderContext
+	suite core.CryptoSuite
+}
+
+func (p *testProviders) CryptoSuite() core.CryptoSuite {
+	return p.suite
+}
+
+// newCryptoSuite returns a suite finding both the ECDSA and SM2 keys in keystore,
+// defaulting to the family of algorithm
+func newCryptoSuite(t *testing.T, algorithm, keystore string) core.CryptoSuite {
+	swCSP, err := sw.NewDefaultSecurityLevel(keystore)
+	require.NoError(t, err)
+	gmCSP, err := gm.NewDefaultSecurityLevel(keystore)
+	require.NoError(t, err)
+	family := dualstack.FamilySW
+	if algorithm == SM2 {
+		family = dualstack.FamilyGM
+	}
+	suite, err := dualstack.New(family, map[string]core.CryptoSuite{
+		dualstack.FamilySW: wrapper.NewCryptoSuite(swCSP),
+		dualstack.FamilyGM: wrapper.NewCryptoSuite(gmCSP),
+	})
+	require.NoError(t, err)
+	return suite
+}
+
+func readPEMs(t *testing.T, dir string) [][]byte {
+	var pems [][]byte
+	for _, file := range readDir(t, dir) {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		require.NoError(t, err)
+		pems = append(pems, raw)
+	}
+	return pems
+}
+
+func readCert(t *testing.T, path string) *verifier.Certificate {
+	raw, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	certs, err := verifier.ParsePEMCertificates(raw)
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	return certs[0]
+}
+
+func readDir(t *testing.T, dir string) []os.FileInfo {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	return files
+}
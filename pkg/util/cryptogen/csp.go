@@ -0,0 +1,133 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptogen
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/gm"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/signer"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/tjfoc/gmsm/sm2"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+	"github.com/pkg/errors"
+)
+
+// privateKey is a private key generated in a file based key store, as
+// <SKI>_sk, together with the BCCSP that signs with it
+type privateKey struct {
+	algorithm string
+	csp       bccsp.BCCSP
+	key       bccsp.Key
+	// public is the *ecdsa.PublicKey or *sm2.PublicKey of the key
+	public interface{}
+}
+
+// generatePrivateKey generates a private key of the given algorithm and
+// stores it in keystorePath
+func generatePrivateKey(keystorePath string, algorithm string) (*privateKey, error) {
+	var csp bccsp.BCCSP
+	var opts bccsp.KeyGenOpts
+	var err error
+	switch algorithm {
+	case SM2:
+		csp, err = gm.NewDefaultSecurityLevel(keystorePath)
+		opts = &bccsp.GMSM2KeyGenOpts{Temporary: false}
+	default:
+		csp, err = sw.NewDefaultSecurityLevel(keystorePath)
+		opts = &bccsp.ECDSAP256KeyGenOpts{Temporary: false}
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create BCCSP")
+	}
+
+	key, err := csp.KeyGen(opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate %s key", algorithm)
+	}
+
+	pub, err := key.PublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get public key")
+	}
+	raw, err := pub.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal public key")
+	}
+
+	priv := &privateKey{algorithm: algorithm, csp: csp, key: key}
+	if algorithm == SM2 {
+		priv.public, err = sm2.ParseSm2PublicKey(raw)
+	} else {
+		priv.public, err = utils.DERToPublicKey(raw)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	return priv, nil
+}
+
+// createCertificate signs template, for the public key pub, with the key of
+// the parent certificate and returns it PEM encoded
+func (k *privateKey) createCertificate(template, parent *x509.Certificate, pub interface{}) ([]byte, error) {
+	if k.algorithm == SM2 {
+		pubKey, ok := pub.(*sm2.PublicKey)
+		if !ok {
+			return nil, errors.New("an SM2 CA can only sign SM2 public keys")
+		}
+		sm2Template := gm.ParseX509Certificate2Sm2(template)
+		sm2Template.PublicKey = pubKey
+		cert, err := gm.CreateCertificateToMem(sm2Template, gm.ParseX509Certificate2Sm2(parent), k.key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create SM2 certificate")
+		}
+		return cert, nil
+	}
+
+	s, err := signer.New(wrapper.NewCryptoSuite(k.csp), wrapper.GetKey(k.key))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create signer")
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create certificate")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// keyFile returns the path of the private key file in the key store
+func keyFile(keystorePath string) (string, error) {
+	files, err := ioutil.ReadDir(keystorePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read key store [%s]", keystorePath)
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), "_sk") {
+			return filepath.Join(keystorePath, f.Name()), nil
+		}
+	}
+	return "", errors.Errorf("no private key found in [%s]", keystorePath)
+}
+
+// writePEM writes the PEM data to path, creating the directory if needed
+func writePEM(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory for [%s]", path)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write [%s]", path)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptogen
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// node types, which are also the node OUs of peers and clients
+const (
+	client = iota
+	peer
+	orderer
+)
+
+var nodeOUs = map[int]string{
+	client: "client",
+	peer:   "peer",
+}
+
+// generateLocalMSP generates the MSP (msp/) and the TLS material (tls/) of a
+// node or a user in baseDir
+func generateLocalMSP(baseDir, name string, sans []string, signCA, tlsCA *ca, nodeType int, enableNodeOUs bool) error {
+	mspDir := filepath.Join(baseDir, "msp")
+	tlsDir := filepath.Join(baseDir, "tls")
+	if err := createFolderStructure(mspDir, true); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tlsDir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory [%s]", tlsDir)
+	}
+
+	// signing identity
+	keystore := filepath.Join(mspDir, "keystore")
+	priv, err := generatePrivateKey(keystore, signCA.algorithm)
+	if err != nil {
+		return err
+	}
+
+	var ous []string
+	if ou, ok := nodeOUs[nodeType]; ok && enableNodeOUs {
+		ous = []string{ou}
+	}
+	cert, err := signCA.signCertificate(filepath.Join(mspDir, "signcerts"), name, ous, nil, priv.public,
+		x509.KeyUsageDigitalSignature, []x509.ExtKeyUsage{})
+	if err != nil {
+		return err
+	}
+
+	if err := writeCACerts(mspDir, signCA, tlsCA); err != nil {
+		return err
+	}
+	// the admin certificate of the organization replaces this one once it is generated
+	if err := writePEM(certFile(filepath.Join(mspDir, "admincerts"), name), cert); err != nil {
+		return err
+	}
+	if enableNodeOUs {
+		if err := exportConfig(mspDir, signCA); err != nil {
+			return err
+		}
+	}
+
+	// TLS identity, the key is generated in a scratch key store then moved into tls/
+	tlsKeystore, err := ioutil.TempDir(tlsDir, "keystore")
+	if err != nil {
+		return errors.Wrap(err, "failed to create TLS key store")
+	}
+	defer os.RemoveAll(tlsKeystore)
+
+	tlsPriv, err := generatePrivateKey(tlsKeystore, tlsCA.algorithm)
+	if err != nil {
+		return err
+	}
+	_, err = tlsCA.signCertificate(tlsDir, name, nil, sans, tlsPriv.public,
+		x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment,
+		[]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth})
+	if err != nil {
+		return err
+	}
+
+	prefix := "server"
+	if nodeType == client {
+		prefix = "client"
+	}
+	if err := writePEM(filepath.Join(tlsDir, "ca.crt"), tlsCA.certPEM); err != nil {
+		return err
+	}
+	if err := os.Rename(certFile(tlsDir, name), filepath.Join(tlsDir, prefix+".crt")); err != nil {
+		return errors.Wrap(err, "failed to rename TLS certificate")
+	}
+	tlsKey, err := keyFile(tlsKeystore)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tlsKey, filepath.Join(tlsDir, prefix+".key")); err != nil {
+		return errors.Wrap(err, "failed to move TLS key")
+	}
+	return nil
+}
+
+// generateVerifyingMSP generates the MSP of an organization, which only holds
+// the CA certificates, in baseDir
+func generateVerifyingMSP(baseDir string, signCA, tlsCA *ca, enableNodeOUs bool) error {
+	if err := createFolderStructure(baseDir, false); err != nil {
+		return err
+	}
+	if err := writeCACerts(baseDir, signCA, tlsCA); err != nil {
+		return err
+	}
+	if enableNodeOUs {
+		return exportConfig(baseDir, signCA)
+	}
+	return nil
+}
+
+func createFolderStructure(rootDir string, local bool) error {
+	folders := []string{
+		filepath.Join(rootDir, "admincerts"),
+		filepath.Join(rootDir, "cacerts"),
+		filepath.Join(rootDir, "tlscacerts"),
+	}
+	if local {
+		folders = append(folders, filepath.Join(rootDir, "keystore"), filepath.Join(rootDir, "signcerts"))
+	}
+
+	for _, folder := range folders {
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			return errors.Wrapf(err, "failed to create directory [%s]", folder)
+		}
+	}
+	return nil
+}
+
+func writeCACerts(mspDir string, signCA, tlsCA *ca) error {
+	if err := writePEM(certFile(filepath.Join(mspDir, "cacerts"), signCA.name), signCA.certPEM); err != nil {
+		return err
+	}
+	return writePEM(certFile(filepath.Join(mspDir, "tlscacerts"), tlsCA.name), tlsCA.certPEM)
+}
+
+// exportConfig writes the config.yaml that enables the node OUs of the MSP
+func exportConfig(mspDir string, signCA *ca) error {
+	caFile := filepath.ToSlash(filepath.Join("cacerts", signCA.name+"-cert.pem"))
+	config := &msp.Configuration{
+		NodeOUs: &msp.NodeOUs{
+			Enable: true,
+			ClientOUIdentifier: &msp.OrganizationalUnitIdentifiersConfiguration{
+				Certificate:                  caFile,
+				OrganizationalUnitIdentifier: nodeOUs[client],
+			},
+			PeerOUIdentifier: &msp.OrganizationalUnitIdentifiersConfiguration{
+				Certificate:                  caFile,
+				OrganizationalUnitIdentifier: nodeOUs[peer],
+			},
+		},
+	}
+
+	raw, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal MSP config")
+	}
+	if err := ioutil.WriteFile(filepath.Join(mspDir, "config.yaml"), raw, 0644); err != nil {
+		return errors.Wrap(err, "failed to write MSP config")
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 
 package configless
 
+import "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+
 // cryptoconfig_override_test.go is an example of programmatically configuring the client by injecting instances that implement CryptoSuiteConfig's functions (representing the client's crypto configs).
 // For the sake of overriding CryptoSuiteConfig in the integration tests, the implementations below return similar values to what is found in /test/fixtures/config/config_e2e.yaml
 // application developers can fully override these functions to load configs in any way that suit their application need
@@ -21,6 +23,7 @@ var (
 	securityProviderPinImpl     = &exampleSecurityProviderPin{}
 	securityProviderLabelImpl   = &exampleSecurityProviderLabel{}
 	exampleKeyStorePathImpl     = &exampleKeyStorePath{}
+	keyStorePasswordImpl        = cryptosuite.KeyStorePasswordFunc(func() ([]byte, error) { return nil, nil })
 	cryptoConfigImpls           = []interface{}{
 		isSecurityEnabledImpl,
 		securityAlgorithmImpl,
@@ -31,6 +34,7 @@ var (
 		securityProviderPinImpl,
 		securityProviderLabelImpl,
 		exampleKeyStorePathImpl,
+		keyStorePasswordImpl,
 	}
 )
 
@@ -30,6 +30,7 @@ var (
 	caClientCertImpl        = &exampleCaClientCert{}
 	caKeyStorePathImpl      = &exampleCaKeyStorePath{}
 	credentialStorePathImpl = &exampleCredentialStorePath{}
+	sm2UIDImpl              = &exampleSM2UID{}
 
 	identityConfigImpls = []interface{}{
 		clientImpl,
@@ -39,6 +40,7 @@ var (
 		caClientCertImpl,
 		caKeyStorePathImpl,
 		credentialStorePathImpl,
+		sm2UIDImpl,
 	}
 )
 
@@ -183,3 +185,10 @@ type exampleCredentialStorePath struct{}
 func (m *exampleCredentialStorePath) CredentialStorePath() string {
 	return "/tmp/state-store"
 }
+
+type exampleSM2UID struct{}
+
+// SM2UID returns no user ID: the identities of the example network sign with the default SM2 user ID
+func (m *exampleSM2UID) SM2UID(mspID, id string) ([]byte, bool) {
+	return nil, false
+}
@@ -6,6 +6,8 @@
 #
 
 CRYPTOGEN_CMD="${CRYPTOGEN_CMD:-cryptogen}"
+# CRYPTOGEN_OPTS are extra generate arguments, such as --algorithm=sm2 for the Go generator (pkg/util/cryptogen)
+CRYPTOGEN_OPTS="${CRYPTOGEN_OPTS:-}"
 FIXTURES_PATH="${FIXTURES_PATH:-/opt/gopath/src/github.com/hyperledger/fabric-sdk-go/test/fixtures}"
 CONFIG_DIR="${CONFIG_DIR:-config}"
 
@@ -29,7 +31,7 @@ echo Clearing old crypto directory ...
 rm -Rf ${FIXTURES_PATH}/${FABRIC_VERSION_DIR}/crypto-config
 
 echo Running cryptogen ...
-${CRYPTOGEN_CMD} generate --config=${FIXTURES_PATH}/${FABRIC_VERSION_DIR}/config/cryptogen.yaml --output=${FIXTURES_PATH}/${FABRIC_VERSION_DIR}/crypto-config
+${CRYPTOGEN_CMD} generate ${CRYPTOGEN_OPTS} --config=${FIXTURES_PATH}/${FABRIC_VERSION_DIR}/config/cryptogen.yaml --output=${FIXTURES_PATH}/${FABRIC_VERSION_DIR}/crypto-config
 
 # Remove unneeded ca MSP
 for org in ${peerOrgs[@]}; do